@@ -0,0 +1,88 @@
+package bacalhau
+
+import (
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	approveLong = templates.LongDesc(i18n.T(`
+		Approve or reject a job that the admission policy of the requester node holds for approval.
+
+		Approved jobs are scheduled, and rejected jobs fail with the given reason. Only the client
+		configured as the job approver of the requester node, through BACALHAU_JOB_APPROVER, can
+		approve jobs.
+`))
+
+	//nolint:lll // Documentation
+	approveExample = templates.Examples(i18n.T(`
+		# Approve a job waiting for an approval
+		bacalhau approve 51225160-807e-48b8-88c9-28311c7899e1
+
+		# Reject a job, with a short ID
+		bacalhau approve --reject --reason "image is not allowed" ebd9bf2f
+`))
+)
+
+type ApproveOptions struct {
+	Reject bool   // Reject the job instead of approving it
+	Reason string // The reason given to the job
+}
+
+func NewApproveOptions() *ApproveOptions {
+	return &ApproveOptions{}
+}
+
+func newApproveCmd() *cobra.Command {
+	OA := NewApproveOptions()
+
+	approveCmd := &cobra.Command{
+		Use:               "approve [id]",
+		Short:             "Approve or reject a job waiting for an approval",
+		Long:              approveLong,
+		Example:           approveExample,
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkVersion,
+		PreRun:            applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return approve(cmd, cmdArgs, OA)
+		},
+	}
+
+	approveCmd.PersistentFlags().BoolVar(
+		&OA.Reject, "reject", OA.Reject,
+		`Reject the job instead of approving it`,
+	)
+	approveCmd.PersistentFlags().StringVar(
+		&OA.Reason, "reason", OA.Reason,
+		`The reason recorded in the job history`,
+	)
+	return approveCmd
+}
+
+func approve(cmd *cobra.Command, cmdArgs []string, OA *ApproveOptions) error {
+	ctx := cmd.Context()
+
+	reason := OA.Reason
+	if reason == "" && OA.Reject {
+		reason = "rejected at user request"
+	} else if reason == "" {
+		reason = "approved at user request"
+	}
+
+	err := GetAPIClient().Approve(ctx, cmdArgs[0], !OA.Reject, reason)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error approving job: %s", err), 1)
+		return err
+	}
+
+	if OA.Reject {
+		cmd.Printf("Job %s rejected\n", cmdArgs[0])
+	} else {
+		cmd.Printf("Job %s approved\n", cmdArgs[0])
+	}
+	return nil
+}
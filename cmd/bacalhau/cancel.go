@@ -13,6 +13,8 @@ import (
 var (
 	cancelLong = templates.LongDesc(i18n.T(`
 		Cancel a previously submitted job.
+
+		When given the parent ID of a job template, all its jobs that are not completed yet are canceled.
 `))
 
 	//nolint:lll // Documentation
@@ -22,6 +24,9 @@ var (
 
 		# Cancel a job, with a short ID.
 		bacalhau cancel ebd9bf2f
+
+		# Cancel the jobs submitted from a job template
+		bacalhau cancel 5fc0ed1e-6fd7-4e15-8a4b-3a7d2e8e6f4c
 `))
 )
 
@@ -120,7 +125,23 @@ func cancel(cmd *cobra.Command, cmdArgs []string, options *CancelOptions) error
 	}
 
 	if !jobFound {
-		spinner.Done(false)
+		// the ID might be the parent ID of a job template
+		states, cancelErr := apiClient.CancelChildren(ctx, requestedJobID, "Canceled at user request")
+		if cancelErr != nil {
+			spinner.Done(false)
+			Fatal(cmd, fmt.Sprintf("Unknown error trying to cancel jobs of parent %s: %+v", requestedJobID, cancelErr), 1)
+			return nil
+		}
+		if states == nil {
+			spinner.Done(false)
+			Fatal(cmd, bacerrors.NewJobNotFound(requestedJobID).Error(), 1)
+			return nil
+		}
+		spinner.Done(true)
+		for _, state := range states {
+			cmd.Printf("Job successfully canceled. Job ID: %s\n", state.JobID)
+		}
+		return nil
 	}
 
 	// Check status to make sure there is something to be canceled. If it is currently
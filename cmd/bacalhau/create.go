@@ -26,6 +26,11 @@ var (
 		Create a job from a file or from stdin.
 
 		JSON and YAML formats are accepted.
+
+		A job template, which has a Spec and a Matrix of parameter values, submits a job
+		for every combination of the parameters, where the {{name}} placeholders of each
+		parameter are replaced by its value. The jobs share a parent ID, which can be
+		used to list or cancel them together.
 	`))
 	//nolint:lll // Documentation
 	createExample = templates.Examples(i18n.T(`
@@ -33,7 +38,10 @@ var (
 		bacalhau create ./job.yaml
 
 		# Create a new job from an already executed job
-		bacalhau describe 6e51df50 | bacalhau create -`))
+		bacalhau describe 6e51df50 | bacalhau create -
+
+		# Create a job for every combination of the parameters of a job template
+		bacalhau create ./sweep.yaml`))
 )
 
 type CreateOptions struct {
@@ -129,6 +137,11 @@ func create(cmd *cobra.Command, cmdArgs []string, OC *CreateOptions) error { //n
 		return err
 	}
 
+	// If it's a job template, every combination of its parameters is submitted as a job
+	if _, isTemplate := rawMap["Matrix"]; isTemplate {
+		return createFromTemplate(cmd, byteResult, OC)
+	}
+
 	// If it's a JobWithInfo, we need to convert it to a Job
 	if _, isJobWithInfo := rawMap["Job"]; isJobWithInfo {
 		err = model.YAMLUnmarshalWithMax(byteResult, &jwi)
@@ -253,3 +266,89 @@ func create(cmd *cobra.Command, cmdArgs []string, OC *CreateOptions) error { //n
 
 	return nil
 }
+
+// createFromTemplate submits a job for every combination of the parameters of a job template,
+// and waits for all of them to complete unless told otherwise.
+func createFromTemplate(cmd *cobra.Command, byteResult []byte, OC *CreateOptions) error {
+	ctx := cmd.Context()
+
+	j, err := model.NewJobWithSaneProductionDefaults()
+	if err != nil {
+		return err
+	}
+	template := &model.JobTemplate{Spec: j.Spec}
+	if err = model.YAMLUnmarshalWithMax(byteResult, template); err != nil {
+		Fatal(cmd, userstrings.JobSpecBad, 1)
+		return err
+	}
+
+	err = jobutils.VerifyJobTemplateCreatePayload(ctx, &model.JobTemplateCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: j.APIVersion,
+		Template:   template,
+	})
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error verifying job template: %s", err), 1)
+		return err
+	}
+
+	if OC.DryRun {
+		var specs []model.Spec
+		specs, err = jobutils.ExpandJobTemplate(*template)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error expanding job template: %s", err), 1)
+			return err
+		}
+		var yamlBytes []byte
+		yamlBytes, err = yaml.Marshal(specs)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error converting jobs to yaml: %s", err), 1)
+			return err
+		}
+		cmd.Print(string(yamlBytes))
+		return nil
+	}
+
+	apiClient := GetAPIClient()
+	parentID, jobs, err := apiClient.SubmitTemplate(ctx, template)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error submitting job template: %s", err), 1)
+		return err
+	}
+
+	cmd.Printf("Parent ID: %s\n", parentID)
+	for _, child := range jobs {
+		cmd.Printf("Job ID: %s\n", child.Metadata.ID)
+	}
+	if !OC.RunTimeSettings.WaitForJobToFinish {
+		return nil
+	}
+
+	var children []*model.JobWithInfo
+	waiter := &system.FunctionWaiter{
+		Name:        "wait for jobs of template",
+		MaxAttempts: OC.RunTimeSettings.WaitForJobTimeoutSecs,
+		Delay:       time.Second,
+		Handler: func() (bool, error) {
+			children, err = apiClient.ListChildren(ctx, parentID)
+			if err != nil {
+				return false, err
+			}
+			for _, child := range children {
+				if !child.State.State.IsTerminal() {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+	}
+	if err = waiter.Wait(ctx); err != nil {
+		Fatal(cmd, fmt.Sprintf("Error waiting for jobs of parent %s: %s", parentID, err), 1)
+		return err
+	}
+
+	for _, child := range children {
+		cmd.Printf("Job %s: %s\n", child.Job.Metadata.ID, child.State.State)
+	}
+	return nil
+}
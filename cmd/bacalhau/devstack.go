@@ -120,6 +120,7 @@ func newDevStackCmd() *cobra.Command {
 
 	setupJobSelectionCLIFlags(devstackCmd, OS)
 	setupCapacityManagerCLIFlags(devstackCmd, OS)
+	setupPricingCLIFlags(devstackCmd, OS)
 
 	return devstackCmd
 }
@@ -162,7 +163,10 @@ func runDevstack(cmd *cobra.Command, ODs *devstack.DevStackOptions, OS *ServeOpt
 	}
 
 	computeConfig := getComputeConfig(OS)
-	requestorConfig := getRequesterConfig(OS)
+	requestorConfig, err := getRequesterConfig(OS)
+	if err != nil {
+		Fatal(cmd, err.Error(), 1)
+	}
 	if ODs.LocalNetworkLotus {
 		cmd.Println("Note that starting up the Lotus node can take many minutes!")
 	}
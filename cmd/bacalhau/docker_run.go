@@ -71,6 +71,20 @@ type DockerRunOptions struct {
 	Labels           []string // Labels for the job on the Bacalhau network (for searching)
 	NodeSelector     string   // Selector (label query) to filter nodes on which this job can be executed
 
+	Priority model.PriorityClass // Priority class of the job in the requester queue
+
+	Timeouts model.JobTimeouts // Timeouts in seconds of each phase of the job's executions
+
+	MaxPrice float64 // Maximum price to pay for each execution of the job
+
+	Gang bool // Start all the executions of the job together
+
+	Reservation string // Token of the capacity reservation the job runs on
+
+	ShardingGlobPattern string // Glob pattern of the input items to split across executions
+	ShardingBasePath    string // Base path of relative sharding glob patterns
+	ShardingBatchSize   int    // Number of input items processed by each execution
+
 	Image      string   // Image to execute
 	Entrypoint []string // Entrypoint to the docker image
 
@@ -190,10 +204,34 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		&ODR.MinBids, "min-bids", ODR.MinBids,
 		`Minimum number of bids that must be received before concurrency-many bids will be accepted (at random)`,
 	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.MaxPrice, "max-price", ODR.MaxPrice,
+		`Maximum price to pay for each execution of the job (0 for no limit). The cheapest bids are accepted once min-bids bids are received`,
+	)
+	dockerRunCmd.PersistentFlags().BoolVar(
+		&ODR.Gang, "gang", ODR.Gang,
+		`Only start the job once concurrency-many nodes can run it together. Each node is given the addresses of the others`,
+	)
 	dockerRunCmd.PersistentFlags().Float64Var(
 		&ODR.Timeout, "timeout", ODR.Timeout,
 		`Job execution timeout in seconds (e.g. 300 for 5 minutes and 0.1 for 100ms)`,
 	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.Timeouts.Bid, "bid-timeout", ODR.Timeouts.Bid,
+		`Seconds a node can take to bid on the job, and its bid can wait to be accepted, before another node is asked instead`,
+	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.Timeouts.Execution, "execution-timeout", ODR.Timeouts.Execution,
+		`Seconds a node can take to run the job before it is canceled and the job is retried on another node`,
+	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.Timeouts.Verification, "verification-timeout", ODR.Timeouts.Verification,
+		`Seconds the results of a node can wait to be verified before they are discarded`,
+	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.Timeouts.Publish, "publish-timeout", ODR.Timeouts.Publish,
+		`Seconds a node can take to publish the results before it is canceled and the job is retried on another node`,
+	)
 	dockerRunCmd.PersistentFlags().StringVar(
 		&ODR.CPU, "cpu", ODR.CPU,
 		`Job CPU cores (e.g. 500m, 2, 8).`,
@@ -239,6 +277,30 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		`Selector (label query) to filter nodes on which this job can be executed, supports '=', '==', and '!='.(e.g. -s key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.`, //nolint:lll // Documentation, ok if long.
 	)
 
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.Reservation, "reservation", ODR.Reservation,
+		`Token of a capacity reservation to run the job on. The job only runs on the nodes holding the reservation`,
+	)
+
+	dockerRunCmd.PersistentFlags().Var(
+		PriorityClassFlag(&ODR.Priority), "priority",
+		fmt.Sprintf(`Priority class of the job in the requester and compute node queues. `+
+			`Running jobs of a lower priority can be preempted. One of %s.`, strings.Join(model.PriorityClassNames(), ", ")),
+	)
+
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.ShardingGlobPattern, "sharding-glob-pattern", ODR.ShardingGlobPattern,
+		`Split the inputs matching this glob pattern across executions (e.g. '/inputs/*.jpg').`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.ShardingBasePath, "sharding-base-path", ODR.ShardingBasePath,
+		`Base path of relative sharding glob patterns (default '/inputs').`,
+	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.ShardingBatchSize, "sharding-batch-size", ODR.ShardingBatchSize,
+		`Number of input items processed by each execution of a sharded job.`,
+	)
+
 	dockerRunCmd.PersistentFlags().BoolVar(
 		&ODR.FilPlus, "filplus", ODR.FilPlus,
 		`Mark the job as a candidate for moderation for FIL+ rewards.`,
@@ -424,5 +486,16 @@ func CreateJob(ctx context.Context, cmdArgs []string, odr *DockerRunOptions) (*m
 		return &model.Job{}, errors.Wrap(err, "CreateJobSpecAndDeal")
 	}
 
+	j.Spec.Sharding = model.JobShardingConfig{
+		GlobPattern: odr.ShardingGlobPattern,
+		BasePath:    odr.ShardingBasePath,
+		BatchSize:   odr.ShardingBatchSize,
+	}
+	j.Spec.Priority = odr.Priority
+	j.Spec.Timeouts = odr.Timeouts
+	j.Spec.Deal.MaxPrice = odr.MaxPrice
+	j.Spec.Deal.Gang = odr.Gang
+	j.Spec.Reservation = odr.Reservation
+
 	return j, nil
 }
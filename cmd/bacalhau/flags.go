@@ -228,6 +228,24 @@ func NetworkFlag(value *model.Network) *ValueFlag[model.Network] {
 	}
 }
 
+func PriorityClassFlag(value *model.PriorityClass) *ValueFlag[model.PriorityClass] {
+	return &ValueFlag[model.PriorityClass]{
+		value:    value,
+		parser:   model.ParsePriorityClass,
+		stringer: func(p *model.PriorityClass) string { return p.String() },
+		typeStr:  "priority-class",
+	}
+}
+
+func ScheduleOverlapPolicyFlag(value *model.ScheduleOverlapPolicy) *ValueFlag[model.ScheduleOverlapPolicy] {
+	return &ValueFlag[model.ScheduleOverlapPolicy]{
+		value:    value,
+		parser:   model.ParseScheduleOverlapPolicy,
+		stringer: func(p *model.ScheduleOverlapPolicy) string { return p.String() },
+		typeStr:  "overlap-policy",
+	}
+}
+
 func LoggingFlag(value *logger.LogMode) *ValueFlag[logger.LogMode] {
 	return &ValueFlag[logger.LogMode]{
 		value:    value,
@@ -0,0 +1,150 @@
+package bacalhau
+
+import (
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	jobUpdateLong = templates.LongDesc(i18n.T(`
+		Update the concurrency, min bids or timeout of a job that is still in progress.
+
+		More nodes are asked to bid on the job right away when its concurrency is raised, and the most
+		recently started executions are canceled when it is lowered. The timeout can only be updated while
+		the job is waiting to run, before any node was asked to execute it.
+`))
+
+	//nolint:lll // Documentation
+	jobUpdateExample = templates.Examples(i18n.T(`
+		# Run a job on 3 more nodes
+		bacalhau job update --concurrency 6 51225160-807e-48b8-88c9-28311c7899e1
+
+		# Give a queued job, with a short ID, an hour to complete
+		bacalhau job update --timeout 3600 ebd9bf2f
+`))
+
+	jobEvictCacheLong = templates.LongDesc(i18n.T(`
+		Stop serving the cached results of a deterministic job to identical jobs.
+
+		WASM jobs without network access, whose inputs are all content-addressed, are served the results
+		of an identical job that already completed instead of running again. Evicting the cached results
+		of any of these jobs makes the next identical job run again.
+`))
+
+	//nolint:lll // Documentation
+	jobEvictCacheExample = templates.Examples(i18n.T(`
+		# Run the next job identical to this one again
+		bacalhau job evict-cache 51225160-807e-48b8-88c9-28311c7899e1
+`))
+)
+
+type JobUpdateOptions struct {
+	Concurrency int     // The new number of nodes that should run the job
+	MinBids     int     // The new number of bids to receive before accepting any of them
+	Timeout     float64 // The new timeout of the job in seconds
+}
+
+func NewJobUpdateOptions() *JobUpdateOptions {
+	return &JobUpdateOptions{}
+}
+
+func newJobCmd() *cobra.Command {
+	jobCmd := &cobra.Command{
+		Use:               "job",
+		Short:             "Manage previously submitted jobs (see subcommands)",
+		PersistentPreRunE: checkVersion,
+	}
+	jobCmd.AddCommand(newJobUpdateCmd())
+	jobCmd.AddCommand(newJobEvictCacheCmd())
+	return jobCmd
+}
+
+func newJobUpdateCmd() *cobra.Command {
+	OU := NewJobUpdateOptions()
+
+	jobUpdateCmd := &cobra.Command{
+		Use:     "update [id]",
+		Short:   "Update the deal or timeout of a job in progress",
+		Long:    jobUpdateLong,
+		Example: jobUpdateExample,
+		Args:    cobra.ExactArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return jobUpdate(cmd, cmdArgs, OU)
+		},
+	}
+
+	jobUpdateCmd.PersistentFlags().IntVar(
+		&OU.Concurrency, "concurrency", OU.Concurrency,
+		`The new number of nodes that should run the job`,
+	)
+	jobUpdateCmd.PersistentFlags().IntVar(
+		&OU.MinBids, "min-bids", OU.MinBids,
+		`The new number of bids to receive before accepting any of them`,
+	)
+	jobUpdateCmd.PersistentFlags().Float64Var(
+		&OU.Timeout, "timeout", OU.Timeout,
+		`The new timeout of the job in seconds`,
+	)
+	return jobUpdateCmd
+}
+
+func jobUpdate(cmd *cobra.Command, cmdArgs []string, OU *JobUpdateOptions) error {
+	ctx := cmd.Context()
+
+	// only the flags given by the user are updated
+	update := model.JobUpdatePayload{}
+	if cmd.Flags().Changed("concurrency") {
+		update.Concurrency = &OU.Concurrency
+	}
+	if cmd.Flags().Changed("min-bids") {
+		update.MinBids = &OU.MinBids
+	}
+	if cmd.Flags().Changed("timeout") {
+		update.Timeout = &OU.Timeout
+	}
+	if update.Concurrency == nil && update.MinBids == nil && update.Timeout == nil {
+		Fatal(cmd, "Nothing to update. Use --concurrency, --min-bids or --timeout", 1)
+		return nil
+	}
+
+	j, err := GetAPIClient().Update(ctx, cmdArgs[0], update)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error updating job: %s", err), 1)
+		return err
+	}
+
+	cmd.Printf("Job %s updated. Concurrency: %d, min bids: %d, timeout: %s\n",
+		j.Metadata.ID, j.Spec.Deal.Concurrency, j.Spec.Deal.MinBids, j.Spec.GetTimeout())
+	return nil
+}
+
+func newJobEvictCacheCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "evict-cache [id]",
+		Short:   "Stop serving the cached results of a job to identical jobs",
+		Long:    jobEvictCacheLong,
+		Example: jobEvictCacheExample,
+		Args:    cobra.ExactArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return jobEvictCache(cmd, cmdArgs)
+		},
+	}
+}
+
+func jobEvictCache(cmd *cobra.Command, cmdArgs []string) error {
+	ctx := cmd.Context()
+
+	if err := GetAPIClient().EvictCachedResult(ctx, cmdArgs[0]); err != nil {
+		Fatal(cmd, fmt.Sprintf("Error evicting cached results: %s", err), 1)
+		return err
+	}
+
+	cmd.Printf("Cached results of job %s evicted\n", cmdArgs[0])
+	return nil
+}
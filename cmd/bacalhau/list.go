@@ -25,7 +25,13 @@ var (
 		bacalhau list
 
 		# List jobs and output as json
-		bacalhau list --output json`))
+		bacalhau list --output json
+
+		# List the jobs submitted from a job template
+		bacalhau list --parent 5fc0ed1e-6fd7-4e15-8a4b-3a7d2e8e6f4c
+
+		# List the jobs that were archived by the retention policy of the requester node
+		bacalhau list --archived`))
 
 	// The tags that will be excluded by default, if the user does not pass any
 	// others to the list command.
@@ -37,6 +43,7 @@ var (
 type ListOptions struct {
 	HideHeader   bool                // Hide the column headers
 	IDFilter     string              // Filter by Job List to IDs matching substring.
+	ParentID     string              // Only return the jobs submitted from the job template with this parent ID.
 	IncludeTags  []model.IncludedTag // Only return jobs with these annotations
 	ExcludeTags  []model.ExcludedTag // Only return jobs without these annotations
 	NoStyle      bool                // Remove all styling from table output.
@@ -46,6 +53,7 @@ type ListOptions struct {
 	SortBy       ColumnEnum          // Sort by field, defaults to creation time, with newest first [Allowed "id", "created_at"].
 	OutputWide   bool                // Print full values in the table results
 	ReturnAll    bool                // Return all jobs, not just those that belong to the user
+	Archived     bool                // List the jobs purged by the retention policy of the requester node
 }
 
 func NewListOptions() *ListOptions {
@@ -61,6 +69,7 @@ func NewListOptions() *ListOptions {
 		SortBy:       ColumnCreatedAt,
 		OutputWide:   false,
 		ReturnAll:    false,
+		Archived:     false,
 	}
 }
 
@@ -81,6 +90,8 @@ func newListCmd() *cobra.Command {
 	listCmd.PersistentFlags().BoolVar(&OL.HideHeader, "hide-header", OL.HideHeader,
 		`do not print the column headers.`)
 	listCmd.PersistentFlags().StringVar(&OL.IDFilter, "id-filter", OL.IDFilter, `filter by Job List to IDs matching substring.`)
+	listCmd.PersistentFlags().StringVar(&OL.ParentID, "parent", OL.ParentID,
+		`only list the jobs submitted from the job template with this parent ID, oldest first.`)
 	listCmd.PersistentFlags().Var(IncludedTagFlag(&OL.IncludeTags), "include-tag",
 		`Only return jobs that have the passed tag in their annotations`)
 	listCmd.PersistentFlags().Var(ExcludedTagFlag(&OL.ExcludeTags), "exclude-tag",
@@ -114,6 +125,10 @@ func newListCmd() *cobra.Command {
 		//nolint:lll // Documentation
 		`Fetch all jobs from the network (default is to filter those belonging to the user). This option may take a long time to return, please use with caution.`,
 	)
+	listCmd.PersistentFlags().BoolVar(
+		&OL.Archived, "archived", OL.Archived,
+		`List the jobs that were purged by the retention policy of the requester node and archived, instead of the current jobs.`,
+	)
 
 	return listCmd
 }
@@ -158,17 +173,35 @@ func list(cmd *cobra.Command, OL *ListOptions) error {
 	log.Ctx(ctx).Debug().Msgf("Found hide header flag set to: %t", OL.HideHeader)
 	log.Ctx(ctx).Debug().Msgf("Found no-style header flag set to: %t", OL.NoStyle)
 	log.Ctx(ctx).Debug().Msgf("Found output wide flag set to: %t", OL.OutputWide)
-
-	jobs, err := GetAPIClient().List(
-		ctx,
-		OL.IDFilter,
-		OL.IncludeTags,
-		OL.ExcludeTags,
-		OL.MaxJobs,
-		OL.ReturnAll,
-		OL.SortBy.String(),
-		OL.SortReverse,
-	)
+	log.Ctx(ctx).Debug().Msgf("Found archived flag set to: %t", OL.Archived)
+
+	var jobs []*model.JobWithInfo
+	var err error
+	if OL.Archived {
+		jobs, err = GetAPIClient().ListArchived(
+			ctx,
+			OL.IDFilter,
+			OL.IncludeTags,
+			OL.ExcludeTags,
+			OL.MaxJobs,
+			OL.ReturnAll,
+			OL.SortBy.String(),
+			OL.SortReverse,
+		)
+	} else if OL.ParentID != "" {
+		jobs, err = GetAPIClient().ListChildren(ctx, OL.ParentID)
+	} else {
+		jobs, err = GetAPIClient().List(
+			ctx,
+			OL.IDFilter,
+			OL.IncludeTags,
+			OL.ExcludeTags,
+			OL.MaxJobs,
+			OL.ReturnAll,
+			OL.SortBy.String(),
+			OL.SortReverse,
+		)
+	}
 	if err != nil {
 		Fatal(cmd, fmt.Sprintf("Error listing jobs: %s", err), 1)
 	}
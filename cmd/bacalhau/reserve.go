@@ -0,0 +1,157 @@
+package bacalhau
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	reserveLong = templates.LongDesc(i18n.T(`
+		Reserve capacity on the compute nodes matching a selector for a future time window.
+
+		Each selected node holds back the requested resources during the window, and only releases
+		them to jobs of the same client submitted with the returned reservation token. Reservations
+		expire at the end of their window, or when they are canceled.
+`))
+
+	//nolint:lll // Documentation
+	reserveExample = templates.Examples(i18n.T(`
+		# Reserve 2 CPUs and 4Gb of memory on the nodes labelled for demos, for two hours from now
+		bacalhau reserve --selector demo=true --cpu 2 --memory 4Gb --duration 2h
+
+		# Reserve a GPU on every node for a nightly processing window
+		bacalhau reserve --gpu 1 --start 2023-03-01T22:00:00Z --duration 6h
+
+		# Run a job on the reserved capacity
+		bacalhau docker run --reservation 4f1c9d2e-6a8b-4e7c-9d0a-1b2c3d4e5f60 --gpu 1 ubuntu nvidia-smi
+
+		# Release the reserved capacity before the end of the window
+		bacalhau reserve cancel 4f1c9d2e-6a8b-4e7c-9d0a-1b2c3d4e5f60
+`))
+)
+
+type ReserveOptions struct {
+	NodeSelector string        // Selector (label query) of the nodes on which capacity is reserved
+	CPU          string        // CPU cores to reserve on each node
+	Memory       string        // Memory to reserve on each node
+	Disk         string        // Disk to reserve on each node
+	GPU          string        // GPUs to reserve on each node
+	Start        string        // When the reservation window starts, in RFC3339 format
+	Duration     time.Duration // How long the reservation window lasts
+}
+
+func NewReserveOptions() *ReserveOptions {
+	return &ReserveOptions{
+		Duration: time.Hour,
+	}
+}
+
+func newReserveCmd() *cobra.Command {
+	OR := NewReserveOptions()
+
+	reserveCmd := &cobra.Command{
+		Use:               "reserve",
+		Short:             "Reserve capacity on compute nodes for a future time window",
+		Long:              reserveLong,
+		Example:           reserveExample,
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkVersion,
+		PreRun:            applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return reserve(cmd, OR)
+		},
+	}
+
+	reserveCmd.Flags().StringVarP(
+		&OR.NodeSelector, "selector", "s", OR.NodeSelector,
+		`Selector (label query) of the nodes on which capacity is reserved, supports '=', '==', and '!='.(e.g. -s key1=value1,key2=value2). Every compute node is selected if not set.`, //nolint:lll // Documentation, ok if long.
+	)
+	reserveCmd.Flags().StringVar(
+		&OR.CPU, "cpu", OR.CPU,
+		`CPU cores to reserve on each node (e.g. 500m, 2, 8).`,
+	)
+	reserveCmd.Flags().StringVar(
+		&OR.Memory, "memory", OR.Memory,
+		`Memory to reserve on each node (e.g. 500Mb, 2Gb, 8Gb).`,
+	)
+	reserveCmd.Flags().StringVar(
+		&OR.Disk, "disk", OR.Disk,
+		`Disk to reserve on each node (e.g. 500Mb, 2Gb, 8Gb).`,
+	)
+	reserveCmd.Flags().StringVar(
+		&OR.GPU, "gpu", OR.GPU,
+		`GPUs to reserve on each node (e.g. 1, 2, 8).`,
+	)
+	reserveCmd.Flags().StringVar(
+		&OR.Start, "start", OR.Start,
+		`When the reservation window starts, in RFC3339 format (e.g. 2023-03-01T22:00:00Z). The window starts now if not set.`,
+	)
+	reserveCmd.Flags().DurationVar(
+		&OR.Duration, "duration", OR.Duration,
+		`How long the reservation window lasts`,
+	)
+
+	reserveCmd.AddCommand(newReserveCancelCmd())
+	return reserveCmd
+}
+
+func newReserveCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "cancel [id]",
+		Short:  "Cancel a reservation and release the capacity it holds back",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if err := GetAPIClient().CancelReservation(cmd.Context(), cmdArgs[0]); err != nil {
+				Fatal(cmd, fmt.Sprintf("Error canceling reservation: %s", err), 1)
+				return err
+			}
+			cmd.Printf("Reservation %s canceled\n", cmdArgs[0])
+			return nil
+		},
+	}
+}
+
+func reserve(cmd *cobra.Command, OR *ReserveOptions) error {
+	ctx := cmd.Context()
+
+	selectors, err := job.ParseNodeSelector(OR.NodeSelector)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing node selector: %s", err), 1)
+		return err
+	}
+	start := time.Now()
+	if OR.Start != "" {
+		start, err = time.Parse(time.RFC3339, OR.Start)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error parsing start time: %s", err), 1)
+			return err
+		}
+	}
+
+	resources := model.ResourceUsageConfig{
+		CPU:    OR.CPU,
+		Memory: OR.Memory,
+		Disk:   OR.Disk,
+		GPU:    OR.GPU,
+	}
+	reservation, nodeIDs, err := GetAPIClient().Reserve(ctx, selectors, resources, start, start.Add(OR.Duration))
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error reserving capacity: %s", err), 1)
+		return err
+	}
+
+	cmd.Printf("Reservation: %s\n", reservation.ID)
+	cmd.Printf("Window: %s - %s\n", reservation.StartTime.Format(time.RFC3339), reservation.EndTime.Format(time.RFC3339))
+	cmd.Printf("Reserved %s on %d nodes:\n", reservation.Resources, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		cmd.Printf("\t%s\n", nodeID)
+	}
+	return nil
+}
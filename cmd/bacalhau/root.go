@@ -95,6 +95,9 @@ func NewRootCmd() *cobra.Command {
 	// Porcelain commands (language specific easy to use commands)
 	RootCmd.AddCommand(newRunCmd())
 
+	// Submit jobs periodically
+	RootCmd.AddCommand(newScheduleCmd())
+
 	RootCmd.AddCommand(newValidateCmd())
 
 	RootCmd.AddCommand(newVersionCmd())
@@ -109,9 +112,21 @@ func NewRootCmd() *cobra.Command {
 	// Cancel a job
 	RootCmd.AddCommand(newCancelCmd())
 
+	// Update the deal of a job
+	RootCmd.AddCommand(newJobCmd())
+
+	// Approve a job held by the admission policy
+	RootCmd.AddCommand(newApproveCmd())
+
 	// List jobs
 	RootCmd.AddCommand(newListCmd())
 
+	// Show the resources consumed by the jobs of the client
+	RootCmd.AddCommand(newUsageCmd())
+
+	// Reserve capacity on compute nodes for a future time window
+	RootCmd.AddCommand(newReserveCmd())
+
 	// ====== Run a server
 
 	// Serve commands
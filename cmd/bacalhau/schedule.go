@@ -0,0 +1,286 @@
+package bacalhau
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/userstrings"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	scheduleCreateLong = templates.LongDesc(i18n.T(`
+		Create a schedule that submits a job from a file or from stdin every time a cron expression is due.
+
+		JSON and YAML formats are accepted. The cron expression is evaluated in UTC.
+	`))
+
+	//nolint:lll // Documentation
+	scheduleCreateExample = templates.Examples(i18n.T(`
+		# Submit the job in job.yaml at the start of every hour
+		bacalhau schedule create --cron "0 * * * *" ./job.yaml
+
+		# Submit the job every day at midnight, and cancel the previous day's job if it is still running
+		bacalhau schedule create --cron @daily --overlap-policy cancel-previous ./job.yaml`))
+
+	scheduleListExample = templates.Examples(i18n.T(`
+		# List your schedules
+		bacalhau schedule list
+
+		# List your schedules and output as json
+		bacalhau schedule list --output json`))
+)
+
+type ScheduleCreateOptions struct {
+	Cron          string                      // Cron expression of the times the job is submitted
+	OverlapPolicy model.ScheduleOverlapPolicy // What to do when a run is due while the previous job is still in progress
+}
+
+func NewScheduleCreateOptions() *ScheduleCreateOptions {
+	return &ScheduleCreateOptions{
+		OverlapPolicy: model.ScheduleOverlapSkip,
+	}
+}
+
+type ScheduleListOptions struct {
+	HideHeader   bool   // Hide the column headers
+	NoStyle      bool   // Remove all styling from table output.
+	OutputFormat string // The output format for the list of schedules (json or text)
+	OutputWide   bool   // Print full values in the table results
+}
+
+func NewScheduleListOptions() *ScheduleListOptions {
+	return &ScheduleListOptions{
+		OutputFormat: "text",
+	}
+}
+
+func newScheduleCmd() *cobra.Command {
+	scheduleCmd := &cobra.Command{
+		Use:               "schedule",
+		Short:             "Manage schedules that submit jobs periodically (see subcommands)",
+		PersistentPreRunE: checkVersion,
+	}
+	scheduleCmd.AddCommand(newScheduleCreateCmd())
+	scheduleCmd.AddCommand(newScheduleListCmd())
+	scheduleCmd.AddCommand(newSchedulePauseCmd(true))
+	scheduleCmd.AddCommand(newSchedulePauseCmd(false))
+	scheduleCmd.AddCommand(newScheduleDeleteCmd())
+	return scheduleCmd
+}
+
+func newScheduleCreateCmd() *cobra.Command {
+	OS := NewScheduleCreateOptions()
+
+	scheduleCreateCmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Create a schedule that submits a job periodically.",
+		Long:    scheduleCreateLong,
+		Example: scheduleCreateExample,
+		Args:    cobra.MaximumNArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return scheduleCreate(cmd, cmdArgs, OS)
+		},
+	}
+
+	scheduleCreateCmd.PersistentFlags().StringVar(
+		&OS.Cron, "cron", OS.Cron,
+		`Cron expression of the times the job is submitted (e.g. "0 * * * *" or "@hourly").`,
+	)
+	scheduleCreateCmd.PersistentFlags().Var(
+		ScheduleOverlapPolicyFlag(&OS.OverlapPolicy), "overlap-policy",
+		fmt.Sprintf(`What to do when a job is due while the previous job is still in progress. One of %s.`,
+			strings.Join(model.ScheduleOverlapPolicyNames(), ", ")),
+	)
+	return scheduleCreateCmd
+}
+
+func scheduleCreate(cmd *cobra.Command, cmdArgs []string, OS *ScheduleCreateOptions) error {
+	ctx := cmd.Context()
+
+	if OS.Cron == "" {
+		Fatal(cmd, "A cron expression must be given with --cron", 1)
+		return nil
+	}
+
+	var byteResult []byte
+	var err error
+	if len(cmdArgs) == 0 {
+		byteResult, err = ReadFromStdinIfAvailable(cmd, cmdArgs)
+	} else {
+		var fileContent *os.File
+		fileContent, err = os.Open(cmdArgs[0])
+		if err == nil {
+			defer fileContent.Close()
+			byteResult, err = io.ReadAll(fileContent)
+		}
+	}
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error reading job spec: %s", err), 1)
+		return err
+	}
+
+	j, err := model.NewJobWithSaneProductionDefaults()
+	if err != nil {
+		return err
+	}
+	if err = model.YAMLUnmarshalWithMax(byteResult, &j); err != nil || len(byteResult) == 0 {
+		Fatal(cmd, userstrings.JobSpecBad, 1)
+		return err
+	}
+	if err = jobutils.VerifyJob(ctx, j); err != nil {
+		Fatal(cmd, fmt.Sprintf("Error verifying job: %s", err), 1)
+		return err
+	}
+
+	schedule, err := GetAPIClient().CreateSchedule(ctx, OS.Cron, OS.OverlapPolicy, j)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error creating schedule: %s", err), 1)
+		return err
+	}
+
+	cmd.Printf("%s\n", schedule.ID)
+	return nil
+}
+
+func newScheduleListCmd() *cobra.Command {
+	OL := NewScheduleListOptions()
+
+	scheduleListCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List your schedules",
+		Example: scheduleListExample,
+		Args:    cobra.NoArgs,
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return scheduleList(cmd, OL)
+		},
+	}
+
+	scheduleListCmd.PersistentFlags().BoolVar(&OL.HideHeader, "hide-header", OL.HideHeader,
+		`do not print the column headers.`)
+	scheduleListCmd.PersistentFlags().BoolVar(&OL.NoStyle, "no-style", OL.NoStyle, `remove all styling from table output.`)
+	scheduleListCmd.PersistentFlags().StringVar(
+		&OL.OutputFormat, "output", OL.OutputFormat,
+		`The output format for the list of schedules (json or text)`,
+	)
+	scheduleListCmd.PersistentFlags().BoolVar(
+		&OL.OutputWide, "wide", OL.OutputWide,
+		`Print full values in the table results`,
+	)
+	return scheduleListCmd
+}
+
+func scheduleList(cmd *cobra.Command, OL *ScheduleListOptions) error {
+	ctx := cmd.Context()
+
+	schedules, err := GetAPIClient().ListSchedules(ctx)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing schedules: %s", err), 1)
+		return err
+	}
+
+	if OL.OutputFormat == JSONFormat {
+		var msgBytes []byte
+		msgBytes, err = model.JSONMarshalWithMax(schedules)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling schedules to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !OL.HideHeader {
+		tw.AppendHeader(table.Row{"id", "cron", "overlap", "state", "next run", "last job"})
+	}
+	for _, schedule := range schedules {
+		state := "active"
+		if schedule.Paused {
+			state = "paused"
+		}
+		tw.AppendRow(table.Row{
+			shortID(OL.OutputWide, schedule.ID),
+			schedule.Cron,
+			schedule.OverlapPolicy.String(),
+			state,
+			schedule.NextRunAt.Format("06-01-02-15:04"),
+			shortID(OL.OutputWide, schedule.LastJobID),
+		})
+	}
+	if OL.NoStyle {
+		tw.SetStyle(table.Style{
+			Name:   "StyleDefault",
+			Box:    table.StyleBoxDefault,
+			Color:  table.ColorOptionsDefault,
+			Format: table.FormatOptionsDefault,
+			HTML:   table.DefaultHTMLOptions,
+			Options: table.Options{
+				DrawBorder:      false,
+				SeparateColumns: false,
+				SeparateFooter:  false,
+				SeparateHeader:  false,
+				SeparateRows:    false,
+			},
+			Title: table.TitleOptionsDefault,
+		})
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+	tw.Render()
+	return nil
+}
+
+// newSchedulePauseCmd returns the command to pause schedules, or to resume them if pause is false
+func newSchedulePauseCmd(pause bool) *cobra.Command {
+	use, short := "resume [id]", "Resume a paused schedule"
+	if pause {
+		use, short = "pause [id]", "Pause a schedule until it is resumed"
+	}
+	return &cobra.Command{
+		Use:    use,
+		Short:  short,
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			schedule, err := GetAPIClient().UpdateSchedule(cmd.Context(), cmdArgs[0], pause)
+			if err != nil {
+				Fatal(cmd, fmt.Sprintf("Error updating schedule: %s", err), 1)
+				return err
+			}
+			if pause {
+				cmd.Printf("Schedule %s paused\n", schedule.ID)
+			} else {
+				cmd.Printf("Schedule %s resumed. Next run at %s\n", schedule.ID, schedule.NextRunAt.Format("2006-01-02 15:04 MST"))
+			}
+			return nil
+		},
+	}
+}
+
+func newScheduleDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "delete [id]",
+		Short:  "Delete a schedule. Jobs it already submitted are not canceled.",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if err := GetAPIClient().DeleteSchedule(cmd.Context(), cmdArgs[0]); err != nil {
+				Fatal(cmd, fmt.Sprintf("Error deleting schedule: %s", err), 1)
+				return err
+			}
+			cmd.Printf("Schedule %s deleted\n", cmdArgs[0])
+			return nil
+		},
+	}
+}
@@ -5,20 +5,27 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	compute_sqlite "github.com/bacalhau-project/bacalhau/pkg/compute/store/sqlite"
+	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/sqlite"
 	"github.com/bacalhau-project/bacalhau/pkg/libp2p"
 	"github.com/bacalhau-project/bacalhau/pkg/libp2p/rcmgr"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/node"
 	filecoinlotus "github.com/bacalhau-project/bacalhau/pkg/publisher/filecoin_lotus"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
 	"github.com/multiformats/go-multiaddr"
@@ -73,6 +80,10 @@ type ServeOptions struct {
 	LimitJobCPU                           string            // The amount of CPU the system can be using at one time for a single job.
 	LimitJobMemory                        string            // The amount of memory the system can be using at one time for a single job.
 	LimitJobGPU                           string            // The amount of GPU the system can be using at one time for a single job.
+	CapacityPolicy                        string            // The policy used to commit the capacity of the compute node to jobs.
+	CPUOvercommitRatio                    float64           // The ratio of CPU committed to jobs over the total CPU limit.
+	MemoryOvercommitRatio                 float64           // The ratio of memory committed to jobs over the total memory limit.
+	BestEffortPool                        bool              // Whether only low priority jobs run on the capacity gained by the policy.
 	LotusFilecoinStorageDuration          time.Duration     // How long deals should be for the Lotus Filecoin publisher
 	LotusFilecoinPathDirectory            string            // The location of the Lotus configuration directory which contains config.toml, etc
 	LotusFilecoinUploadDirectory          string            // Directory to put files when uploading to Lotus (optional)
@@ -81,6 +92,21 @@ type ServeOptions struct {
 	Labels                                map[string]string // Labels to apply to the node that can be used for node selection and filtering
 	IPFSSwarmAddresses                    []string          // IPFS multiaddresses that the in-process IPFS should connect to
 	PrivateInternalIPFS                   bool              // Whether the in-process IPFS should automatically discover other IPFS nodes
+	RequesterJobStore                     string            // The type of job store used by the requester node ("inmemory" or "sqlite")
+	RequesterJobStorePath                 string            // The path of the requester job store database when using a persistent job store
+	ComputeExecutionStore                 string            // The type of execution store used by the compute node ("inmemory" or "sqlite")
+	ComputeExecutionStorePath             string            // The path of the compute execution store database when using a persistent store
+	ComputeInputCacheSize                 string            // The disk quota of the input cache of the compute node, disabled when empty
+	RequesterAdmissionPolicy              string            // The path of the admission policy applied by the requester node to submitted jobs
+	RequesterClientQuota                  model.ClientQuota // The limits applied by the requester node to the jobs of each client
+	RequesterMaxReservations              int               // The number of reservations of a client that have not expired yet
+	RequesterMaxReservationNodes          int               // The number of compute nodes a reservation can hold back capacity on
+	RequesterMaxReservationWindow         time.Duration     // The length of the window of a reservation
+	RequesterJobRetentionMaxAge           time.Duration     // How long the requester node keeps terminal jobs in its job store
+	RequesterJobRetentionMaxCount         int               // The number of terminal jobs kept in the requester job store
+	RequesterJobRetentionStateMaxAge      map[string]string // How long terminal jobs are kept per state, overriding the max age
+	RequesterJobArchivePath               string            // The path of the archive purged jobs are exported to
+	RateCard                              model.RateCard    // The prices used to quote the bids of the compute node
 }
 
 func NewServeOptions() *ServeOptions {
@@ -103,8 +129,20 @@ func NewServeOptions() *ServeOptions {
 		LimitJobCPU:                     "",
 		LimitJobMemory:                  "",
 		LimitJobGPU:                     "",
+		CapacityPolicy:                  string(capacity.TrackerPolicyStrict),
+		CPUOvercommitRatio:              1,
+		MemoryOvercommitRatio:           1,
+		BestEffortPool:                  false,
 		LotusFilecoinPathDirectory:      os.Getenv("LOTUS_PATH"),
 		LotusFilecoinMaximumPing:        2 * time.Second,
+		RequesterJobStore:               "inmemory",
+		RequesterJobStorePath:           "",
+		ComputeExecutionStore:           "inmemory",
+		ComputeExecutionStorePath:       "",
+		ComputeInputCacheSize:           "",
+		RequesterMaxReservations:        5,
+		RequesterMaxReservationNodes:    10,
+		RequesterMaxReservationWindow:   24 * time.Hour,
 	}
 }
 
@@ -160,6 +198,48 @@ func setupCapacityManagerCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
 		&OS.JobExecutionTimeoutClientIDBypassList, "job-execution-timeout-bypass-client-id", OS.JobExecutionTimeoutClientIDBypassList,
 		`List of IDs of clients that are allowed to bypass the job execution timeout check`,
 	)
+	cmd.PersistentFlags().StringVar(
+		&OS.CapacityPolicy, "capacity-policy", OS.CapacityPolicy,
+		`How the total capacity is committed to jobs: to the resources they request ("strict"), `+
+			`to more resources than the limits by the overcommit ratios ("overcommit"), `+
+			`or to the resources running jobs actually use ("bin-packing").`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.CPUOvercommitRatio, "cpu-overcommit-ratio", OS.CPUOvercommitRatio,
+		`Ratio of CPU committed to jobs over the total CPU limit, with the "overcommit" capacity policy.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.MemoryOvercommitRatio, "memory-overcommit-ratio", OS.MemoryOvercommitRatio,
+		`Ratio of memory committed to jobs over the total memory limit, with the "overcommit" capacity policy.`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&OS.BestEffortPool, "best-effort-pool", OS.BestEffortPool,
+		`Only run low priority jobs on the capacity gained by the capacity policy, `+
+			`while other jobs are guaranteed the resources they request.`,
+	)
+}
+
+func setupPricingCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
+	cmd.PersistentFlags().Float64Var(
+		&OS.RateCard.Base, "price-base", OS.RateCard.Base,
+		`Price quoted for every job, regardless of its resources and duration.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.RateCard.CPU, "price-cpu", OS.RateCard.CPU,
+		`Price quoted per CPU core per hour of job execution timeout.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.RateCard.Memory, "price-memory", OS.RateCard.Memory,
+		`Price quoted per GB of memory per hour of job execution timeout.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.RateCard.Disk, "price-disk", OS.RateCard.Disk,
+		`Price quoted per GB of disk per hour of job execution timeout.`,
+	)
+	cmd.PersistentFlags().Float64Var(
+		&OS.RateCard.GPU, "price-gpu", OS.RateCard.GPU,
+		`Price quoted per GPU per hour of job execution timeout.`,
+	)
 }
 
 func setupLibp2pCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
@@ -230,15 +310,180 @@ func getComputeConfig(OS *ServeOptions) node.ComputeConfig {
 			Memory: OS.LimitJobMemory,
 			GPU:    OS.LimitJobGPU,
 		}),
-		IgnorePhysicalResourceLimits:          os.Getenv("BACALHAU_CAPACITY_MANAGER_OVER_COMMIT") != "",
+		IgnorePhysicalResourceLimits: os.Getenv("BACALHAU_CAPACITY_MANAGER_OVER_COMMIT") != "",
+		CapacityTrackerConfig: capacity.TrackerConfig{
+			Policy:                capacity.TrackerPolicy(strings.ToLower(OS.CapacityPolicy)),
+			CPUOvercommitRatio:    OS.CPUOvercommitRatio,
+			MemoryOvercommitRatio: OS.MemoryOvercommitRatio,
+			BestEffortPool:        OS.BestEffortPool,
+		},
 		JobExecutionTimeoutClientIDBypassList: OS.JobExecutionTimeoutClientIDBypassList,
+		RateCard:                              OS.RateCard,
 	})
 }
 
-func getRequesterConfig(OS *ServeOptions) node.RequesterConfig {
+func getJobStore(OS *ServeOptions, cm *system.CleanupManager) (jobstore.Store, error) {
+	switch OS.RequesterJobStore {
+	case "inmemory":
+		return inmemory.NewJobStore(), nil
+	case "sqlite":
+		path := OS.RequesterJobStorePath
+		if path == "" {
+			configDir, err := system.EnsureConfigDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(configDir, "requester-jobs.db")
+		}
+		store, err := sqlite.NewJobStore(path)
+		if err != nil {
+			return nil, err
+		}
+		cm.RegisterCallback(store.Close)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("--requester-job-store must be either 'inmemory' or 'sqlite'")
+	}
+}
+
+// getExecutionStore returns the persistent execution store of the compute node, or nil if executions are only
+// kept in memory.
+func getExecutionStore(OS *ServeOptions, cm *system.CleanupManager) (store.ExecutionStore, error) {
+	switch OS.ComputeExecutionStore {
+	case "inmemory":
+		return nil, nil
+	case "sqlite":
+		path := OS.ComputeExecutionStorePath
+		if path == "" {
+			configDir, err := system.EnsureConfigDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(configDir, "compute-executions.db")
+		}
+		executionStore, err := compute_sqlite.NewStore(path)
+		if err != nil {
+			return nil, err
+		}
+		cm.RegisterCallback(executionStore.Close)
+		return executionStore, nil
+	default:
+		return nil, fmt.Errorf("--compute-execution-store must be either 'inmemory' or 'sqlite'")
+	}
+}
+
+// getInputCache returns the cache of the inputs of the jobs run by the compute node, or nil if inputs are fetched
+// again for each job. Cached inputs are removed when the node stops, as the cache only keeps its index in memory.
+func getInputCache(OS *ServeOptions, cm *system.CleanupManager) (*cache.Cache, error) {
+	if OS.ComputeInputCacheSize == "" {
+		return nil, nil
+	}
+	maxSize := capacity.ConvertBytesString(OS.ComputeInputCacheSize)
+	if maxSize == 0 {
+		return nil, fmt.Errorf("--compute-input-cache-size must be a size such as 500Mb or 20Gb")
+	}
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-input-cache")
+	if err != nil {
+		return nil, err
+	}
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove input cache directory: %w", err)
+		}
+		return nil
+	})
+	return cache.New(cache.Params{Dir: dir, MaxSize: maxSize})
+}
+
+func getRequesterConfig(OS *ServeOptions) (node.RequesterConfig, error) {
+	admissionPolicy, err := getAdmissionPolicy(OS.RequesterAdmissionPolicy)
+	if err != nil {
+		return node.RequesterConfig{}, fmt.Errorf("error reading admission policy: %w", err)
+	}
+	jobRetention, err := getJobRetention(OS)
+	if err != nil {
+		return node.RequesterConfig{}, fmt.Errorf("error reading job retention policy: %w", err)
+	}
+	jobArchivePath, err := getJobArchivePath(OS, jobRetention)
+	if err != nil {
+		return node.RequesterConfig{}, err
+	}
 	return node.NewRequesterConfigWith(node.RequesterConfigParams{
 		JobSelectionPolicy: getJobSelectionConfig(OS),
-	})
+		AdmissionPolicy:    admissionPolicy,
+		ClientQuota:        OS.RequesterClientQuota,
+		ReservationLimits: model.ReservationLimits{
+			MaxActiveReservations: OS.RequesterMaxReservations,
+			MaxNodes:              OS.RequesterMaxReservationNodes,
+			MaxDuration:           OS.RequesterMaxReservationWindow,
+		},
+		JobRetention:   jobRetention,
+		JobArchivePath: jobArchivePath,
+	}), nil
+}
+
+// getJobRetention adds the max age of each terminal state to the retention policy of the requester node.
+func getJobRetention(OS *ServeOptions) (model.RetentionPolicy, error) {
+	policy := model.RetentionPolicy{
+		MaxAge:   OS.RequesterJobRetentionMaxAge,
+		MaxCount: OS.RequesterJobRetentionMaxCount,
+	}
+	if len(OS.RequesterJobRetentionStateMaxAge) == 0 {
+		return policy, nil
+	}
+	policy.StateMaxAge = make(map[model.JobStateType]time.Duration, len(OS.RequesterJobRetentionStateMaxAge))
+	for stateName, maxAgeValue := range OS.RequesterJobRetentionStateMaxAge {
+		var state model.JobStateType
+		if err := state.UnmarshalText([]byte(stateName)); err != nil {
+			return policy, err
+		}
+		if !state.IsTerminal() {
+			return policy, fmt.Errorf("job state %s is not terminal", state)
+		}
+		maxAge, err := time.ParseDuration(maxAgeValue)
+		if err != nil {
+			return policy, err
+		}
+		policy.StateMaxAge[state] = maxAge
+	}
+	return policy, nil
+}
+
+// getJobArchivePath returns the path of the archive purged jobs are exported to, which defaults to
+// requester-jobs-archive.jsonl.gz in the config directory if the retention policy is enabled.
+func getJobArchivePath(OS *ServeOptions, policy model.RetentionPolicy) (string, error) {
+	if OS.RequesterJobArchivePath != "" || !policy.IsEnabled() {
+		return OS.RequesterJobArchivePath, nil
+	}
+	configDir, err := system.EnsureConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "requester-jobs-archive.jsonl.gz"), nil
+}
+
+// getAdmissionPolicy reads the admission policy from the given file. All jobs are accepted if no file is given.
+func getAdmissionPolicy(path string) (model.AdmissionPolicy, error) {
+	var policy model.AdmissionPolicy
+	if path == "" {
+		return policy, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy, err
+	}
+	if err = model.YAMLUnmarshalWithMax(data, &policy); err != nil {
+		return policy, err
+	}
+	for i, rule := range policy.Rules {
+		if rule.Name == "" {
+			return policy, fmt.Errorf("admission rule %d has no name", i)
+		}
+		if rule.Action == "" {
+			return policy, fmt.Errorf("admission rule %s has no action", rule.Name)
+		}
+	}
+	return policy, nil
 }
 
 func newServeCmd() *cobra.Command {
@@ -302,9 +547,77 @@ func newServeCmd() *cobra.Command {
 			"cannot be used with --ipfs-connect.",
 	)
 
+	serveCmd.PersistentFlags().StringVar(
+		&OS.RequesterJobStore, "requester-job-store", OS.RequesterJobStore,
+		`The job store used by the requester node to persist jobs ("inmemory" or "sqlite").`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.RequesterJobStorePath, "requester-job-store-path", OS.RequesterJobStorePath,
+		`The path of the requester job store database. Defaults to requester-jobs.db in the bacalhau config directory.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ComputeExecutionStore, "compute-execution-store", OS.ComputeExecutionStore,
+		`The execution store used by the compute node to persist its executions ("inmemory" or "sqlite").`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ComputeExecutionStorePath, "compute-execution-store-path", OS.ComputeExecutionStorePath,
+		`The path of the compute execution store database. Defaults to compute-executions.db in the bacalhau config directory.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ComputeInputCacheSize, "compute-input-cache-size", OS.ComputeInputCacheSize,
+		`The disk quota of the cache that keeps the inputs fetched from IPFS and URLs across jobs (e.g. 500Mb, 20Gb). `+
+			`URL inputs are revalidated with their server before being reused. Inputs are fetched again for each job when not set.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.RequesterAdmissionPolicy, "requester-admission-policy", OS.RequesterAdmissionPolicy,
+		`The path of a JSON or YAML admission policy, whose rules accept, reject or hold for approval the jobs submitted to the requester node.`,
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.RequesterClientQuota.MaxConcurrentJobs, "requester-max-concurrent-jobs-per-client",
+		OS.RequesterClientQuota.MaxConcurrentJobs,
+		`The maximum number of jobs of a client that can be in progress at the same time, including jobs waiting to run. Unlimited if 0.`,
+	)
+	serveCmd.PersistentFlags().Float64Var(
+		&OS.RequesterClientQuota.MaxCPUHoursPerDay, "requester-max-cpu-hours-per-client",
+		OS.RequesterClientQuota.MaxCPUHoursPerDay,
+		`The maximum number of CPU hours the jobs of a client can consume over the last 24 hours. Unlimited if 0.`,
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.RequesterMaxReservations, "requester-max-reservations-per-client",
+		OS.RequesterMaxReservations,
+		`The maximum number of reservations of a client that have not expired yet. Unlimited if 0.`,
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.RequesterMaxReservationNodes, "requester-max-reservation-nodes",
+		OS.RequesterMaxReservationNodes,
+		`The maximum number of compute nodes a reservation can hold back capacity on. Unlimited if 0.`,
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.RequesterMaxReservationWindow, "requester-max-reservation-duration",
+		OS.RequesterMaxReservationWindow,
+		`The maximum length of the window of a reservation. Unlimited if 0.`,
+	)
+	serveCmd.PersistentFlags().DurationVar(
+		&OS.RequesterJobRetentionMaxAge, "requester-job-retention-max-age", OS.RequesterJobRetentionMaxAge,
+		`How long terminal jobs are kept by the requester node before they are archived and purged. Kept forever if 0.`,
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.RequesterJobRetentionMaxCount, "requester-job-retention-max-count", OS.RequesterJobRetentionMaxCount,
+		`The number of terminal jobs kept by the requester node, the oldest being archived and purged first. Unlimited if 0.`,
+	)
+	serveCmd.PersistentFlags().StringToStringVar(
+		&OS.RequesterJobRetentionStateMaxAge, "requester-job-retention-state-max-age", OS.RequesterJobRetentionStateMaxAge,
+		`How long terminal jobs are kept per state, overriding the max age. (e.g. Completed=24h,Error=168h,Cancelled=1h)`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.RequesterJobArchivePath, "requester-job-archive-path", OS.RequesterJobArchivePath,
+		`The path of the archive purged jobs are exported to. Defaults to requester-jobs-archive.jsonl.gz in the bacalhau config directory.`,
+	)
+
 	setupLibp2pCLIFlags(serveCmd, OS)
 	setupJobSelectionCLIFlags(serveCmd, OS)
 	setupCapacityManagerCLIFlags(serveCmd, OS)
+	setupPricingCLIFlags(serveCmd, OS)
 
 	return serveCmd
 }
@@ -359,9 +672,21 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		return err
 	}
 
-	datastore := inmemory.NewJobStore()
+	datastore, err := getJobStore(OS, cm)
+	if err != nil {
+		return fmt.Errorf("error creating job store: %s", err)
+	}
+	executionStore, err := getExecutionStore(OS, cm)
+	if err != nil {
+		return fmt.Errorf("error creating execution store: %s", err)
+	}
+	inputCache, err := getInputCache(OS, cm)
 	if err != nil {
-		return fmt.Errorf("error creating in memory datastore: %s", err)
+		return fmt.Errorf("error creating input cache: %s", err)
+	}
+	requesterConfig, err := getRequesterConfig(OS)
+	if err != nil {
+		return err
 	}
 	AutoLabels := AutoOutputLabels()
 	combinedMap := make(map[string]string)
@@ -377,13 +702,15 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		IPFSClient:           ipfsClient,
 		CleanupManager:       cm,
 		JobStore:             datastore,
+		ExecutionStore:       executionStore,
+		InputCache:           inputCache,
 		Host:                 libp2pHost,
 		FilecoinUnsealedPath: OS.FilecoinUnsealedPath,
 		EstuaryAPIKey:        OS.EstuaryAPIKey,
 		HostAddress:          OS.HostAddress,
 		APIPort:              apiPort,
 		ComputeConfig:        getComputeConfig(OS),
-		RequesterNodeConfig:  getRequesterConfig(OS),
+		RequesterNodeConfig:  requesterConfig,
 		IsComputeNode:        isComputeNode,
 		IsRequesterNode:      isRequesterNode,
 		Labels:               combinedMap,
@@ -0,0 +1,120 @@
+package bacalhau
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	usageLong = templates.LongDesc(i18n.T(`
+		Show the resources consumed by your jobs, and the quota the requester node applies to them.
+
+		Usage is aggregated over the executions that completed their run during the given period. CPU,
+		memory and GPU hours are the resources requested by each job multiplied by the time its
+		executions were running.
+`))
+
+	//nolint:lll // Documentation
+	usageExample = templates.Examples(i18n.T(`
+		# Show the usage of the last 24 hours
+		bacalhau usage
+
+		# Show the usage of the last week as json
+		bacalhau usage --since 168h --output json
+`))
+)
+
+type UsageOptions struct {
+	Since        time.Duration // How far back usage is aggregated
+	OutputFormat string        // The output format of the usage (json or text)
+}
+
+func NewUsageOptions() *UsageOptions {
+	return &UsageOptions{
+		Since:        24 * time.Hour,
+		OutputFormat: "text",
+	}
+}
+
+func newUsageCmd() *cobra.Command {
+	OU := NewUsageOptions()
+
+	usageCmd := &cobra.Command{
+		Use:               "usage",
+		Short:             "Show the resources consumed by your jobs",
+		Long:              usageLong,
+		Example:           usageExample,
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkVersion,
+		PreRun:            applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return usage(cmd, OU)
+		},
+	}
+
+	usageCmd.PersistentFlags().DurationVar(
+		&OU.Since, "since", OU.Since,
+		`How far back to aggregate the usage of completed executions`,
+	)
+	usageCmd.PersistentFlags().StringVar(
+		&OU.OutputFormat, "output", OU.OutputFormat,
+		`The output format for the usage (json or text)`,
+	)
+	return usageCmd
+}
+
+func usage(cmd *cobra.Command, OU *UsageOptions) error {
+	ctx := cmd.Context()
+
+	clientUsage, err := GetAPIClient().Usage(ctx, time.Now().Add(-OU.Since))
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error getting usage: %s", err), 1)
+		return err
+	}
+
+	if OU.OutputFormat == JSONFormat {
+		msgBytes, err := model.JSONMarshalWithMax(clientUsage)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error marshaling usage to JSON: %s", err), 1)
+			return err
+		}
+		cmd.Printf("%s\n", msgBytes)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	// the CPU hours quota applies to the last 24 hours, whatever the period usage is shown for
+	tw.AppendHeader(table.Row{"usage", "value", "quota"})
+	tw.AppendRows([]table.Row{
+		{"running jobs", clientUsage.RunningJobs, formatQuota(float64(clientUsage.Quota.MaxConcurrentJobs))},
+		{"executions", clientUsage.Executions, "-"},
+		{"wall-clock hours", formatHours(clientUsage.WallClockHours), "-"},
+		{"cpu hours", formatHours(clientUsage.CPUHours), formatQuota(clientUsage.Quota.MaxCPUHoursPerDay)},
+		{"memory GB hours", formatHours(clientUsage.MemoryGBHours), "-"},
+		{"gpu hours", formatHours(clientUsage.GPUHours), "-"},
+	})
+	tw.SetStyle(table.StyleLight)
+	cmd.Printf("Usage since %s\n", clientUsage.Since.Format(time.RFC3339))
+	tw.Render()
+	return nil
+}
+
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', 2, 64)
+}
+
+// formatQuota formats a quota limit, which is unlimited if not set.
+func formatQuota(limit float64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return strconv.FormatFloat(limit, 'f', -1, 64)
+}
@@ -64,6 +64,9 @@ var eventsWorthPrinting = map[model.ExecutionStateType]eventStruct{
 
 	// Job is canceled by the user
 	model.ExecutionStateCanceled: {Message: "Job canceled by the user.", IsTerminal: true, PrintDownload: false, IsError: true},
+
+	// Node running the job is no longer reachable
+	model.ExecutionStateLost: {Message: "Node running the job was lost.", IsTerminal: true, PrintDownload: false, IsError: true},
 }
 
 // Struct for tracking what's been printedEvents
@@ -100,6 +100,10 @@ func newRunWasmCmd() *cobra.Command {
 		&wasmJob.Spec.Deal.Confidence, "confidence", wasmJob.Spec.Deal.Confidence,
 		`The minimum number of nodes that must agree on a verification result`,
 	)
+	runWasmCommand.PersistentFlags().BoolVar(
+		&wasmJob.Spec.Deal.Gang, "gang", wasmJob.Spec.Deal.Gang,
+		`Only start the job once concurrency-many nodes can run it together. Each node is given the addresses of the others`,
+	)
 	runWasmCommand.PersistentFlags().IntVar(
 		&wasmJob.Spec.Deal.MinBids, "min-bids", wasmJob.Spec.Deal.MinBids,
 		`Minimum number of bids that must be received before concurrency-many bids will be accepted (at random)`,
@@ -136,6 +140,14 @@ func newRunWasmCmd() *cobra.Command {
 		NewIPFSStorageSpecArrayFlag(&wasmJob.Spec.Wasm.ImportModules), "import-module-volumes", "I",
 		`CID:path of the WASM modules to import from IPFS, if you need to set the path of the mounted data.`,
 	)
+	runWasmCommand.PersistentFlags().BoolVar(
+		&wasmJob.Spec.DoNotCache, "no-cache", wasmJob.Spec.DoNotCache,
+		`Always run the job, instead of reusing the results of an identical job that already completed.`,
+	)
+	runWasmCommand.PersistentFlags().StringVar(
+		&wasmJob.Spec.Reservation, "reservation", wasmJob.Spec.Reservation,
+		`Token of a capacity reservation to run the job on. The job only runs on the nodes holding the reservation`,
+	)
 
 	return runWasmCommand
 }
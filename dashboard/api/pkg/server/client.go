@@ -11,6 +11,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/requester/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
 )
 
 const maxWaitTime = 900
@@ -63,6 +64,32 @@ var testSpec model.Spec = model.Spec{
 	},
 }
 
+func getClient() *publicapi.RequesterAPIClient {
+	env := system.GetEnvironment()
+	baseURI := fmt.Sprintf("http://%s:%d", system.Envs[env].APIHost, system.Envs[env].APIPort)
+	return publicapi.NewRequesterAPIClient(baseURI)
+}
+
+// approveJob releases a job held for approval by the admission policy of the requester node. Jobs that are not
+// held for approval, or that no longer exist, are left untouched. The dashboard must run as the client configured
+// as the job approver of the requester node.
+func approveJob(ctx context.Context, jobID string, approved bool, reason string) error {
+	client := getClient()
+	jobInfo, found, err := client.Get(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// jobs that were purged by the requester node have nothing left to release
+		log.Ctx(ctx).Warn().Msgf("job %s was not found on the requester node, so it was not released", jobID)
+		return nil
+	}
+	if jobInfo.State.State != model.JobStatePendingApproval {
+		return nil
+	}
+	return client.Approve(ctx, jobID, approved, reason)
+}
+
 func runGenericJob(s model.Spec) (string, error) {
 	j, err := model.NewJobWithSaneProductionDefaults()
 	if err != nil {
@@ -70,9 +97,7 @@ func runGenericJob(s model.Spec) (string, error) {
 	}
 	j.Spec = s
 
-	env := system.EnvironmentProd
-	baseURI := fmt.Sprintf("http://%s:%d", system.Envs[env].APIHost, system.Envs[env].APIPort)
-	client := publicapi.NewRequesterAPIClient(baseURI)
+	client := getClient()
 
 	submittedJob, err := client.Submit(context.Background(), j)
 	if err != nil {
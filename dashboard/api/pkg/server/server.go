@@ -406,6 +406,15 @@ func (apiServer *DashboardAPIServer) adminmoderate(res http.ResponseWriter, req
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	// release the job if the admission policy of the requester node holds it for approval, before recording the
+	// moderation so that it can be retried if the job could not be released. Jobs that no longer exist are only
+	// recorded as moderated.
+	err = approveJob(req.Context(), data.JobID, data.Status == "yes", data.Notes)
+	if err != nil {
+		log.Ctx(req.Context()).Error().Msgf("error for adminmoderate route: job %s was not released: %s", data.JobID, err.Error())
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	err = apiServer.API.CreateJobModeration(context.Background(), *data)
 	if err != nil {
 		log.Ctx(req.Context()).Error().Msgf("error for adminmoderate route: %s", err.Error())
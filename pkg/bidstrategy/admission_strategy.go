@@ -0,0 +1,97 @@
+package bidstrategy
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+// AdmissionPolicyStrategy is used by the requester node to decide which submitted jobs are scheduled,
+// which are rejected, and which wait for an approval, based on the first rule of the admission policy
+// matching the job.
+type AdmissionPolicyStrategy struct {
+	policy model.AdmissionPolicy
+}
+
+func NewAdmissionPolicyStrategy(policy model.AdmissionPolicy) *AdmissionPolicyStrategy {
+	return &AdmissionPolicyStrategy{policy: policy}
+}
+
+// ShouldBid implements BidStrategy
+func (s *AdmissionPolicyStrategy) ShouldBid(ctx context.Context, request BidStrategyRequest) (BidStrategyResponse, error) {
+	for _, rule := range s.policy.Rules {
+		if matchesAdmissionRule(rule, request.Job) {
+			reason := fmt.Sprintf("admission rule %s", rule.Name)
+			if rule.Reason != "" {
+				reason += ": " + rule.Reason
+			}
+			return admissionResponse(rule.Action, reason), nil
+		}
+	}
+	return admissionResponse(s.policy.DefaultAction, "no admission rule matched the job"), nil
+}
+
+// ShouldBidBasedOnUsage implements BidStrategy
+func (s *AdmissionPolicyStrategy) ShouldBidBasedOnUsage(
+	ctx context.Context,
+	request BidStrategyRequest,
+	resourceUsage model.ResourceUsageData,
+) (BidStrategyResponse, error) {
+	return s.ShouldBid(ctx, request)
+}
+
+func admissionResponse(action model.AdmissionAction, reason string) BidStrategyResponse {
+	switch action {
+	case model.AdmissionActionReject:
+		return BidStrategyResponse{ShouldBid: false, Reason: reason}
+	case model.AdmissionActionRequireApproval:
+		return BidStrategyResponse{ShouldBid: false, ShouldWait: true, Reason: reason}
+	default:
+		return BidStrategyResponse{ShouldBid: true, Reason: reason}
+	}
+}
+
+// matchesAdmissionRule returns true if the job matches all the conditions of the rule that are set
+func matchesAdmissionRule(rule model.AdmissionRule, job model.Job) bool {
+	if len(rule.ClientIDs) > 0 && !slices.Contains(rule.ClientIDs, job.Metadata.ClientID) {
+		return false
+	}
+	if len(rule.Images) > 0 && !matchesAnyImage(rule.Images, job) {
+		return false
+	}
+	if len(rule.Networks) > 0 && !slices.Contains(rule.Networks, job.Spec.Network.Type) {
+		return false
+	}
+	if rule.ResourcesAbove != (model.ResourceUsageConfig{}) && !exceedsResources(rule.ResourcesAbove, job) {
+		return false
+	}
+	return true
+}
+
+func matchesAnyImage(patterns []string, job model.Job) bool {
+	if job.Spec.Engine != model.EngineDocker {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, job.Spec.Docker.Image); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// exceedsResources returns true if the job requests more than any of the resources that are set
+func exceedsResources(limits model.ResourceUsageConfig, job model.Job) bool {
+	limit := capacity.ParseResourceUsageConfig(limits)
+	usage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	return (limit.CPU > 0 && usage.CPU > limit.CPU) ||
+		(limit.Memory > 0 && usage.Memory > limit.Memory) ||
+		(limit.Disk > 0 && usage.Disk > limit.Disk) ||
+		(limit.GPU > 0 && usage.GPU > limit.GPU)
+}
+
+var _ BidStrategy = (*AdmissionPolicyStrategy)(nil)
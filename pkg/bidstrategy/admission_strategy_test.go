@@ -0,0 +1,57 @@
+//go:build unit || !integration
+
+package bidstrategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionPolicyStrategy(t *testing.T) {
+	strategy := NewAdmissionPolicyStrategy(model.AdmissionPolicy{
+		Rules: []model.AdmissionRule{
+			{Name: "trusted", Action: model.AdmissionActionAccept, ClientIDs: []string{"trusted"}},
+			{Name: "networked", Action: model.AdmissionActionRequireApproval, Networks: []model.Network{model.NetworkFull}},
+			{Name: "large", Action: model.AdmissionActionRequireApproval, ResourcesAbove: model.ResourceUsageConfig{GPU: "1"}},
+			{Name: "allowlist", Action: model.AdmissionActionAccept, Images: []string{"ubuntu", "ubuntu:*"}},
+		},
+		DefaultAction: model.AdmissionActionReject,
+	})
+
+	newJob := func(clientID string, image string, network model.Network, gpu string) model.Job {
+		return model.Job{
+			Metadata: model.Metadata{ClientID: clientID},
+			Spec: model.Spec{
+				Engine:    model.EngineDocker,
+				Docker:    model.JobSpecDocker{Image: image},
+				Network:   model.NetworkConfig{Type: network},
+				Resources: model.ResourceUsageConfig{GPU: gpu},
+			},
+		}
+	}
+
+	for _, test := range []struct {
+		name       string
+		job        model.Job
+		shouldBid  bool
+		shouldWait bool
+	}{
+		{"trusted client", newJob("trusted", "anything", model.NetworkFull, "4"), true, false},
+		{"allowed image", newJob("client", "ubuntu:22.04", model.NetworkNone, ""), true, false},
+		{"networked job", newJob("client", "ubuntu", model.NetworkFull, ""), false, true},
+		{"too many GPUs", newJob("client", "ubuntu", model.NetworkNone, "2"), false, true},
+		{"few enough GPUs", newJob("client", "ubuntu", model.NetworkNone, "1"), true, false},
+		{"unknown image", newJob("client", "alpine", model.NetworkNone, ""), false, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := strategy.ShouldBid(context.Background(), BidStrategyRequest{Job: test.job})
+			require.NoError(t, err)
+			require.Equal(t, test.shouldBid, response.ShouldBid)
+			require.Equal(t, test.shouldWait, response.ShouldWait)
+			require.NotEmpty(t, response.Reason)
+		})
+	}
+}
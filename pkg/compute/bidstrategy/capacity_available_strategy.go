@@ -2,6 +2,8 @@ package bidstrategy
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
@@ -33,6 +35,11 @@ func (s *AvailableCapacityStrategy) ShouldBid(
 
 func (s *AvailableCapacityStrategy) ShouldBidBasedOnUsage(
 	ctx context.Context, request bidstrategy.BidStrategyRequest, usage model.ResourceUsageData) (bidstrategy.BidStrategyResponse, error) {
+	// jobs carrying a reservation token run on the capacity held back by their reservation
+	if reservationID := request.Job.Spec.Reservation; reservationID != "" {
+		return s.shouldBidOnReservation(ctx, reservationID, request.Job.Metadata.ClientID, usage), nil
+	}
+
 	// skip bidding if we don't have enough capacity available
 	availableCapacity := s.runningCapacityTracker.GetAvailableCapacity(ctx).Add(s.enqueuedCapacityTracker.GetAvailableCapacity(ctx))
 	if !usage.LessThanEq(availableCapacity) {
@@ -45,5 +52,39 @@ func (s *AvailableCapacityStrategy) ShouldBidBasedOnUsage(
 	return bidstrategy.NewShouldBidResponse(), nil
 }
 
+// shouldBidOnReservation bids on jobs whose reservation is held by this node, was made by the job's client, is active
+// and is large enough to run them. The job is enqueued until other executions on the reservation are done.
+func (s *AvailableCapacityStrategy) shouldBidOnReservation(
+	ctx context.Context, reservationID string, clientID string, usage model.ResourceUsageData) bidstrategy.BidStrategyResponse {
+	for _, reservation := range s.runningCapacityTracker.GetReservations(ctx) {
+		if reservation.ID != reservationID {
+			continue
+		}
+		if reservation.ClientID != clientID {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    fmt.Sprintf("reservation %s was made by another client", reservationID),
+			}
+		}
+		if !reservation.IsActive(time.Now()) {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    fmt.Sprintf("reservation %s is not active", reservationID),
+			}
+		}
+		if !usage.LessThanEq(reservation.Resources) {
+			return bidstrategy.BidStrategyResponse{
+				ShouldBid: false,
+				Reason:    fmt.Sprintf("job requires more than the capacity of reservation %s", reservationID),
+			}
+		}
+		return bidstrategy.NewShouldBidResponse()
+	}
+	return bidstrategy.BidStrategyResponse{
+		ShouldBid: false,
+		Reason:    fmt.Sprintf("reservation %s is not held by this node", reservationID),
+	}
+}
+
 // compile-time interface check
 var _ bidstrategy.BidStrategy = (*AvailableCapacityStrategy)(nil)
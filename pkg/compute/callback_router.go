@@ -0,0 +1,58 @@
+package compute
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+)
+
+type RequesterRoutingCallbackParams struct {
+	Callback Callback
+	Store    store.ExecutionStore
+}
+
+// RequesterRoutingCallback Callback that routes the calls to the requester node currently owning the execution,
+// as the job of the execution can be adopted by another requester node while the execution is in progress.
+type RequesterRoutingCallback struct {
+	callback Callback
+	store    store.ExecutionStore
+}
+
+func NewRequesterRoutingCallback(params RequesterRoutingCallbackParams) *RequesterRoutingCallback {
+	return &RequesterRoutingCallback{
+		callback: params.Callback,
+		store:    params.Store,
+	}
+}
+
+func (c RequesterRoutingCallback) OnRunComplete(ctx context.Context, result RunResult) {
+	result.RoutingMetadata = c.route(ctx, result.RoutingMetadata, result.ExecutionID)
+	c.callback.OnRunComplete(ctx, result)
+}
+
+func (c RequesterRoutingCallback) OnPublishComplete(ctx context.Context, result PublishResult) {
+	result.RoutingMetadata = c.route(ctx, result.RoutingMetadata, result.ExecutionID)
+	c.callback.OnPublishComplete(ctx, result)
+}
+
+func (c RequesterRoutingCallback) OnCancelComplete(ctx context.Context, result CancelResult) {
+	result.RoutingMetadata = c.route(ctx, result.RoutingMetadata, result.ExecutionID)
+	c.callback.OnCancelComplete(ctx, result)
+}
+
+func (c RequesterRoutingCallback) OnComputeFailure(ctx context.Context, err ComputeError) {
+	err.RoutingMetadata = c.route(ctx, err.RoutingMetadata, err.ExecutionID)
+	c.callback.OnComputeFailure(ctx, err)
+}
+
+// route returns the routing metadata targeting the latest requester node of the execution
+func (c RequesterRoutingCallback) route(ctx context.Context, metadata RoutingMetadata, executionID string) RoutingMetadata {
+	execution, err := c.store.GetExecution(ctx, executionID)
+	if err == nil && execution.RequesterNodeID != "" {
+		metadata.TargetPeerID = execution.RequesterNodeID
+	}
+	return metadata
+}
+
+// compile-time interface check
+var _ Callback = &RequesterRoutingCallback{}
@@ -0,0 +1,204 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+)
+
+// TrackerPolicy is how the capacity of a compute node is committed to the executions it runs.
+type TrackerPolicy string
+
+const (
+	// TrackerPolicyStrict commits the capacity of the node to the resources requested by executions.
+	TrackerPolicyStrict TrackerPolicy = "strict"
+	// TrackerPolicyOvercommit commits more CPU and memory than the node has, by configurable ratios.
+	TrackerPolicyOvercommit TrackerPolicy = "overcommit"
+	// TrackerPolicyBinPacking commits again the resources that running executions requested but don't actually use,
+	// according to their sampled resource usage.
+	TrackerPolicyBinPacking TrackerPolicy = "bin-packing"
+)
+
+// TrackerConfig configures the policy of the tracker of the running capacity of a compute node.
+type TrackerConfig struct {
+	Policy TrackerPolicy
+	// CPUOvercommitRatio and MemoryOvercommitRatio multiply the CPU and memory that the overcommit policy commits
+	CPUOvercommitRatio    float64
+	MemoryOvercommitRatio float64
+	// BestEffortPool only runs best effort executions, of low priority jobs, on the capacity gained by the policy,
+	// while the other executions are guaranteed that the node has the resources they requested.
+	BestEffortPool bool
+}
+
+// UnusedCapacityProvider returns the resources that running executions requested but are not actually using.
+type UnusedCapacityProvider interface {
+	GetUnusedCapacity() model.ResourceUsageData
+}
+
+type TrackerParams struct {
+	MaxCapacity    model.ResourceUsageData
+	Config         TrackerConfig
+	UnusedCapacity UnusedCapacityProvider
+}
+
+// NewTracker returns a tracker of the running capacity of a compute node that follows the configured policy.
+func NewTracker(params TrackerParams) (Tracker, error) {
+	var tracker Tracker
+	switch params.Config.Policy {
+	case "", TrackerPolicyStrict:
+		tracker = NewLocalTracker(LocalTrackerParams{MaxCapacity: params.MaxCapacity})
+	case TrackerPolicyOvercommit:
+		if params.Config.CPUOvercommitRatio < 1 || params.Config.MemoryOvercommitRatio < 1 {
+			return nil, fmt.Errorf("overcommit ratios must be at least 1, got %f for CPU and %f for memory",
+				params.Config.CPUOvercommitRatio, params.Config.MemoryOvercommitRatio)
+		}
+		tracker = NewOvercommitTracker(OvercommitTrackerParams{
+			MaxCapacity: params.MaxCapacity,
+			CPURatio:    params.Config.CPUOvercommitRatio,
+			MemoryRatio: params.Config.MemoryOvercommitRatio,
+		})
+	case TrackerPolicyBinPacking:
+		if params.UnusedCapacity == nil {
+			return nil, fmt.Errorf("bin-packing requires the resource usage of running executions to be sampled")
+		}
+		tracker = NewBinPackingTracker(BinPackingTrackerParams{
+			MaxCapacity:    params.MaxCapacity,
+			UnusedCapacity: params.UnusedCapacity,
+		})
+	default:
+		return nil, fmt.Errorf("unknown capacity tracker policy %q", params.Config.Policy)
+	}
+
+	if params.Config.BestEffortPool {
+		tracker = NewPooledTracker(PooledTrackerParams{
+			Tracker:            tracker,
+			GuaranteedCapacity: params.MaxCapacity,
+		})
+	}
+	return tracker, nil
+}
+
+type OvercommitTrackerParams struct {
+	MaxCapacity model.ResourceUsageData
+	CPURatio    float64
+	MemoryRatio float64
+}
+
+// NewOvercommitTracker returns a tracker that commits the CPU and memory of the node multiplied by the overcommit
+// ratios, as executions rarely use all the resources they request. A single execution still can't request more
+// than the node has.
+func NewOvercommitTracker(params OvercommitTrackerParams) *LocalTracker {
+	overcommitted := params.MaxCapacity
+	overcommitted.CPU *= params.CPURatio
+	overcommitted.Memory = uint64(float64(overcommitted.Memory) * params.MemoryRatio)
+
+	tracker := NewLocalTracker(LocalTrackerParams{MaxCapacity: overcommitted})
+	tracker.limits = params.MaxCapacity
+	return tracker
+}
+
+type BinPackingTrackerParams struct {
+	MaxCapacity    model.ResourceUsageData
+	UnusedCapacity UnusedCapacityProvider
+}
+
+// NewBinPackingTracker returns a tracker that packs executions against the resources actually used by the running
+// executions, instead of the resources they requested. Executions are committed their full request until their
+// usage is sampled, and the capacity they don't use is committed to new executions.
+func NewBinPackingTracker(params BinPackingTrackerParams) *LocalTracker {
+	tracker := NewLocalTracker(LocalTrackerParams{MaxCapacity: params.MaxCapacity})
+	tracker.unusedCapacity = params.UnusedCapacity
+	return tracker
+}
+
+// BestEffortTracker is implemented by trackers that run best effort executions on a separate pool of capacity.
+type BestEffortTracker interface {
+	// AddBestEffortIfHasCapacity atomically adds the resource usage of a best effort execution to the tracker if the
+	// best effort pool has capacity for it. until is the time by which the usage is released, as in AddIfHasCapacity.
+	AddBestEffortIfHasCapacity(ctx context.Context, usage model.ResourceUsageData, until time.Time) bool
+	// RemoveBestEffort removes the resource usage of a best effort execution from the tracker.
+	RemoveBestEffort(ctx context.Context, usage model.ResourceUsageData, until time.Time)
+	// GetAvailableGuaranteedCapacity returns the capacity of the guaranteed pool that is not used by guaranteed
+	// executions. Guaranteed executions also need the capacity returned by GetAvailableCapacity to run.
+	GetAvailableGuaranteedCapacity(ctx context.Context) model.ResourceUsageData
+}
+
+type PooledTrackerParams struct {
+	Tracker            Tracker
+	GuaranteedCapacity model.ResourceUsageData
+}
+
+// PooledTracker splits the capacity committed by a tracker into a guaranteed pool and a best effort pool.
+// Guaranteed executions are limited to the guaranteed capacity, which is the capacity the node actually has, while
+// best effort executions can use all the capacity committed by the wrapped tracker. Best effort executions are
+// the first to be preempted when guaranteed executions need the capacity they use.
+type PooledTracker struct {
+	Tracker
+	guaranteedCapacity model.ResourceUsageData
+	guaranteedUsage    model.ResourceUsageData
+	mu                 sync.Mutex
+}
+
+func NewPooledTracker(params PooledTrackerParams) *PooledTracker {
+	return &PooledTracker{
+		Tracker:            params.Tracker,
+		guaranteedCapacity: params.GuaranteedCapacity,
+	}
+}
+
+func (t *PooledTracker) AddIfHasCapacity(ctx context.Context, usage model.ResourceUsageData, until time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	newGuaranteedUsage := t.guaranteedUsage.Add(usage)
+	if !newGuaranteedUsage.LessThanEq(t.guaranteedCapacity) || !t.Tracker.AddIfHasCapacity(ctx, usage, until) {
+		return false
+	}
+	t.guaranteedUsage = newGuaranteedUsage
+	return true
+}
+
+func (t *PooledTracker) Remove(ctx context.Context, usage model.ResourceUsageData, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Tracker.Remove(ctx, usage, until)
+	t.guaranteedUsage = subOrZero(t.guaranteedUsage, usage)
+}
+
+func (t *PooledTracker) AddBestEffortIfHasCapacity(ctx context.Context, usage model.ResourceUsageData, until time.Time) bool {
+	return t.Tracker.AddIfHasCapacity(ctx, usage, until)
+}
+
+func (t *PooledTracker) RemoveBestEffort(ctx context.Context, usage model.ResourceUsageData, until time.Time) {
+	t.Tracker.Remove(ctx, usage, until)
+}
+
+func (t *PooledTracker) GetAvailableGuaranteedCapacity(ctx context.Context) model.ResourceUsageData {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return subOrZero(t.guaranteedCapacity, t.guaranteedUsage)
+}
+
+// subOrZero subtracts other from usage, replacing the resources that would be negative with zeros.
+func subOrZero(usage, other model.ResourceUsageData) model.ResourceUsageData {
+	var result model.ResourceUsageData
+	if usage.CPU > other.CPU {
+		result.CPU = usage.CPU - other.CPU
+	}
+	if usage.Memory > other.Memory {
+		result.Memory = usage.Memory - other.Memory
+	}
+	if usage.Disk > other.Disk {
+		result.Disk = usage.Disk - other.Disk
+	}
+	if usage.GPU > other.GPU {
+		result.GPU = usage.GPU - other.GPU
+	}
+	return result
+}
+
+// compile-time check that the trackers implement the interfaces
+var _ Tracker = (*PooledTracker)(nil)
+var _ BestEffortTracker = (*PooledTracker)(nil)
@@ -0,0 +1,118 @@
+//go:build unit || !integration
+
+package capacity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedUnusedCapacity struct {
+	unused model.ResourceUsageData
+}
+
+func (f *fixedUnusedCapacity) GetUnusedCapacity() model.ResourceUsageData {
+	return f.unused
+}
+
+func TestNewTrackerValidation(t *testing.T) {
+	maxCapacity := model.ResourceUsageData{CPU: 4, Memory: 4096}
+	_, err := NewTracker(TrackerParams{
+		MaxCapacity: maxCapacity,
+		Config:      TrackerConfig{Policy: TrackerPolicyOvercommit, CPUOvercommitRatio: 0.5, MemoryOvercommitRatio: 1},
+	})
+	require.Error(t, err)
+
+	_, err = NewTracker(TrackerParams{
+		MaxCapacity: maxCapacity,
+		Config:      TrackerConfig{Policy: TrackerPolicyBinPacking},
+	})
+	require.Error(t, err, "bin-packing requires sampled usage")
+
+	_, err = NewTracker(TrackerParams{
+		MaxCapacity: maxCapacity,
+		Config:      TrackerConfig{Policy: "unknown"},
+	})
+	require.Error(t, err)
+}
+
+func TestOvercommitTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker, err := NewTracker(TrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 4096},
+		Config:      TrackerConfig{Policy: TrackerPolicyOvercommit, CPUOvercommitRatio: 2, MemoryOvercommitRatio: 1.5},
+	})
+	require.NoError(t, err)
+
+	usage := model.ResourceUsageData{CPU: 2, Memory: 1024}
+	for i := 0; i < 4; i++ {
+		require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	}
+	// memory is overcommitted by 1.5 while CPU is exhausted
+	require.False(t, tracker.AddIfHasCapacity(ctx, model.ResourceUsageData{CPU: 0.1, Memory: 1024}, time.Time{}))
+	require.True(t, tracker.AddIfHasCapacity(ctx, model.ResourceUsageData{Memory: 2048}, time.Time{}))
+
+	// a single execution is still limited by the actual capacity of the node
+	require.False(t, tracker.IsWithinLimits(ctx, model.ResourceUsageData{CPU: 6}))
+	require.True(t, tracker.IsWithinLimits(ctx, model.ResourceUsageData{CPU: 4}))
+}
+
+func TestBinPackingTracker(t *testing.T) {
+	ctx := context.Background()
+	unused := &fixedUnusedCapacity{}
+	tracker, err := NewTracker(TrackerParams{
+		MaxCapacity:    model.ResourceUsageData{CPU: 4, Memory: 4096},
+		Config:         TrackerConfig{Policy: TrackerPolicyBinPacking},
+		UnusedCapacity: unused,
+	})
+	require.NoError(t, err)
+
+	usage := model.ResourceUsageData{CPU: 2, Memory: 2048}
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+
+	// running executions only use half of what they requested
+	unused.unused = model.ResourceUsageData{CPU: 2, Memory: 2048}
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+}
+
+func TestPooledTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker, err := NewTracker(TrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 4096},
+		Config: TrackerConfig{
+			Policy:                TrackerPolicyOvercommit,
+			CPUOvercommitRatio:    2,
+			MemoryOvercommitRatio: 2,
+			BestEffortPool:        true,
+		},
+	})
+	require.NoError(t, err)
+	pooled, ok := tracker.(BestEffortTracker)
+	require.True(t, ok)
+
+	usage := model.ResourceUsageData{CPU: 2, Memory: 2048}
+	// guaranteed executions are limited to the actual capacity of the node
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+
+	// best effort executions use the overcommitted capacity
+	require.True(t, pooled.AddBestEffortIfHasCapacity(ctx, usage, time.Time{}))
+	require.True(t, pooled.AddBestEffortIfHasCapacity(ctx, usage, time.Time{}))
+	require.False(t, pooled.AddBestEffortIfHasCapacity(ctx, usage, time.Time{}))
+
+	// and guaranteed executions can't use the capacity held by best effort executions
+	tracker.Remove(ctx, usage, time.Time{})
+	require.True(t, pooled.AddBestEffortIfHasCapacity(ctx, usage, time.Time{}))
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	require.Equal(t, usage, pooled.GetAvailableGuaranteedCapacity(ctx))
+	pooled.RemoveBestEffort(ctx, usage, time.Time{})
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+}
@@ -2,6 +2,8 @@ package capacity
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	sync "github.com/bacalhau-project/golang-mutex-tracer"
@@ -12,48 +14,214 @@ type LocalTrackerParams struct {
 }
 
 // LocalTracker keeps track of the current resource usage of the local node in-memory.
+// By default, it is a strict sum-of-requests check against the max capacity. See policies.go for the trackers
+// that commit more capacity than the node has.
 type LocalTracker struct {
 	maxCapacity  model.ResourceUsageData
 	usedCapacity model.ResourceUsageData
-	mu           sync.Mutex
+	reservations map[string]*reservedCapacity
+	// usedUntil is the part of the used capacity that is released by a known time, keyed by the time in
+	// nanoseconds. The rest of the used capacity might be used indefinitely.
+	usedUntil map[int64]model.ResourceUsageData
+	mu        sync.Mutex
+
+	// limits is the largest resource usage of a single execution, which is the max capacity unless overcommitted
+	limits model.ResourceUsageData
+	// unusedCapacity, if set, is committed again to new executions on top of the max capacity
+	unusedCapacity UnusedCapacityProvider
+}
+
+// reservedCapacity is the capacity held back by a reservation, and how much of it is used by executions
+type reservedCapacity struct {
+	reservation  model.Reservation
+	usedCapacity model.ResourceUsageData
 }
 
 func NewLocalTracker(params LocalTrackerParams) *LocalTracker {
 	return &LocalTracker{
-		maxCapacity: params.MaxCapacity,
+		maxCapacity:  params.MaxCapacity,
+		reservations: make(map[string]*reservedCapacity),
+		usedUntil:    make(map[int64]model.ResourceUsageData),
+		limits:       params.MaxCapacity,
 	}
 }
 
 func (t *LocalTracker) IsWithinLimits(ctx context.Context, usage model.ResourceUsageData) bool {
-	return usage.LessThanEq(t.maxCapacity)
+	return usage.LessThanEq(t.limits)
 }
 
-func (t *LocalTracker) AddIfHasCapacity(ctx context.Context, usage model.ResourceUsageData) bool {
+func (t *LocalTracker) AddIfHasCapacity(ctx context.Context, usage model.ResourceUsageData, until time.Time) bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	newUsedCapacity := t.usedCapacity.Add(usage)
-	if newUsedCapacity.LessThanEq(t.maxCapacity) {
-		t.usedCapacity = newUsedCapacity
-		return true
+	if !newUsedCapacity.Add(t.heldCapacity(time.Now(), until)).LessThanEq(t.committableCapacity()) {
+		return false
 	}
-	return false
+	t.usedCapacity = newUsedCapacity
+	if !until.IsZero() {
+		t.usedUntil[until.UnixNano()] = t.usedUntil[until.UnixNano()].Add(usage)
+	}
+	return true
 }
 
 func (t *LocalTracker) GetAvailableCapacity(ctx context.Context) model.ResourceUsageData {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	return t.maxCapacity.Sub(t.usedCapacity)
+	now := time.Now()
+	return subOrZero(subOrZero(t.committableCapacity(), t.usedCapacity), t.heldCapacity(now, now))
 }
 
 func (t *LocalTracker) GetMaxCapacity(ctx context.Context) model.ResourceUsageData {
 	return t.maxCapacity
 }
 
-func (t *LocalTracker) Remove(ctx context.Context, usage model.ResourceUsageData) {
+func (t *LocalTracker) Remove(ctx context.Context, usage model.ResourceUsageData, until time.Time) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.usedCapacity = t.usedCapacity.Sub(usage)
+	if until.IsZero() {
+		return
+	}
+	if remaining := subOrZero(t.usedUntil[until.UnixNano()], usage); remaining.IsZero() {
+		delete(t.usedUntil, until.UnixNano())
+	} else {
+		t.usedUntil[until.UnixNano()] = remaining
+	}
+}
+
+func (t *LocalTracker) Reserve(ctx context.Context, reservation model.Reservation) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.expireReservations(now)
+	if _, ok := t.reservations[reservation.ID]; ok || reservation.IsExpired(now) {
+		return false
+	}
+
+	// the reservation must fit alongside the reservations it overlaps with, and alongside the running executions
+	// that can still be running when its window opens
+	required := reservation.Resources
+	for _, reserved := range t.reservations {
+		if reserved.reservation.Overlaps(reservation) {
+			required = required.Add(reserved.reservation.Resources)
+		}
+	}
+	if reservation.IsActive(now) {
+		required = required.Add(t.usedCapacity)
+	} else {
+		required = required.Add(t.usedCapacityAt(reservation.StartTime))
+	}
+	if !required.LessThanEq(t.maxCapacity) {
+		return false
+	}
+	t.reservations[reservation.ID] = &reservedCapacity{reservation: reservation}
+	return true
+}
+
+func (t *LocalTracker) AddReservedIfHasCapacity(ctx context.Context, reservationID string, usage model.ResourceUsageData) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.expireReservations(now)
+	reserved, ok := t.reservations[reservationID]
+	if !ok || !reserved.reservation.IsActive(now) {
+		return false
+	}
+
+	newUsedCapacity := reserved.usedCapacity.Add(usage)
+	if newUsedCapacity.LessThanEq(reserved.reservation.Resources) {
+		reserved.usedCapacity = newUsedCapacity
+		return true
+	}
+	return false
+}
+
+func (t *LocalTracker) RemoveReserved(ctx context.Context, reservationID string, usage model.ResourceUsageData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expireReservations(time.Now())
+	if reserved, ok := t.reservations[reservationID]; ok {
+		reserved.usedCapacity = reserved.usedCapacity.Sub(usage)
+		return
+	}
+	// the usage of expired reservations was moved to the node's used capacity
+	t.usedCapacity = t.usedCapacity.Sub(usage)
+}
+
+func (t *LocalTracker) CancelReservation(ctx context.Context, reservationID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expireReservations(time.Now())
+	reserved, ok := t.reservations[reservationID]
+	if !ok {
+		return false
+	}
+	t.usedCapacity = t.usedCapacity.Add(reserved.usedCapacity)
+	delete(t.reservations, reservationID)
+	return true
+}
+
+func (t *LocalTracker) GetReservations(ctx context.Context) []model.Reservation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expireReservations(time.Now())
+	reservations := make([]model.Reservation, 0, len(t.reservations))
+	for _, reserved := range t.reservations {
+		reservations = append(reservations, reserved.reservation)
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].StartTime.Before(reservations[j].StartTime)
+	})
+	return reservations
+}
+
+// committableCapacity returns the capacity that can be committed to executions, which is the max capacity plus
+// the capacity that running executions requested but are not using, when bin-packing.
+func (t *LocalTracker) committableCapacity() model.ResourceUsageData {
+	if t.unusedCapacity == nil {
+		return t.maxCapacity
+	}
+	return t.maxCapacity.Add(t.unusedCapacity.GetUnusedCapacity())
+}
+
+// heldCapacity returns the capacity held back by the reservations that are active now, or whose window opens
+// before until. Every reservation that has not expired is held back if until is zero.
+// make sure to call this function with the lock held
+func (t *LocalTracker) heldCapacity(now time.Time, until time.Time) model.ResourceUsageData {
+	t.expireReservations(now)
+	var held model.ResourceUsageData
+	for _, reserved := range t.reservations {
+		if reserved.reservation.IsActive(now) || until.IsZero() || reserved.reservation.StartTime.Before(until) {
+			held = held.Add(reserved.reservation.Resources)
+		}
+	}
+	return held
+}
+
+// usedCapacityAt returns the part of the used capacity that can still be used at the given time, which is all of
+// it except for the usage that is released by then.
+// make sure to call this function with the lock held
+func (t *LocalTracker) usedCapacityAt(at time.Time) model.ResourceUsageData {
+	used := t.usedCapacity
+	for until, usage := range t.usedUntil {
+		if until <= at.UnixNano() {
+			used = subOrZero(used, usage)
+		}
+	}
+	return used
+}
+
+// expireReservations removes the reservations whose window ended. Executions still running on the capacity of an
+// expired reservation keep using it as regular capacity until they are done.
+// make sure to call this function with the lock held
+func (t *LocalTracker) expireReservations(now time.Time) {
+	for id, reserved := range t.reservations {
+		if reserved.reservation.IsExpired(now) {
+			t.usedCapacity = t.usedCapacity.Add(reserved.usedCapacity)
+			delete(t.reservations, id)
+		}
+	}
 }
 
 // compile-time check that LocalTracker implements Tracker
@@ -0,0 +1,169 @@
+//go:build unit || !integration
+
+package capacity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalTrackerReservations(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewLocalTracker(LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 4096},
+	})
+	usage := model.ResourceUsageData{CPU: 1, Memory: 1024}
+	now := time.Now()
+
+	active := model.Reservation{
+		ID:        "active",
+		Resources: model.ResourceUsageData{CPU: 2, Memory: 2048},
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now.Add(time.Hour),
+	}
+	require.True(t, tracker.Reserve(ctx, active))
+	require.False(t, tracker.Reserve(ctx, active), "reservation ids are unique")
+
+	// a future reservation can't overlap with the active one beyond the node's capacity
+	require.False(t, tracker.Reserve(ctx, model.Reservation{
+		ID:        "too-large",
+		Resources: model.ResourceUsageData{CPU: 3, Memory: 3072},
+		StartTime: now.Add(30 * time.Minute),
+		EndTime:   now.Add(2 * time.Hour),
+	}))
+	future := model.Reservation{
+		ID:        "future",
+		Resources: model.ResourceUsageData{CPU: 3, Memory: 3072},
+		StartTime: now.Add(time.Hour),
+		EndTime:   now.Add(2 * time.Hour),
+	}
+	require.True(t, tracker.Reserve(ctx, future))
+	require.Equal(t, []model.Reservation{active, future}, tracker.GetReservations(ctx))
+
+	// the active reservation is held back from regular executions
+	require.Equal(t, model.ResourceUsageData{CPU: 2, Memory: 2048}, tracker.GetAvailableCapacity(ctx))
+	until := now.Add(30 * time.Minute)
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, until))
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, until))
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, until))
+
+	// and only released to executions carrying its token, while it is active
+	require.True(t, tracker.AddReservedIfHasCapacity(ctx, active.ID, usage))
+	require.True(t, tracker.AddReservedIfHasCapacity(ctx, active.ID, usage))
+	require.False(t, tracker.AddReservedIfHasCapacity(ctx, active.ID, usage))
+	require.False(t, tracker.AddReservedIfHasCapacity(ctx, future.ID, usage))
+	require.False(t, tracker.AddReservedIfHasCapacity(ctx, "unknown", usage))
+
+	tracker.RemoveReserved(ctx, active.ID, usage)
+	require.True(t, tracker.AddReservedIfHasCapacity(ctx, active.ID, usage))
+}
+
+func TestLocalTrackerExpiresReservations(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewLocalTracker(LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 4096},
+	})
+	usage := model.ResourceUsageData{CPU: 1, Memory: 1024}
+
+	reservation := model.Reservation{
+		ID:        "expiring",
+		Resources: model.ResourceUsageData{CPU: 2, Memory: 2048},
+		StartTime: time.Now().Add(-time.Minute),
+		EndTime:   time.Now().Add(100 * time.Millisecond),
+	}
+	require.True(t, tracker.Reserve(ctx, reservation))
+	require.True(t, tracker.AddReservedIfHasCapacity(ctx, reservation.ID, usage))
+	require.Equal(t, model.ResourceUsageData{CPU: 2, Memory: 2048}, tracker.GetAvailableCapacity(ctx))
+
+	// executions still running on the expired reservation keep using its capacity until they are done
+	time.Sleep(200 * time.Millisecond)
+	require.Empty(t, tracker.GetReservations(ctx))
+	require.Equal(t, model.ResourceUsageData{CPU: 3, Memory: 3072}, tracker.GetAvailableCapacity(ctx))
+	tracker.RemoveReserved(ctx, reservation.ID, usage)
+	require.Equal(t, model.ResourceUsageData{CPU: 4, Memory: 4096}, tracker.GetAvailableCapacity(ctx))
+}
+
+func TestLocalTrackerCancelsReservations(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewLocalTracker(LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 4096},
+	})
+	usage := model.ResourceUsageData{CPU: 1, Memory: 1024}
+
+	reservation := model.Reservation{
+		ID:        "canceled",
+		Resources: model.ResourceUsageData{CPU: 2, Memory: 2048},
+		StartTime: time.Now().Add(-time.Minute),
+		EndTime:   time.Now().Add(time.Hour),
+	}
+	require.True(t, tracker.Reserve(ctx, reservation))
+	require.True(t, tracker.AddReservedIfHasCapacity(ctx, reservation.ID, usage))
+
+	// executions still running on the canceled reservation keep using its capacity until they are done
+	require.True(t, tracker.CancelReservation(ctx, reservation.ID))
+	require.False(t, tracker.CancelReservation(ctx, reservation.ID))
+	require.Empty(t, tracker.GetReservations(ctx))
+	require.False(t, tracker.AddReservedIfHasCapacity(ctx, reservation.ID, usage))
+	require.Equal(t, model.ResourceUsageData{CPU: 3, Memory: 3072}, tracker.GetAvailableCapacity(ctx))
+	tracker.RemoveReserved(ctx, reservation.ID, usage)
+	require.Equal(t, model.ResourceUsageData{CPU: 4, Memory: 4096}, tracker.GetAvailableCapacity(ctx))
+}
+
+func TestLocalTrackerHoldsBackUpcomingReservations(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewLocalTracker(LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 4, Memory: 4096},
+	})
+	usage := model.ResourceUsageData{CPU: 2, Memory: 2048}
+	now := time.Now()
+
+	upcoming := model.Reservation{
+		ID:        "upcoming",
+		Resources: model.ResourceUsageData{CPU: 3, Memory: 3072},
+		StartTime: now.Add(time.Hour),
+		EndTime:   now.Add(2 * time.Hour),
+	}
+	require.True(t, tracker.Reserve(ctx, upcoming))
+
+	// an execution starting just before the window would still be running when it opens
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, now.Add(time.Hour+time.Minute)))
+	require.False(t, tracker.AddIfHasCapacity(ctx, usage, time.Time{}))
+	// while executions that time out before the window opens can use its capacity
+	require.True(t, tracker.AddIfHasCapacity(ctx, usage, now.Add(time.Hour-time.Minute)))
+	require.Equal(t, model.ResourceUsageData{CPU: 2, Memory: 2048}, tracker.GetAvailableCapacity(ctx))
+
+	// reservations must fit alongside the executions that can still be running when their window opens
+	overlapping := model.Reservation{
+		ID:        "overlapping",
+		Resources: model.ResourceUsageData{CPU: 1, Memory: 1024},
+		StartTime: now.Add(30 * time.Minute),
+		EndTime:   now.Add(45 * time.Minute),
+	}
+	require.True(t, tracker.Reserve(ctx, overlapping))
+	require.False(t, tracker.Reserve(ctx, model.Reservation{
+		ID:        "too-large",
+		Resources: model.ResourceUsageData{CPU: 3, Memory: 3072},
+		StartTime: now.Add(30 * time.Minute),
+		EndTime:   now.Add(45 * time.Minute),
+	}))
+	later := model.Reservation{
+		ID:        "later",
+		Resources: model.ResourceUsageData{CPU: 1, Memory: 1024},
+		StartTime: now.Add(2 * time.Hour),
+		EndTime:   now.Add(3 * time.Hour),
+	}
+	require.True(t, tracker.Reserve(ctx, later))
+
+	// the execution's usage no longer counts once it is removed
+	tracker.Remove(ctx, usage, now.Add(time.Hour-time.Minute))
+	require.True(t, tracker.Reserve(ctx, model.Reservation{
+		ID:        "fits",
+		Resources: model.ResourceUsageData{CPU: 3, Memory: 3072},
+		StartTime: now.Add(50 * time.Minute),
+		EndTime:   now.Add(55 * time.Minute),
+	}))
+}
@@ -2,25 +2,45 @@ package capacity
 
 import (
 	"context"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 )
 
 // Tracker keeps track of the current resource usage of the compute node.
 // The regular flow is to call AddIfHasCapacity before starting a new execution to reserve capacity, and Remove after
-// the execution is done to release the reserved capacity.
+// the execution is done to release the reserved capacity. Executions of jobs carrying a reservation token use
+// AddReservedIfHasCapacity and RemoveReserved instead.
 type Tracker interface {
 	// IsWithinLimits returns true if the given resource usage is within the limits of the compute node.
 	// Limits refer to the total capacity of the compute node, and not to the currently available capacity.
 	IsWithinLimits(ctx context.Context, usage model.ResourceUsageData) bool
 	// AddIfHasCapacity atomically adds the given resource usage to the tracker if the compute node has capacity for it.
-	AddIfHasCapacity(ctx context.Context, usage model.ResourceUsageData) bool
+	// until is the time by which the usage is released, such as when the execution times out, and the capacity held
+	// back by the reservations whose window opens before then is not available. A zero until holds back every
+	// reservation, as the usage might last indefinitely.
+	AddIfHasCapacity(ctx context.Context, usage model.ResourceUsageData, until time.Time) bool
 	// GetAvailableCapacity returns the available capacity of the compute node.
 	GetAvailableCapacity(ctx context.Context) model.ResourceUsageData
 	// GetMaxCapacity returns the total capacity of the compute node.
 	GetMaxCapacity(ctx context.Context) model.ResourceUsageData
-	// Remove removes the given resource usage from the tracker.
-	Remove(ctx context.Context, usage model.ResourceUsageData)
+	// Remove removes the given resource usage from the tracker, with the same until it was added with.
+	Remove(ctx context.Context, usage model.ResourceUsageData, until time.Time)
+
+	// Reserve holds back the reservation's resources during its window if the compute node has capacity for it.
+	// The held back capacity is no longer available to AddIfHasCapacity for usage that can last until the window
+	// opens, and the reservation must fit alongside the usage that can still last when its window opens.
+	Reserve(ctx context.Context, reservation model.Reservation) bool
+	// AddReservedIfHasCapacity atomically adds the given resource usage to the capacity held back by the reservation
+	// if the reservation is active and has capacity for it.
+	AddReservedIfHasCapacity(ctx context.Context, reservationID string, usage model.ResourceUsageData) bool
+	// RemoveReserved removes the given resource usage from the capacity held back by the reservation.
+	RemoveReserved(ctx context.Context, reservationID string, usage model.ResourceUsageData)
+	// CancelReservation releases the capacity held back by the reservation. Executions still running on it keep using
+	// it as regular capacity until they are done. It returns false if the tracker doesn't hold the reservation.
+	CancelReservation(ctx context.Context, reservationID string) bool
+	// GetReservations returns the reservations that have not expired yet.
+	GetReservations(ctx context.Context) []model.Reservation
 }
 
 // UsageCalculator calculates the resource usage of a job.
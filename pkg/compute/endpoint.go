@@ -3,6 +3,7 @@ package compute
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
@@ -20,24 +21,34 @@ type BaseEndpointParams struct {
 	UsageCalculator capacity.UsageCalculator
 	BidStrategy     bidstrategy.BidStrategy
 	Executor        Executor
+	RateCard        model.RateCard
+	CapacityTracker capacity.Tracker
+	// DefaultJobExecutionTimeout is the timeout of the executions of jobs that don't set one, which they are priced for
+	DefaultJobExecutionTimeout time.Duration
 }
 
 // Base implementation of Endpoint
 type BaseEndpoint struct {
-	id              string
-	executionStore  store.ExecutionStore
-	usageCalculator capacity.UsageCalculator
-	bidStrategy     bidstrategy.BidStrategy
-	executor        Executor
+	id                         string
+	executionStore             store.ExecutionStore
+	usageCalculator            capacity.UsageCalculator
+	bidStrategy                bidstrategy.BidStrategy
+	executor                   Executor
+	rateCard                   model.RateCard
+	capacityTracker            capacity.Tracker
+	defaultJobExecutionTimeout time.Duration
 }
 
 func NewBaseEndpoint(params BaseEndpointParams) BaseEndpoint {
 	return BaseEndpoint{
-		id:              params.ID,
-		executionStore:  params.ExecutionStore,
-		usageCalculator: params.UsageCalculator,
-		bidStrategy:     params.BidStrategy,
-		executor:        params.Executor,
+		id:                         params.ID,
+		executionStore:             params.ExecutionStore,
+		usageCalculator:            params.UsageCalculator,
+		bidStrategy:                params.BidStrategy,
+		executor:                   params.Executor,
+		rateCard:                   params.RateCard,
+		capacityTracker:            params.CapacityTracker,
+		defaultJobExecutionTimeout: params.DefaultJobExecutionTimeout,
 	}
 }
 
@@ -79,7 +90,37 @@ func (s BaseEndpoint) AskForBid(ctx context.Context, request AskForBidRequest) (
 		}
 	}
 
-	return s.prepareAskForBidResponse(ctx, request, jobRequirements, bidStrategyResponse)
+	var price float64
+	if bidStrategyResponse.ShouldBid {
+		// quote a price for the job and check it is within the client's budget
+		price, bidStrategyResponse = s.quote(request.Job, jobRequirements)
+	}
+
+	return s.prepareAskForBidResponse(ctx, request, jobRequirements, price, bidStrategyResponse)
+}
+
+// quote prices the execution of the job for as long as it can run, which is the node's default execution timeout
+// if the job doesn't set one. Jobs that don't require any resources are not priced by nodes that charge for them,
+// as they could run for free on any amount of resources.
+func (s BaseEndpoint) quote(job model.Job, jobRequirements model.ResourceUsageData) (float64, bidstrategy.BidStrategyResponse) {
+	if jobRequirements.IsZero() && !s.rateCard.IsZero() {
+		return 0, bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    "job doesn't require any resources to be priced for",
+		}
+	}
+	timeout := job.Spec.GetTimeout()
+	if timeout == 0 {
+		timeout = s.defaultJobExecutionTimeout
+	}
+	price := s.rateCard.Quote(jobRequirements, timeout)
+	if maxPrice := job.Spec.Deal.MaxPrice; maxPrice > 0 && price > maxPrice {
+		return price, bidstrategy.BidStrategyResponse{
+			ShouldBid: false,
+			Reason:    fmt.Sprintf("price quote %.2f exceeds the job's max price %.2f", price, maxPrice),
+		}
+	}
+	return price, bidstrategy.NewShouldBidResponse()
 }
 
 // Enqueues the job in the execution executionStore, and returns the response.
@@ -88,6 +129,7 @@ func (s BaseEndpoint) prepareAskForBidResponse(
 	ctx context.Context,
 	request AskForBidRequest,
 	resourceUsage model.ResourceUsageData,
+	price float64,
 	bidStrategyResponse bidstrategy.BidStrategyResponse) (AskForBidResponse, error) {
 	if !bidStrategyResponse.ShouldBid {
 		return AskForBidResponse{
@@ -124,6 +166,7 @@ func (s BaseEndpoint) prepareAskForBidResponse(
 				JobID:       request.Job.Metadata.ID,
 			},
 			Accepted: true,
+			Price:    price,
 		}, nil
 	}
 }
@@ -147,6 +190,12 @@ func (s BaseEndpoint) BidAccepted(ctx context.Context, request BidAcceptedReques
 	// Increment the number of jobs accepted by this compute node:
 	jobsAccepted.Add(ctx, 1)
 
+	if execution.Job.Spec.Deal.Gang {
+		execution.Job, err = withGangPeers(execution.Job, request.Gang)
+		if err != nil {
+			return BidAcceptedResponse{}, err
+		}
+	}
 	err = s.executor.Run(ctx, execution)
 	if err != nil {
 		return BidAcceptedResponse{}, err
@@ -250,5 +299,46 @@ func (s BaseEndpoint) CancelExecution(ctx context.Context, request CancelExecuti
 	}, nil
 }
 
+func (s BaseEndpoint) UpdateRequester(ctx context.Context, request UpdateRequesterRequest) (UpdateRequesterResponse, error) {
+	log.Ctx(ctx).Debug().Msgf("requester of execution %s updated to %s", request.ExecutionID, request.SourcePeerID)
+	err := s.executionStore.UpdateExecutionRequester(ctx, request.ExecutionID, request.SourcePeerID)
+	if err != nil {
+		return UpdateRequesterResponse{}, err
+	}
+	execution, err := s.executionStore.GetExecution(ctx, request.ExecutionID)
+	if err != nil {
+		return UpdateRequesterResponse{}, err
+	}
+	return UpdateRequesterResponse{
+		ExecutionMetadata: NewExecutionMetadata(execution),
+	}, nil
+}
+
+func (s BaseEndpoint) Reserve(ctx context.Context, request ReserveRequest) (ReserveResponse, error) {
+	log.Ctx(ctx).Debug().Msgf("asked to reserve capacity: %+v", request.Reservation)
+	if !s.capacityTracker.Reserve(ctx, request.Reservation) {
+		return ReserveResponse{
+			Reason: "not enough capacity available during the reservation window",
+		}, nil
+	}
+	return ReserveResponse{Accepted: true}, nil
+}
+
+func (s BaseEndpoint) CancelReservation(ctx context.Context, request CancelReservationRequest) (CancelReservationResponse, error) {
+	log.Ctx(ctx).Debug().Msgf("asked to cancel reservation %s", request.ReservationID)
+	for _, reservation := range s.capacityTracker.GetReservations(ctx) {
+		if reservation.ID != request.ReservationID {
+			continue
+		}
+		if reservation.ClientID != request.ClientID {
+			return CancelReservationResponse{Reason: "reservation was made by another client"}, nil
+		}
+		if s.capacityTracker.CancelReservation(ctx, request.ReservationID) {
+			return CancelReservationResponse{Canceled: true}, nil
+		}
+	}
+	return CancelReservationResponse{Reason: "reservation is not held by this node"}, nil
+}
+
 // Compile-time interface check:
 var _ Endpoint = (*BaseEndpoint)(nil)
@@ -0,0 +1,60 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPricingEndpoint(defaults model.ResourceUsageData) BaseEndpoint {
+	return NewBaseEndpoint(BaseEndpointParams{
+		ID:                         "compute",
+		ExecutionStore:             inmemory.NewStore(),
+		UsageCalculator:            capacity.NewDefaultsUsageCalculator(capacity.DefaultsUsageCalculatorParams{Defaults: defaults}),
+		BidStrategy:                bidstrategy.NewChainedBidStrategy(),
+		RateCard:                   model.RateCard{CPU: 1},
+		DefaultJobExecutionTimeout: 2 * time.Hour,
+	})
+}
+
+func TestAskForBidQuotesTheExecutionTimeout(t *testing.T) {
+	ctx := context.Background()
+	endpoint := newTestPricingEndpoint(model.ResourceUsageData{CPU: 1})
+
+	// jobs without a timeout are priced for the node's default execution timeout
+	response, err := endpoint.AskForBid(ctx, AskForBidRequest{Job: model.Job{Metadata: model.Metadata{ID: "default"}}})
+	require.NoError(t, err)
+	require.True(t, response.Accepted, response.Reason)
+	require.Equal(t, 2.0, response.Price)
+
+	response, err = endpoint.AskForBid(ctx, AskForBidRequest{Job: model.Job{
+		Metadata: model.Metadata{ID: "timeout"},
+		Spec:     model.Spec{Timeout: 3600},
+	}})
+	require.NoError(t, err)
+	require.True(t, response.Accepted, response.Reason)
+	require.Equal(t, 1.0, response.Price)
+
+	response, err = endpoint.AskForBid(ctx, AskForBidRequest{Job: model.Job{
+		Metadata: model.Metadata{ID: "budget"},
+		Spec:     model.Spec{Deal: model.Deal{MaxPrice: 1.5}},
+	}})
+	require.NoError(t, err)
+	require.False(t, response.Accepted)
+}
+
+func TestAskForBidRejectsJobsWithoutResources(t *testing.T) {
+	endpoint := newTestPricingEndpoint(model.ResourceUsageData{})
+	response, err := endpoint.AskForBid(context.Background(), AskForBidRequest{Job: model.Job{Metadata: model.Metadata{ID: "job"}}})
+	require.NoError(t, err)
+	require.False(t, response.Accepted)
+	require.Contains(t, response.Reason, "doesn't require any resources")
+}
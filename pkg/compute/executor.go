@@ -19,6 +19,7 @@ type BaseExecutorParams struct {
 	Executors       executor.ExecutorProvider
 	Verifiers       verifier.VerifierProvider
 	Publishers      publisher.PublisherProvider
+	UsageSampler    *ResourceUsageSampler
 	SimulatorConfig model.SimulatorConfigCompute
 }
 
@@ -32,6 +33,7 @@ type BaseExecutor struct {
 	executors       executor.ExecutorProvider
 	verifiers       verifier.VerifierProvider
 	publishers      publisher.PublisherProvider
+	usageSampler    *ResourceUsageSampler
 	simulatorConfig model.SimulatorConfigCompute
 }
 
@@ -43,6 +45,7 @@ func NewBaseExecutor(params BaseExecutorParams) *BaseExecutor {
 		executors:       params.Executors,
 		verifiers:       params.Verifiers,
 		publishers:      params.Publishers,
+		usageSampler:    params.UsageSampler,
 		simulatorConfig: params.SimulatorConfig,
 	}
 }
@@ -95,9 +98,16 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 	}
 
 	var runCommandResult *model.RunCommandResult
+	var resourceUsage *model.ResourceUsageSummary
 
 	if !e.simulatorConfig.IsBadActor {
+		if e.usageSampler != nil {
+			e.usageSampler.Track(execution)
+		}
 		runCommandResult, err = jobExecutor.Run(ctx, execution.Job, resultFolder)
+		if e.usageSampler != nil {
+			resourceUsage = e.usageSampler.Untrack(execution.ID)
+		}
 		if err != nil {
 			jobsFailed.Add(ctx, 1)
 		} else {
@@ -132,6 +142,7 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 		},
 		ResultProposal:   proposal,
 		RunCommandResult: runCommandResult,
+		ResourceUsage:    resourceUsage,
 	})
 	return err
 }
@@ -2,19 +2,29 @@ package compute
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util"
 	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/rs/zerolog/log"
 )
 
 type bufferTask struct {
 	execution  store.Execution
 	enqueuedAt time.Time
+	startedAt  time.Time
+	// deadline is when the execution times out, by which its running capacity is released
+	deadline  time.Time
+	cancel    context.CancelCauseFunc
+	preempted bool
 }
 
 func newBufferTask(execution store.Execution) *bufferTask {
@@ -24,10 +34,25 @@ func newBufferTask(execution store.Execution) *bufferTask {
 	}
 }
 
+func (t *bufferTask) priority() model.PriorityClass {
+	return t.execution.Job.Spec.Priority
+}
+
+// preemptionError is the cause of the cancellation of a running execution that was preempted to make room for an
+// execution of a higher priority job.
+type preemptionError struct {
+	preemptedBy string
+}
+
+func (e preemptionError) Error() string {
+	return fmt.Sprintf("execution preempted by higher priority execution %s", e.preemptedBy)
+}
+
 type ExecutorBufferParams struct {
 	ID                         string
 	DelegateExecutor           Executor
 	Callback                   Callback
+	Store                      store.ExecutionStore
 	RunningCapacityTracker     capacity.Tracker
 	EnqueuedCapacityTracker    capacity.Tracker
 	DefaultJobExecutionTimeout time.Duration
@@ -36,16 +61,21 @@ type ExecutorBufferParams struct {
 
 // ExecutorBuffer is a backend.Executor implementation that buffers executions locally until enough capacity is
 // available to be able to run them. The buffer accepts a delegate backend.Executor that will be used to run the jobs.
-// The buffer is implemented as a FIFO queue, where the order of the executions is determined by the order in which
-// they were enqueued. However, an execution with high resource usage requirements might be skipped if there are newer
-// jobs with lower resource usage requirements that can be executed immediately. This is done to improve utilization
-// of compute nodes, though it might result in starvation and should be re-evaluated in the future.
+// The buffer is implemented as a priority queue, where executions are ordered by the priority of their job, and then
+// by the order in which they were enqueued. However, an execution with high resource usage requirements might be
+// skipped if there are newer jobs with lower resource usage requirements that can be executed immediately. This is
+// done to improve utilization of compute nodes, though it might result in starvation and should be re-evaluated in
+// the future.
+// When an execution can't run because the node is busy with executions of lower priority jobs, some of them are
+// preempted to make room for it. Preempted executions are canceled, as executors can't checkpoint them, and are
+// reported to their requester node as preempted failures so that they can be retried elsewhere.
 type ExecutorBuffer struct {
 	ID                         string
 	runningCapacity            capacity.Tracker
 	enqueuedCapacity           capacity.Tracker
 	delegateService            Executor
 	callback                   Callback
+	store                      store.ExecutionStore
 	running                    map[string]*bufferTask
 	enqueued                   map[string]*bufferTask
 	enqueuedList               []string
@@ -62,6 +92,7 @@ func NewExecutorBuffer(params ExecutorBufferParams) *ExecutorBuffer {
 		enqueuedCapacity:           params.EnqueuedCapacityTracker,
 		delegateService:            params.DelegateExecutor,
 		callback:                   params.Callback,
+		store:                      params.Store,
 		running:                    make(map[string]*bufferTask),
 		enqueued:                   make(map[string]*bufferTask),
 		enqueuedList:               make([]string, 0),
@@ -109,17 +140,27 @@ func (s *ExecutorBuffer) Run(ctx context.Context, execution store.Execution) (er
 		err = fmt.Errorf("execution %s already running", execution.ID)
 		return
 	}
-	if !s.enqueuedCapacity.AddIfHasCapacity(ctx, execution.ResourceUsage) {
+	if !s.enqueuedCapacity.AddIfHasCapacity(ctx, execution.ResourceUsage, time.Time{}) {
 		err = fmt.Errorf("not enough capacity to enqueue job")
 		return
 	}
 
-	s.enqueued[execution.ID] = newBufferTask(execution)
-	s.enqueuedList = append(s.enqueuedList, execution.ID)
+	s.enqueue(newBufferTask(execution))
 	s.deque()
 	return err
 }
 
+// enqueue adds the task to the queue after all the tasks of the same or higher priority.
+func (s *ExecutorBuffer) enqueue(task *bufferTask) {
+	position := sort.Search(len(s.enqueuedList), func(i int) bool {
+		return s.enqueued[s.enqueuedList[i]].priority() < task.priority()
+	})
+	s.enqueued[task.execution.ID] = task
+	s.enqueuedList = append(s.enqueuedList, "")
+	copy(s.enqueuedList[position+1:], s.enqueuedList[position:])
+	s.enqueuedList[position] = task.execution.ID
+}
+
 // doRun triggers the execution by the delegate backend.Executor and frees up the capacity when the execution is done.
 func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 	ctx = system.AddJobIDToBaggage(ctx, task.execution.Job.Metadata.ID)
@@ -127,11 +168,9 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute.ExecutorBuffer.Run")
 	defer span.End()
 
-	timeout := task.execution.Job.Spec.GetTimeout()
-	if timeout == 0 {
-		timeout = s.defaultJobExecutionTimeout
-	}
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+	timeout := s.executionTimeout(task.execution)
+	defer task.cancel(nil)
+	ctx, cancel := context.WithDeadline(ctx, task.deadline)
 	defer cancel()
 
 	ch := make(chan error)
@@ -139,8 +178,28 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 		ch <- s.delegateService.Run(ctx, task.execution)
 	}()
 
+	timedOut := false
 	select {
 	case <-ctx.Done():
+		timedOut = true
+	case <-ch:
+		// no need to check for run errors as they are already handled by the delegate backend.Executor and
+		// to the callback.
+	}
+
+	// preempted executions were already failed in the store, and the delegate backend.Executor doesn't report them.
+	var preemption preemptionError
+	if errors.As(context.Cause(ctx), &preemption) {
+		s.callback.OnComputeFailure(util.NewDetachedContext(ctx), ComputeError{
+			ExecutionMetadata: NewExecutionMetadata(task.execution),
+			RoutingMetadata: RoutingMetadata{
+				SourcePeerID: s.ID,
+				TargetPeerID: task.execution.RequesterNodeID,
+			},
+			Err:       preemption.Error(),
+			Preempted: true,
+		})
+	} else if timedOut {
 		s.callback.OnComputeFailure(ctx, ComputeError{
 			ExecutionMetadata: NewExecutionMetadata(task.execution),
 			RoutingMetadata: RoutingMetadata{
@@ -149,14 +208,11 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 			},
 			Err: fmt.Sprintf("execution timed out after %s", timeout),
 		})
-	case <-ch:
-		// no need to check for run errors as they are already handled by the delegate backend.Executor and
-		// to the callback.
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.runningCapacity.Remove(ctx, task.execution.ResourceUsage)
+	s.removeRunningCapacity(ctx, task)
 	delete(s.running, task.execution.ID)
 	s.deque()
 }
@@ -171,7 +227,7 @@ func (s *ExecutorBuffer) deque() {
 	}
 	ctx := context.Background()
 
-	// We are maintain the order of enqueued executions treat it as a FIFO queue, while allowing to skip over jobs
+	// We are maintain the order of enqueued executions treat it as a priority queue, while allowing to skip over jobs
 	// that require more resources than the current capacity. This is done to improve utilization of compute nodes,
 	// though it might result in starvation and should be re-evaluated in the future.
 	remainingEnqueuedList := make([]string, 0, len(s.enqueuedList))
@@ -179,12 +235,18 @@ func (s *ExecutorBuffer) deque() {
 	for _, executionID := range s.enqueuedList {
 		task := s.enqueued[executionID]
 
-		if s.runningCapacity.AddIfHasCapacity(ctx, task.execution.ResourceUsage) {
-			s.enqueuedCapacity.Remove(ctx, task.execution.ResourceUsage)
+		deadline := time.Now().Add(s.executionTimeout(task.execution))
+		if s.addRunningCapacity(ctx, task.execution, deadline) {
+			s.enqueuedCapacity.Remove(ctx, task.execution.ResourceUsage, time.Time{})
 			delete(s.enqueued, executionID)
 			s.running[executionID] = task
-			go s.doRun(logger.ContextWithNodeIDLogger(context.Background(), s.ID), task)
+			runCtx, cancel := context.WithCancelCause(logger.ContextWithNodeIDLogger(context.Background(), s.ID))
+			task.startedAt = time.Now()
+			task.deadline = deadline
+			task.cancel = cancel
+			go s.doRun(runCtx, task)
 		} else {
+			s.preemptFor(ctx, task)
 			remainingEnqueuedList = append(remainingEnqueuedList, executionID)
 		}
 	}
@@ -192,6 +254,142 @@ func (s *ExecutorBuffer) deque() {
 	s.backoffUntil = time.Now().Add(s.backoffDuration)
 }
 
+// preemptFor preempts running executions of lower priority jobs if this frees up enough capacity to run the task.
+// The most recently started executions of the lowest priority jobs are preempted first. Executions running on a
+// reservation are never preempted, nor used to run other executions. No further executions are preempted while
+// previously preempted ones are still winding down, as the capacity they free up goes to the next enqueued task.
+// If the running capacity has a best effort pool, the task also needs room in the guaranteed pool, which only
+// preempting guaranteed executions frees up.
+func (s *ExecutorBuffer) preemptFor(ctx context.Context, task *bufferTask) {
+	if task.execution.Job.Spec.Reservation != "" {
+		return
+	}
+	var candidates []*bufferTask
+	for _, running := range s.running {
+		if running.preempted {
+			return
+		}
+		if running.priority() < task.priority() && running.execution.Job.Spec.Reservation == "" {
+			candidates = append(candidates, running)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].priority() != candidates[j].priority() {
+			return candidates[i].priority() < candidates[j].priority()
+		}
+		return candidates[i].startedAt.After(candidates[j].startedAt)
+	})
+
+	usage := task.execution.ResourceUsage
+	available := s.runningCapacity.GetAvailableCapacity(ctx)
+	pool, pooled := s.runningCapacity.(capacity.BestEffortTracker)
+	var guaranteed model.ResourceUsageData
+	if pooled {
+		guaranteed = pool.GetAvailableGuaranteedCapacity(ctx)
+	}
+	fits := func() bool {
+		return usage.LessThanEq(available) && (!pooled || usage.LessThanEq(guaranteed))
+	}
+
+	var victims []*bufferTask
+	for _, candidate := range candidates {
+		if fits() {
+			break
+		}
+		_, bestEffort := s.bestEffortPool(candidate.execution)
+		if bestEffort && usage.LessThanEq(available) {
+			// only the guaranteed pool is short of capacity, which best effort executions don't use
+			continue
+		}
+		available = available.Add(candidate.execution.ResourceUsage)
+		if !bestEffort {
+			guaranteed = guaranteed.Add(candidate.execution.ResourceUsage)
+		}
+		victims = append(victims, candidate)
+	}
+	if len(victims) == 0 || !fits() {
+		return
+	}
+
+	cause := preemptionError{preemptedBy: task.execution.ID}
+	for _, victim := range victims {
+		// executions that are no longer running are about to free up their capacity anyway
+		err := s.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+			ExecutionID:   victim.execution.ID,
+			ExpectedState: store.ExecutionStateRunning,
+			NewState:      store.ExecutionStateFailed,
+			Comment:       cause.Error(),
+		})
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("[preemptFor] not preempting execution %s", victim.execution.ID)
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("preempting execution %s of job %s for execution %s of job %s",
+			victim.execution.ID, victim.execution.Job.ID(), task.execution.ID, task.execution.Job.ID())
+		victim.preempted = true
+		victim.cancel(cause)
+	}
+}
+
+// addRunningCapacity adds the execution's resource usage to the running capacity if there is enough capacity for it
+// until the execution's deadline, including the capacity of the reservations whose window opens in the meantime.
+// Executions of jobs carrying a reservation token only run on the capacity held back by their reservation, if it was
+// made by the job's client, and executions of low priority jobs run on the best effort pool if the running capacity
+// has one.
+func (s *ExecutorBuffer) addRunningCapacity(ctx context.Context, execution store.Execution, deadline time.Time) bool {
+	if reservationID := execution.Job.Spec.Reservation; reservationID != "" {
+		if !s.isReservedForClient(ctx, reservationID, execution.Job.Metadata.ClientID) {
+			return false
+		}
+		return s.runningCapacity.AddReservedIfHasCapacity(ctx, reservationID, execution.ResourceUsage)
+	}
+	if pool, ok := s.bestEffortPool(execution); ok {
+		return pool.AddBestEffortIfHasCapacity(ctx, execution.ResourceUsage, deadline)
+	}
+	return s.runningCapacity.AddIfHasCapacity(ctx, execution.ResourceUsage, deadline)
+}
+
+// isReservedForClient returns true if the reservation is held by this node and was made by the client.
+func (s *ExecutorBuffer) isReservedForClient(ctx context.Context, reservationID string, clientID string) bool {
+	for _, reservation := range s.runningCapacity.GetReservations(ctx) {
+		if reservation.ID == reservationID {
+			return reservation.ClientID == clientID
+		}
+	}
+	return false
+}
+
+// removeRunningCapacity frees up the running capacity that was used by the task's execution.
+func (s *ExecutorBuffer) removeRunningCapacity(ctx context.Context, task *bufferTask) {
+	execution := task.execution
+	if reservationID := execution.Job.Spec.Reservation; reservationID != "" {
+		s.runningCapacity.RemoveReserved(ctx, reservationID, execution.ResourceUsage)
+		return
+	}
+	if pool, ok := s.bestEffortPool(execution); ok {
+		pool.RemoveBestEffort(ctx, execution.ResourceUsage, task.deadline)
+		return
+	}
+	s.runningCapacity.Remove(ctx, execution.ResourceUsage, task.deadline)
+}
+
+// executionTimeout returns the timeout of the execution, which is the node's default timeout if its job has none.
+func (s *ExecutorBuffer) executionTimeout(execution store.Execution) time.Duration {
+	if timeout := execution.Job.Spec.GetTimeout(); timeout != 0 {
+		return timeout
+	}
+	return s.defaultJobExecutionTimeout
+}
+
+// bestEffortPool returns the best effort pool of the running capacity if the execution is best effort.
+func (s *ExecutorBuffer) bestEffortPool(execution store.Execution) (capacity.BestEffortTracker, bool) {
+	if execution.Job.Spec.Priority != model.PriorityClassLow {
+		return nil, false
+	}
+	pool, ok := s.runningCapacity.(capacity.BestEffortTracker)
+	return pool, ok
+}
+
 func (s *ExecutorBuffer) Publish(_ context.Context, execution store.Execution) error {
 	// TODO: Enqueue publish tasks
 	go func() {
@@ -0,0 +1,327 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+// mockBufferedExecutor runs executions until they are finished by the test, or canceled by the buffer
+type mockBufferedExecutor struct {
+	Executor
+	store    store.ExecutionStore
+	mu       sync.Mutex
+	started  []string
+	finished map[string]chan struct{}
+}
+
+func (e *mockBufferedExecutor) Run(ctx context.Context, execution store.Execution) error {
+	err := e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateRunning,
+	})
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.started = append(e.started, execution.ID)
+	e.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-e.finishedChannel(execution.ID):
+	}
+	return nil
+}
+
+func (e *mockBufferedExecutor) finish(executionID string) {
+	close(e.finishedChannel(executionID))
+}
+
+func (e *mockBufferedExecutor) finishedChannel(executionID string) chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.finished[executionID]; !ok {
+		e.finished[executionID] = make(chan struct{})
+	}
+	return e.finished[executionID]
+}
+
+func (e *mockBufferedExecutor) startedExecutions() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.started...)
+}
+
+// mockFailureCallback records the failures reported by the buffer
+type mockFailureCallback struct {
+	Callback
+	mu       sync.Mutex
+	failures []ComputeError
+}
+
+func (c *mockFailureCallback) OnComputeFailure(_ context.Context, err ComputeError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = append(c.failures, err)
+}
+
+func (c *mockFailureCallback) computeFailures() []ComputeError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ComputeError{}, c.failures...)
+}
+
+type ExecutorBufferSuite struct {
+	suite.Suite
+	ctx      context.Context
+	store    store.ExecutionStore
+	executor *mockBufferedExecutor
+	callback *mockFailureCallback
+	tracker  *capacity.LocalTracker
+	buffer   *ExecutorBuffer
+}
+
+func TestExecutorBufferSuite(t *testing.T) {
+	suite.Run(t, new(ExecutorBufferSuite))
+}
+
+func (s *ExecutorBufferSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = inmemory.NewStore()
+	s.executor = &mockBufferedExecutor{store: s.store, finished: make(map[string]chan struct{})}
+	s.callback = &mockFailureCallback{}
+	s.setupBuffer(capacity.NewLocalTracker(capacity.LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 2},
+	}))
+}
+
+func (s *ExecutorBufferSuite) setupBuffer(runningCapacity capacity.Tracker) {
+	s.buffer = NewExecutorBuffer(ExecutorBufferParams{
+		ID:                     "compute",
+		DelegateExecutor:       s.executor,
+		Callback:               s.callback,
+		Store:                  s.store,
+		RunningCapacityTracker: runningCapacity,
+		EnqueuedCapacityTracker: capacity.NewLocalTracker(capacity.LocalTrackerParams{
+			MaxCapacity: model.ResourceUsageData{CPU: 10},
+		}),
+		DefaultJobExecutionTimeout: time.Minute,
+	})
+	if tracker, ok := runningCapacity.(*capacity.LocalTracker); ok {
+		s.tracker = tracker
+	}
+	s.T().Cleanup(s.finishAll)
+}
+
+// newExecution creates an execution of a job of the given priority requiring one CPU
+func (s *ExecutorBufferSuite) newExecution(id string, priority model.PriorityClass, reservation string) store.Execution {
+	job := model.Job{
+		Metadata: model.Metadata{ID: id, ClientID: "client"},
+		Spec:     model.Spec{Priority: priority, Reservation: reservation},
+	}
+	execution := *store.NewExecution(id, job, "requester", model.ResourceUsageData{CPU: 1})
+	s.Require().NoError(s.store.CreateExecution(s.ctx, execution))
+	return execution
+}
+
+func (s *ExecutorBufferSuite) run(id string, priority model.PriorityClass) {
+	s.runReserved(id, priority, "")
+}
+
+func (s *ExecutorBufferSuite) runReserved(id string, priority model.PriorityClass, reservation string) {
+	s.Require().NoError(s.buffer.Run(s.ctx, s.newExecution(id, priority, reservation)))
+}
+
+func (s *ExecutorBufferSuite) requireStarted(ids ...string) {
+	s.Require().Eventually(func() bool {
+		return len(s.executor.startedExecutions()) == len(ids)
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Require().Equal(ids, s.executor.startedExecutions())
+}
+
+// requireStartedInAnyOrder is like requireStarted for executions that are started concurrently
+func (s *ExecutorBufferSuite) requireStartedInAnyOrder(ids ...string) {
+	s.Require().Eventually(func() bool {
+		return len(s.executor.startedExecutions()) == len(ids)
+	}, 5*time.Second, 10*time.Millisecond)
+	s.Require().ElementsMatch(ids, s.executor.startedExecutions())
+}
+
+func (s *ExecutorBufferSuite) requireState(id string, state store.ExecutionState) {
+	execution, err := s.store.GetExecution(s.ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(state, execution.State, "execution %s", id)
+}
+
+func (s *ExecutorBufferSuite) requirePreempted(ids ...string) {
+	var preempted []string
+	for _, failure := range s.callback.computeFailures() {
+		s.Require().True(failure.Preempted, failure.Err)
+		preempted = append(preempted, failure.ExecutionID)
+	}
+	s.Require().ElementsMatch(ids, preempted)
+	for _, id := range ids {
+		s.requireState(id, store.ExecutionStateFailed)
+	}
+}
+
+// finishAll finishes the executions that are still running, and waits for the buffer to be empty
+func (s *ExecutorBufferSuite) finishAll() {
+	for _, id := range s.executor.startedExecutions() {
+		select {
+		case <-s.executor.finishedChannel(id):
+		default:
+			s.executor.finish(id)
+		}
+	}
+	s.Require().Eventually(func() bool {
+		return len(s.buffer.RunningExecutions()) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *ExecutorBufferSuite) TestPriorityOrdering() {
+	s.setupBuffer(capacity.NewLocalTracker(capacity.LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 1},
+	}))
+	s.run("blocker", model.PriorityClassHigh)
+	s.requireStarted("blocker")
+
+	s.run("low", model.PriorityClassLow)
+	s.run("normal-1", model.PriorityClassNormal)
+	s.run("high", model.PriorityClassHigh)
+	s.run("normal-2", model.PriorityClassNormal)
+	s.Len(s.buffer.EnqueuedExecutions(), 4)
+
+	// executions start by priority, and then in the order they were enqueued
+	s.executor.finish("blocker")
+	s.requireStarted("blocker", "high")
+	s.executor.finish("high")
+	s.requireStarted("blocker", "high", "normal-1")
+	s.executor.finish("normal-1")
+	s.requireStarted("blocker", "high", "normal-1", "normal-2")
+	s.executor.finish("normal-2")
+	s.requireStarted("blocker", "high", "normal-1", "normal-2", "low")
+	s.finishAll()
+	s.Empty(s.callback.computeFailures())
+}
+
+func (s *ExecutorBufferSuite) TestPreemptsLowestPriorityMostRecentlyStarted() {
+	s.setupBuffer(capacity.NewLocalTracker(capacity.LocalTrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 3},
+	}))
+	s.run("low-1", model.PriorityClassLow)
+	s.requireStarted("low-1")
+	s.run("low-2", model.PriorityClassLow)
+	s.requireStarted("low-1", "low-2")
+	s.run("normal", model.PriorityClassNormal)
+	s.requireStarted("low-1", "low-2", "normal")
+
+	s.run("high", model.PriorityClassHigh)
+	s.requireStarted("low-1", "low-2", "normal", "high")
+	s.requirePreempted("low-2")
+	s.requireState("low-1", store.ExecutionStateRunning)
+	s.requireState("normal", store.ExecutionStateRunning)
+
+	// the preempted execution is only reported once, and the others complete on their own
+	s.finishAll()
+	s.requirePreempted("low-2")
+}
+
+func (s *ExecutorBufferSuite) TestNoPreemptionWhileVictimsWindDown() {
+	s.run("low-1", model.PriorityClassLow)
+	s.requireStarted("low-1")
+	s.run("low-2", model.PriorityClassLow)
+	s.requireStarted("low-1", "low-2")
+
+	// enqueue two executions at once, so that the second one is considered while the victim of the first one is
+	// still running
+	s.buffer.mu.Lock()
+	for _, id := range []string{"high-1", "high-2"} {
+		execution := s.newExecution(id, model.PriorityClassHigh, "")
+		s.Require().True(s.buffer.enqueuedCapacity.AddIfHasCapacity(s.ctx, execution.ResourceUsage, time.Time{}))
+		s.buffer.enqueue(newBufferTask(execution))
+	}
+	s.buffer.deque()
+	s.True(s.buffer.running["low-2"].preempted)
+	s.False(s.buffer.running["low-1"].preempted)
+	s.buffer.mu.Unlock()
+
+	// once the victim is done, the next execution preempts another one
+	s.requireStartedInAnyOrder("low-1", "low-2", "high-1", "high-2")
+	s.requirePreempted("low-1", "low-2")
+	s.finishAll()
+	s.requirePreempted("low-1", "low-2")
+}
+
+func (s *ExecutorBufferSuite) TestPreemptionWithBestEffortPool() {
+	tracker, err := capacity.NewTracker(capacity.TrackerParams{
+		MaxCapacity: model.ResourceUsageData{CPU: 2},
+		Config: capacity.TrackerConfig{
+			Policy:                capacity.TrackerPolicyOvercommit,
+			CPUOvercommitRatio:    2,
+			MemoryOvercommitRatio: 1,
+			BestEffortPool:        true,
+		},
+	})
+	s.Require().NoError(err)
+	s.setupBuffer(tracker)
+
+	s.run("normal-1", model.PriorityClassNormal)
+	s.requireStarted("normal-1")
+	s.run("normal-2", model.PriorityClassNormal)
+	s.requireStarted("normal-1", "normal-2")
+	s.run("low", model.PriorityClassLow)
+	s.requireStarted("normal-1", "normal-2", "low")
+
+	// the overcommitted capacity has room for the execution, but the guaranteed pool doesn't, and preempting the
+	// best effort execution wouldn't free it up
+	s.run("high", model.PriorityClassHigh)
+	s.requireStarted("normal-1", "normal-2", "low", "high")
+	s.requirePreempted("normal-2")
+	s.requireState("low", store.ExecutionStateRunning)
+	s.requireState("normal-1", store.ExecutionStateRunning)
+	s.finishAll()
+}
+
+func (s *ExecutorBufferSuite) TestReservationsAreExemptFromPreemption() {
+	now := time.Now()
+	s.Require().True(s.tracker.Reserve(s.ctx, model.Reservation{
+		ID:        "reservation",
+		ClientID:  "client",
+		Resources: model.ResourceUsageData{CPU: 1},
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now.Add(time.Hour),
+	}))
+	s.Require().Equal(model.ResourceUsageData{CPU: 1}, s.tracker.GetAvailableCapacity(s.ctx))
+
+	s.runReserved("reserved", model.PriorityClassLow, "reservation")
+	s.requireStarted("reserved")
+	s.run("normal", model.PriorityClassNormal)
+	s.requireStarted("reserved", "normal")
+
+	// executions waiting for their reservation don't preempt executions that are not running on it
+	s.runReserved("high-reserved", model.PriorityClassHigh, "reservation")
+	s.Len(s.buffer.EnqueuedExecutions(), 1)
+	s.Empty(s.callback.computeFailures())
+
+	// executions running on a reservation are not preempted, even if their job has a lower priority
+	s.run("high", model.PriorityClassHigh)
+	s.requireStarted("reserved", "normal", "high")
+	s.requirePreempted("normal")
+	s.requireState("reserved", store.ExecutionStateRunning)
+
+	s.executor.finish("reserved")
+	s.requireStarted("reserved", "normal", "high", "high-reserved")
+	s.finishAll()
+	s.requirePreempted("normal")
+}
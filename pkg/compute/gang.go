@@ -0,0 +1,59 @@
+package compute
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+const (
+	// GangAddressEnvVar is the host:port address the member of a gang job listens on for the other members, which
+	// reach it directly on the host of its node when the job has full networking. The port is picked for the job
+	// and rank of the member, so that members running on the same host don't collide.
+	GangAddressEnvVar = "BACALHAU_GANG_ADDRESS"
+	// GangPeersEnvVar lists the host:port addresses of the other members of a gang job, ordered by rank and
+	// separated by commas, e.g. "10.0.0.1:31234,10.0.0.2:31236"
+	GangPeersEnvVar = "BACALHAU_GANG_PEERS"
+	// GangSizeEnvVar is the number of members of a gang job
+	GangSizeEnvVar = "BACALHAU_GANG_SIZE"
+	// GangRankEnvVar is the position of this member among the members of a gang job, from 0
+	GangRankEnvVar = "BACALHAU_GANG_RANK"
+)
+
+// withGangPeers returns a copy of the job whose execution is given its own address and the addresses of the other
+// members of its gang through environment variables, so that the members can reach each other.
+func withGangPeers(job model.Job, gang GangMembership) (model.Job, error) {
+	if gang.Rank < 0 || gang.Rank >= len(gang.Addresses) {
+		return job, fmt.Errorf("gang rank %d is out of the %d members of the gang", gang.Rank, len(gang.Addresses))
+	}
+	peers := make([]string, 0, len(gang.Addresses)-1)
+	peers = append(peers, gang.Addresses[:gang.Rank]...)
+	peers = append(peers, gang.Addresses[gang.Rank+1:]...)
+
+	env := map[string]string{
+		GangAddressEnvVar: gang.Addresses[gang.Rank],
+		GangPeersEnvVar:   strings.Join(peers, ","),
+		GangSizeEnvVar:    fmt.Sprint(len(gang.Addresses)),
+		GangRankEnvVar:    fmt.Sprint(gang.Rank),
+	}
+	switch job.Spec.Engine {
+	case model.EngineDocker:
+		variables := make([]string, 0, len(job.Spec.Docker.EnvironmentVariables)+len(env))
+		variables = append(variables, job.Spec.Docker.EnvironmentVariables...)
+		for _, key := range []string{GangAddressEnvVar, GangPeersEnvVar, GangSizeEnvVar, GangRankEnvVar} {
+			variables = append(variables, fmt.Sprintf("%s=%s", key, env[key]))
+		}
+		job.Spec.Docker.EnvironmentVariables = variables
+	case model.EngineWasm:
+		variables := make(map[string]string, len(job.Spec.Wasm.EnvironmentVariables)+len(env))
+		for key, value := range job.Spec.Wasm.EnvironmentVariables {
+			variables[key] = value
+		}
+		for key, value := range env {
+			variables[key] = value
+		}
+		job.Spec.Wasm.EnvironmentVariables = variables
+	}
+	return job, nil
+}
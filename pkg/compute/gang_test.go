@@ -0,0 +1,53 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithGangPeers(t *testing.T) {
+	gang := GangMembership{
+		Addresses: []string{"10.0.0.1:31234", "10.0.0.2:31235", "[fd00::3]:31236"},
+		Rank:      1,
+	}
+	job, err := withGangPeers(model.Job{Spec: model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{EnvironmentVariables: []string{"FOO=bar"}},
+	}}, gang)
+	require.NoError(t, err)
+
+	env := make(map[string]string)
+	for _, variable := range job.Spec.Docker.EnvironmentVariables {
+		key, value, _ := strings.Cut(variable, "=")
+		env[key] = value
+	}
+	require.Equal(t, "bar", env["FOO"])
+	require.Equal(t, "3", env[GangSizeEnvVar])
+	require.Equal(t, "1", env[GangRankEnvVar])
+
+	// the member listens on its own address, and reaches the others at theirs
+	own, err := net.ResolveTCPAddr("tcp", env[GangAddressEnvVar])
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2", own.IP.String())
+	require.Equal(t, 31235, own.Port)
+	var peers []string
+	for _, address := range strings.Split(env[GangPeersEnvVar], ",") {
+		peer, resolveErr := net.ResolveTCPAddr("tcp", address)
+		require.NoError(t, resolveErr)
+		peers = append(peers, peer.String())
+	}
+	require.Equal(t, []string{"10.0.0.1:31234", "[fd00::3]:31236"}, peers)
+
+	job, err = withGangPeers(model.Job{Spec: model.Spec{Engine: model.EngineWasm}}, gang)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2:31235", job.Spec.Wasm.EnvironmentVariables[GangAddressEnvVar])
+
+	_, err = withGangPeers(model.Job{}, GangMembership{Addresses: gang.Addresses, Rank: 3})
+	require.Error(t, err)
+}
@@ -2,10 +2,22 @@ package compute
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/util/bloom"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// localStorageRefreshInterval is how often the storage held locally is listed again, as listing it can be expensive
+	localStorageRefreshInterval = time.Minute
+	// localStorageFalsePositiveRate is the rate of the storage that is advertised as held locally while it is not
+	localStorageFalsePositiveRate = 0.01
 )
 
 type NodeInfoProviderParams struct {
@@ -13,6 +25,8 @@ type NodeInfoProviderParams struct {
 	CapacityTracker    capacity.Tracker
 	ExecutorBuffer     *ExecutorBuffer
 	MaxJobRequirements model.ResourceUsageData
+	Storages           storage.StorageProvider
+	UsageSampler       *ResourceUsageSampler
 }
 
 type NodeInfoProvider struct {
@@ -20,6 +34,12 @@ type NodeInfoProvider struct {
 	capacityTracker    capacity.Tracker
 	executorBuffer     *ExecutorBuffer
 	maxJobRequirements model.ResourceUsageData
+	storages           storage.StorageProvider
+	usageSampler       *ResourceUsageSampler
+
+	localStorage           *bloom.Filter
+	localStorageUpdateTime time.Time
+	localStorageMu         sync.Mutex
 }
 
 func NewNodeInfoProvider(params NodeInfoProviderParams) *NodeInfoProvider {
@@ -28,6 +48,8 @@ func NewNodeInfoProvider(params NodeInfoProviderParams) *NodeInfoProvider {
 		capacityTracker:    params.CapacityTracker,
 		executorBuffer:     params.ExecutorBuffer,
 		maxJobRequirements: params.MaxJobRequirements,
+		storages:           params.Storages,
+		usageSampler:       params.UsageSampler,
 	}
 }
 
@@ -39,6 +61,22 @@ func (n *NodeInfoProvider) GetComputeInfo(ctx context.Context) model.ComputeNode
 		}
 	}
 
+	// only the keys of the reservations are published, as their IDs are the tokens that jobs carry to use them
+	now := time.Now()
+	var reservationKeys []string
+	var reservedCapacity model.ResourceUsageData
+	for _, reservation := range n.capacityTracker.GetReservations(ctx) {
+		reservationKeys = append(reservationKeys, model.ReservationKey(reservation.ID))
+		if reservation.IsActive(now) {
+			reservedCapacity = reservedCapacity.Add(reservation.Resources)
+		}
+	}
+
+	var sampledUsage model.ResourceUsageData
+	if n.usageSampler != nil {
+		sampledUsage = n.usageSampler.GetSampledUsage()
+	}
+
 	return model.ComputeNodeInfo{
 		ExecutionEngines:   executionEngines,
 		MaxCapacity:        n.capacityTracker.GetMaxCapacity(ctx),
@@ -46,7 +84,54 @@ func (n *NodeInfoProvider) GetComputeInfo(ctx context.Context) model.ComputeNode
 		MaxJobRequirements: n.maxJobRequirements,
 		RunningExecutions:  len(n.executorBuffer.RunningExecutions()),
 		EnqueuedExecutions: len(n.executorBuffer.EnqueuedExecutions()),
+		LocalStorage:       n.getLocalStorage(ctx),
+		ReservationKeys:    reservationKeys,
+		ReservedCapacity:   reservedCapacity,
+		SampledUsage:       sampledUsage,
+	}
+}
+
+// getLocalStorage returns a bloom filter of the locality keys of the storage held locally by the node
+func (n *NodeInfoProvider) getLocalStorage(ctx context.Context) *bloom.Filter {
+	if n.storages == nil {
+		return nil
+	}
+	n.localStorageMu.Lock()
+	defer n.localStorageMu.Unlock()
+	if time.Since(n.localStorageUpdateTime) < localStorageRefreshInterval {
+		return n.localStorage
+	}
+
+	var keys []string
+	for _, sourceType := range model.StorageSourceTypes() {
+		if !n.storages.Has(ctx, sourceType) {
+			continue
+		}
+		storageProvider, err := n.storages.Get(ctx, sourceType)
+		if err != nil {
+			continue
+		}
+		specs, err := storageProvider.ListLocalStorage(ctx)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("failed to list local storage of %s", sourceType)
+			continue
+		}
+		for _, spec := range specs {
+			if key := spec.LocalityKey(); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	n.localStorage = nil
+	if len(keys) > 0 {
+		n.localStorage = bloom.New(len(keys), localStorageFalsePositiveRate)
+		for _, key := range keys {
+			n.localStorage.Add(key)
+		}
 	}
+	n.localStorageUpdateTime = time.Now()
+	return n.localStorage
 }
 
 // compile-time interface check
@@ -5,6 +5,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 )
 
@@ -37,3 +38,32 @@ func (apiClient *ComputeAPIClient) Debug(ctx context.Context) (map[string]model.
 
 	return res, nil
 }
+
+// InputCacheStats returns the usage of the cache of the inputs of the jobs run by the node.
+func (apiClient *ComputeAPIClient) InputCacheStats(ctx context.Context) (cache.Stats, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.InputCacheStats")
+	defer span.End()
+
+	req := struct{}{}
+	var res cache.Stats
+	if err := apiClient.Post(ctx, APIPrefix+"cache", req, &res); err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// PurgeInputCache removes the inputs that are not used by any running job from the cache of the node, and returns
+// how many were removed.
+func (apiClient *ComputeAPIClient) PurgeInputCache(ctx context.Context) (int, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/compute/publicapi.ComputeAPIClient.PurgeInputCache")
+	defer span.End()
+
+	req := struct{}{}
+	var res CachePurgeResponse
+	if err := apiClient.Post(ctx, APIPrefix+"cache/purge", req, &res); err != nil {
+		return 0, err
+	}
+
+	return res.Purged, nil
+}
@@ -0,0 +1,47 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// CachePurgeResponse is the response of the cache purge endpoint
+type CachePurgeResponse struct {
+	// Purged is the number of inputs removed from the cache
+	Purged int
+}
+
+// cacheStats godoc
+//
+//	@ID			apiServer/cacheStats
+//	@Summary	Returns the usage of the cache of the inputs of the jobs run by the node.
+//	@Tags		Health
+//	@Produce	json
+//	@Success	200	{object}	cache.Stats
+//	@Failure	500	{object}	string
+//	@Router		/cache [get]
+func (s *ComputeAPIServer) cacheStats(res http.ResponseWriter, req *http.Request) {
+	res.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(res).Encode(s.inputCache.Stats())
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// cachePurge godoc
+//
+//	@ID			apiServer/cachePurge
+//	@Summary	Removes the inputs that are not used by any running job from the cache of the node.
+//	@Tags		Health
+//	@Produce	json
+//	@Success	200	{object}	CachePurgeResponse
+//	@Failure	500	{object}	string
+//	@Router		/cache/purge [post]
+func (s *ComputeAPIServer) cachePurge(res http.ResponseWriter, req *http.Request) {
+	purged := s.inputCache.Purge(req.Context())
+	res.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(res).Encode(CachePurgeResponse{Purged: purged})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+	}
+}
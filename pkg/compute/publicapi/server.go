@@ -5,6 +5,7 @@ import (
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 )
 
 const APIPrefix = "compute/"
@@ -12,17 +13,21 @@ const APIPrefix = "compute/"
 type ComputeAPIServerParams struct {
 	APIServer          *publicapi.APIServer
 	DebugInfoProviders []model.DebugInfoProvider
+	// InputCache is the cache of the inputs of the jobs, if the node has one
+	InputCache *cache.Cache
 }
 
 type ComputeAPIServer struct {
 	apiServer          *publicapi.APIServer
 	debugInfoProviders []model.DebugInfoProvider
+	inputCache         *cache.Cache
 }
 
 func NewComputeAPIServer(params ComputeAPIServerParams) *ComputeAPIServer {
 	return &ComputeAPIServer{
 		apiServer:          params.APIServer,
 		debugInfoProviders: params.DebugInfoProviders,
+		inputCache:         params.InputCache,
 	}
 }
 
@@ -30,5 +35,11 @@ func (s *ComputeAPIServer) RegisterAllHandlers() error {
 	handlerConfigs := []publicapi.HandlerConfig{
 		{URI: "/" + APIPrefix + "debug", Handler: http.HandlerFunc(s.debug)},
 	}
+	if s.inputCache != nil {
+		handlerConfigs = append(handlerConfigs,
+			publicapi.HandlerConfig{URI: "/" + APIPrefix + "cache", Handler: http.HandlerFunc(s.cacheStats)},
+			publicapi.HandlerConfig{URI: "/" + APIPrefix + "cache/purge", Handler: http.HandlerFunc(s.cachePurge)},
+		)
+	}
 	return s.apiServer.RegisterHandlers(handlerConfigs...)
 }
@@ -0,0 +1,60 @@
+package compute
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/rs/zerolog/log"
+)
+
+const orphanedExecutionComment = "compute node restarted while the execution was in progress"
+
+type ExecutionReconcilerParams struct {
+	ID       string
+	Store    store.ExecutionStore
+	Callback Callback
+}
+
+// ExecutionReconciler reconciles the executions found in a persistent execution store when the compute node starts
+// with what is actually running on the node. Executions that were still active when the node stopped are orphaned,
+// as nothing is running or publishing them anymore, so they are failed and reported to their requester node, which
+// can retry them on other nodes instead of waiting for them to time out.
+type ExecutionReconciler struct {
+	id       string
+	store    store.ExecutionStore
+	callback Callback
+}
+
+func NewExecutionReconciler(params ExecutionReconcilerParams) *ExecutionReconciler {
+	return &ExecutionReconciler{
+		id:       params.ID,
+		store:    params.Store,
+		callback: params.Callback,
+	}
+}
+
+// Reconcile fails the orphaned executions, which were active in the store when the node started, and notifies their
+// requester nodes. Executions that were updated since the node started are left untouched.
+func (r *ExecutionReconciler) Reconcile(ctx context.Context, orphans []store.Execution) {
+	for _, execution := range orphans {
+		err := r.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+			ExecutionID:     execution.ID,
+			ExpectedVersion: execution.Version,
+			NewState:        store.ExecutionStateFailed,
+			Comment:         orphanedExecutionComment,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[Reconcile] failed to update state of orphaned execution %s", execution.ID)
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("failed orphaned execution %s of job %s", execution.ID, execution.Job.Metadata.ID)
+		r.callback.OnComputeFailure(ctx, ComputeError{
+			ExecutionMetadata: NewExecutionMetadata(execution),
+			RoutingMetadata: RoutingMetadata{
+				SourcePeerID: r.id,
+				TargetPeerID: execution.RequesterNodeID,
+			},
+			Err: orphanedExecutionComment,
+		})
+	}
+}
@@ -69,6 +69,11 @@ func (proxy *PersistentJobStore) GetExecutionCount(ctx context.Context) (uint, e
 	return readCounter(jsonFilepath)
 }
 
+// GetActiveExecutions implements store.ExecutionStore
+func (proxy *PersistentJobStore) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	return proxy.store.GetActiveExecutions(ctx)
+}
+
 // GetExecutionHistory implements store.ExecutionStore
 func (proxy *PersistentJobStore) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
 	return proxy.store.GetExecutionHistory(ctx, id)
@@ -103,6 +108,11 @@ func (proxy *PersistentJobStore) UpdateExecutionState(ctx context.Context, reque
 	return err
 }
 
+// UpdateExecutionRequester implements store.ExecutionStore
+func (proxy *PersistentJobStore) UpdateExecutionRequester(ctx context.Context, id string, requesterNodeID string) error {
+	return proxy.store.UpdateExecutionRequester(ctx, id, requesterNodeID)
+}
+
 func writeCounter(filepath string, count uint) error {
 	var jobStore JobStats
 	jobStore.JobsCompleted += count
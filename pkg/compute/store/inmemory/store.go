@@ -55,6 +55,18 @@ func (s *Store) GetExecutions(ctx context.Context, jobID string) ([]store.Execut
 	return executions, nil
 }
 
+func (s *Store) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var executions []store.Execution
+	for _, execution := range s.executionMap {
+		if execution.State.IsActive() {
+			executions = append(executions, execution)
+		}
+	}
+	return executions, nil
+}
+
 func (s *Store) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -106,6 +118,20 @@ func (s *Store) UpdateExecutionState(ctx context.Context, request store.UpdateEx
 	return nil
 }
 
+func (s *Store) UpdateExecutionRequester(ctx context.Context, id string, requesterNodeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	execution, ok := s.executionMap[id]
+	if !ok {
+		return store.NewErrExecutionNotFound(id)
+	}
+	execution.RequesterNodeID = requesterNodeID
+	execution.Version += 1
+	execution.UpdateTime = time.Now()
+	s.executionMap[execution.ID] = execution
+	return nil
+}
+
 func (s *Store) appendHistory(updatedExecution store.Execution, previousState store.ExecutionState, comment string) {
 	historyEntry := store.ExecutionHistory{
 		ExecutionID:   updatedExecution.ID,
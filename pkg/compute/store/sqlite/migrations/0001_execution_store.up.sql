@@ -0,0 +1,16 @@
+create table execution (
+  id varchar(255) PRIMARY KEY,
+  job_id varchar(255) not null,
+  state integer not null,
+  executiondata text not null
+);
+CREATE INDEX idx_execution_job_id ON execution (job_id);
+CREATE INDEX idx_execution_state ON execution (state);
+
+create table execution_history (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  execution_id varchar(255),
+  historydata text not null,
+  FOREIGN KEY(execution_id) REFERENCES execution(id)
+);
+CREATE INDEX idx_execution_history_execution_id ON execution_history (execution_id);
@@ -0,0 +1,361 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+const newExecutionComment = "Execution created"
+
+//go:embed migrations/*.sql
+var fs embed.FS
+
+// Store is a store.ExecutionStore that persists executions and their history to a SQLite database,
+// so that the compute node still knows about its executions after it restarts.
+// Each execution is stored as a single JSON document, and all updates are done in transactions.
+type Store struct {
+	db *sql.DB
+	mu sync.RWMutex
+}
+
+func NewStore(filename string) (*Store, error) {
+	dataSource := fmt.Sprintf("file:%s?_txlock=immediate&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", filename)
+	db, err := otelsql.Open(
+		"sqlite",
+		dataSource,
+		otelsql.WithAttributes(semconv.DBSystemSqlite, semconv.PeerService("sqlite")),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = migrateUp(filename); err != nil {
+		return nil, err
+	}
+
+	res := &Store{
+		db: db,
+	}
+	res.mu.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        "SQLiteExecutionStore.mu",
+	})
+	return res, nil
+}
+
+func migrateUp(filename string) error {
+	files, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return err
+	}
+	migrations, err := migrate.NewWithSourceInstance("iofs", files, fmt.Sprintf("sqlite://%s", filename))
+	if err != nil {
+		return err
+	}
+	defer migrations.Close()
+	err = migrations.Up()
+	if err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) GetExecution(ctx context.Context, id string) (store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return getExecution(ctx, s.db, id)
+}
+
+func (s *Store) GetExecutions(ctx context.Context, jobID string) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	executions, err := queryExecutions(ctx, s.db, "select executiondata from execution where job_id = ? order by rowid asc", jobID)
+	if err != nil {
+		return nil, err
+	}
+	if len(executions) == 0 {
+		return []store.Execution{}, store.NewErrExecutionsNotFoundForJob(jobID)
+	}
+	return executions, nil
+}
+
+func (s *Store) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	executions, err := queryExecutions(ctx, s.db, "select executiondata from execution order by rowid asc")
+	if err != nil {
+		return nil, err
+	}
+	var active []store.Execution
+	for _, execution := range executions {
+		if execution.State.IsActive() {
+			active = append(active, execution)
+		}
+	}
+	return active, nil
+}
+
+func (s *Store) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.QueryContext(ctx,
+		"select historydata from execution_history where execution_id = ? order by id asc", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []store.ExecutionHistory
+	for rows.Next() {
+		var historyData string
+		if err = rows.Scan(&historyData); err != nil {
+			return nil, err
+		}
+		var entry store.ExecutionHistory
+		if err = json.Unmarshal([]byte(historyData), &entry); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return history, store.NewErrExecutionHistoryNotFound(id)
+	}
+	return history, nil
+}
+
+func (s *Store) CreateExecution(ctx context.Context, execution store.Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "select count(id) from execution where id = ?", execution.ID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return store.NewErrExecutionAlreadyExists(execution.ID)
+	}
+	if err = store.ValidateNewExecution(ctx, execution); err != nil {
+		return fmt.Errorf("CreateExecution failure: %w", err)
+	}
+
+	executionData, err := json.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"insert into execution (id, job_id, state, executiondata) values (?, ?, ?, ?)",
+		execution.ID,
+		execution.Job.ID(),
+		int(execution.State),
+		string(executionData),
+	)
+	if err != nil {
+		return err
+	}
+	if err = appendHistory(ctx, tx, execution, store.ExecutionStateUndefined, newExecutionComment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) UpdateExecutionState(ctx context.Context, request store.UpdateExecutionStateRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	execution, err := getExecution(ctx, tx, request.ExecutionID)
+	if err != nil {
+		return err
+	}
+	if request.ExpectedState != store.ExecutionStateUndefined && execution.State != request.ExpectedState {
+		return store.NewErrInvalidExecutionState(request.ExecutionID, execution.State, request.ExpectedState)
+	}
+	if request.ExpectedVersion != 0 && execution.Version != request.ExpectedVersion {
+		return store.NewErrInvalidExecutionVersion(request.ExecutionID, execution.Version, request.ExpectedVersion)
+	}
+	if execution.State.IsTerminal() {
+		return store.NewErrExecutionAlreadyTerminal(request.ExecutionID, execution.State, request.NewState)
+	}
+	previousState := execution.State
+	execution.State = request.NewState
+	execution.Version += 1
+	execution.UpdateTime = time.Now()
+	if err = updateExecution(ctx, tx, execution); err != nil {
+		return err
+	}
+	if err = appendHistory(ctx, tx, execution, previousState, request.Comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) UpdateExecutionRequester(ctx context.Context, id string, requesterNodeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	execution, err := getExecution(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	execution.RequesterNodeID = requesterNodeID
+	execution.Version += 1
+	execution.UpdateTime = time.Now()
+	if err = updateExecution(ctx, tx, execution); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) DeleteExecution(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "delete from execution_history where execution_id = ?", id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, "delete from execution where id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) GetExecutionCount(ctx context.Context) (uint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var counter uint
+	err := s.db.QueryRowContext(ctx,
+		"select count(id) from execution where state = ?", int(store.ExecutionStateCompleted)).Scan(&counter)
+	if err != nil {
+		return 0, err
+	}
+	return counter, nil
+}
+
+// sqlClient is so we can pass *sql.DB and *sql.Tx to the same functions
+type sqlClient interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getExecution(ctx context.Context, db sqlClient, id string) (store.Execution, error) {
+	var executionData string
+	err := db.QueryRowContext(ctx, "select executiondata from execution where id = ?", id).Scan(&executionData)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.Execution{}, store.NewErrExecutionNotFound(id)
+		}
+		return store.Execution{}, err
+	}
+	var execution store.Execution
+	if err = json.Unmarshal([]byte(executionData), &execution); err != nil {
+		return store.Execution{}, err
+	}
+	return execution, nil
+}
+
+func queryExecutions(ctx context.Context, db sqlClient, query string, args ...any) ([]store.Execution, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []store.Execution
+	for rows.Next() {
+		var executionData string
+		if err = rows.Scan(&executionData); err != nil {
+			return nil, err
+		}
+		var execution store.Execution
+		if err = json.Unmarshal([]byte(executionData), &execution); err != nil {
+			return nil, err
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+func updateExecution(ctx context.Context, db sqlClient, execution store.Execution) error {
+	executionData, err := json.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "update execution set state = ?, executiondata = ? where id = ?",
+		int(execution.State), string(executionData), execution.ID)
+	return err
+}
+
+func appendHistory(
+	ctx context.Context, db sqlClient, updatedExecution store.Execution, previousState store.ExecutionState, comment string) error {
+	historyEntry := store.ExecutionHistory{
+		ExecutionID:   updatedExecution.ID,
+		PreviousState: previousState,
+		NewState:      updatedExecution.State,
+		NewVersion:    updatedExecution.Version,
+		Comment:       comment,
+		Time:          updatedExecution.UpdateTime,
+	}
+	historyData, err := json.Marshal(historyEntry)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "insert into execution_history (execution_id, historydata) values (?, ?)",
+		updatedExecution.ID, string(historyData))
+	return err
+}
+
+// compile-time check that we implement the interface ExecutionStore
+var _ store.ExecutionStore = (*Store)(nil)
@@ -0,0 +1,191 @@
+//go:build unit || !integration
+
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type Suite struct {
+	suite.Suite
+	filename       string
+	executionStore *Store
+	execution      store.Execution
+}
+
+func (s *Suite) SetupTest() {
+	s.filename = filepath.Join(s.T().TempDir(), "executions.db")
+	var err error
+	s.executionStore, err = NewStore(s.filename)
+	s.Require().NoError(err)
+	s.execution = newExecution()
+}
+
+func (s *Suite) TearDownTest() {
+	s.NoError(s.executionStore.Close())
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestCreateExecution() {
+	ctx := context.Background()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+
+	readExecution, err := s.executionStore.GetExecution(ctx, s.execution.ID)
+	s.NoError(err)
+	s.Equal(s.execution, readExecution)
+
+	history, err := s.executionStore.GetExecutionHistory(ctx, s.execution.ID)
+	s.NoError(err)
+	s.Require().Len(history, 1)
+	s.Equal(store.ExecutionStateUndefined, history[0].PreviousState)
+	s.Equal(newExecutionComment, history[0].Comment)
+
+	s.ErrorAs(s.executionStore.CreateExecution(ctx, s.execution), &store.ErrExecutionAlreadyExists{})
+
+	_, err = s.executionStore.GetExecution(ctx, uuid.NewString())
+	s.ErrorAs(err, &store.ErrExecutionNotFound{})
+}
+
+func (s *Suite) TestGetExecutions() {
+	ctx := context.Background()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+	anotherExecution := newExecution()
+	anotherExecution.Job = s.execution.Job
+	s.NoError(s.executionStore.CreateExecution(ctx, anotherExecution))
+
+	executions, err := s.executionStore.GetExecutions(ctx, s.execution.Job.ID())
+	s.NoError(err)
+	s.Equal([]store.Execution{s.execution, anotherExecution}, executions)
+
+	_, err = s.executionStore.GetExecutions(ctx, uuid.NewString())
+	s.ErrorAs(err, &store.ErrExecutionsNotFoundForJob{})
+}
+
+func (s *Suite) TestUpdateExecution() {
+	ctx := context.Background()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+
+	err := s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:   s.execution.ID,
+		ExpectedState: store.ExecutionStateBidAccepted,
+		NewState:      store.ExecutionStateRunning,
+	})
+	s.ErrorAs(err, &store.ErrInvalidExecutionState{})
+	err = s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:     s.execution.ID,
+		ExpectedVersion: s.execution.Version + 1,
+		NewState:        store.ExecutionStateRunning,
+	})
+	s.ErrorAs(err, &store.ErrInvalidExecutionVersion{})
+
+	err = s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:     s.execution.ID,
+		ExpectedState:   s.execution.State,
+		ExpectedVersion: s.execution.Version,
+		NewState:        store.ExecutionStateCompleted,
+		Comment:         "done",
+	})
+	s.NoError(err)
+	readExecution, err := s.executionStore.GetExecution(ctx, s.execution.ID)
+	s.NoError(err)
+	s.Equal(store.ExecutionStateCompleted, readExecution.State)
+	s.Equal(s.execution.Version+1, readExecution.Version)
+
+	history, err := s.executionStore.GetExecutionHistory(ctx, s.execution.ID)
+	s.NoError(err)
+	s.Require().Len(history, 2)
+	s.Equal(s.execution.State, history[1].PreviousState)
+	s.Equal(store.ExecutionStateCompleted, history[1].NewState)
+	s.Equal("done", history[1].Comment)
+
+	count, err := s.executionStore.GetExecutionCount(ctx)
+	s.NoError(err)
+	s.Equal(uint(1), count)
+
+	// terminal executions can't be updated
+	err = s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: s.execution.ID,
+		NewState:    store.ExecutionStateFailed,
+	})
+	s.ErrorAs(err, &store.ErrExecutionAlreadyTerminal{})
+}
+
+func (s *Suite) TestUpdateExecutionRequester() {
+	ctx := context.Background()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+	s.NoError(s.executionStore.UpdateExecutionRequester(ctx, s.execution.ID, "nodeID-2"))
+
+	readExecution, err := s.executionStore.GetExecution(ctx, s.execution.ID)
+	s.NoError(err)
+	s.Equal("nodeID-2", readExecution.RequesterNodeID)
+	s.Equal(s.execution.Version+1, readExecution.Version)
+
+	s.ErrorAs(s.executionStore.UpdateExecutionRequester(ctx, uuid.NewString(), "nodeID-2"), &store.ErrExecutionNotFound{})
+}
+
+func (s *Suite) TestDeleteExecution() {
+	ctx := context.Background()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+	s.NoError(s.executionStore.DeleteExecution(ctx, s.execution.ID))
+
+	_, err := s.executionStore.GetExecution(ctx, s.execution.ID)
+	s.ErrorAs(err, &store.ErrExecutionNotFound{})
+	_, err = s.executionStore.GetExecutionHistory(ctx, s.execution.ID)
+	s.ErrorAs(err, &store.ErrExecutionHistoryNotFound{})
+
+	// deleting a missing execution is a no-op
+	s.NoError(s.executionStore.DeleteExecution(ctx, s.execution.ID))
+}
+
+func (s *Suite) TestSurvivesRestart() {
+	ctx := context.Background()
+	s.NoError(s.executionStore.CreateExecution(ctx, s.execution))
+	completedExecution := newExecution()
+	s.NoError(s.executionStore.CreateExecution(ctx, completedExecution))
+	s.NoError(s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: completedExecution.ID,
+		NewState:    store.ExecutionStateCompleted,
+	}))
+	s.NoError(s.executionStore.Close())
+
+	var err error
+	s.executionStore, err = NewStore(s.filename)
+	s.Require().NoError(err)
+
+	active, err := s.executionStore.GetActiveExecutions(ctx)
+	s.NoError(err)
+	s.Equal([]store.Execution{s.execution}, active)
+
+	history, err := s.executionStore.GetExecutionHistory(ctx, completedExecution.ID)
+	s.NoError(err)
+	s.Len(history, 2)
+}
+
+func newExecution() store.Execution {
+	execution := *store.NewExecution(
+		uuid.NewString(),
+		model.Job{
+			Metadata: model.Metadata{
+				ID: uuid.NewString(),
+			},
+		},
+		"nodeID-1",
+		model.ResourceUsageData{
+			CPU:    1,
+			Memory: 2,
+		})
+	// times are persisted without their monotonic clock reading and location
+	execution.CreateTime = execution.CreateTime.UTC()
+	execution.UpdateTime = execution.UpdateTime.UTC()
+	return execution
+}
@@ -83,12 +83,16 @@ type ExecutionStore interface {
 	GetExecution(ctx context.Context, id string) (Execution, error)
 	// GetExecutions returns all the executions for a given job
 	GetExecutions(ctx context.Context, jobID string) ([]Execution, error)
+	// GetActiveExecutions returns the executions of all jobs that are still active
+	GetActiveExecutions(ctx context.Context) ([]Execution, error)
 	// GetExecutionHistory returns the history of an execution
 	GetExecutionHistory(ctx context.Context, id string) ([]ExecutionHistory, error)
 	// CreateExecution creates a new execution for a given job
 	CreateExecution(ctx context.Context, execution Execution) error
 	// UpdateExecutionState updates the execution state
 	UpdateExecutionState(ctx context.Context, request UpdateExecutionStateRequest) error
+	// UpdateExecutionRequester changes the requester node that is notified of the progress of the execution
+	UpdateExecutionRequester(ctx context.Context, id string, requesterNodeID string) error
 	// DeleteExecution deletes an execution
 	DeleteExecution(ctx context.Context, id string) error
 	// GetExecutionCount returns a count of all executions that completed
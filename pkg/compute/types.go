@@ -25,6 +25,14 @@ type Endpoint interface {
 	ResultRejected(context.Context, ResultRejectedRequest) (ResultRejectedResponse, error)
 	// CancelExecution cancels a job for a given executionID.
 	CancelExecution(context.Context, CancelExecutionRequest) (CancelExecutionResponse, error)
+	// UpdateRequester changes the requester node that is notified of the progress of an executionID, after
+	// the requester node adopted the job from another requester node.
+	UpdateRequester(context.Context, UpdateRequesterRequest) (UpdateRequesterResponse, error)
+	// Reserve holds back capacity on the compute node for the window of a reservation, which is only released
+	// to jobs carrying the reservation's ID.
+	Reserve(context.Context, ReserveRequest) (ReserveResponse, error)
+	// CancelReservation releases the capacity held back by a reservation made by the client of the request.
+	CancelReservation(context.Context, CancelReservationRequest) (CancelReservationResponse, error)
 }
 
 // Executor Backend service that is responsible for running and publishing executions.
@@ -77,6 +85,8 @@ type AskForBidResponse struct {
 	ExecutionMetadata
 	Accepted bool
 	Reason   string
+	// Price is the price quoted by the compute node to run the execution
+	Price float64
 }
 
 type BidAcceptedRequest struct {
@@ -84,6 +94,16 @@ type BidAcceptedRequest struct {
 	ExecutionID   string
 	Accepted      bool
 	Justification string
+	// Gang tells the members of a gang job how to reach each other
+	Gang GangMembership
+}
+
+// GangMembership is the position of an execution among the members of a gang job, and how to reach the members.
+type GangMembership struct {
+	// Addresses are the host:port addresses of all the members of the gang, ordered by rank
+	Addresses []string
+	// Rank is the position of the execution's own address in Addresses
+	Rank int
 }
 
 type BidAcceptedResponse struct {
@@ -129,6 +149,36 @@ type CancelExecutionResponse struct {
 	ExecutionMetadata
 }
 
+type UpdateRequesterRequest struct {
+	RoutingMetadata
+	ExecutionID string
+}
+
+type UpdateRequesterResponse struct {
+	ExecutionMetadata
+}
+
+type ReserveRequest struct {
+	RoutingMetadata
+	Reservation model.Reservation
+}
+
+type ReserveResponse struct {
+	Accepted bool
+	Reason   string
+}
+
+type CancelReservationRequest struct {
+	RoutingMetadata
+	ReservationID string
+	ClientID      string
+}
+
+type CancelReservationResponse struct {
+	Canceled bool
+	Reason   string
+}
+
 ///////////////////////////////////
 // Callback result models
 ///////////////////////////////////
@@ -139,6 +189,8 @@ type RunResult struct {
 	ExecutionMetadata
 	ResultProposal   []byte
 	RunCommandResult *model.RunCommandResult
+	// ResourceUsage is the peak resource usage of the execution while it was running, if it was sampled
+	ResourceUsage *model.ResourceUsageSummary
 }
 
 // PublishResult Result of a job publish that is returned to the caller through a Callback.
@@ -158,6 +210,9 @@ type ComputeError struct {
 	RoutingMetadata
 	ExecutionMetadata
 	Err string
+	// Preempted is true if the execution didn't fail on its own, but was canceled to make room for an execution of
+	// a higher priority job.
+	Preempted bool
 }
 
 func (e ComputeError) Error() string {
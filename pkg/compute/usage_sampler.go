@@ -0,0 +1,205 @@
+package compute
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+type ResourceUsageSamplerParams struct {
+	Name      string
+	Executors executor.ExecutorProvider
+	Interval  time.Duration
+}
+
+// ResourceUsageSampler is a background task that periodically measures the resources actually used by the running
+// executions, as opposed to the resources they requested. Executions are only sampled if their executor implements
+// executor.ResourceUsageSampler. The latest samples are exposed as DebugInfo, and the peak usage of an execution is
+// summarized when it stops being tracked, so that it can be reported to the requester node with its results.
+type ResourceUsageSampler struct {
+	name       string
+	executors  executor.ExecutorProvider
+	interval   time.Duration
+	executions map[string]*sampledExecution
+	mu         sync.Mutex
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+type sampledExecution struct {
+	execution store.Execution
+	latest    *model.ResourceUsageSample
+	peak      model.ResourceUsageData
+	samples   int
+}
+
+// ExecutionResourceUsage is the DebugInfo of a sampled execution
+type ExecutionResourceUsage struct {
+	ExecutionID string
+	JobID       string
+	Requested   model.ResourceUsageData
+	Latest      *model.ResourceUsageSample `json:",omitempty"`
+	Peak        model.ResourceUsageData
+}
+
+func NewResourceUsageSampler(params ResourceUsageSamplerParams) *ResourceUsageSampler {
+	s := &ResourceUsageSampler{
+		name:        params.Name,
+		executors:   params.Executors,
+		interval:    params.Interval,
+		executions:  make(map[string]*sampledExecution),
+		stopChannel: make(chan struct{}),
+	}
+
+	go s.samplingBackgroundTask()
+	return s
+}
+
+func (s *ResourceUsageSampler) samplingBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.sample(ctx)
+		case <-s.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped resource usage sampler task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// Track starts sampling the resource usage of the execution, which is about to run.
+func (s *ResourceUsageSampler) Track(execution store.Execution) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executions[execution.ID] = &sampledExecution{execution: execution}
+}
+
+// Untrack stops sampling the resource usage of the execution, and returns the summary of its usage.
+// It returns nil if the execution was never sampled.
+func (s *ResourceUsageSampler) Untrack(executionID string) *model.ResourceUsageSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tracked, ok := s.executions[executionID]
+	delete(s.executions, executionID)
+	if !ok || tracked.samples == 0 {
+		return nil
+	}
+	return &model.ResourceUsageSummary{
+		Requested: tracked.execution.ResourceUsage,
+		Peak:      tracked.peak,
+		Samples:   tracked.samples,
+	}
+}
+
+// GetSampledUsage returns the sum of the latest resource usage samples of the tracked executions.
+func (s *ResourceUsageSampler) GetSampledUsage() model.ResourceUsageData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var usage model.ResourceUsageData
+	for _, tracked := range s.executions {
+		if tracked.latest != nil {
+			usage = usage.Add(tracked.latest.Usage)
+		}
+	}
+	return usage
+}
+
+// GetUnusedCapacity implements capacity.UnusedCapacityProvider. It returns the CPU and memory that the sampled
+// executions requested but did not use at their peak. Executions that were not sampled yet, and resources that
+// their executor doesn't measure, are assumed to be fully used.
+func (s *ResourceUsageSampler) GetUnusedCapacity() model.ResourceUsageData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var unused model.ResourceUsageData
+	for _, tracked := range s.executions {
+		requested := tracked.execution.ResourceUsage
+		if tracked.peak.CPU > 0 && tracked.peak.CPU < requested.CPU {
+			unused.CPU += requested.CPU - tracked.peak.CPU
+		}
+		if tracked.peak.Memory > 0 && tracked.peak.Memory < requested.Memory {
+			unused.Memory += requested.Memory - tracked.peak.Memory
+		}
+	}
+	return unused
+}
+
+// sample measures the resource usage of all the tracked executions. The lock is not held while the executors
+// are sampling, as it can take a while.
+func (s *ResourceUsageSampler) sample(ctx context.Context) {
+	s.mu.Lock()
+	executions := make([]store.Execution, 0, len(s.executions))
+	for _, tracked := range s.executions {
+		executions = append(executions, tracked.execution)
+	}
+	s.mu.Unlock()
+
+	for _, execution := range executions {
+		e, err := s.executors.Get(ctx, execution.Job.Spec.Engine)
+		if err != nil {
+			continue
+		}
+		sampler, ok := e.(executor.ResourceUsageSampler)
+		if !ok {
+			continue
+		}
+		usage, err := sampler.GetResourceUsage(ctx, execution.Job)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("[sample] failed to sample resource usage of execution %s", execution.ID)
+			continue
+		}
+		s.record(execution.ID, model.ResourceUsageSample{Usage: usage, Time: time.Now()})
+	}
+}
+
+func (s *ResourceUsageSampler) record(executionID string, sample model.ResourceUsageSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the execution might have stopped while it was sampled
+	tracked, ok := s.executions[executionID]
+	if !ok {
+		return
+	}
+	tracked.latest = &sample
+	tracked.peak = tracked.peak.Max(sample.Usage)
+	tracked.samples++
+}
+
+// GetDebugInfo implements model.DebugInfoProvider
+func (s *ResourceUsageSampler) GetDebugInfo(ctx context.Context) (model.DebugInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usages := make([]ExecutionResourceUsage, 0, len(s.executions))
+	for _, tracked := range s.executions {
+		usages = append(usages, ExecutionResourceUsage{
+			ExecutionID: tracked.execution.ID,
+			JobID:       tracked.execution.Job.ID(),
+			Requested:   tracked.execution.ResourceUsage,
+			Latest:      tracked.latest,
+			Peak:        tracked.peak,
+		})
+	}
+	return model.DebugInfo{
+		Component: s.name,
+		Info:      usages,
+	}, nil
+}
+
+func (s *ResourceUsageSampler) Stop() {
+	s.stopOnce.Do(func() {
+		s.stopChannel <- struct{}{}
+	})
+}
+
+// compile-time check that we implement the interfaces
+var _ model.DebugInfoProvider = (*ResourceUsageSampler)(nil)
+var _ capacity.UnusedCapacityProvider = (*ResourceUsageSampler)(nil)
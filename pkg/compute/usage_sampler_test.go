@@ -0,0 +1,123 @@
+//go:build unit || !integration
+
+package compute
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
+	"github.com/bacalhau-project/bacalhau/pkg/executor"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+// mockSamplingExecutor returns the resource usage set by the test for each job
+type mockSamplingExecutor struct {
+	executor.Executor
+	mu    sync.Mutex
+	usage map[string]model.ResourceUsageData
+}
+
+func (e *mockSamplingExecutor) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (e *mockSamplingExecutor) GetResourceUsage(_ context.Context, job model.Job) (model.ResourceUsageData, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	usage, ok := e.usage[job.Metadata.ID]
+	if !ok {
+		return model.ResourceUsageData{}, fmt.Errorf("job %s is not running", job.Metadata.ID)
+	}
+	return usage, nil
+}
+
+func (e *mockSamplingExecutor) setUsage(jobID string, usage model.ResourceUsageData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.usage[jobID] = usage
+}
+
+type ResourceUsageSamplerSuite struct {
+	suite.Suite
+	ctx      context.Context
+	executor *mockSamplingExecutor
+	sampler  *ResourceUsageSampler
+}
+
+func TestResourceUsageSamplerSuite(t *testing.T) {
+	suite.Run(t, new(ResourceUsageSamplerSuite))
+}
+
+func (s *ResourceUsageSamplerSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.executor = &mockSamplingExecutor{usage: make(map[string]model.ResourceUsageData)}
+	// executions are sampled by the test instead of the background task
+	s.sampler = NewResourceUsageSampler(ResourceUsageSamplerParams{
+		Name:      "ResourceUsageSampler",
+		Executors: model.NewNoopProvider[model.Engine, executor.Executor](s.executor),
+		Interval:  time.Hour,
+	})
+	s.T().Cleanup(s.sampler.Stop)
+}
+
+func (s *ResourceUsageSamplerSuite) track(id string, requested model.ResourceUsageData) {
+	job := model.Job{Metadata: model.Metadata{ID: id}}
+	s.sampler.Track(*store.NewExecution(id, job, "requester", requested))
+}
+
+func (s *ResourceUsageSamplerSuite) TestPeakUsageIsSummarized() {
+	requested := model.ResourceUsageData{CPU: 2, Memory: 2048}
+	s.track("execution", requested)
+
+	s.executor.setUsage("execution", model.ResourceUsageData{CPU: 1.5, Memory: 512})
+	s.sampler.sample(s.ctx)
+	s.Equal(model.ResourceUsageData{CPU: 1.5, Memory: 512}, s.sampler.GetSampledUsage())
+
+	// the peak is the maximum of each resource, while the latest sample is the current usage
+	s.executor.setUsage("execution", model.ResourceUsageData{CPU: 0.5, Memory: 1024})
+	s.sampler.sample(s.ctx)
+	s.Equal(model.ResourceUsageData{CPU: 0.5, Memory: 1024}, s.sampler.GetSampledUsage())
+
+	s.Equal(&model.ResourceUsageSummary{
+		Requested: requested,
+		Peak:      model.ResourceUsageData{CPU: 1.5, Memory: 1024},
+		Samples:   2,
+	}, s.sampler.Untrack("execution"))
+
+	// untracked executions are no longer sampled
+	s.sampler.sample(s.ctx)
+	s.sampler.record("execution", model.ResourceUsageSample{Usage: requested, Time: time.Now()})
+	s.Equal(model.ResourceUsageData{}, s.sampler.GetSampledUsage())
+	s.Nil(s.sampler.Untrack("execution"))
+}
+
+func (s *ResourceUsageSamplerSuite) TestExecutionsThatWereNotSampled() {
+	s.track("execution", model.ResourceUsageData{CPU: 1, Memory: 1024})
+
+	// executions that the executor fails to sample have no summary
+	s.sampler.sample(s.ctx)
+	s.Equal(model.ResourceUsageData{}, s.sampler.GetSampledUsage())
+	s.Nil(s.sampler.Untrack("execution"))
+	s.Nil(s.sampler.Untrack("unknown"))
+}
+
+func (s *ResourceUsageSamplerSuite) TestUnusedCapacity() {
+	s.track("idle", model.ResourceUsageData{CPU: 2, Memory: 2048})
+	s.track("busy", model.ResourceUsageData{CPU: 1, Memory: 1024})
+	s.track("unsampled", model.ResourceUsageData{CPU: 4, Memory: 4096})
+	s.executor.setUsage("idle", model.ResourceUsageData{CPU: 0.5, Memory: 512})
+	s.executor.setUsage("busy", model.ResourceUsageData{CPU: 1.5, Memory: 1024})
+	s.sampler.sample(s.ctx)
+
+	// executions using more than they requested, and executions that were not sampled yet, are fully used
+	s.Equal(model.ResourceUsageData{CPU: 1.5, Memory: 1536}, s.sampler.GetUnusedCapacity())
+
+	// the unused capacity of an execution is given back once it stops being tracked
+	s.sampler.Untrack("idle")
+	s.Equal(model.ResourceUsageData{}, s.sampler.GetUnusedCapacity())
+}
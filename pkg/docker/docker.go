@@ -181,6 +181,22 @@ func (c *Client) GetOutputStream(ctx context.Context, id string, since string) (
 	return logsReader, nil
 }
 
+// GetContainerStats returns a single sample of the resource usage of the container. The sample also holds the
+// previous CPU usage of the container, so that its CPU usage rate can be computed.
+func (c *Client) GetContainerStats(ctx context.Context, id string) (types.StatsJSON, error) {
+	response, err := c.ContainerStats(ctx, id, false)
+	if err != nil {
+		return types.StatsJSON{}, errors.Wrap(err, "failed to get container stats")
+	}
+	defer closer.CloseWithLogOnError("statsReader", response.Body)
+
+	var stats types.StatsJSON
+	if err = json.NewDecoder(response.Body).Decode(&stats); err != nil {
+		return types.StatsJSON{}, errors.Wrap(err, "failed to decode container stats")
+	}
+	return stats, nil
+}
+
 func (c *Client) RemoveContainer(ctx context.Context, id string) error {
 	log.Ctx(ctx).Debug().Str("id", id).Msgf("Container Stop")
 	timeout := time.Millisecond * 100
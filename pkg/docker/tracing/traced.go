@@ -89,6 +89,13 @@ func (c TracedClient) ContainerStart(ctx context.Context, id string, options typ
 	return telemetry.RecordErrorOnSpan(span)(c.client.ContainerStart(ctx, id, options))
 }
 
+func (c TracedClient) ContainerStats(ctx context.Context, containerID string, stream bool) (types.ContainerStats, error) {
+	ctx, span := c.span(ctx, "container.stats")
+	defer span.End()
+
+	return telemetry.RecordErrorOnSpanTwo[types.ContainerStats](span)(c.client.ContainerStats(ctx, containerID, stream))
+}
+
 func (c TracedClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
 	ctx, span := c.span(ctx, "container.stop")
 	defer span.End()
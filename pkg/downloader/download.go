@@ -21,9 +21,8 @@ var specialFiles = map[string]bool{
 }
 
 // DownloadResult downloads published results from a storage source and saves them to the specific download path.
-// It supports downloading multiple results from different jobs and will append the logs to the global log file. This behavior is left
-// from when we supported sharded jobs, and multiple results per job. It is not currently being user, and we can evaluate removing it in
-// the future if we don't expose merging results from multiple jobs.
+// It supports downloading multiple results, such as the results of each shard of a sharded job, and will merge their files in the
+// order of the results and append their logs to the global log file.
 // * make a temp dir
 // * download all cids into temp dir
 // * ensure top level output dir exists
@@ -69,8 +68,10 @@ func DownloadResults( //nolint:funlen,gocyclo
 	log.Ctx(ctx).Info().Msgf("Downloading %d results to: %s.", len(publishedResults), resultsOutputDir)
 
 	// keep track of which cids we have downloaded to avoid
-	// downloading the same cid multiple times
+	// downloading the same cid multiple times, and of the order
+	// they were downloaded in to merge them in the same order
 	downloadedCids := map[string]string{}
+	var downloadDirs []string
 
 	for _, publishedResult := range publishedResults {
 		cidDownloadDir := filepath.Join(cidParentDir, publishedResult.Data.CID)
@@ -82,10 +83,11 @@ func DownloadResults( //nolint:funlen,gocyclo
 				return err
 			}
 			downloadedCids[publishedResult.Data.CID] = cidDownloadDir
+			downloadDirs = append(downloadDirs, cidDownloadDir)
 		}
 	}
 
-	for _, cidDownloadDir := range downloadedCids {
+	for _, cidDownloadDir := range downloadDirs {
 		err = moveData(ctx, resultsOutputDir, cidDownloadDir, len(downloadedCids) > 1)
 		if err != nil {
 			return err
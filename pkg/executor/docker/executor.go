@@ -314,6 +314,15 @@ func (e *Executor) GetOutputStream(ctx context.Context, job model.Job) (io.ReadC
 	return reader, nil
 }
 
+// GetResourceUsage implements executor.ResourceUsageSampler using the cgroup stats of the job's container
+func (e *Executor) GetResourceUsage(ctx context.Context, job model.Job) (model.ResourceUsageData, error) {
+	stats, err := e.client.GetContainerStats(ctx, e.jobContainerName(job))
+	if err != nil {
+		return model.ResourceUsageData{}, err
+	}
+	return containerResourceUsage(stats), nil
+}
+
 func (e *Executor) cleanupJob(ctx context.Context, job model.Job) {
 	// Use a detached context in case the current one has already been canceled
 	separateCtx, cancel := context.WithTimeout(pkgUtil.NewDetachedContext(ctx), 1*time.Minute)
@@ -366,3 +375,4 @@ func (e *Executor) labelJobValue(job model.Job) string {
 
 // Compile-time interface check:
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.ResourceUsageSampler = (*Executor)(nil)
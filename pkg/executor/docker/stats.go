@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	dockertypes "github.com/docker/docker/api/types"
+)
+
+// containerResourceUsage converts the stats of a container to the resources it is using, in the same way as
+// `docker stats`: the CPU usage is the number of cores used since the previous stats were collected, and the
+// memory usage excludes the page cache that the kernel can reclaim.
+func containerResourceUsage(stats dockertypes.StatsJSON) model.ResourceUsageData {
+	var usage model.ResourceUsageData
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		usage.CPU = cpuDelta / systemDelta * onlineCPUs
+	}
+
+	// cgroup v1 reports the reclaimable page cache as total_inactive_file, and cgroup v2 as inactive_file
+	cache, ok := stats.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = stats.MemoryStats.Stats["inactive_file"]
+	}
+	if stats.MemoryStats.Usage > cache {
+		usage.Memory = stats.MemoryStats.Usage - cache
+	}
+	return usage
+}
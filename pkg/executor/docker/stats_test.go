@@ -0,0 +1,30 @@
+//go:build unit || !integration
+
+package docker
+
+import (
+	"testing"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerResourceUsage(t *testing.T) {
+	var stats dockertypes.StatsJSON
+	stats.PreCPUStats.CPUUsage.TotalUsage = 1000
+	stats.PreCPUStats.SystemUsage = 10000
+	stats.CPUStats.CPUUsage.TotalUsage = 3000
+	stats.CPUStats.SystemUsage = 18000
+	stats.CPUStats.OnlineCPUs = 4
+	stats.MemoryStats.Usage = 100 * 1024 * 1024
+	stats.MemoryStats.Stats = map[string]uint64{"inactive_file": 40 * 1024 * 1024}
+
+	usage := containerResourceUsage(stats)
+	require.InDelta(t, 1.0, usage.CPU, 0.0001)
+	require.Equal(t, uint64(60*1024*1024), usage.Memory)
+
+	// the first stats of a container have no previous CPU usage
+	stats.PreCPUStats = dockertypes.CPUStats{}
+	stats.CPUStats.SystemUsage = 0
+	require.Zero(t, containerResourceUsage(stats).CPU)
+}
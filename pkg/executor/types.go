@@ -42,3 +42,10 @@ type Executor interface {
 		resultsDir string,
 	) (*model.RunCommandResult, error)
 }
+
+// ResourceUsageSampler is implemented by executors that can measure the resources actually used by the jobs they
+// are running, such as the CPU and memory used by a container.
+type ResourceUsageSampler interface {
+	// GetResourceUsage returns the resources currently used by the job, which must be running.
+	GetResourceUsage(ctx context.Context, job model.Job) (model.ResourceUsageData, error)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/combo"
 	filecoinunsealed "github.com/bacalhau-project/bacalhau/pkg/storage/filecoin_unsealed"
 	"github.com/bacalhau-project/bacalhau/pkg/storage/inline"
@@ -26,6 +27,8 @@ type StandardStorageProviderOptions struct {
 	API                  ipfs.Client
 	FilecoinUnsealedPath string
 	DownloadPath         string
+	// InputCache keeps the inputs fetched from IPFS and URLs across jobs when set
+	InputCache *cache.Cache
 }
 
 type StandardExecutorOptions struct {
@@ -55,7 +58,14 @@ func NewStandardStorageProvider(
 
 	inlineStorage := inline.NewStorage()
 
-	var useIPFSDriver storage.Storage = ipfsAPICopyStorage
+	var ipfsStorage, urlStorage storage.Storage = ipfsAPICopyStorage, urlDownloadStorage
+	// filecoin unsealed inputs are already stored locally, so only the inputs that are fetched are cached
+	if options.InputCache != nil {
+		ipfsStorage = cache.Wrap(ipfsStorage, options.InputCache)
+		urlStorage = cache.Wrap(urlStorage, options.InputCache)
+	}
+
+	useIPFSDriver := ipfsStorage
 
 	// if we are using a FilecoinUnsealedPath then construct a combo
 	// driver that will give preference to the filecoin unsealed driver
@@ -66,22 +76,22 @@ func NewStandardStorageProvider(
 			func(ctx context.Context) ([]storage.Storage, error) {
 				return []storage.Storage{
 					filecoinUnsealedStorage,
-					ipfsAPICopyStorage,
+					ipfsStorage,
 				}, nil
 			},
 			func(ctx context.Context, spec model.StorageSpec) (storage.Storage, error) {
 				filecoinUnsealedHasCid, err := filecoinUnsealedStorage.HasStorageLocally(ctx, spec)
 				if err != nil {
-					return ipfsAPICopyStorage, err
+					return ipfsStorage, err
 				}
 				if filecoinUnsealedHasCid {
 					return filecoinUnsealedStorage, nil
 				} else {
-					return ipfsAPICopyStorage, nil
+					return ipfsStorage, nil
 				}
 			},
 			func(ctx context.Context) (storage.Storage, error) {
-				return ipfsAPICopyStorage, nil
+				return ipfsStorage, nil
 			},
 		)
 
@@ -94,7 +104,7 @@ func NewStandardStorageProvider(
 
 	return model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
 		model.StorageSourceIPFS:             tracing.Wrap(useIPFSDriver),
-		model.StorageSourceURLDownload:      tracing.Wrap(urlDownloadStorage),
+		model.StorageSourceURLDownload:      tracing.Wrap(urlStorage),
 		model.StorageSourceFilecoinUnsealed: tracing.Wrap(filecoinUnsealedStorage),
 		model.StorageSourceInline:           tracing.Wrap(inlineStorage),
 	}), nil
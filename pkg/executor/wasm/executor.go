@@ -19,11 +19,13 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/util/closer"
 	"github.com/bacalhau-project/bacalhau/pkg/util/filefs"
+	"github.com/bacalhau-project/bacalhau/pkg/util/generic"
 	"github.com/bacalhau-project/bacalhau/pkg/util/mountfs"
 	"github.com/bacalhau-project/bacalhau/pkg/util/touchfs"
 	"github.com/c2h5oh/datasize"
 	"github.com/rs/zerolog/log"
 	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/sys"
 	"golang.org/x/exp/maps"
@@ -31,6 +33,9 @@ import (
 
 type Executor struct {
 	StorageProvider storage.StorageProvider
+
+	// the instances of the entry modules of the running jobs, by job id
+	instances generic.SyncMap[string, api.Module]
 }
 
 func NewExecutor(_ context.Context, storageProvider storage.StorageProvider) (*Executor, error) {
@@ -234,6 +239,9 @@ func (e *Executor) Run(ctx context.Context, job model.Job, jobResultsDir string)
 		return executor.FailResult(err)
 	}
 
+	e.instances.Put(job.ID(), instance)
+	defer e.instances.Delete(job.ID())
+
 	// The function should exit which results in a sys.ExitError. So we capture
 	// the exit code for inclusion in the job output, and ignore the return code
 	// from the function (most WASI compilers will not give one). Some compilers
@@ -257,5 +265,20 @@ func (e *Executor) GetOutputStream(context.Context, model.Job) (io.ReadCloser, e
 	return nil, fmt.Errorf("not implemented for wasm executor")
 }
 
+// GetResourceUsage implements executor.ResourceUsageSampler. Only the memory used by the job's entry module is
+// known, as WASM jobs run within the compute node process.
+func (e *Executor) GetResourceUsage(_ context.Context, job model.Job) (model.ResourceUsageData, error) {
+	instance, found := e.instances.Get(job.ID())
+	if !found {
+		return model.ResourceUsageData{}, fmt.Errorf("job %s is not running", job.ID())
+	}
+	var usage model.ResourceUsageData
+	if memory := instance.Memory(); memory != nil {
+		usage.Memory = uint64(memory.Size())
+	}
+	return usage, nil
+}
+
 // Compile-time check that Executor implements the Executor interface.
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.ResourceUsageSampler = (*Executor)(nil)
@@ -262,6 +262,28 @@ func (cl Client) HasCID(ctx context.Context, cid string) (bool, error) {
 	return false, nil
 }
 
+// PinnedCIDs returns the CIDs that are recursively pinned by the node.
+func (cl Client) PinnedCIDs(ctx context.Context) ([]string, error) {
+	pins, err := cl.API.Pin().Ls(ctx, icoreoptions.Pin.Ls.Recursive())
+	if err != nil {
+		return nil, fmt.Errorf("error listing pins: %w", err)
+	}
+
+	var res []string
+	for pin := range pins {
+		// keep draining the channel on errors so that the producer is not blocked
+		if pin.Err() != nil {
+			err = pin.Err()
+			continue
+		}
+		res = append(res, pin.Path().Cid().String())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing pins: %w", err)
+	}
+	return res, nil
+}
+
 func (cl Client) GetTreeNode(ctx context.Context, cid string) (IPLDTreeNode, error) {
 	ipldNode, err := cl.API.ResolveNode(ctx, icorepath.New(cid))
 	if err != nil {
@@ -0,0 +1,82 @@
+package job
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// cacheableSpec holds the fields of a spec that determine the results of a deterministic job.
+// Fields that only affect how or where the job runs, such as its deal, resources and timeouts, are left out
+// so that resubmissions of the same job with a different deal are still served from the cache.
+type cacheableSpec struct {
+	Engine    model.Engine            `json:"Engine"`
+	Verifier  model.Verifier          `json:"Verifier"`
+	Publisher model.Publisher         `json:"Publisher"`
+	Wasm      model.JobSpecWasm       `json:"Wasm"`
+	Inputs    []model.StorageSpec     `json:"Inputs"`
+	Outputs   []model.StorageSpec     `json:"Outputs"`
+	Sharding  model.JobShardingConfig `json:"Sharding"`
+}
+
+// IsCacheable returns true if the job always produces the same results for the same spec, so that
+// the results of a completed job can be served to identical jobs instead of running them again.
+// Only WASM jobs without network access, whose inputs and modules are all content-addressed, are cacheable.
+func IsCacheable(spec model.Spec) bool {
+	if spec.DoNotCache || spec.Engine != model.EngineWasm || !spec.Network.Disabled() || len(spec.Dependencies) > 0 {
+		return false
+	}
+	storageSpecs := append([]model.StorageSpec{spec.Wasm.EntryModule}, spec.Wasm.ImportModules...)
+	for _, storageSpec := range append(storageSpecs, spec.Inputs...) {
+		if !isContentAddressed(storageSpec) {
+			return false
+		}
+	}
+	return true
+}
+
+func isContentAddressed(storageSpec model.StorageSpec) bool {
+	switch storageSpec.StorageSource {
+	case model.StorageSourceIPFS, model.StorageSourceEstuary:
+		return storageSpec.CID != ""
+	case model.StorageSourceInline:
+		// the content is part of the spec itself
+		return true
+	default:
+		return false
+	}
+}
+
+// SpecHash returns the hash of the fields of the spec that determine the results of a cacheable job.
+func SpecHash(spec model.Spec) (string, error) {
+	data, err := json.Marshal(cacheableSpec{
+		Engine:    spec.Engine,
+		Verifier:  spec.Verifier,
+		Publisher: spec.Publisher,
+		Wasm:      spec.Wasm,
+		Inputs:    spec.Inputs,
+		Outputs:   spec.Outputs,
+		Sharding:  spec.Sharding,
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// WorkloadHash returns the hash of the full spec, except for the deal and annotations, which don't change what
+// the job runs. Unlike SpecHash, it covers the fields of every engine, so that jobs with the same workload hash
+// are expected to run for similar durations.
+func WorkloadHash(spec model.Spec) (string, error) {
+	spec.Deal = model.Deal{}
+	spec.Annotations = nil
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,93 @@
+//go:build unit || !integration
+
+package job
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheableTestSpec() model.Spec {
+	return model.Spec{
+		Engine: model.EngineWasm,
+		Wasm: model.JobSpecWasm{
+			EntryModule: model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmModule"},
+			EntryPoint:  "_start",
+		},
+		Inputs: []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmInput", Path: "/inputs"}},
+		Deal:   model.Deal{Concurrency: 1},
+	}
+}
+
+func TestIsCacheable(t *testing.T) {
+	require.True(t, IsCacheable(cacheableTestSpec()))
+
+	for name, update := range map[string]func(*model.Spec){
+		"opted out":      func(s *model.Spec) { s.DoNotCache = true },
+		"docker":         func(s *model.Spec) { s.Engine = model.EngineDocker },
+		"network access": func(s *model.Spec) { s.Network = model.NetworkConfig{Type: model.NetworkFull} },
+		"url input": func(s *model.Spec) {
+			s.Inputs = append(s.Inputs, model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: "https://example.com"})
+		},
+		"url module": func(s *model.Spec) {
+			s.Wasm.ImportModules = []model.StorageSpec{{StorageSource: model.StorageSourceURLDownload, URL: "https://example.com"}}
+		},
+		"dependencies": func(s *model.Spec) { s.Dependencies = []model.JobDependency{{JobID: "job"}} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			spec := cacheableTestSpec()
+			update(&spec)
+			require.False(t, IsCacheable(spec))
+		})
+	}
+}
+
+func TestSpecHash(t *testing.T) {
+	hash, err := SpecHash(cacheableTestSpec())
+	require.NoError(t, err)
+
+	// the deal and timeout do not change the results of the job
+	spec := cacheableTestSpec()
+	spec.Deal.Concurrency = 3
+	spec.Timeout = 60
+	otherHash, err := SpecHash(spec)
+	require.NoError(t, err)
+	require.Equal(t, hash, otherHash)
+
+	spec.Wasm.Parameters = []string{"--verbose"}
+	otherHash, err = SpecHash(spec)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherHash)
+}
+
+func TestWorkloadHash(t *testing.T) {
+	dockerSpec := func(entrypoint ...string) model.Spec {
+		return model.Spec{
+			Engine: model.EngineDocker,
+			Docker: model.JobSpecDocker{Image: "ubuntu", Entrypoint: entrypoint},
+			Deal:   model.Deal{Concurrency: 1},
+		}
+	}
+	hash, err := WorkloadHash(dockerSpec("sleep", "1"))
+	require.NoError(t, err)
+
+	// different docker jobs don't share their run durations, even though they have the same cacheable fields
+	otherHash, err := WorkloadHash(dockerSpec("sleep", "3600"))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherHash)
+	specHash, err := SpecHash(dockerSpec("sleep", "1"))
+	require.NoError(t, err)
+	otherSpecHash, err := SpecHash(dockerSpec("sleep", "3600"))
+	require.NoError(t, err)
+	require.Equal(t, specHash, otherSpecHash)
+
+	// the deal does not change what the job runs
+	spec := dockerSpec("sleep", "1")
+	spec.Deal = model.Deal{Concurrency: 3, Speculation: model.SpeculationPolicy{Percentile: 90}}
+	spec.Annotations = []string{"test"}
+	otherHash, err = WorkloadHash(spec)
+	require.NoError(t, err)
+	require.Equal(t, hash, otherHash)
+}
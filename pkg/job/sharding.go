@@ -0,0 +1,93 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+)
+
+const defaultShardingBasePath = "/inputs"
+
+// ExplodeShardedVolumes breaks the job's input volumes down into the items matching the sharding
+// glob pattern, sorted by their path so that the shards are the same every time they are generated.
+func ExplodeShardedVolumes(ctx context.Context, spec model.Spec, providers storage.StorageProvider) ([]model.StorageSpec, error) {
+	basePath := spec.Sharding.BasePath
+	if basePath == "" {
+		basePath = defaultShardingBasePath
+	}
+	globPattern := spec.Sharding.GlobPattern
+	if globPattern == "" {
+		// shard on the top level items of the base path
+		globPattern = "*"
+	}
+	// relative patterns are applied to the base path
+	if !strings.HasPrefix(globPattern, "/") {
+		globPattern = filepath.Join(basePath, globPattern)
+	}
+	if _, err := filepath.Match(globPattern, basePath); err != nil {
+		return nil, fmt.Errorf("invalid sharding glob pattern %s: %w", spec.Sharding.GlobPattern, err)
+	}
+
+	var items []model.StorageSpec
+	for _, input := range spec.Inputs {
+		provider, err := providers.Get(ctx, input.StorageSource)
+		if err != nil {
+			return nil, err
+		}
+		exploded, err := provider.Explode(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explode input volume %s: %w", input.Path, err)
+		}
+		for _, item := range exploded {
+			// the error was already checked against the base path
+			matched, _ := filepath.Match(globPattern, item.Path)
+			if matched {
+				items = append(items, item)
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+	return items, nil
+}
+
+// GenerateShards splits the job's inputs into shards of BatchSize items each according to the job's sharding config.
+// No shards are returned if sharding is not enabled for the job.
+func GenerateShards(ctx context.Context, spec model.Spec, providers storage.StorageProvider) ([]model.JobShard, error) {
+	if !spec.Sharding.IsEnabled() {
+		return nil, nil
+	}
+
+	items, err := ExplodeShardedVolumes(ctx, spec, providers)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no input items match the sharding glob pattern %s", spec.Sharding.GlobPattern)
+	}
+
+	batchSize := spec.Sharding.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	shards := make([]model.JobShard, 0, (len(items)+batchSize-1)/batchSize)
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		shards = append(shards, model.JobShard{
+			Index:  len(shards),
+			Inputs: items[start:end],
+		})
+	}
+	return shards, nil
+}
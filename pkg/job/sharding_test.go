@@ -0,0 +1,90 @@
+//go:build unit || !integration
+
+package job
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	noop_storage "github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateShards(t *testing.T) {
+	providers := model.NewNoopProvider[model.StorageSourceType, storage.Storage](noop_storage.NewNoopStorage(noop_storage.StorageConfig{
+		ExternalHooks: noop_storage.StorageConfigExternalHooks{
+			Explode: func(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+				items := []model.StorageSpec{spec}
+				for _, name := range []string{"c.txt", "a.txt", "b.csv", "d.txt"} {
+					item := spec
+					item.Path = filepath.Join(spec.Path, name)
+					items = append(items, item)
+				}
+				return items, nil
+			},
+		},
+	}))
+	input := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmInputs", Path: "/inputs"}
+
+	paths := func(shard model.JobShard) []string {
+		var result []string
+		for _, item := range shard.Inputs {
+			result = append(result, item.Path)
+		}
+		return result
+	}
+
+	for _, test := range []struct {
+		name     string
+		sharding model.JobShardingConfig
+		expected [][]string
+	}{
+		{
+			name:     "disabled",
+			sharding: model.JobShardingConfig{},
+		},
+		{
+			name:     "batch size",
+			sharding: model.JobShardingConfig{BatchSize: 3},
+			expected: [][]string{{"/inputs/a.txt", "/inputs/b.csv", "/inputs/c.txt"}, {"/inputs/d.txt"}},
+		},
+		{
+			name:     "absolute glob pattern",
+			sharding: model.JobShardingConfig{GlobPattern: "/inputs/*.txt"},
+			expected: [][]string{{"/inputs/a.txt"}, {"/inputs/c.txt"}, {"/inputs/d.txt"}},
+		},
+		{
+			name:     "relative glob pattern",
+			sharding: model.JobShardingConfig{GlobPattern: "*.csv", BasePath: "/inputs"},
+			expected: [][]string{{"/inputs/b.csv"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			shards, err := GenerateShards(context.Background(), model.Spec{
+				Inputs:   []model.StorageSpec{input},
+				Sharding: test.sharding,
+			}, providers)
+			require.NoError(t, err)
+			require.Len(t, shards, len(test.expected))
+			for i, shard := range shards {
+				require.Equal(t, i, shard.Index)
+				require.Equal(t, test.expected[i], paths(shard))
+			}
+		})
+	}
+
+	_, err := GenerateShards(context.Background(), model.Spec{
+		Inputs:   []model.StorageSpec{input},
+		Sharding: model.JobShardingConfig{GlobPattern: "*.json"},
+	}, providers)
+	require.Error(t, err)
+
+	_, err = GenerateShards(context.Background(), model.Spec{
+		Inputs:   []model.StorageSpec{input},
+		Sharding: model.JobShardingConfig{GlobPattern: "["},
+	}, providers)
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package job
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
@@ -208,10 +209,15 @@ func (resolver *StateResolver) GetResults(ctx context.Context, jobID string) ([]
 
 	for _, executionState := range GetCompletedVerifiedExecutionStates(jobState) {
 		results = append(results, model.PublishedResult{
-			NodeID: executionState.NodeID,
-			Data:   executionState.PublishedResult,
+			NodeID:     executionState.NodeID,
+			ShardIndex: executionState.ShardIndex,
+			Data:       executionState.PublishedResult,
 		})
 	}
+	// results of sharded jobs are merged in the order of their shards
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].ShardIndex < results[j].ShardIndex
+	})
 
 	return results, nil
 }
@@ -0,0 +1,88 @@
+package job
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// MaxTemplateJobs is the maximum number of child jobs a job template can expand to
+const MaxTemplateJobs = 1000
+
+// ExpandJobTemplate returns the spec of a child job for every combination of the values of the template's matrix,
+// where the placeholders of each parameter are replaced by its value. Combinations are ordered by the parameters'
+// names, with the values of the last parameter varying first.
+func ExpandJobTemplate(template model.JobTemplate) ([]model.Spec, error) {
+	if len(template.Matrix) == 0 {
+		return nil, fmt.Errorf("job template matrix is empty")
+	}
+
+	names := make([]string, 0, len(template.Matrix))
+	total := 1
+	for name, values := range template.Matrix {
+		if name == "" {
+			return nil, fmt.Errorf("job template parameter name is empty")
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("job template parameter %s has no values", name)
+		}
+		total *= len(values)
+		if total > MaxTemplateJobs {
+			return nil, fmt.Errorf("job template expands to more than %d jobs", MaxTemplateJobs)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	specs := make([]model.Spec, 0, total)
+	indexes := make([]int, len(names))
+	for i := 0; i < total; i++ {
+		replacements := make([]string, 0, 2*len(names))
+		for j, name := range names {
+			replacements = append(replacements, "{{"+name+"}}", template.Matrix[name][indexes[j]])
+		}
+		specs = append(specs, substituteParameters(template.Spec, strings.NewReplacer(replacements...)))
+
+		// move to the next combination, starting with the last parameter
+		for j := len(indexes) - 1; j >= 0; j-- {
+			indexes[j]++
+			if indexes[j] < len(template.Matrix[names[j]]) {
+				break
+			}
+			indexes[j] = 0
+		}
+	}
+	return specs, nil
+}
+
+// substituteParameters returns a copy of the spec where the placeholders of the docker entrypoint and environment
+// variables, wasm parameters and input paths are replaced
+func substituteParameters(spec model.Spec, replacer *strings.Replacer) model.Spec {
+	replaceAll := func(values []string) []string {
+		if values == nil {
+			return nil
+		}
+		replaced := make([]string, len(values))
+		for i, value := range values {
+			replaced[i] = replacer.Replace(value)
+		}
+		return replaced
+	}
+
+	spec.Docker.Entrypoint = replaceAll(spec.Docker.Entrypoint)
+	spec.Docker.EnvironmentVariables = replaceAll(spec.Docker.EnvironmentVariables)
+	spec.Wasm.Parameters = replaceAll(spec.Wasm.Parameters)
+	if spec.Inputs != nil {
+		inputs := make([]model.StorageSpec, len(spec.Inputs))
+		for i, input := range spec.Inputs {
+			input.CID = replacer.Replace(input.CID)
+			input.URL = replacer.Replace(input.URL)
+			input.Path = replacer.Replace(input.Path)
+			inputs[i] = input
+		}
+		spec.Inputs = inputs
+	}
+	return spec
+}
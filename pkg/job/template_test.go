@@ -0,0 +1,57 @@
+//go:build unit || !integration
+
+package job
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandJobTemplate(t *testing.T) {
+	template := model.JobTemplate{
+		Spec: model.Spec{
+			Docker: model.JobSpecDocker{
+				Entrypoint:           []string{"train", "--rate={{rate}}"},
+				EnvironmentVariables: []string{"MODEL={{model}}"},
+			},
+			Inputs: []model.StorageSpec{{StorageSource: model.StorageSourceURLDownload, URL: "https://example.com/{{model}}.bin"}},
+		},
+		Matrix: map[string][]string{
+			"rate":  {"0.1", "0.01"},
+			"model": {"small", "large"},
+		},
+	}
+
+	specs, err := ExpandJobTemplate(template)
+	require.NoError(t, err)
+	require.Len(t, specs, 4)
+	var combinations [][]string
+	for _, spec := range specs {
+		combinations = append(combinations, append(spec.Docker.EnvironmentVariables, spec.Docker.Entrypoint[1], spec.Inputs[0].URL))
+	}
+	require.Equal(t, [][]string{
+		{"MODEL=small", "--rate=0.1", "https://example.com/small.bin"},
+		{"MODEL=small", "--rate=0.01", "https://example.com/small.bin"},
+		{"MODEL=large", "--rate=0.1", "https://example.com/large.bin"},
+		{"MODEL=large", "--rate=0.01", "https://example.com/large.bin"},
+	}, combinations)
+
+	// the template itself is not modified
+	require.Equal(t, "MODEL={{model}}", template.Spec.Docker.EnvironmentVariables[0])
+	require.Equal(t, "https://example.com/{{model}}.bin", template.Spec.Inputs[0].URL)
+
+	tooLarge := make([]string, 40)
+	for name, matrix := range map[string]map[string][]string{
+		"empty":     nil,
+		"no values": {"rate": nil},
+		"unnamed":   {"": {"a"}},
+		"too large": {"a": tooLarge, "b": tooLarge},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ExpandJobTemplate(model.JobTemplate{Matrix: matrix})
+			require.Error(t, err)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/cron"
 )
 
 // VerifyJobCreatePayload verifies the values in a job creation request are legal.
@@ -42,6 +43,40 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		return fmt.Errorf("confidence must be >= 0")
 	}
 
+	if j.Spec.Deal.RetryPolicy.MaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts must be >= 0")
+	}
+
+	if j.Spec.Deal.RetryPolicy.Backoff < 0 {
+		return fmt.Errorf("retry backoff must be >= 0")
+	}
+
+	speculation := j.Spec.Deal.Speculation
+	if speculation.Percentile < 0 || speculation.Percentile >= 100 {
+		return fmt.Errorf("speculation percentile must be >= 0 and < 100")
+	}
+
+	if speculation.MinDuration < 0 {
+		return fmt.Errorf("speculation min duration must be >= 0")
+	}
+
+	if speculation.IsEnabled() && j.Spec.Deal.Concurrency > 1 {
+		return fmt.Errorf("speculative execution is only supported for jobs with a concurrency of 1")
+	}
+
+	if speculation.IsEnabled() && j.Spec.Deal.Gang {
+		return fmt.Errorf("speculative execution is not supported for gang jobs")
+	}
+
+	if j.Spec.Deal.MaxPrice < 0 {
+		return fmt.Errorf("max price must be >= 0")
+	}
+
+	timeouts := j.Spec.Timeouts
+	if timeouts.Bid < 0 || timeouts.Execution < 0 || timeouts.Verification < 0 || timeouts.Publish < 0 {
+		return fmt.Errorf("phase timeouts must be >= 0")
+	}
+
 	if !model.IsValidEngine(j.Spec.Engine) {
 		return fmt.Errorf("invalid executor type: %s", j.Spec.Engine.String())
 	}
@@ -54,6 +89,10 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		return fmt.Errorf("invalid publisher type: %s", j.Spec.Publisher.String())
 	}
 
+	if !model.IsValidPriorityClass(j.Spec.Priority) {
+		return fmt.Errorf("invalid priority class: %s", j.Spec.Priority.String())
+	}
+
 	if err := j.Spec.Network.IsValid(); err != nil {
 		return err
 	}
@@ -68,5 +107,174 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		}
 	}
 
+	if j.Spec.Sharding.BatchSize < 0 {
+		return fmt.Errorf("sharding batch size must be >= 0")
+	}
+
+	if len(j.Spec.ExecutionPlan.Shards) > 0 {
+		return fmt.Errorf("the execution plan is generated by the requester node and cannot be set")
+	}
+
+	for _, dependency := range j.Spec.Dependencies {
+		if dependency.JobID == "" {
+			return fmt.Errorf("dependency job id is empty")
+		}
+		if dependency.Path == "" {
+			return fmt.Errorf("dependency path is empty for job %s", dependency.JobID)
+		}
+	}
+
 	return nil
 }
+
+// VerifyPipelineCreatePayload verifies the values in a pipeline creation request are legal.
+func VerifyPipelineCreatePayload(ctx context.Context, pc *model.PipelineCreatePayload) error {
+	if pc.ClientID == "" {
+		return fmt.Errorf("ClientID is empty")
+	}
+
+	if pc.APIVersion == "" {
+		return fmt.Errorf("APIVersion is empty")
+	}
+
+	if pc.Pipeline == nil {
+		return fmt.Errorf("pipeline is empty")
+	}
+
+	if _, err := SortPipelineStages(*pc.Pipeline); err != nil {
+		return err
+	}
+
+	for _, stage := range pc.Pipeline.Stages {
+		for _, dependency := range stage.Dependencies {
+			if dependency.Path == "" {
+				return fmt.Errorf("stage %s: dependency path is empty for stage %s", stage.Name, dependency.Stage)
+			}
+		}
+		err := VerifyJob(ctx, &model.Job{
+			APIVersion: pc.APIVersion,
+			Spec:       stage.Spec,
+		})
+		if err != nil {
+			return fmt.Errorf("stage %s: %w", stage.Name, err)
+		}
+	}
+	return nil
+}
+
+// VerifyScheduleCreatePayload verifies the values in a schedule creation request are legal.
+func VerifyScheduleCreatePayload(ctx context.Context, sc *model.ScheduleCreatePayload) error {
+	if sc.ClientID == "" {
+		return fmt.Errorf("ClientID is empty")
+	}
+
+	if sc.APIVersion == "" {
+		return fmt.Errorf("APIVersion is empty")
+	}
+
+	if sc.Spec == nil {
+		return fmt.Errorf("job spec is empty")
+	}
+
+	if _, err := cron.Parse(sc.Cron); err != nil {
+		return err
+	}
+
+	if !model.IsValidScheduleOverlapPolicy(sc.OverlapPolicy) {
+		return fmt.Errorf("invalid overlap policy: %s", sc.OverlapPolicy)
+	}
+
+	return VerifyJob(ctx, &model.Job{
+		APIVersion: sc.APIVersion,
+		Spec:       *sc.Spec,
+	})
+}
+
+// VerifyJobTemplateCreatePayload verifies the values in a job template creation request are legal,
+// and that every child job expanded from the template is valid.
+func VerifyJobTemplateCreatePayload(ctx context.Context, tc *model.JobTemplateCreatePayload) error {
+	if tc.ClientID == "" {
+		return fmt.Errorf("ClientID is empty")
+	}
+
+	if tc.APIVersion == "" {
+		return fmt.Errorf("APIVersion is empty")
+	}
+
+	if tc.Template == nil {
+		return fmt.Errorf("job template is empty")
+	}
+
+	specs, err := ExpandJobTemplate(*tc.Template)
+	if err != nil {
+		return err
+	}
+	for i, spec := range specs {
+		err = VerifyJob(ctx, &model.Job{
+			APIVersion: tc.APIVersion,
+			Spec:       spec,
+		})
+		if err != nil {
+			return fmt.Errorf("job %d of template: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SortPipelineStages returns the stages of the pipeline in an order where every stage comes after
+// the stages it depends on. An error is returned if the stages do not form a directed acyclic graph.
+func SortPipelineStages(pipeline model.Pipeline) ([]model.PipelineStage, error) {
+	if len(pipeline.Stages) == 0 {
+		return nil, fmt.Errorf("pipeline has no stages")
+	}
+
+	stages := make(map[string]model.PipelineStage, len(pipeline.Stages))
+	for _, stage := range pipeline.Stages {
+		if stage.Name == "" {
+			return nil, fmt.Errorf("pipeline stage name is empty")
+		}
+		if _, ok := stages[stage.Name]; ok {
+			return nil, fmt.Errorf("duplicate pipeline stage %s", stage.Name)
+		}
+		stages[stage.Name] = stage
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(pipeline.Stages))
+	sorted := make([]model.PipelineStage, 0, len(pipeline.Stages))
+
+	var visit func(stage model.PipelineStage) error
+	visit = func(stage model.PipelineStage) error {
+		switch marks[stage.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("pipeline has a dependency cycle through stage %s", stage.Name)
+		}
+		marks[stage.Name] = visiting
+		for _, dependency := range stage.Dependencies {
+			parent, ok := stages[dependency.Stage]
+			if !ok {
+				return fmt.Errorf("stage %s depends on unknown stage %s", stage.Name, dependency.Stage)
+			}
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		marks[stage.Name] = visited
+		sorted = append(sorted, stage)
+		return nil
+	}
+
+	// visit the stages in their submitted order to keep the result deterministic
+	for _, stage := range pipeline.Stages {
+		if err := visit(stage); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,47 @@
+//go:build unit || !integration
+
+package job
+
+import (
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortPipelineStages(t *testing.T) {
+	stage := func(name string, dependencies ...string) model.PipelineStage {
+		s := model.PipelineStage{Name: name}
+		for _, dependency := range dependencies {
+			s.Dependencies = append(s.Dependencies, model.PipelineDependency{Stage: dependency, Path: "/inputs/" + dependency})
+		}
+		return s
+	}
+
+	sorted, err := SortPipelineStages(model.Pipeline{Stages: []model.PipelineStage{
+		stage("c", "b", "a"),
+		stage("b", "a"),
+		stage("a"),
+		stage("d"),
+	}})
+	require.NoError(t, err)
+	var names []string
+	for _, s := range sorted {
+		names = append(names, s.Name)
+	}
+	require.Equal(t, []string{"a", "b", "c", "d"}, names)
+
+	for name, stages := range map[string][]model.PipelineStage{
+		"empty":     nil,
+		"unnamed":   {stage("")},
+		"duplicate": {stage("a"), stage("a")},
+		"unknown":   {stage("a", "b")},
+		"self":      {stage("a", "a")},
+		"cycle":     {stage("a", "c"), stage("b", "a"), stage("c", "b")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := SortPipelineStages(model.Pipeline{Stages: stages})
+			require.Error(t, err)
+		})
+	}
+}
@@ -154,3 +154,57 @@ func (e ErrExecutionAlreadyTerminal) Error() string {
 	return fmt.Sprintf("execution %s is in terminal state %s and cannot transition to %s",
 		e.ExecutionID, e.Actual.String(), e.NewState.String())
 }
+
+// ErrScheduleNotFound is returned when the schedule is not found
+type ErrScheduleNotFound struct {
+	ScheduleID string
+}
+
+func NewErrScheduleNotFound(id string) ErrScheduleNotFound {
+	return ErrScheduleNotFound{ScheduleID: id}
+}
+
+func (e ErrScheduleNotFound) Error() string {
+	return "schedule not found: " + e.ScheduleID
+}
+
+// ErrScheduleAlreadyExists is returned when a schedule already exists
+type ErrScheduleAlreadyExists struct {
+	ScheduleID string
+}
+
+func NewErrScheduleAlreadyExists(id string) ErrScheduleAlreadyExists {
+	return ErrScheduleAlreadyExists{ScheduleID: id}
+}
+
+func (e ErrScheduleAlreadyExists) Error() string {
+	return "schedule already exists: " + e.ScheduleID
+}
+
+// ErrInvalidScheduleVersion is returned when a schedule has an invalid version.
+type ErrInvalidScheduleVersion struct {
+	ScheduleID string
+	Actual     int
+	Expected   int
+}
+
+func NewErrInvalidScheduleVersion(id string, actual int, expected int) ErrInvalidScheduleVersion {
+	return ErrInvalidScheduleVersion{ScheduleID: id, Actual: actual, Expected: expected}
+}
+
+func (e ErrInvalidScheduleVersion) Error() string {
+	return fmt.Sprintf("schedule %s has version %d but expected %d", e.ScheduleID, e.Actual, e.Expected)
+}
+
+// ErrResultCacheEntryNotFound is returned when no completed job is cached for a spec hash
+type ErrResultCacheEntryNotFound struct {
+	SpecHash string
+}
+
+func NewErrResultCacheEntryNotFound(specHash string) ErrResultCacheEntryNotFound {
+	return ErrResultCacheEntryNotFound{SpecHash: specHash}
+}
+
+func (e ErrResultCacheEntryNotFound) Error() string {
+	return "result cache entry not found: " + e.SpecHash
+}
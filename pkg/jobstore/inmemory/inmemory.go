@@ -24,6 +24,10 @@ type JobStore struct {
 	states     map[string]model.JobState
 	history    map[string][]model.JobHistory
 	inprogress map[string]struct{}
+	schedules  map[string]model.Schedule
+	leases     map[string]model.RequesterLease
+	usage      map[string][]model.UsageRecord
+	cache      map[string]model.ResultCacheEntry
 	mtx        sync.RWMutex
 }
 
@@ -33,6 +37,10 @@ func NewJobStore() *JobStore {
 		states:     make(map[string]model.JobState),
 		history:    make(map[string][]model.JobHistory),
 		inprogress: make(map[string]struct{}),
+		schedules:  make(map[string]model.Schedule),
+		leases:     make(map[string]model.RequesterLease),
+		usage:      make(map[string][]model.UsageRecord),
+		cache:      make(map[string]model.ResultCacheEntry),
 	}
 	res.mtx.EnableTracerWithOpts(sync.Opts{
 		Threshold: 10 * time.Millisecond,
@@ -75,6 +83,10 @@ func (d *JobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]mode
 			continue
 		}
 
+		if query.ParentID != "" && query.ParentID != j.Metadata.ParentID {
+			continue
+		}
+
 		// If we are not using include tags, by default every job is included.
 		// If a job is specifically included, that overrides it being excluded.
 		included := len(query.IncludeTags) == 0
@@ -214,6 +226,33 @@ func (d *JobStore) getJob(id string) (model.Job, error) {
 	return j, nil
 }
 
+func (d *JobStore) UpdateJob(_ context.Context, request jobstore.UpdateJobRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	jobID := request.Job.Metadata.ID
+	jobState, ok := d.states[jobID]
+	if !ok {
+		return jobstore.NewErrJobNotFound(jobID)
+	}
+
+	// check the expected state
+	if err := request.Condition.Validate(jobState); err != nil {
+		return err
+	}
+	if jobState.State.IsTerminal() {
+		return jobstore.NewErrJobAlreadyTerminal(jobID, jobState.State, jobState.State)
+	}
+
+	// update the job and bump the state version so that concurrent updates are detected
+	d.jobs[jobID] = request.Job
+	jobState.Version++
+	jobState.UpdateTime = time.Now()
+	d.states[jobID] = jobState
+	d.appendJobHistory(jobState, jobState.State, request.Comment)
+	return nil
+}
+
 func (d *JobStore) UpdateJobState(_ context.Context, request jobstore.UpdateJobStateRequest) error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -237,6 +276,9 @@ func (d *JobStore) UpdateJobState(_ context.Context, request jobstore.UpdateJobS
 	jobState.State = request.NewState
 	jobState.Version++
 	jobState.UpdateTime = time.Now()
+	if previousState == model.JobStateNew && request.NewState != model.JobStateNew {
+		jobState.ScheduleTime = jobState.UpdateTime
+	}
 	d.states[request.JobID] = jobState
 	if request.NewState.IsTerminal() {
 		delete(d.inprogress, request.JobID)
@@ -245,6 +287,19 @@ func (d *JobStore) UpdateJobState(_ context.Context, request jobstore.UpdateJobS
 	return nil
 }
 
+func (d *JobStore) DeleteJob(_ context.Context, jobID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.jobs[jobID]; !ok {
+		return bacerrors.NewJobNotFound(jobID)
+	}
+	delete(d.jobs, jobID)
+	delete(d.states, jobID)
+	delete(d.history, jobID)
+	delete(d.inprogress, jobID)
+	return nil
+}
+
 func (d *JobStore) CreateExecution(_ context.Context, execution model.ExecutionState) error {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -302,13 +357,13 @@ func (d *JobStore) UpdateExecution(_ context.Context, request jobstore.UpdateExe
 		return jobstore.NewErrExecutionAlreadyTerminal(request.ExecutionID, existingExecution.State, request.NewValues.State)
 	}
 
-	// populate default values
+	// populate default values. The creation time is copied explicitly, as mergo doesn't merge time.Time values
 	newExecution := request.NewValues
 	if newExecution.CreateTime.IsZero() {
-		newExecution.CreateTime = time.Now()
+		newExecution.CreateTime = existingExecution.CreateTime
 	}
 	if newExecution.UpdateTime.IsZero() {
-		newExecution.UpdateTime = existingExecution.CreateTime
+		newExecution.UpdateTime = time.Now()
 	}
 	if newExecution.Version == 0 {
 		newExecution.Version = existingExecution.Version + 1
@@ -327,6 +382,141 @@ func (d *JobStore) UpdateExecution(_ context.Context, request jobstore.UpdateExe
 	return nil
 }
 
+func (d *JobStore) CreateSchedule(_ context.Context, schedule model.Schedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.schedules[schedule.ID]; ok {
+		return jobstore.NewErrScheduleAlreadyExists(schedule.ID)
+	}
+	if schedule.Version == 0 {
+		schedule.Version = 1
+	}
+	d.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (d *JobStore) GetSchedule(_ context.Context, id string) (model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return d.getSchedule(id)
+}
+
+func (d *JobStore) GetSchedules(_ context.Context, clientID string) ([]model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	var result []model.Schedule
+	for _, schedule := range d.schedules {
+		if clientID == "" || schedule.ClientID == clientID {
+			result = append(result, schedule)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (d *JobStore) UpdateSchedule(_ context.Context, request jobstore.UpdateScheduleRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	existing, ok := d.schedules[request.Schedule.ID]
+	if !ok {
+		return jobstore.NewErrScheduleNotFound(request.Schedule.ID)
+	}
+	if request.ExpectedVersion != 0 && request.ExpectedVersion != existing.Version {
+		return jobstore.NewErrInvalidScheduleVersion(existing.ID, existing.Version, request.ExpectedVersion)
+	}
+	schedule := request.Schedule
+	schedule.Version = existing.Version + 1
+	d.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (d *JobStore) DeleteSchedule(_ context.Context, id string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.schedules[id]; !ok {
+		return jobstore.NewErrScheduleNotFound(id)
+	}
+	delete(d.schedules, id)
+	return nil
+}
+
+func (d *JobStore) RenewLease(_ context.Context, lease model.RequesterLease) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.leases[lease.NodeID] = lease
+	return nil
+}
+
+func (d *JobStore) GetLeases(_ context.Context) ([]model.RequesterLease, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return maps.Values(d.leases), nil
+}
+
+func (d *JobStore) AddUsageRecord(_ context.Context, record model.UsageRecord) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.usage[record.ClientID] = append(d.usage[record.ClientID], record)
+	return nil
+}
+
+func (d *JobStore) GetUsageRecords(_ context.Context, clientID string, since time.Time) ([]model.UsageRecord, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	var result []model.UsageRecord
+	for _, record := range d.usage[clientID] {
+		if !record.CompletedAt.Before(since) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+func (d *JobStore) PutResultCacheEntry(_ context.Context, entry model.ResultCacheEntry) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.cache[entry.SpecHash] = entry
+	return nil
+}
+
+func (d *JobStore) GetResultCacheEntry(_ context.Context, specHash string) (model.ResultCacheEntry, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	entry, ok := d.cache[specHash]
+	if !ok {
+		return model.ResultCacheEntry{}, jobstore.NewErrResultCacheEntryNotFound(specHash)
+	}
+	return entry, nil
+}
+
+func (d *JobStore) DeleteResultCacheEntry(_ context.Context, specHash string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if _, ok := d.cache[specHash]; !ok {
+		return jobstore.NewErrResultCacheEntryNotFound(specHash)
+	}
+	delete(d.cache, specHash)
+	return nil
+}
+
+// helper method to read a single schedule from memory, supporting short ids.
+// The callers are expected to be holding a lock.
+func (d *JobStore) getSchedule(id string) (model.Schedule, error) {
+	if schedule, ok := d.schedules[id]; ok {
+		return schedule, nil
+	}
+	if len(id) == model.ShortIDLength {
+		for scheduleID, schedule := range d.schedules {
+			if jobutils.ShortID(scheduleID) == id {
+				return schedule, nil
+			}
+		}
+	}
+	return model.Schedule{}, jobstore.NewErrScheduleNotFound(id)
+}
+
 func (d *JobStore) appendJobHistory(updateJob model.JobState, previousState model.JobStateType, comment string) {
 	historyEntry := model.JobHistory{
 		Type:  model.JobHistoryTypeJobLevel,
@@ -0,0 +1,3 @@
+drop table job_history;
+drop table job_annotation;
+drop table job;
@@ -0,0 +1,27 @@
+create table job (
+  id varchar(255) PRIMARY KEY,
+  created timestamp,
+  clientid varchar(255),
+  state integer not null,
+  version integer not null,
+  jobdata text not null,
+  statedata text not null
+);
+CREATE INDEX idx_job_clientid ON job (clientid);
+CREATE INDEX idx_job_state ON job (state);
+
+create table job_annotation (
+  job_id varchar(255),
+  annotation varchar(255),
+  FOREIGN KEY(job_id) REFERENCES job(id)
+);
+CREATE INDEX idx_job_annotation ON job_annotation (annotation);
+CREATE INDEX idx_job_annotation_job_id ON job_annotation (job_id);
+
+create table job_history (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  job_id varchar(255),
+  historydata text not null,
+  FOREIGN KEY(job_id) REFERENCES job(id)
+);
+CREATE INDEX idx_job_history_job_id ON job_history (job_id);
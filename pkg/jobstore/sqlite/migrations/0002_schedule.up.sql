@@ -0,0 +1,8 @@
+create table schedule (
+  id varchar(255) PRIMARY KEY,
+  created timestamp,
+  clientid varchar(255),
+  version integer not null,
+  scheduledata text not null
+);
+CREATE INDEX idx_schedule_clientid ON schedule (clientid);
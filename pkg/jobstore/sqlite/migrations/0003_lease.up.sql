@@ -0,0 +1,5 @@
+create table lease (
+  nodeid varchar(255) PRIMARY KEY,
+  expires timestamp,
+  leasedata text not null
+);
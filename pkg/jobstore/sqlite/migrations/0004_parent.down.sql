@@ -0,0 +1,2 @@
+DROP INDEX idx_job_parentid;
+alter table job drop column parentid;
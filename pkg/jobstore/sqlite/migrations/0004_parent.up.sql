@@ -0,0 +1,2 @@
+alter table job add column parentid varchar(255) not null default '';
+CREATE INDEX idx_job_parentid ON job (parentid);
@@ -0,0 +1 @@
+drop table usage_record;
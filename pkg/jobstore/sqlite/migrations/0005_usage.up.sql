@@ -0,0 +1,7 @@
+create table usage_record (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  clientid varchar(255) not null,
+  completed integer not null,
+  recorddata text not null
+);
+CREATE INDEX idx_usage_record_clientid_completed ON usage_record (clientid, completed);
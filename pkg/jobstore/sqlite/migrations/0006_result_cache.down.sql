@@ -0,0 +1 @@
+drop table result_cache;
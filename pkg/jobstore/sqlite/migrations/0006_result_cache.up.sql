@@ -0,0 +1,4 @@
+create table result_cache (
+  spechash varchar(64) PRIMARY KEY,
+  entrydata text not null
+);
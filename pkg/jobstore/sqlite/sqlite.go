@@ -0,0 +1,996 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/imdario/mergo"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+const newJobComment = "Job created"
+
+//go:embed migrations/*.sql
+var fs embed.FS
+
+// JobStore is a jobstore.Store that persists jobs, their state and history to a SQLite database,
+// so that they survive restarts of the requester node.
+// The job state, including its executions, is stored as a single JSON document per job, and all updates
+// are done in transactions to keep the optimistic concurrency checks consistent with the persisted data.
+type JobStore struct {
+	db  *sql.DB
+	mtx sync.RWMutex
+}
+
+func NewJobStore(filename string) (*JobStore, error) {
+	// immediate transactions take the database write lock upfront, which serializes read-modify-write
+	// updates even if the database file is shared between multiple processes.
+	dataSource := fmt.Sprintf("file:%s?_txlock=immediate&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", filename)
+	db, err := otelsql.Open(
+		"sqlite",
+		dataSource,
+		otelsql.WithAttributes(semconv.DBSystemSqlite, semconv.PeerService("sqlite")),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = otelsql.RegisterDBStatsMetrics(db, otelsql.WithAttributes(semconv.DBSystemSqlite)); err != nil {
+		return nil, err
+	}
+	if err = migrateUp(filename); err != nil {
+		return nil, err
+	}
+
+	res := &JobStore{
+		db: db,
+	}
+	res.mtx.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        "SQLiteJobStore.mtx",
+	})
+	return res, nil
+}
+
+func migrateUp(filename string) error {
+	files, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return err
+	}
+	migrations, err := migrate.NewWithSourceInstance("iofs", files, fmt.Sprintf("sqlite://%s", filename))
+	if err != nil {
+		return err
+	}
+	defer migrations.Close()
+	err = migrations.Up()
+	if err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// Close closes the underlying database
+func (d *JobStore) Close() error {
+	return d.db.Close()
+}
+
+// Gets a job from the datastore.
+//
+// Errors:
+//
+//   - error-job-not-found        		  -- if the job is not found
+func (d *JobStore) GetJob(ctx context.Context, id string) (model.Job, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return getJob(ctx, d.db, id)
+}
+
+func (d *JobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]model.Job, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	if query.ID != "" {
+		j, err := getJob(ctx, d.db, query.ID)
+		if err != nil {
+			return nil, err
+		}
+		return []model.Job{j}, nil
+	}
+
+	where, args := getJobsWhereClause(query)
+	after := ""
+	order := "asc"
+	if query.SortReverse {
+		order = "desc"
+	}
+	switch query.SortBy {
+	case "id":
+		after += " order by id " + order
+	case "created_at":
+		after += " order by created " + order
+	}
+	if query.Limit > 0 {
+		after += fmt.Sprintf(" limit %d", query.Limit)
+		if query.Offset > 0 {
+			after += fmt.Sprintf(" offset %d", query.Offset)
+		}
+	}
+
+	rows, err := d.db.QueryContext(ctx, "select jobdata from job "+where+after, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Job
+	for rows.Next() {
+		var jobData string
+		if err = rows.Scan(&jobData); err != nil {
+			return nil, err
+		}
+		var j model.Job
+		if err = json.Unmarshal([]byte(jobData), &j); err != nil {
+			return nil, err
+		}
+		result = append(result, j)
+	}
+	return result, rows.Err()
+}
+
+func (d *JobStore) GetJobState(ctx context.Context, jobID string) (model.JobState, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	state, err := getJobState(ctx, d.db, jobID)
+	if errors.As(err, &jobstore.ErrJobNotFound{}) {
+		return model.JobState{}, bacerrors.NewJobNotFound(jobID)
+	}
+	return state, err
+}
+
+func (d *JobStore) GetInProgressJobs(ctx context.Context) ([]model.JobWithInfo, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var args []interface{}
+	var placeholders []string
+	for _, state := range model.JobStateTypes() {
+		if state.IsTerminal() {
+			args = append(args, int(state))
+			placeholders = append(placeholders, "?")
+		}
+	}
+
+	rows, err := d.db.QueryContext(ctx,
+		"select jobdata, statedata from job where state not in ("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.JobWithInfo
+	for rows.Next() {
+		var jobData, stateData string
+		if err = rows.Scan(&jobData, &stateData); err != nil {
+			return nil, err
+		}
+		var info model.JobWithInfo
+		if err = json.Unmarshal([]byte(jobData), &info.Job); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(stateData), &info.State); err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+	return result, rows.Err()
+}
+
+func (d *JobStore) GetJobHistory(ctx context.Context, jobID string) ([]model.JobHistory, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	rows, err := d.db.QueryContext(ctx, "select historydata from job_history where job_id = ? order by id asc", jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.JobHistory
+	for rows.Next() {
+		var historyData string
+		if err = rows.Scan(&historyData); err != nil {
+			return nil, err
+		}
+		var entry model.JobHistory
+		if err = json.Unmarshal([]byte(historyData), &entry); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, jobstore.NewErrJobNotFound(jobID)
+	}
+	return history, nil
+}
+
+func (d *JobStore) GetJobsCount(ctx context.Context, query jobstore.JobQuery) (int, error) {
+	if query.ID != "" {
+		_, err := d.GetJob(ctx, query.ID)
+		if err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	where, args := getJobsWhereClause(query)
+	var count int
+	err := d.db.QueryRowContext(ctx, "select count(id) from job "+where, args...).Scan(&count)
+	return count, err
+}
+
+func (d *JobStore) CreateJob(ctx context.Context, job model.Job) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "select count(id) from job where id = ?", job.Metadata.ID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return jobstore.NewErrJobAlreadyExists(job.Metadata.ID)
+	}
+
+	jobData, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	// populate job state
+	jobState := model.JobState{
+		JobID:      job.Metadata.ID,
+		State:      model.JobStateNew,
+		Version:    1,
+		CreateTime: time.Now(),
+		UpdateTime: time.Now(),
+	}
+	stateData, err := json.Marshal(jobState)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"insert into job (id, created, clientid, parentid, state, version, jobdata, statedata) values (?, ?, ?, ?, ?, ?, ?, ?)",
+		job.Metadata.ID,
+		job.Metadata.CreatedAt.UTC().Format(time.RFC3339Nano),
+		job.Metadata.ClientID,
+		job.Metadata.ParentID,
+		int(jobState.State),
+		jobState.Version,
+		string(jobData),
+		string(stateData),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, annotation := range job.Spec.Annotations {
+		_, err = tx.ExecContext(ctx, "insert into job_annotation (job_id, annotation) values (?, ?)", job.Metadata.ID, annotation)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = appendJobHistory(ctx, tx, jobState, model.JobStateNew, newJobComment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) UpdateJob(ctx context.Context, request jobstore.UpdateJobRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	job := request.Job
+	jobState, err := getJobState(ctx, tx, job.Metadata.ID)
+	if err != nil {
+		return err
+	}
+
+	// check the expected state
+	if err = request.Condition.Validate(jobState); err != nil {
+		return err
+	}
+	if jobState.State.IsTerminal() {
+		return jobstore.NewErrJobAlreadyTerminal(job.Metadata.ID, jobState.State, jobState.State)
+	}
+
+	jobData, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "update job set jobdata = ? where id = ?", string(jobData), job.Metadata.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "delete from job_annotation where job_id = ?", job.Metadata.ID)
+	if err != nil {
+		return err
+	}
+	for _, annotation := range job.Spec.Annotations {
+		_, err = tx.ExecContext(ctx, "insert into job_annotation (job_id, annotation) values (?, ?)", job.Metadata.ID, annotation)
+		if err != nil {
+			return err
+		}
+	}
+
+	// bump the state version so that concurrent updates are detected
+	jobState.Version++
+	jobState.UpdateTime = time.Now()
+	if err = updateJobState(ctx, tx, jobState, jobState.Version-1); err != nil {
+		return err
+	}
+	if err = appendJobHistory(ctx, tx, jobState, jobState.State, request.Comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) UpdateJobState(ctx context.Context, request jobstore.UpdateJobStateRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	// get the existing job state
+	jobState, err := getJobState(ctx, tx, request.JobID)
+	if err != nil {
+		return err
+	}
+
+	// check the expected state
+	if err = request.Condition.Validate(jobState); err != nil {
+		return err
+	}
+	if jobState.State.IsTerminal() {
+		return jobstore.NewErrJobAlreadyTerminal(request.JobID, jobState.State, request.NewState)
+	}
+
+	// update the job state
+	previousState := jobState.State
+	jobState.State = request.NewState
+	jobState.Version++
+	jobState.UpdateTime = time.Now()
+	if previousState == model.JobStateNew && request.NewState != model.JobStateNew {
+		jobState.ScheduleTime = jobState.UpdateTime
+	}
+	if err = updateJobState(ctx, tx, jobState, jobState.Version-1); err != nil {
+		return err
+	}
+	if err = appendJobHistory(ctx, tx, jobState, previousState, request.Comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) DeleteJob(ctx context.Context, jobID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "delete from job where id = ?", jobID)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return bacerrors.NewJobNotFound(jobID)
+	}
+	if _, err = tx.ExecContext(ctx, "delete from job_annotation where job_id = ?", jobID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, "delete from job_history where job_id = ?", jobID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) CreateExecution(ctx context.Context, execution model.ExecutionState) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	jobState, err := getJobState(ctx, tx, execution.JobID)
+	if err != nil {
+		return err
+	}
+	for _, e := range jobState.Executions {
+		if e.ID() == execution.ID() {
+			return jobstore.NewErrExecutionAlreadyExists(execution.ID())
+		}
+	}
+	if execution.CreateTime.IsZero() {
+		execution.CreateTime = time.Now()
+	}
+	if execution.UpdateTime.IsZero() {
+		execution.UpdateTime = execution.CreateTime
+	}
+	if execution.Version == 0 {
+		execution.Version = 1
+	}
+	jobState.Executions = append(jobState.Executions, execution)
+	if err = updateJobState(ctx, tx, jobState, jobState.Version); err != nil {
+		return err
+	}
+	if err = appendExecutionHistory(ctx, tx, execution, model.ExecutionStateNew, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) UpdateExecution(ctx context.Context, request jobstore.UpdateExecutionRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	// find the existing execution
+	jobState, err := getJobState(ctx, tx, request.ExecutionID.JobID)
+	if err != nil {
+		return err
+	}
+	var existingExecution model.ExecutionState
+	executionIndex := -1
+	for i, e := range jobState.Executions {
+		if e.ID() == request.ExecutionID {
+			existingExecution = e
+			executionIndex = i
+			break
+		}
+	}
+	if executionIndex == -1 {
+		return jobstore.NewErrExecutionNotFound(request.ExecutionID)
+	}
+
+	// check the expected state
+	if err = request.Condition.Validate(existingExecution); err != nil {
+		return err
+	}
+	if existingExecution.State.IsTerminal() {
+		return jobstore.NewErrExecutionAlreadyTerminal(request.ExecutionID, existingExecution.State, request.NewValues.State)
+	}
+
+	// populate default values. The creation time is copied explicitly, as mergo doesn't merge time.Time values
+	newExecution := request.NewValues
+	if newExecution.CreateTime.IsZero() {
+		newExecution.CreateTime = existingExecution.CreateTime
+	}
+	if newExecution.UpdateTime.IsZero() {
+		newExecution.UpdateTime = time.Now()
+	}
+	if newExecution.Version == 0 {
+		newExecution.Version = existingExecution.Version + 1
+	}
+
+	err = mergo.Merge(&newExecution, existingExecution)
+	if err != nil {
+		return err
+	}
+
+	// update the execution
+	previousState := existingExecution.State
+	jobState.Executions[executionIndex] = newExecution
+	if err = updateJobState(ctx, tx, jobState, jobState.Version); err != nil {
+		return err
+	}
+	if err = appendExecutionHistory(ctx, tx, newExecution, previousState, request.Comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) CreateSchedule(ctx context.Context, schedule model.Schedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "select count(id) from schedule where id = ?", schedule.ID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return jobstore.NewErrScheduleAlreadyExists(schedule.ID)
+	}
+
+	if schedule.Version == 0 {
+		schedule.Version = 1
+	}
+	scheduleData, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"insert into schedule (id, created, clientid, version, scheduledata) values (?, ?, ?, ?, ?)",
+		schedule.ID,
+		schedule.CreatedAt.UTC().Format(time.RFC3339Nano),
+		schedule.ClientID,
+		schedule.Version,
+		string(scheduleData),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) GetSchedule(ctx context.Context, id string) (model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return getSchedule(ctx, d.db, id)
+}
+
+func (d *JobStore) GetSchedules(ctx context.Context, clientID string) ([]model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	query := "select scheduledata from schedule"
+	var args []interface{}
+	if clientID != "" {
+		query += " where clientid = ?"
+		args = append(args, clientID)
+	}
+	rows, err := d.db.QueryContext(ctx, query+" order by created asc", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Schedule
+	for rows.Next() {
+		var scheduleData string
+		if err = rows.Scan(&scheduleData); err != nil {
+			return nil, err
+		}
+		var schedule model.Schedule
+		if err = json.Unmarshal([]byte(scheduleData), &schedule); err != nil {
+			return nil, err
+		}
+		result = append(result, schedule)
+	}
+	return result, rows.Err()
+}
+
+func (d *JobStore) UpdateSchedule(ctx context.Context, request jobstore.UpdateScheduleRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	existing, err := getSchedule(ctx, tx, request.Schedule.ID)
+	if err != nil {
+		return err
+	}
+	if request.ExpectedVersion != 0 && request.ExpectedVersion != existing.Version {
+		return jobstore.NewErrInvalidScheduleVersion(existing.ID, existing.Version, request.ExpectedVersion)
+	}
+
+	schedule := request.Schedule
+	schedule.Version = existing.Version + 1
+	scheduleData, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "update schedule set version = ?, scheduledata = ? where id = ?",
+		schedule.Version, string(scheduleData), schedule.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *JobStore) DeleteSchedule(ctx context.Context, id string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	result, err := d.db.ExecContext(ctx, "delete from schedule where id = ?", id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return jobstore.NewErrScheduleNotFound(id)
+	}
+	return nil
+}
+
+func (d *JobStore) RenewLease(ctx context.Context, lease model.RequesterLease) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	leaseData, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.ExecContext(ctx,
+		"insert into lease (nodeid, expires, leasedata) values (?, ?, ?) "+
+			"on conflict (nodeid) do update set expires = excluded.expires, leasedata = excluded.leasedata",
+		lease.NodeID,
+		lease.ExpireTime.UTC().Format(time.RFC3339Nano),
+		string(leaseData),
+	)
+	return err
+}
+
+func (d *JobStore) GetLeases(ctx context.Context) ([]model.RequesterLease, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	rows, err := d.db.QueryContext(ctx, "select leasedata from lease order by nodeid asc")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.RequesterLease
+	for rows.Next() {
+		var leaseData string
+		if err = rows.Scan(&leaseData); err != nil {
+			return nil, err
+		}
+		var lease model.RequesterLease
+		if err = json.Unmarshal([]byte(leaseData), &lease); err != nil {
+			return nil, err
+		}
+		result = append(result, lease)
+	}
+	return result, rows.Err()
+}
+
+func (d *JobStore) AddUsageRecord(ctx context.Context, record model.UsageRecord) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	recordData, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.ExecContext(ctx,
+		"insert into usage_record (clientid, completed, recorddata) values (?, ?, ?)",
+		record.ClientID,
+		// stored as nanoseconds so that records are compared by time rather than by their formatted string
+		record.CompletedAt.UnixNano(),
+		string(recordData),
+	)
+	return err
+}
+
+func (d *JobStore) GetUsageRecords(ctx context.Context, clientID string, since time.Time) ([]model.UsageRecord, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	rows, err := d.db.QueryContext(ctx,
+		"select recorddata from usage_record where clientid = ? and completed >= ? order by completed asc",
+		clientID,
+		since.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.UsageRecord
+	for rows.Next() {
+		var recordData string
+		if err = rows.Scan(&recordData); err != nil {
+			return nil, err
+		}
+		var record model.UsageRecord
+		if err = json.Unmarshal([]byte(recordData), &record); err != nil {
+			return nil, err
+		}
+		result = append(result, record)
+	}
+	return result, rows.Err()
+}
+
+func (d *JobStore) PutResultCacheEntry(ctx context.Context, entry model.ResultCacheEntry) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.ExecContext(ctx,
+		"insert into result_cache (spechash, entrydata) values (?, ?) "+
+			"on conflict (spechash) do update set entrydata = excluded.entrydata",
+		entry.SpecHash,
+		string(entryData),
+	)
+	return err
+}
+
+func (d *JobStore) GetResultCacheEntry(ctx context.Context, specHash string) (model.ResultCacheEntry, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var entryData string
+	row := d.db.QueryRowContext(ctx, "select entrydata from result_cache where spechash = ?", specHash)
+	if err := row.Scan(&entryData); err != nil {
+		if err == sql.ErrNoRows {
+			return model.ResultCacheEntry{}, jobstore.NewErrResultCacheEntryNotFound(specHash)
+		}
+		return model.ResultCacheEntry{}, err
+	}
+	var entry model.ResultCacheEntry
+	err := json.Unmarshal([]byte(entryData), &entry)
+	return entry, err
+}
+
+func (d *JobStore) DeleteResultCacheEntry(ctx context.Context, specHash string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	result, err := d.db.ExecContext(ctx, "delete from result_cache where spechash = ?", specHash)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return jobstore.NewErrResultCacheEntryNotFound(specHash)
+	}
+	return nil
+}
+
+// sqlClient is so we can pass *sql.DB and *sql.Tx to the same functions
+type sqlClient interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getJob(ctx context.Context, db sqlClient, id string) (model.Job, error) {
+	if len(id) < model.ShortIDLength {
+		return model.Job{}, bacerrors.NewJobNotFound(id)
+	}
+
+	var row *sql.Row
+	if jobutils.ShortID(id) == id {
+		// support for short job IDs
+		row = db.QueryRowContext(ctx, "select jobdata from job where id like ? || '%' limit 1", id)
+	} else {
+		row = db.QueryRowContext(ctx, "select jobdata from job where id = ?", id)
+	}
+
+	var jobData string
+	if err := row.Scan(&jobData); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Job{}, bacerrors.NewJobNotFound(id)
+		}
+		return model.Job{}, err
+	}
+	var j model.Job
+	err := json.Unmarshal([]byte(jobData), &j)
+	return j, err
+}
+
+func getJobState(ctx context.Context, db sqlClient, jobID string) (model.JobState, error) {
+	var stateData string
+	err := db.QueryRowContext(ctx, "select statedata from job where id = ?", jobID).Scan(&stateData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.JobState{}, jobstore.NewErrJobNotFound(jobID)
+		}
+		return model.JobState{}, err
+	}
+	var state model.JobState
+	err = json.Unmarshal([]byte(stateData), &state)
+	return state, err
+}
+
+func getSchedule(ctx context.Context, db sqlClient, id string) (model.Schedule, error) {
+	var row *sql.Row
+	if len(id) == model.ShortIDLength {
+		// support for short schedule IDs
+		row = db.QueryRowContext(ctx, "select scheduledata from schedule where id like ? || '%' limit 1", id)
+	} else {
+		row = db.QueryRowContext(ctx, "select scheduledata from schedule where id = ?", id)
+	}
+
+	var scheduleData string
+	if err := row.Scan(&scheduleData); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Schedule{}, jobstore.NewErrScheduleNotFound(id)
+		}
+		return model.Schedule{}, err
+	}
+	var schedule model.Schedule
+	err := json.Unmarshal([]byte(scheduleData), &schedule)
+	return schedule, err
+}
+
+// updateJobState persists the job state if the stored version still matches the expected version
+func updateJobState(ctx context.Context, db sqlClient, jobState model.JobState, expectedVersion int) error {
+	stateData, err := json.Marshal(jobState)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx,
+		"update job set state = ?, version = ?, statedata = ? where id = ? and version = ?",
+		int(jobState.State), jobState.Version, string(stateData), jobState.JobID, expectedVersion)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return jobstore.NewErrInvalidJobVersion(jobState.JobID, jobState.Version, expectedVersion)
+	}
+	return nil
+}
+
+func getJobsWhereClause(query jobstore.JobQuery) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if !query.ReturnAll && query.ClientID != "" {
+		clauses = append(clauses, "clientid = ?")
+		args = append(args, query.ClientID)
+	}
+
+	if query.ParentID != "" {
+		clauses = append(clauses, "parentid = ?")
+		args = append(args, query.ParentID)
+	}
+
+	// If we are not using include tags, by default every job is included.
+	// If a job is specifically included, that overrides it being excluded.
+	tags := make([]string, 0)
+	annotationFilter := "exists (select 1 from job_annotation where job_annotation.job_id = job.id and annotation in (%s))"
+	if len(query.IncludeTags) > 0 {
+		for _, tag := range query.IncludeTags {
+			tags = append(tags, "?")
+			args = append(args, string(tag))
+		}
+	} else if len(query.ExcludeTags) > 0 {
+		for _, tag := range query.ExcludeTags {
+			tags = append(tags, "?")
+			args = append(args, string(tag))
+		}
+		annotationFilter = "not " + annotationFilter
+	}
+	if len(tags) > 0 {
+		clauses = append(clauses, fmt.Sprintf(annotationFilter, strings.Join(tags, ", ")))
+	}
+
+	if len(clauses) == 0 {
+		return "", args
+	}
+	return "where " + strings.Join(clauses, " and "), args
+}
+
+func appendJobHistory(
+	ctx context.Context, db sqlClient, updateJob model.JobState, previousState model.JobStateType, comment string) error {
+	historyEntry := model.JobHistory{
+		Type:  model.JobHistoryTypeJobLevel,
+		JobID: updateJob.JobID,
+		JobState: &model.StateChange[model.JobStateType]{
+			Previous: previousState,
+			New:      updateJob.State,
+		},
+		NewVersion: updateJob.Version,
+		Comment:    comment,
+		Time:       updateJob.UpdateTime,
+	}
+	return appendHistory(ctx, db, historyEntry)
+}
+
+func appendExecutionHistory(ctx context.Context, db sqlClient,
+	updatedExecution model.ExecutionState, previousState model.ExecutionStateType, comment string) error {
+	historyEntry := model.JobHistory{
+		Type:             model.JobHistoryTypeExecutionLevel,
+		JobID:            updatedExecution.JobID,
+		NodeID:           updatedExecution.NodeID,
+		ComputeReference: updatedExecution.ComputeReference,
+		ExecutionState: &model.StateChange[model.ExecutionStateType]{
+			Previous: previousState,
+			New:      updatedExecution.State,
+		},
+		NewVersion: updatedExecution.Version,
+		Comment:    comment,
+		Time:       updatedExecution.UpdateTime,
+	}
+	return appendHistory(ctx, db, historyEntry)
+}
+
+func appendHistory(ctx context.Context, db sqlClient, historyEntry model.JobHistory) error {
+	historyData, err := json.Marshal(historyEntry)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "insert into job_history (job_id, historydata) values (?, ?)", historyEntry.JobID, string(historyData))
+	return err
+}
+
+// Static check to ensure that JobStore implements jobstore.Store:
+var _ jobstore.Store = (*JobStore)(nil)
@@ -0,0 +1,378 @@
+//go:build unit || !integration
+
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLiteJobStoreSuite struct {
+	suite.Suite
+	filename string
+	store    *JobStore
+}
+
+func (s *SQLiteJobStoreSuite) SetupTest() {
+	s.filename = filepath.Join(s.T().TempDir(), "jobs.db")
+	var err error
+	s.store, err = NewJobStore(s.filename)
+	s.Require().NoError(err)
+}
+
+func (s *SQLiteJobStoreSuite) TearDownTest() {
+	s.NoError(s.store.Close())
+}
+
+func TestSQLiteJobStoreSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteJobStoreSuite))
+}
+
+func (s *SQLiteJobStoreSuite) createJob(clientID string, annotations ...string) model.Job {
+	job := model.Job{
+		Metadata: model.Metadata{
+			ID:       uuid.NewString(),
+			ClientID: clientID,
+		},
+		Spec: model.Spec{
+			Annotations: annotations,
+			Deal:        model.Deal{Concurrency: 1},
+		},
+	}
+	s.Require().NoError(s.store.CreateJob(context.Background(), job))
+	return job
+}
+
+func (s *SQLiteJobStoreSuite) TestCreateAndGetJob() {
+	ctx := context.Background()
+	job := s.createJob("client")
+
+	stored, err := s.store.GetJob(ctx, job.ID())
+	s.NoError(err)
+	s.Equal(job.ID(), stored.ID())
+	s.Equal(job.Spec.Deal, stored.Spec.Deal)
+
+	// short ids are resolved
+	stored, err = s.store.GetJob(ctx, model.ShortID(job.ID()))
+	s.NoError(err)
+	s.Equal(job.ID(), stored.ID())
+
+	state, err := s.store.GetJobState(ctx, job.ID())
+	s.NoError(err)
+	s.Equal(model.JobStateNew, state.State)
+	s.Equal(1, state.Version)
+
+	s.ErrorAs(s.store.CreateJob(ctx, job), &jobstore.ErrJobAlreadyExists{})
+
+	_, err = s.store.GetJob(ctx, uuid.NewString())
+	s.Error(err)
+}
+
+func (s *SQLiteJobStoreSuite) TestUpdateJobStateCondition() {
+	ctx := context.Background()
+	job := s.createJob("client")
+
+	err := s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:     job.ID(),
+		Condition: jobstore.UpdateJobCondition{ExpectedState: model.JobStateQueued},
+		NewState:  model.JobStateInProgress,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobState{})
+
+	err = s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:     job.ID(),
+		Condition: jobstore.UpdateJobCondition{ExpectedVersion: 2},
+		NewState:  model.JobStateInProgress,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobVersion{})
+
+	s.NoError(s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:     job.ID(),
+		Condition: jobstore.UpdateJobCondition{ExpectedVersion: 1},
+		NewState:  model.JobStateQueued,
+	}))
+	s.NoError(s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:     job.ID(),
+		Condition: jobstore.UpdateJobCondition{ExpectedState: model.JobStateQueued},
+		NewState:  model.JobStateCompleted,
+	}))
+
+	err = s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    job.ID(),
+		NewState: model.JobStateInProgress,
+	})
+	s.ErrorAs(err, &jobstore.ErrJobAlreadyTerminal{})
+
+	history, err := s.store.GetJobHistory(ctx, job.ID())
+	s.NoError(err)
+	s.Len(history, 3)
+	s.Equal(model.JobStateQueued, history[2].JobState.Previous)
+	s.Equal(model.JobStateCompleted, history[2].JobState.New)
+}
+
+func (s *SQLiteJobStoreSuite) TestExecutions() {
+	ctx := context.Background()
+	job := s.createJob("client")
+	execution := model.ExecutionState{
+		JobID:  job.ID(),
+		NodeID: "node",
+		State:  model.ExecutionStateAskForBid,
+	}
+	s.NoError(s.store.CreateExecution(ctx, execution))
+	s.ErrorAs(s.store.CreateExecution(ctx, execution), &jobstore.ErrExecutionAlreadyExists{})
+	created, err := s.store.GetJobState(ctx, job.ID())
+	s.Require().NoError(err)
+	createTime := created.Executions[0].CreateTime
+
+	err = s.store.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition:   jobstore.UpdateExecutionCondition{ExpectedState: model.ExecutionStateBidAccepted},
+		NewValues:   model.ExecutionState{State: model.ExecutionStateAskForBidAccepted},
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidExecutionState{})
+
+	s.NoError(s.store.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition:   jobstore.UpdateExecutionCondition{ExpectedState: model.ExecutionStateAskForBid},
+		NewValues: model.ExecutionState{
+			ComputeReference: "e-1",
+			State:            model.ExecutionStateAskForBidAccepted,
+		},
+	}))
+
+	state, err := s.store.GetJobState(ctx, job.ID())
+	s.NoError(err)
+	s.Require().Len(state.Executions, 1)
+	s.Equal("node", state.Executions[0].NodeID)
+	s.Equal("e-1", state.Executions[0].ComputeReference)
+	s.Equal(model.ExecutionStateAskForBidAccepted, state.Executions[0].State)
+	s.Equal(2, state.Executions[0].Version)
+	// updates keep the creation time, and record when the execution was last updated
+	s.True(createTime.Equal(state.Executions[0].CreateTime))
+	s.True(state.Executions[0].UpdateTime.After(createTime))
+}
+
+func (s *SQLiteJobStoreSuite) TestGetJobs() {
+	ctx := context.Background()
+	job1 := s.createJob("client-1", "a")
+	job2 := s.createJob("client-1", "b")
+	s.createJob("client-2", "a")
+
+	jobs, err := s.store.GetJobs(ctx, jobstore.JobQuery{ClientID: "client-1"})
+	s.NoError(err)
+	s.Len(jobs, 2)
+
+	jobs, err = s.store.GetJobs(ctx, jobstore.JobQuery{IncludeTags: []model.IncludedTag{"a"}})
+	s.NoError(err)
+	s.Len(jobs, 2)
+
+	jobs, err = s.store.GetJobs(ctx, jobstore.JobQuery{ClientID: "client-1", ExcludeTags: []model.ExcludedTag{"a"}})
+	s.NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(job2.ID(), jobs[0].ID())
+
+	jobs, err = s.store.GetJobs(ctx, jobstore.JobQuery{ID: job1.ID()})
+	s.NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(job1.ID(), jobs[0].ID())
+
+	count, err := s.store.GetJobsCount(ctx, jobstore.JobQuery{ReturnAll: true, Limit: 1})
+	s.NoError(err)
+	s.Equal(3, count)
+
+	child := model.Job{Metadata: model.Metadata{ID: uuid.NewString(), ClientID: "client-1", ParentID: "parent"}}
+	s.Require().NoError(s.store.CreateJob(ctx, child))
+	jobs, err = s.store.GetJobs(ctx, jobstore.JobQuery{ClientID: "client-1", ParentID: "parent"})
+	s.NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(child.ID(), jobs[0].ID())
+}
+
+func (s *SQLiteJobStoreSuite) TestSurvivesRestart() {
+	ctx := context.Background()
+	inProgress := s.createJob("client")
+	completed := s.createJob("client")
+	s.NoError(s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    inProgress.ID(),
+		NewState: model.JobStateInProgress,
+	}))
+	s.NoError(s.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    completed.ID(),
+		NewState: model.JobStateCompleted,
+	}))
+	s.NoError(s.store.Close())
+
+	var err error
+	s.store, err = NewJobStore(s.filename)
+	s.Require().NoError(err)
+
+	jobs, err := s.store.GetInProgressJobs(ctx)
+	s.NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(inProgress.ID(), jobs[0].Job.ID())
+	s.Equal(model.JobStateInProgress, jobs[0].State.State)
+
+	history, err := s.store.GetJobHistory(ctx, completed.ID())
+	s.NoError(err)
+	s.Len(history, 2)
+}
+
+func (s *SQLiteJobStoreSuite) TestUpdateJob() {
+	ctx := context.Background()
+	job := s.createJob("client", "a")
+
+	job.Spec.Annotations = []string{"b"}
+	job.Spec.Inputs = []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmInput", Path: "/inputs"}}
+	err := s.store.UpdateJob(ctx, jobstore.UpdateJobRequest{
+		Job:       job,
+		Condition: jobstore.UpdateJobCondition{ExpectedVersion: 2},
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobVersion{})
+
+	s.NoError(s.store.UpdateJob(ctx, jobstore.UpdateJobRequest{
+		Job:       job,
+		Condition: jobstore.UpdateJobCondition{ExpectedVersion: 1},
+		Comment:   "inputs updated",
+	}))
+
+	stored, err := s.store.GetJob(ctx, job.ID())
+	s.NoError(err)
+	s.Equal(job.Spec.Inputs, stored.Spec.Inputs)
+
+	jobs, err := s.store.GetJobs(ctx, jobstore.JobQuery{IncludeTags: []model.IncludedTag{"b"}})
+	s.NoError(err)
+	s.Len(jobs, 1)
+
+	state, err := s.store.GetJobState(ctx, job.ID())
+	s.NoError(err)
+	s.Equal(model.JobStateNew, state.State)
+	s.Equal(2, state.Version)
+}
+
+func (s *SQLiteJobStoreSuite) TestDeleteJob() {
+	ctx := context.Background()
+	job := s.createJob("client", "tag")
+	other := s.createJob("client", "tag")
+
+	s.NoError(s.store.DeleteJob(ctx, job.ID()))
+	_, err := s.store.GetJob(ctx, job.ID())
+	s.ErrorAs(err, new(*bacerrors.JobNotFound))
+	_, err = s.store.GetJobHistory(ctx, job.ID())
+	s.ErrorAs(err, &jobstore.ErrJobNotFound{})
+	s.ErrorAs(s.store.DeleteJob(ctx, job.ID()), new(*bacerrors.JobNotFound))
+
+	// other jobs are not affected
+	jobs, err := s.store.GetJobs(ctx, jobstore.JobQuery{IncludeTags: []model.IncludedTag{"tag"}})
+	s.NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(other.ID(), jobs[0].ID())
+}
+
+func (s *SQLiteJobStoreSuite) TestSchedules() {
+	ctx := context.Background()
+	schedule := model.Schedule{
+		ID:            uuid.NewString(),
+		ClientID:      "client-1",
+		Cron:          "@hourly",
+		OverlapPolicy: model.ScheduleOverlapQueue,
+		Spec:          model.Spec{Deal: model.Deal{Concurrency: 1}},
+		CreatedAt:     time.Now(),
+	}
+	s.NoError(s.store.CreateSchedule(ctx, schedule))
+	s.ErrorAs(s.store.CreateSchedule(ctx, schedule), &jobstore.ErrScheduleAlreadyExists{})
+	s.NoError(s.store.CreateSchedule(ctx, model.Schedule{ID: uuid.NewString(), ClientID: "client-2", CreatedAt: time.Now()}))
+
+	stored, err := s.store.GetSchedule(ctx, model.ShortID(schedule.ID))
+	s.NoError(err)
+	s.Equal(schedule.ID, stored.ID)
+	s.Equal(model.ScheduleOverlapQueue, stored.OverlapPolicy)
+	s.Equal(1, stored.Version)
+
+	schedules, err := s.store.GetSchedules(ctx, "client-1")
+	s.NoError(err)
+	s.Len(schedules, 1)
+	schedules, err = s.store.GetSchedules(ctx, "")
+	s.NoError(err)
+	s.Len(schedules, 2)
+
+	stored.Paused = true
+	s.ErrorAs(s.store.UpdateSchedule(ctx, jobstore.UpdateScheduleRequest{Schedule: stored, ExpectedVersion: 2}),
+		&jobstore.ErrInvalidScheduleVersion{})
+	s.NoError(s.store.UpdateSchedule(ctx, jobstore.UpdateScheduleRequest{Schedule: stored, ExpectedVersion: 1}))
+	stored, err = s.store.GetSchedule(ctx, schedule.ID)
+	s.NoError(err)
+	s.True(stored.Paused)
+	s.Equal(2, stored.Version)
+
+	s.NoError(s.store.DeleteSchedule(ctx, schedule.ID))
+	s.ErrorAs(s.store.DeleteSchedule(ctx, schedule.ID), &jobstore.ErrScheduleNotFound{})
+	_, err = s.store.GetSchedule(ctx, schedule.ID)
+	s.ErrorAs(err, &jobstore.ErrScheduleNotFound{})
+}
+
+func (s *SQLiteJobStoreSuite) TestLeases() {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	s.NoError(s.store.RenewLease(ctx, model.RequesterLease{NodeID: "node-1", RenewTime: now, ExpireTime: now.Add(time.Minute)}))
+	s.NoError(s.store.RenewLease(ctx, model.RequesterLease{NodeID: "node-2", RenewTime: now, ExpireTime: now.Add(time.Minute)}))
+
+	// renewing a lease replaces the previous one
+	s.NoError(s.store.RenewLease(ctx, model.RequesterLease{NodeID: "node-1", RenewTime: now, ExpireTime: now.Add(-time.Second)}))
+
+	leases, err := s.store.GetLeases(ctx)
+	s.NoError(err)
+	s.Len(leases, 2)
+	s.Equal("node-1", leases[0].NodeID)
+	s.True(leases[0].IsExpired(now))
+	s.Equal("node-2", leases[1].NodeID)
+	s.False(leases[1].IsExpired(now))
+}
+
+func (s *SQLiteJobStoreSuite) TestUsageRecords() {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	records := []model.UsageRecord{
+		{ClientID: "client-1", JobID: "job-1", ExecutionID: "e-1", Duration: time.Hour, CompletedAt: now.Add(-2 * time.Hour)},
+		{ClientID: "client-1", JobID: "job-2", ExecutionID: "e-2", Duration: time.Minute, CompletedAt: now.Add(-time.Minute),
+			Resources: model.ResourceUsageData{CPU: 1.5, Memory: 1024}},
+		{ClientID: "client-2", JobID: "job-3", ExecutionID: "e-3", Duration: time.Hour, CompletedAt: now},
+	}
+	for _, record := range records {
+		s.NoError(s.store.AddUsageRecord(ctx, record))
+	}
+
+	usage, err := s.store.GetUsageRecords(ctx, "client-1", now.Add(-time.Hour))
+	s.NoError(err)
+	s.Len(usage, 1)
+	s.Equal("e-2", usage[0].ExecutionID)
+	s.Equal(records[1].Resources, usage[0].Resources)
+	s.Equal(time.Minute, usage[0].Duration)
+
+	usage, err = s.store.GetUsageRecords(ctx, "client-1", now.Add(-24*time.Hour))
+	s.NoError(err)
+	s.Len(usage, 2)
+}
+
+func (s *SQLiteJobStoreSuite) TestResultCacheEntries() {
+	ctx := context.Background()
+	_, err := s.store.GetResultCacheEntry(ctx, "hash")
+	s.ErrorAs(err, &jobstore.ErrResultCacheEntryNotFound{})
+
+	s.NoError(s.store.PutResultCacheEntry(ctx, model.ResultCacheEntry{SpecHash: "hash", JobID: "job-1"}))
+	// putting an entry for the same hash replaces the cached job
+	s.NoError(s.store.PutResultCacheEntry(ctx, model.ResultCacheEntry{SpecHash: "hash", JobID: "job-2"}))
+	entry, err := s.store.GetResultCacheEntry(ctx, "hash")
+	s.NoError(err)
+	s.Equal("job-2", entry.JobID)
+
+	s.NoError(s.store.DeleteResultCacheEntry(ctx, "hash"))
+	s.ErrorAs(s.store.DeleteResultCacheEntry(ctx, "hash"), &jobstore.ErrResultCacheEntryNotFound{})
+}
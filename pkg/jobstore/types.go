@@ -2,6 +2,7 @@ package jobstore
 
 import (
 	"context"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 )
@@ -9,6 +10,7 @@ import (
 type JobQuery struct {
 	ID          string              `json:"id"`
 	ClientID    string              `json:"clientID"`
+	ParentID    string              `json:"parentID"`
 	IncludeTags []model.IncludedTag `json:"include_tags"`
 	ExcludeTags []model.ExcludedTag `json:"exclude_tags"`
 	Limit       int                 `json:"limit"`
@@ -28,12 +30,52 @@ type Store interface {
 	GetJobHistory(ctx context.Context, jobID string) ([]model.JobHistory, error)
 	GetJobsCount(ctx context.Context, query JobQuery) (int, error)
 	CreateJob(ctx context.Context, j model.Job) error
+	// UpdateJob replaces the specification of an existing job
+	UpdateJob(ctx context.Context, request UpdateJobRequest) error
 	// UpdateJobState updates the Job state
 	UpdateJobState(ctx context.Context, request UpdateJobStateRequest) error
+	// DeleteJob deletes a job together with its state and history
+	DeleteJob(ctx context.Context, jobID string) error
 	// CreateExecution creates a new execution for a given job
 	CreateExecution(ctx context.Context, execution model.ExecutionState) error
 	// UpdateExecution updates the Job state
 	UpdateExecution(ctx context.Context, request UpdateExecutionRequest) error
+	// CreateSchedule persists a new schedule of recurring jobs
+	CreateSchedule(ctx context.Context, schedule model.Schedule) error
+	// GetSchedule returns the schedule with the given full or short id
+	GetSchedule(ctx context.Context, id string) (model.Schedule, error)
+	// GetSchedules returns the schedules created by the client, or all schedules if the client id is empty
+	GetSchedules(ctx context.Context, clientID string) ([]model.Schedule, error)
+	// UpdateSchedule replaces an existing schedule
+	UpdateSchedule(ctx context.Context, request UpdateScheduleRequest) error
+	// DeleteSchedule deletes an existing schedule
+	DeleteSchedule(ctx context.Context, id string) error
+	// RenewLease creates or replaces the lease of a requester node
+	RenewLease(ctx context.Context, lease model.RequesterLease) error
+	// GetLeases returns the leases of all requester nodes sharing the store
+	GetLeases(ctx context.Context) ([]model.RequesterLease, error)
+	// AddUsageRecord adds the resources consumed by an execution to the ledger of its client
+	AddUsageRecord(ctx context.Context, record model.UsageRecord) error
+	// GetUsageRecords returns the usage recorded for the client since the given time
+	GetUsageRecords(ctx context.Context, clientID string, since time.Time) ([]model.UsageRecord, error)
+	// PutResultCacheEntry creates or replaces the cached job of a spec hash
+	PutResultCacheEntry(ctx context.Context, entry model.ResultCacheEntry) error
+	// GetResultCacheEntry returns the cached job of a spec hash
+	GetResultCacheEntry(ctx context.Context, specHash string) (model.ResultCacheEntry, error)
+	// DeleteResultCacheEntry deletes the cached job of a spec hash
+	DeleteResultCacheEntry(ctx context.Context, specHash string) error
+}
+
+type UpdateJobRequest struct {
+	Job       model.Job
+	Condition UpdateJobCondition
+	Comment   string
+}
+
+type UpdateScheduleRequest struct {
+	Schedule model.Schedule
+	// ExpectedVersion of the stored schedule. The version is not checked if zero.
+	ExpectedVersion int
 }
 
 type UpdateJobStateRequest struct {
@@ -0,0 +1,59 @@
+package model
+
+import "fmt"
+
+// AdmissionAction is what the requester node does with a submitted job that matches an admission rule.
+type AdmissionAction string
+
+const (
+	// AdmissionActionAccept lets the job be scheduled.
+	AdmissionActionAccept AdmissionAction = "accept"
+	// AdmissionActionReject fails the job with the reason of the rule.
+	AdmissionActionReject AdmissionAction = "reject"
+	// AdmissionActionRequireApproval parks the job until it is approved or rejected with `bacalhau approve`.
+	AdmissionActionRequireApproval AdmissionAction = "require-approval"
+)
+
+func ParseAdmissionAction(s string) (AdmissionAction, error) {
+	for _, action := range []AdmissionAction{
+		AdmissionActionAccept, AdmissionActionReject, AdmissionActionRequireApproval,
+	} {
+		if equal(string(action), s) {
+			return action, nil
+		}
+	}
+	return "", fmt.Errorf("%T: unknown type '%s'", AdmissionActionAccept, s)
+}
+
+func (a *AdmissionAction) UnmarshalText(text []byte) (err error) {
+	*a, err = ParseAdmissionAction(string(text))
+	return
+}
+
+// AdmissionRule matches submitted jobs and decides what to do with them. A job matches the rule if it matches
+// all the conditions that are set, and a rule without any condition matches every job.
+type AdmissionRule struct {
+	// Name identifies the rule in the reason given to rejected or parked jobs.
+	Name string `json:"Name"`
+	// Action is what to do with the jobs matching the rule.
+	Action AdmissionAction `json:"Action"`
+	// Reason is given to the jobs matching the rule, in addition to the name of the rule.
+	Reason string `json:"Reason,omitempty"`
+	// ClientIDs matches the jobs submitted by any of these clients.
+	ClientIDs []string `json:"ClientIDs,omitempty"`
+	// Images matches the docker jobs whose image matches any of these glob patterns, e.g. `ubuntu:*`.
+	Images []string `json:"Images,omitempty"`
+	// Networks matches the jobs requiring any of these network types.
+	Networks []Network `json:"Networks,omitempty"`
+	// ResourcesAbove matches the jobs requesting more than any of the resources that are set.
+	ResourcesAbove ResourceUsageConfig `json:"ResourcesAbove,omitempty"`
+}
+
+// AdmissionPolicy decides which submitted jobs are scheduled by the requester node, which are rejected,
+// and which are parked until they are approved. The first rule matching a job decides what to do with it,
+// and jobs matching no rule are given the default action.
+type AdmissionPolicy struct {
+	Rules []AdmissionRule `json:"Rules,omitempty"`
+	// DefaultAction is the action for jobs matching no rule. Jobs are accepted if it is not set.
+	DefaultAction AdmissionAction `json:"DefaultAction,omitempty"`
+}
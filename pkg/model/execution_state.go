@@ -34,20 +34,22 @@ const (
 	ExecutionStateFailed
 	// ExecutionStateCanceled The execution has been canceled by the user
 	ExecutionStateCanceled
+	// ExecutionStateLost The compute node running the execution stopped publishing its node info.
+	ExecutionStateLost
 )
 
 func ExecutionStateTypes() []ExecutionStateType {
 	var res []ExecutionStateType
-	for typ := ExecutionStateNew; typ <= ExecutionStateCanceled; typ++ {
+	for typ := ExecutionStateNew; typ <= ExecutionStateLost; typ++ {
 		res = append(res, typ)
 	}
 	return res
 }
 
-// IsDiscarded returns true if the execution has been discarded due to a failure, rejection or cancellation
+// IsDiscarded returns true if the execution has been discarded due to a failure, rejection, cancellation or loss of its node
 func (s ExecutionStateType) IsDiscarded() bool {
 	return s == ExecutionStateAskForBidRejected || s == ExecutionStateBidRejected || s == ExecutionStateResultRejected ||
-		s == ExecutionStateCanceled || s == ExecutionStateFailed
+		s == ExecutionStateCanceled || s == ExecutionStateFailed || s == ExecutionStateLost
 }
 
 // IsActive returns true if the execution is running or has completed
@@ -67,7 +69,7 @@ func (s ExecutionStateType) MarshalText() ([]byte, error) {
 
 func (s *ExecutionStateType) UnmarshalText(text []byte) (err error) {
 	name := string(text)
-	for typ := ExecutionStateNew; typ <= ExecutionStateCanceled; typ++ {
+	for typ := ExecutionStateNew; typ <= ExecutionStateLost; typ++ {
 		if equal(typ.String(), name) {
 			*s = typ
 			return
@@ -107,6 +109,17 @@ type ExecutionState struct {
 
 	// RunOutput of the job
 	RunOutput *RunCommandResult `json:"RunOutput,omitempty"`
+	// ResourceUsage is the actual resource usage of the execution while it was running, if it was sampled
+	ResourceUsage *ResourceUsageSummary `json:"ResourceUsage,omitempty"`
+	// ShardIndex is the shard of the job this execution is processing.
+	ShardIndex int `json:"ShardIndex,omitempty"`
+	// Attempt is the scheduling attempt that created this execution. Zero for executions
+	// created when the job was first scheduled, and incremented every time the job is retried.
+	Attempt int `json:"Attempt,omitempty"`
+	// Speculative is true if this execution is a backup of a slow execution of the same shard.
+	Speculative bool `json:"Speculative,omitempty"`
+	// Price is the price quoted by the compute node in its bid to run this execution.
+	Price float64 `json:"Price,omitempty"`
 	// Version is the version of the job state. It is incremented every time the job state is updated.
 	Version int `json:"Version"`
 	// CreateTime is the time when the job was created.
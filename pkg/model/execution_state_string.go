@@ -20,11 +20,12 @@ func _() {
 	_ = x[ExecutionStateCompleted-9]
 	_ = x[ExecutionStateFailed-10]
 	_ = x[ExecutionStateCanceled-11]
+	_ = x[ExecutionStateLost-12]
 }
 
-const _ExecutionStateType_name = "NewAskForBidAskForBidAcceptedAskForBidRejectedBidAcceptedBidRejectedWaitingVerificationResultAcceptedResultRejectedCompletedFailedCancelled"
+const _ExecutionStateType_name = "NewAskForBidAskForBidAcceptedAskForBidRejectedBidAcceptedBidRejectedWaitingVerificationResultAcceptedResultRejectedCompletedFailedCancelledLost"
 
-var _ExecutionStateType_index = [...]uint8{0, 3, 12, 29, 46, 57, 68, 87, 101, 115, 124, 130, 139}
+var _ExecutionStateType_index = [...]uint8{0, 3, 12, 29, 46, 57, 68, 87, 101, 115, 124, 130, 139, 143}
 
 func (i ExecutionStateType) String() string {
 	if i < 0 || i >= ExecutionStateType(len(_ExecutionStateType_index)-1) {
@@ -27,6 +27,18 @@ func (j Job) String() string {
 	return j.Metadata.ID
 }
 
+// Shard returns the job as seen by the executions of the given shard, where the inputs
+// are limited to the shard's inputs. Jobs without shards are returned as is.
+func (j Job) Shard(index int) Job {
+	if index >= len(j.Spec.ExecutionPlan.Shards) {
+		return j
+	}
+	shardJob := j
+	shardJob.Spec.Inputs = j.Spec.ExecutionPlan.Shards[index].Inputs
+	shardJob.Spec.ExecutionPlan = JobExecutionPlan{}
+	return shardJob
+}
+
 type Metadata struct {
 	// The unique global ID of this job in the bacalhau network.
 	ID string `json:"ID,omitempty" example:"92d5d4ee-3765-4f78-8353-623f5f26df08"`
@@ -38,6 +50,9 @@ type Metadata struct {
 	ClientID string `json:"ClientID,omitempty" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
 
 	Requester JobRequester `json:"Requester,omitempty"`
+
+	// The ID shared by the jobs expanded from the same job template, if the job was submitted from a template.
+	ParentID string `json:"ParentID,omitempty" example:"b5c6a8e4-7d31-4b5f-a0a8-9e7f2c1d3e4f"`
 }
 type JobRequester struct {
 	// The ID of the requester node that owns this job.
@@ -103,6 +118,141 @@ type Deal struct {
 	// jobs will be spread evenly across the network (assuming that this value
 	// is some large proportion of the size of the network).
 	MinBids int `json:"MinBids,omitempty"`
+	// The policy the requester node follows to reschedule the job on other
+	// compute nodes when some of its executions fail or are rejected.
+	RetryPolicy RetryPolicy `json:"RetryPolicy,omitempty"`
+	// The policy the requester node follows to launch backup executions on
+	// other compute nodes when an execution is running slower than usual.
+	Speculation SpeculationPolicy `json:"Speculation,omitempty"`
+	// The maximum price the client is willing to pay for each execution of the
+	// job. Bids quoting a higher price are rejected, and the cheapest bids are
+	// accepted once MinBids bids were received. Zero means there is no price limit.
+	MaxPrice float64 `json:"MaxPrice,omitempty"`
+	// Gang requires the Concurrency executions of each shard to start together.
+	// The requester node holds the bids it receives until it can accept enough
+	// of them at once, and releases the other members if any of them drops out
+	// before it finished running. Each member is given the host:port address it
+	// listens on, and the addresses of the others, through environment variables.
+	Gang bool `json:"Gang,omitempty"`
+}
+
+// RetryPolicy describes how the requester node should recover from failed executions
+// by asking fresh compute nodes to bid on the job.
+type RetryPolicy struct {
+	// The maximum number of times the requester node will try to reschedule the job
+	// after it can no longer meet the deal's concurrency. Zero disables retries.
+	MaxAttempts int `json:"MaxAttempts,omitempty"`
+	// How long in seconds to wait before asking fresh nodes to bid on the job.
+	Backoff float64 `json:"Backoff,omitempty"`
+	// Do not ask nodes that already had an execution for this job to bid again.
+	ExcludePreviousNodes bool `json:"ExcludePreviousNodes,omitempty"`
+}
+
+// Return backoff duration
+func (r RetryPolicy) GetBackoff() time.Duration {
+	return time.Duration(r.Backoff * float64(time.Second))
+}
+
+// JobTimeouts are the maximum durations in seconds of each phase of an execution. Zero means the phase is not
+// timed out by the requester node.
+type JobTimeouts struct {
+	// How long a node can take to respond to a bid request, and how long its bid can wait to be accepted.
+	Bid float64 `json:"Bid,omitempty"`
+	// How long a node can take to run the job once its bid was accepted.
+	Execution float64 `json:"Execution,omitempty"`
+	// How long the proposed results of an execution can wait to be verified.
+	Verification float64 `json:"Verification,omitempty"`
+	// How long a node can take to publish the results once they were verified.
+	Publish float64 `json:"Publish,omitempty"`
+}
+
+// IsSet returns true if any phase has a timeout
+func (t JobTimeouts) IsSet() bool {
+	return t.Bid > 0 || t.Execution > 0 || t.Verification > 0 || t.Publish > 0
+}
+
+// GetPhaseTimeout returns the timeout of the phase an execution in the given state is in,
+// or zero if the state has no timeout.
+func (t JobTimeouts) GetPhaseTimeout(state ExecutionStateType) time.Duration {
+	var timeout float64
+	switch state {
+	case ExecutionStateAskForBid, ExecutionStateAskForBidAccepted:
+		timeout = t.Bid
+	case ExecutionStateBidAccepted:
+		timeout = t.Execution
+	case ExecutionStateResultProposed:
+		timeout = t.Verification
+	case ExecutionStateResultAccepted:
+		timeout = t.Publish
+	}
+	return time.Duration(timeout * float64(time.Second))
+}
+
+// SpeculationPolicy describes when the requester node should ask another compute node to run a backup
+// execution of a job whose execution is running for longer than most recently completed executions of the same job,
+// i.e. its other shards and previous runs of the same spec.
+// The results of whichever execution completes first are used, and the other execution is canceled.
+type SpeculationPolicy struct {
+	// The percentile of the run durations of recently completed executions of the same job after which a backup
+	// execution is launched (e.g. 90). Zero disables speculative execution.
+	Percentile float64 `json:"Percentile,omitempty"`
+	// The minimum time in seconds an execution must run before a backup execution is launched, which is also
+	// the threshold used until enough executions of the same job have completed to compute the percentile.
+	MinDuration float64 `json:"MinDuration,omitempty"`
+}
+
+// IsEnabled returns true if backup executions should be launched for slow executions
+func (p SpeculationPolicy) IsEnabled() bool {
+	return p.Percentile > 0
+}
+
+// Return min duration
+func (p SpeculationPolicy) GetMinDuration() time.Duration {
+	return time.Duration(p.MinDuration * float64(time.Second))
+}
+
+// describe how we chunk a job up into shards
+type JobShardingConfig struct {
+	// divide the inputs up into the smallest possible unit
+	// for example /* would mean "all top level files or folders"
+	// this being an empty string means "no sharding"
+	GlobPattern string `json:"GlobPattern,omitempty"`
+	// how many "items" are to be processed in each shard
+	// we first apply the glob pattern which will result in a flat list of items
+	// this number decides how to group that flat list into actual shards run by compute nodes
+	BatchSize int `json:"BatchSize,omitempty"`
+	// when using multiple input volumes
+	// what path do we treat as the common mount path to apply the glob pattern to
+	BasePath string `json:"GlobPatternBasePath,omitempty"`
+}
+
+// IsEnabled returns true if the inputs of the job should be split into shards
+func (c JobShardingConfig) IsEnabled() bool {
+	return c.GlobPattern != "" || c.BatchSize > 0
+}
+
+// JobExecutionPlan describes how the requester node split the job into shards
+type JobExecutionPlan struct {
+	// the shards of the job. Jobs without sharding have no shards here,
+	// and are executed as a single implicit shard with all the job's inputs
+	Shards []JobShard `json:"Shards,omitempty"`
+}
+
+// TotalShards returns how many shards are there in total for this job.
+// We are expecting this number x concurrency executions to complete for the job to complete.
+func (p JobExecutionPlan) TotalShards() int {
+	if len(p.Shards) == 0 {
+		return 1
+	}
+	return len(p.Shards)
+}
+
+// JobShard is a subset of the job's inputs processed by its own executions
+type JobShard struct {
+	// Index of the shard within the job's execution plan
+	Index int `json:"Index"`
+	// Inputs are the storage specs processed by this shard
+	Inputs []StorageSpec `json:"Inputs,omitempty"`
 }
 
 // LabelSelectorRequirement A selector that contains values, a key, and an operator that relates the key and values.
@@ -147,6 +297,11 @@ type Spec struct {
 	// This includes the time required to run, verify and publish results
 	Timeout float64 `json:"Timeout,omitempty"`
 
+	// Timeouts of each phase of the job's executions. When any is set, executions that exceed the timeout
+	// of their phase are replaced by executions on other nodes, while Timeout still limits the whole
+	// lifetime of the job.
+	Timeouts JobTimeouts `json:"Timeouts,omitempty"`
+
 	// the data volumes we will read in the job
 	// for example "read this ipfs cid"
 	// TODO: #667 Replace with "Inputs", "Outputs" (note the caps) for yaml/json when we update the n.js file
@@ -165,8 +320,39 @@ type Spec struct {
 	// Do not track specified by the client
 	DoNotTrack bool `json:"DoNotTrack,omitempty"`
 
+	// DoNotCache always runs the job, instead of serving the results of an identical deterministic job
+	// that already completed.
+	DoNotCache bool `json:"DoNotCache,omitempty"`
+
+	// Reservation is the ID of the capacity reservation the job runs on. Capacity held back by a
+	// reservation is only released to the jobs carrying its ID, and only during its window.
+	Reservation string `json:"Reservation,omitempty"`
+
 	// The deal the client has made, such as which job bids they have accepted.
 	Deal Deal `json:"Deal,omitempty"`
+
+	// Priority of the job when waiting in the requester queue for enough capacity to run, and in the queue of
+	// compute nodes, where executions of lower priority jobs are preempted to make room for it.
+	Priority PriorityClass `json:"Priority,omitempty"`
+
+	// Sharding describes how to split the inputs of the job across multiple executions.
+	Sharding JobShardingConfig `json:"Sharding,omitempty"`
+
+	// ExecutionPlan is populated by the requester node with the shards generated from the sharding config.
+	ExecutionPlan JobExecutionPlan `json:"ExecutionPlan,omitempty"`
+
+	// Dependencies are jobs whose published results are inputs of this job.
+	// The job is only scheduled once all of its dependencies have completed.
+	// Dependencies must have been submitted by the same client as the job.
+	Dependencies []JobDependency `json:"Dependencies,omitempty"`
+}
+
+// JobDependency describes a job whose published result is used as an input of another job
+type JobDependency struct {
+	// JobID is the id of the job the result is taken from
+	JobID string `json:"JobID,omitempty"`
+	// Path is where the published result is mounted in the dependent job
+	Path string `json:"Path,omitempty"`
 }
 
 // Return timeout duration
@@ -268,6 +454,8 @@ type JobEvent struct {
 	VerificationProposal []byte             `json:"VerificationProposal,omitempty"`
 	VerificationResult   VerificationResult `json:"VerificationResult,omitempty"`
 	PublishedResult      StorageSpec        `json:"PublishedResult,omitempty"`
+	// this is only defined in "bid" events
+	Price float64 `json:"Price,omitempty"`
 
 	EventTime       time.Time `json:"EventTime,omitempty" example:"2022-11-17T13:32:55.756658941Z"`
 	SenderPublicKey PublicKey `json:"SenderPublicKey,omitempty"`
@@ -313,3 +501,27 @@ type JobCancelPayload struct {
 func (j JobCancelPayload) GetClientID() string {
 	return j.ClientID
 }
+
+// JobUpdatePayload changes the deal or timeout of a job that is still in progress.
+// Only the fields that are set are updated.
+type JobUpdatePayload struct {
+	// the id of the client that submitted the job
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the job id of the job to be updated
+	JobID string `json:"JobID,omitempty" validate:"required"`
+
+	// The new number of nodes that should run the job
+	Concurrency *int `json:"Concurrency,omitempty"`
+
+	// The new number of bids to receive before accepting any of them
+	MinBids *int `json:"MinBids,omitempty"`
+
+	// The new timeout of the job in seconds.
+	// The timeout can't be updated once executions of the job were created, as they keep the timeout they were created with.
+	Timeout *float64 `json:"Timeout,omitempty"`
+}
+
+func (j JobUpdatePayload) GetClientID() string {
+	return j.ClientID
+}
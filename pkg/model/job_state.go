@@ -27,6 +27,9 @@ const (
 
 	// Job is waiting to be scheduled.
 	JobStateQueued
+
+	// Job is waiting to be approved by the requester node's admission policy.
+	JobStatePendingApproval
 )
 
 // IsTerminal returns true if the given job type signals the end of the lifecycle of
@@ -39,9 +42,17 @@ func (s JobStateType) MarshalText() ([]byte, error) {
 	return []byte(s.String()), nil
 }
 
+func JobStateTypes() []JobStateType {
+	var res []JobStateType
+	for typ := JobStateNew; typ <= JobStatePendingApproval; typ++ {
+		res = append(res, typ)
+	}
+	return res
+}
+
 func (s *JobStateType) UnmarshalText(text []byte) (err error) {
 	name := string(text)
-	for typ := JobStateNew; typ <= JobStateCompleted; typ++ {
+	for typ := JobStateNew; typ <= JobStatePendingApproval; typ++ {
 		if equal(typ.String(), name) {
 			*s = typ
 			return
@@ -65,6 +76,9 @@ type JobState struct {
 	Version int `json:"Version"`
 	// CreateTime is the time when the job was created.
 	CreateTime time.Time `json:"CreateTime"`
+	// ScheduleTime is the time when the job left the new state, which is after its dependencies completed
+	// for jobs that depend on other jobs.
+	ScheduleTime time.Time `json:"ScheduleTime,omitempty"`
 	// UpdateTime is the time when the job state was last updated.
 	UpdateTime time.Time `json:"UpdateTime"`
 	// TimeoutAt is the time when the job will be timed out if it is not completed.
@@ -80,3 +94,14 @@ func (j JobState) ExecutionsInTerminalState() bool {
 
 	return true
 }
+
+// LatestAttempt returns the most recent scheduling attempt across the job's executions.
+func (j JobState) LatestAttempt() int {
+	attempt := 0
+	for _, execution := range j.Executions {
+		if execution.Attempt > attempt {
+			attempt = execution.Attempt
+		}
+	}
+	return attempt
+}
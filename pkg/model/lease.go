@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RequesterLease is renewed by a requester node while it is alive to keep ownership of its jobs and schedules.
+// When the job store is shared between multiple requester nodes, the jobs and schedules of a requester node
+// whose lease expired are adopted by another requester node.
+type RequesterLease struct {
+	// NodeID is the id of the requester node holding the lease
+	NodeID string `json:"NodeID"`
+	// RenewTime is the last time the requester node renewed its lease
+	RenewTime time.Time `json:"RenewTime"`
+	// ExpireTime is the time after which the jobs of the requester node can be adopted by other requester nodes
+	ExpireTime time.Time `json:"ExpireTime"`
+}
+
+// IsExpired returns true if the requester node didn't renew its lease in time
+func (l RequesterLease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpireTime)
+}
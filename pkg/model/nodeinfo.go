@@ -3,6 +3,7 @@ package model
 import (
 	"context"
 
+	"github.com/bacalhau-project/bacalhau/pkg/util/bloom"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
@@ -41,4 +42,15 @@ type ComputeNodeInfo struct {
 	MaxJobRequirements ResourceUsageData `json:"MaxJobRequirements"`
 	RunningExecutions  int               `json:"RunningExecutions"`
 	EnqueuedExecutions int               `json:"EnqueuedExecutions"`
+	// LocalStorage is a bloom filter of the locality keys of the storage held locally by the node,
+	// which is used to schedule jobs on nodes that already hold their inputs.
+	LocalStorage *bloom.Filter `json:"LocalStorage,omitempty"`
+	// ReservationKeys are the keys of the capacity reservations held by the node that have not expired yet,
+	// which don't reveal the reservation tokens. See ReservationKey.
+	ReservationKeys []string `json:"ReservationKeys,omitempty"`
+	// ReservedCapacity is the capacity held back by the reservations of the node that are active
+	ReservedCapacity ResourceUsageData `json:"ReservedCapacity"`
+	// SampledUsage is the resource usage of the running executions that was last measured by their executors,
+	// as opposed to the capacity they requested
+	SampledUsage ResourceUsageData `json:"SampledUsage,omitempty"`
 }
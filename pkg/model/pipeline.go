@@ -0,0 +1,40 @@
+package model
+
+// Pipeline is a directed acyclic graph of jobs, where the published results of a stage
+// are wired into the inputs of the stages that depend on it.
+type Pipeline struct {
+	Stages []PipelineStage `json:"Stages,omitempty"`
+}
+
+// PipelineStage is a single job within a pipeline
+type PipelineStage struct {
+	// Name uniquely identifies the stage within the pipeline
+	Name string `json:"Name,omitempty"`
+	// Spec is the specification of the stage's job
+	Spec Spec `json:"Spec,omitempty"`
+	// Dependencies are the stages whose published results are inputs of this stage
+	Dependencies []PipelineDependency `json:"Dependencies,omitempty"`
+}
+
+// PipelineDependency describes a stage whose published result is used as an input of another stage
+type PipelineDependency struct {
+	// Stage is the name of the stage the result is taken from
+	Stage string `json:"Stage,omitempty"`
+	// Path is where the published result is mounted in the dependent stage
+	Path string `json:"Path,omitempty"`
+}
+
+// PipelineCreatePayload is the data needed to submit a pipeline to the requester node
+type PipelineCreatePayload struct {
+	// the id of the client that is submitting the pipeline
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// The specification of this pipeline.
+	Pipeline *Pipeline `json:"Pipeline,omitempty" validate:"required"`
+}
+
+func (p PipelineCreatePayload) GetClientID() string {
+	return p.ClientID
+}
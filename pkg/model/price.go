@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const bytesPerGB = 1 << 30
+
+// RateCard is the price a compute node charges to run an execution, based on the resources the execution
+// requires and for how long it is expected to run.
+type RateCard struct {
+	// Base is the price charged for every execution, regardless of its resources and duration
+	Base float64 `json:"Base,omitempty"`
+	// CPU is the price per CPU core per hour
+	CPU float64 `json:"CPU,omitempty"`
+	// Memory is the price per GB of memory per hour
+	Memory float64 `json:"Memory,omitempty"`
+	// Disk is the price per GB of disk per hour
+	Disk float64 `json:"Disk,omitempty"`
+	// GPU is the price per GPU per hour
+	GPU float64 `json:"GPU,omitempty"`
+}
+
+// IsZero returns true if the rate card doesn't charge anything
+func (r RateCard) IsZero() bool {
+	return r == RateCard{}
+}
+
+// Quote returns the price of an execution that requires the given resources for the given duration
+func (r RateCard) Quote(usage ResourceUsageData, duration time.Duration) float64 {
+	hourlyPrice := usage.CPU*r.CPU +
+		float64(usage.Memory)/bytesPerGB*r.Memory +
+		float64(usage.Disk)/bytesPerGB*r.Disk +
+		float64(usage.GPU)*r.GPU
+	return r.Base + hourlyPrice*duration.Hours()
+}
@@ -0,0 +1,22 @@
+//go:build unit || !integration
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateCardQuote(t *testing.T) {
+	rateCard := RateCard{Base: 1, CPU: 2, Memory: 0.5, Disk: 0.1, GPU: 10}
+	usage := ResourceUsageData{CPU: 1.5, Memory: 4 * bytesPerGB, Disk: 10 * bytesPerGB, GPU: 1}
+
+	require.InDelta(t, 1.0, rateCard.Quote(usage, 0), 0.0001)
+	require.InDelta(t, 1+(3+2+1+10)*0.5, rateCard.Quote(usage, 30*time.Minute), 0.0001)
+	require.InDelta(t, 1.0, rateCard.Quote(ResourceUsageData{}, time.Hour), 0.0001)
+	require.Zero(t, RateCard{}.Quote(usage, time.Hour))
+	require.True(t, RateCard{}.IsZero())
+	require.False(t, rateCard.IsZero())
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate stringer -type=PriorityClass --trimprefix=PriorityClass
+type PriorityClass int
+
+const (
+	// PriorityClassLow jobs are only started when no job of a higher priority is waiting to run.
+	PriorityClassLow PriorityClass = iota - 1
+
+	// PriorityClassNormal is the default priority of jobs.
+	PriorityClassNormal
+
+	// PriorityClassHigh jobs are started before jobs of any other priority.
+	PriorityClassHigh
+)
+
+func IsValidPriorityClass(p PriorityClass) bool {
+	return p >= PriorityClassLow && p <= PriorityClassHigh
+}
+
+func ParsePriorityClass(str string) (PriorityClass, error) {
+	for typ := PriorityClassLow; typ <= PriorityClassHigh; typ++ {
+		if strings.EqualFold(typ.String(), str) {
+			return typ, nil
+		}
+	}
+
+	return PriorityClassNormal, fmt.Errorf("%T: unknown priority class '%s'", PriorityClassNormal, str)
+}
+
+func PriorityClassNames() []string {
+	var names []string
+	for typ := PriorityClassLow; typ <= PriorityClassHigh; typ++ {
+		names = append(names, typ.String())
+	}
+	return names
+}
+
+func (p PriorityClass) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *PriorityClass) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	*p, err = ParsePriorityClass(name)
+	return
+}
@@ -0,0 +1,26 @@
+// Code generated by "stringer -type=PriorityClass --trimprefix=PriorityClass"; DO NOT EDIT.
+
+package model
+
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PriorityClassLow - -1]
+	_ = x[PriorityClassNormal-0]
+	_ = x[PriorityClassHigh-1]
+}
+
+const _PriorityClass_name = "LowNormalHigh"
+
+var _PriorityClass_index = [...]uint8{0, 3, 9, 13}
+
+func (i PriorityClass) String() string {
+	i -= -1
+	if i < 0 || i >= PriorityClass(len(_PriorityClass_index)-1) {
+		return "PriorityClass(" + strconv.FormatInt(int64(i+-1), 10) + ")"
+	}
+	return _PriorityClass_name[_PriorityClass_index[i]:_PriorityClass_index[i+1]]
+}
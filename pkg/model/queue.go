@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// QueuedJob is a job approved to run that is waiting in the requester queue for enough capacity.
+type QueuedJob struct {
+	JobID    string        `json:"JobID"`
+	ClientID string        `json:"ClientID"`
+	Priority PriorityClass `json:"Priority"`
+	QueuedAt time.Time     `json:"QueuedAt"`
+	// Position of the job in the queue, starting at 1 for the next job to start.
+	Position int `json:"Position"`
+}
+
+// QueueInfo describes the state of the requester queue.
+type QueueInfo struct {
+	// Jobs waiting for capacity, in the order they will be started.
+	Jobs []QueuedJob `json:"Jobs"`
+	// RunningJobs is the number of jobs in progress per client, which the queue shares capacity by.
+	RunningJobs map[string]int `json:"RunningJobs"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Reservation holds back capacity on a compute node for a time window. The held back capacity is only released to
+// jobs carrying the reservation's ID as their reservation token, and the reservation expires at the end of the window.
+type Reservation struct {
+	// ID of the reservation, which is also the token that jobs carry to run on the reserved capacity.
+	ID string `json:"ID"`
+	// ClientID of the client that made the reservation.
+	ClientID  string            `json:"ClientID"`
+	Resources ResourceUsageData `json:"Resources"`
+	StartTime time.Time         `json:"StartTime"`
+	EndTime   time.Time         `json:"EndTime"`
+}
+
+// IsActive returns true if the reservation's window includes the given time
+func (r Reservation) IsActive(now time.Time) bool {
+	return !now.Before(r.StartTime) && now.Before(r.EndTime)
+}
+
+// IsExpired returns true if the reservation's window ended before the given time
+func (r Reservation) IsExpired(now time.Time) bool {
+	return !now.Before(r.EndTime)
+}
+
+// Overlaps returns true if the windows of both reservations overlap
+func (r Reservation) Overlaps(other Reservation) bool {
+	return r.StartTime.Before(other.EndTime) && other.StartTime.Before(r.EndTime)
+}
+
+type ReservationPayload struct {
+	// the id of the client that is reserving capacity
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the labels of the compute nodes on which capacity is reserved
+	NodeSelectors []LabelSelectorRequirement `json:"NodeSelectors,omitempty"`
+
+	// the capacity to reserve on each of the selected nodes
+	Resources ResourceUsageConfig `json:"Resources,omitempty"`
+
+	// the window during which the capacity is reserved
+	StartTime time.Time `json:"StartTime,omitempty" validate:"required"`
+	EndTime   time.Time `json:"EndTime,omitempty" validate:"required"`
+}
+
+func (e ReservationPayload) GetClientID() string {
+	return e.ClientID
+}
+
+// ReservationKey returns the key that identifies a reservation in the node info published by compute nodes. It is a
+// hash of the reservation's ID, so that the token of a reservation is not published to the network.
+func ReservationKey(reservationID string) string {
+	hash := sha256.Sum256([]byte(reservationID))
+	return hex.EncodeToString(hash[:])
+}
+
+// ReservationLimits limits the capacity each client can hold back with reservations.
+type ReservationLimits struct {
+	// MaxActiveReservations is the number of reservations of a client that have not expired yet.
+	MaxActiveReservations int `json:"MaxActiveReservations,omitempty"`
+	// MaxNodes is the number of compute nodes a single reservation can hold back capacity on.
+	MaxNodes int `json:"MaxNodes,omitempty"`
+	// MaxDuration is the length of the window of a reservation.
+	MaxDuration time.Duration `json:"MaxDuration,omitempty"`
+	// MaxResources is the capacity a reservation can hold back on each node.
+	MaxResources ResourceUsageData `json:"MaxResources,omitempty"`
+}
+
+type CancelReservationPayload struct {
+	// the id of the client that made the reservation
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the reservation to cancel
+	ReservationID string `json:"ReservationID,omitempty" validate:"required"`
+}
+
+func (e CancelReservationPayload) GetClientID() string {
+	return e.ClientID
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -136,3 +137,18 @@ type ResourceUsageProfile struct {
 	// what is the total amount of resources available to the system
 	SystemTotal ResourceUsageData `json:"SystemTotal,omitempty"`
 }
+
+// ResourceUsageSample is the resource usage of a running execution, as measured by its executor at a point in time
+type ResourceUsageSample struct {
+	Usage ResourceUsageData `json:"Usage"`
+	Time  time.Time         `json:"Time"`
+}
+
+// ResourceUsageSummary compares the resources an execution actually used while it was running with the resources
+// it requested. The peak usage is the maximum of each resource over all the samples taken during the execution.
+type ResourceUsageSummary struct {
+	Requested ResourceUsageData `json:"Requested"`
+	Peak      ResourceUsageData `json:"Peak"`
+	// Samples is the number of samples the peak usage was computed from
+	Samples int `json:"Samples"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ResultCacheEntry points to a completed job whose verified results are served to identical deterministic jobs,
+// instead of running them again.
+type ResultCacheEntry struct {
+	// SpecHash is the hash of the normalized spec of the job.
+	SpecHash string `json:"SpecHash"`
+	// JobID of the completed job whose results are served.
+	JobID     string    `json:"JobID"`
+	CreatedAt time.Time `json:"CreatedAt"`
+}
+
+type ResultCacheEvictPayload struct {
+	// the id of the client that is evicting the cached results
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of a job whose results should no longer be served to identical jobs
+	JobID string `json:"JobID,omitempty" validate:"required"`
+}
+
+func (e ResultCacheEvictPayload) GetClientID() string {
+	return e.ClientID
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// RetentionPolicy decides how long the requester node keeps jobs that reached a terminal state in its job store,
+// before they are exported to the job archive and purged. Jobs that are still in progress are never purged.
+type RetentionPolicy struct {
+	// MaxAge is how long terminal jobs are kept after their state last changed. Jobs are not purged by age if zero.
+	MaxAge time.Duration `json:"MaxAge,omitempty"`
+	// MaxCount is the number of terminal jobs kept in the store, the oldest being purged first. Unlimited if zero.
+	MaxCount int `json:"MaxCount,omitempty"`
+	// StateMaxAge overrides MaxAge for the jobs in the given terminal states.
+	StateMaxAge map[JobStateType]time.Duration `json:"StateMaxAge,omitempty"`
+}
+
+// IsEnabled returns true if the policy purges any job.
+func (p RetentionPolicy) IsEnabled() bool {
+	return p.MaxAge > 0 || p.MaxCount > 0 || len(p.StateMaxAge) > 0
+}
+
+// IsExpired returns true if the job in the given state is older than the max age of its state.
+func (p RetentionPolicy) IsExpired(state JobState, now time.Time) bool {
+	if !state.State.IsTerminal() {
+		return false
+	}
+	maxAge, ok := p.StateMaxAge[state.State]
+	if !ok {
+		maxAge = p.MaxAge
+	}
+	return maxAge > 0 && now.Sub(state.UpdateTime) > maxAge
+}
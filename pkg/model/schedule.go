@@ -0,0 +1,133 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:generate stringer -type=ScheduleOverlapPolicy --trimprefix=ScheduleOverlap
+type ScheduleOverlapPolicy int
+
+const (
+	// ScheduleOverlapSkip skips the runs that are due while the job of the previous run is still in progress.
+	ScheduleOverlapSkip ScheduleOverlapPolicy = iota
+
+	// ScheduleOverlapQueue delays the runs that are due while the job of the previous run is still in progress
+	// until it completes.
+	ScheduleOverlapQueue
+
+	// ScheduleOverlapCancelPrevious cancels the job of the previous run if it is still in progress when the next
+	// run is due.
+	ScheduleOverlapCancelPrevious
+)
+
+func IsValidScheduleOverlapPolicy(p ScheduleOverlapPolicy) bool {
+	return p >= ScheduleOverlapSkip && p <= ScheduleOverlapCancelPrevious
+}
+
+// ParseScheduleOverlapPolicy parses the name of a policy, ignoring case and dashes so that both
+// "CancelPrevious" and "cancel-previous" are accepted.
+func ParseScheduleOverlapPolicy(str string) (ScheduleOverlapPolicy, error) {
+	for typ := ScheduleOverlapSkip; typ <= ScheduleOverlapCancelPrevious; typ++ {
+		if strings.EqualFold(typ.String(), strings.ReplaceAll(str, "-", "")) {
+			return typ, nil
+		}
+	}
+
+	return ScheduleOverlapSkip, fmt.Errorf("%T: unknown overlap policy '%s'", ScheduleOverlapSkip, str)
+}
+
+func ScheduleOverlapPolicyNames() []string {
+	var names []string
+	for typ := ScheduleOverlapSkip; typ <= ScheduleOverlapCancelPrevious; typ++ {
+		names = append(names, typ.String())
+	}
+	return names
+}
+
+func (p ScheduleOverlapPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *ScheduleOverlapPolicy) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	*p, err = ParseScheduleOverlapPolicy(name)
+	return
+}
+
+// Schedule submits a new job with the same spec every time its cron expression is due.
+type Schedule struct {
+	// The unique global ID of this schedule
+	ID string `json:"ID"`
+	// The ID of the client that created this schedule, and that owns the jobs it submits
+	ClientID   string `json:"ClientID"`
+	APIVersion string `json:"APIVersion"`
+	// Cron expression of the times a job is submitted, evaluated in UTC
+	Cron          string                `json:"Cron"`
+	OverlapPolicy ScheduleOverlapPolicy `json:"OverlapPolicy"`
+	// Paused schedules don't submit jobs until they are resumed
+	Paused bool `json:"Paused,omitempty"`
+	// The specification of the jobs submitted by this schedule
+	Spec Spec `json:"Spec"`
+	// The requester node that submits the jobs of this schedule
+	RequesterNodeID string    `json:"RequesterNodeID"`
+	CreatedAt       time.Time `json:"CreatedAt"`
+	// The next time a job is due
+	NextRunAt time.Time `json:"NextRunAt"`
+	// The last time a job was submitted
+	LastRunAt time.Time `json:"LastRunAt,omitempty"`
+	// The job submitted by the last run
+	LastJobID string `json:"LastJobID,omitempty"`
+	// The number of runs delayed until the job of the last run completes by the queue overlap policy
+	PendingRuns int `json:"PendingRuns,omitempty"`
+	// Version is incremented every time the schedule is updated to detect concurrent updates
+	Version int `json:"Version"`
+}
+
+type ScheduleCreatePayload struct {
+	// the id of the client that is creating the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// Cron expression of the times a job is submitted, evaluated in UTC
+	Cron string `json:"Cron,omitempty" validate:"required"`
+
+	// What to do when a job is due while the job of the previous run is still in progress
+	OverlapPolicy ScheduleOverlapPolicy `json:"OverlapPolicy,omitempty"`
+
+	// The specification of the jobs submitted by the schedule.
+	Spec *Spec `json:"Spec,omitempty" validate:"required"`
+}
+
+func (s ScheduleCreatePayload) GetClientID() string {
+	return s.ClientID
+}
+
+type ScheduleUpdatePayload struct {
+	// the id of the client that is updating the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the schedule to update
+	ScheduleID string `json:"ScheduleID,omitempty" validate:"required"`
+
+	// pause or resume the schedule
+	Paused bool `json:"Paused"`
+}
+
+func (s ScheduleUpdatePayload) GetClientID() string {
+	return s.ClientID
+}
+
+type ScheduleDeletePayload struct {
+	// the id of the client that is deleting the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the schedule to delete
+	ScheduleID string `json:"ScheduleID,omitempty" validate:"required"`
+}
+
+func (s ScheduleDeletePayload) GetClientID() string {
+	return s.ClientID
+}
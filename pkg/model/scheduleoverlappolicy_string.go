@@ -0,0 +1,25 @@
+// Code generated by "stringer -type=ScheduleOverlapPolicy --trimprefix=ScheduleOverlap"; DO NOT EDIT.
+
+package model
+
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[ScheduleOverlapSkip-0]
+	_ = x[ScheduleOverlapQueue-1]
+	_ = x[ScheduleOverlapCancelPrevious-2]
+}
+
+const _ScheduleOverlapPolicy_name = "SkipQueueCancelPrevious"
+
+var _ScheduleOverlapPolicy_index = [...]uint8{0, 4, 9, 23}
+
+func (i ScheduleOverlapPolicy) String() string {
+	if i < 0 || i >= ScheduleOverlapPolicy(len(_ScheduleOverlapPolicy_index)-1) {
+		return "ScheduleOverlapPolicy(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _ScheduleOverlapPolicy_name[_ScheduleOverlapPolicy_index[i]:_ScheduleOverlapPolicy_index[i+1]]
+}
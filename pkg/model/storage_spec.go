@@ -33,10 +33,27 @@ type StorageSpec struct {
 	Metadata map[string]string `json:"Metadata,omitempty"`
 }
 
+// LocalityKey identifies the data of the spec across nodes, so that nodes can advertise the data they hold locally.
+// It is empty for storage sources whose data can't be shared across nodes.
+func (s StorageSpec) LocalityKey() string {
+	switch s.StorageSource {
+	case StorageSourceIPFS, StorageSourceEstuary:
+		if s.CID != "" {
+			return "ipfs:" + s.CID
+		}
+	case StorageSourceURLDownload:
+		if s.URL != "" {
+			return "url:" + s.URL
+		}
+	}
+	return ""
+}
+
 // PublishedStorageSpec is a wrapper for a StorageSpec that has been published
 // by a compute provider - it keeps info about the host job that
 // lead to the given storage spec being published
 type PublishedResult struct {
-	NodeID string      `json:"NodeID,omitempty"`
-	Data   StorageSpec `json:"Data,omitempty"`
+	NodeID     string      `json:"NodeID,omitempty"`
+	ShardIndex int         `json:"ShardIndex,omitempty"`
+	Data       StorageSpec `json:"Data,omitempty"`
 }
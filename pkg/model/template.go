@@ -0,0 +1,26 @@
+package model
+
+// JobTemplate is a parametric job specification that is expanded into a child job for every combination of the
+// values of its matrix. The `{{name}}` placeholders of the spec's docker entrypoint and environment variables,
+// wasm parameters and input paths are replaced by the value of the parameter with the same name.
+type JobTemplate struct {
+	// Spec is the specification of the child jobs, containing the placeholders of the matrix parameters
+	Spec Spec `json:"Spec,omitempty"`
+	// Matrix maps the name of each parameter to the values it takes
+	Matrix map[string][]string `json:"Matrix,omitempty"`
+}
+
+// JobTemplateCreatePayload is the data needed to submit a job template to the requester node
+type JobTemplateCreatePayload struct {
+	// the id of the client that is submitting the template
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// The template to expand into child jobs.
+	Template *JobTemplate `json:"Template,omitempty" validate:"required"`
+}
+
+func (t JobTemplateCreatePayload) GetClientID() string {
+	return t.ClientID
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const bytesInGB = 1 << 30
+
+// UsageRecord is the resources consumed by an execution that completed its run, charged to the client
+// that submitted the execution's job.
+type UsageRecord struct {
+	ClientID    string `json:"ClientID"`
+	JobID       string `json:"JobID"`
+	NodeID      string `json:"NodeID"`
+	ExecutionID string `json:"ExecutionID"`
+	// Resources requested by the job while the execution was running.
+	Resources ResourceUsageData `json:"Resources"`
+	// Duration is the wall-clock time the execution was running for.
+	Duration    time.Duration `json:"Duration"`
+	CompletedAt time.Time     `json:"CompletedAt"`
+}
+
+// ClientQuota limits the jobs a client can submit to the requester node. Limits that are not set are unlimited.
+type ClientQuota struct {
+	// MaxConcurrentJobs is the number of jobs of the client that can be in progress at the same time, including
+	// jobs waiting for an approval, for capacity or for their dependencies.
+	MaxConcurrentJobs int `json:"MaxConcurrentJobs,omitempty"`
+	// MaxCPUHoursPerDay is the number of CPU hours the executions of the client can consume over the last 24 hours.
+	MaxCPUHoursPerDay float64 `json:"MaxCPUHoursPerDay,omitempty"`
+}
+
+// ClientUsage aggregates the resources consumed by a client since a point in time.
+type ClientUsage struct {
+	ClientID string    `json:"ClientID"`
+	Since    time.Time `json:"Since"`
+	// RunningJobs is the number of jobs of the client that are currently running on the compute nodes.
+	RunningJobs int `json:"RunningJobs"`
+	// Executions is the number of executions that completed their run since the start of the period.
+	Executions     int     `json:"Executions"`
+	WallClockHours float64 `json:"WallClockHours"`
+	CPUHours       float64 `json:"CPUHours"`
+	MemoryGBHours  float64 `json:"MemoryGBHours"`
+	GPUHours       float64 `json:"GPUHours"`
+	// Quota is the quota the client is subject to.
+	Quota ClientQuota `json:"Quota"`
+}
+
+// Add adds the resources consumed by an execution to the usage.
+func (u *ClientUsage) Add(record UsageRecord) {
+	hours := record.Duration.Hours()
+	u.Executions++
+	u.WallClockHours += hours
+	u.CPUHours += record.Resources.CPU * hours
+	u.MemoryGBHours += float64(record.Resources.Memory) / bytesInGB * hours
+	u.GPUHours += float64(record.Resources.GPU) * hours
+}
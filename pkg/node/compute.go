@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
@@ -15,12 +16,14 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/compute/store/inmemory"
 	"github.com/bacalhau-project/bacalhau/pkg/executor"
 	executor_util "github.com/bacalhau-project/bacalhau/pkg/executor/util"
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/publisher"
 	"github.com/bacalhau-project/bacalhau/pkg/simulator"
 	"github.com/bacalhau-project/bacalhau/pkg/storage"
 	storage_bidstrategy "github.com/bacalhau-project/bacalhau/pkg/storage/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/transport/bprotocol"
 	simulator_protocol "github.com/bacalhau-project/bacalhau/pkg/transport/simulator"
@@ -46,18 +49,44 @@ func NewComputeNode(
 	host host.Host,
 	apiServer *publicapi.APIServer,
 	config ComputeConfig,
+	executionStore store.ExecutionStore,
+	inputCache *cache.Cache,
 	simulatorNodeID string,
 	simulatorRequestHandler *simulator.RequestHandler,
 	storages storage.StorageProvider,
 	executors executor.ExecutorProvider,
 	verifiers verifier.VerifierProvider,
 	publishers publisher.PublisherProvider) (*Compute, error) {
-	executionStore := inlocalstore.NewPersistentJobStore(inmemory.NewStore())
+	// executions are only kept in memory unless a persistent execution store is provided, in which case the executions
+	// still active when this node last stopped are orphaned.
+	var orphanedExecutions []store.Execution
+	if executionStore == nil {
+		executionStore = inlocalstore.NewPersistentJobStore(inmemory.NewStore())
+	} else {
+		var err error
+		orphanedExecutions, err = executionStore.GetActiveExecutions(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// sample the resources actually used by running executions
+	usageSampler := compute.NewResourceUsageSampler(compute.ResourceUsageSamplerParams{
+		Name:      "ResourceUsage",
+		Executors: executors,
+		Interval:  config.ResourceUsageSamplingInterval,
+	})
 
 	// executor/backend
-	runningCapacityTracker := capacity.NewLocalTracker(capacity.LocalTrackerParams{
-		MaxCapacity: config.TotalResourceLimits,
+	runningCapacityTracker, err := capacity.NewTracker(capacity.TrackerParams{
+		MaxCapacity:    config.TotalResourceLimits,
+		Config:         config.CapacityTrackerConfig,
+		UnusedCapacity: usageSampler,
 	})
+	if err != nil {
+		usageSampler.Stop()
+		return nil, err
+	}
 	enqueuedCapacityTracker := capacity.NewLocalTracker(capacity.LocalTrackerParams{
 		MaxCapacity: config.QueueResourceLimits,
 	})
@@ -83,6 +112,11 @@ func NewComputeNode(
 	} else {
 		computeCallback = standardComputeCallback
 	}
+	// the job of an execution can be adopted by another requester node while the execution is in progress
+	computeCallback = compute.NewRequesterRoutingCallback(compute.RequesterRoutingCallbackParams{
+		Callback: computeCallback,
+		Store:    executionStore,
+	})
 
 	baseExecutor := compute.NewBaseExecutor(compute.BaseExecutorParams{
 		ID:              host.ID().String(),
@@ -91,6 +125,7 @@ func NewComputeNode(
 		Executors:       executors,
 		Verifiers:       verifiers,
 		Publishers:      publishers,
+		UsageSampler:    usageSampler,
 		SimulatorConfig: config.SimulatorConfig,
 	})
 
@@ -98,6 +133,7 @@ func NewComputeNode(
 		ID:                         host.ID().String(),
 		DelegateExecutor:           baseExecutor,
 		Callback:                   computeCallback,
+		Store:                      executionStore,
 		RunningCapacityTracker:     runningCapacityTracker,
 		EnqueuedCapacityTracker:    enqueuedCapacityTracker,
 		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
@@ -173,14 +209,19 @@ func NewComputeNode(
 		CapacityTracker:    runningCapacityTracker,
 		ExecutorBuffer:     bufferRunner,
 		MaxJobRequirements: config.JobResourceLimits,
+		Storages:           storages,
+		UsageSampler:       usageSampler,
 	})
 
 	baseEndpoint := compute.NewBaseEndpoint(compute.BaseEndpointParams{
-		ID:              host.ID().String(),
-		ExecutionStore:  executionStore,
-		UsageCalculator: capacityCalculator,
-		BidStrategy:     biddingStrategy,
-		Executor:        bufferRunner,
+		ID:                         host.ID().String(),
+		ExecutionStore:             executionStore,
+		UsageCalculator:            capacityCalculator,
+		BidStrategy:                biddingStrategy,
+		Executor:                   bufferRunner,
+		RateCard:                   config.RateCard,
+		CapacityTracker:            runningCapacityTracker,
+		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
 	})
 
 	// if this node is the simulator, then we set the simulator request handler as the stream handler
@@ -200,21 +241,43 @@ func NewComputeNode(
 	debugInfoProviders := []model.DebugInfoProvider{
 		runningInfoProvider,
 		sensors.NewCompletedJobs(executionStore),
+		usageSampler,
+	}
+	if inputCache != nil {
+		debugInfoProviders = append(debugInfoProviders, inputCache)
 	}
 
 	// register compute public http apis
 	computeAPIServer := compute_publicapi.NewComputeAPIServer(compute_publicapi.ComputeAPIServerParams{
 		APIServer:          apiServer,
 		DebugInfoProviders: debugInfoProviders,
+		InputCache:         inputCache,
 	})
-	err := computeAPIServer.RegisterAllHandlers()
+	err = computeAPIServer.RegisterAllHandlers()
 	if err != nil {
 		return nil, err
 	}
 
+	// fail the executions that were orphaned when this node last stopped. We wait before reconciling them to give
+	// the node a chance to connect to their requester nodes.
+	var reconcileTimer *time.Timer
+	if len(orphanedExecutions) > 0 {
+		reconciler := compute.NewExecutionReconciler(compute.ExecutionReconcilerParams{
+			ID:       host.ID().String(),
+			Store:    executionStore,
+			Callback: computeCallback,
+		})
+		reconcileTimer = time.AfterFunc(config.ExecutionReconciliationDelay, func() {
+			reconciler.Reconcile(logger.ContextWithNodeIDLogger(context.Background(), host.ID().String()), orphanedExecutions)
+		})
+	}
+
 	// A single cleanup function to make sure the order of closing dependencies is correct
 	cleanupFunc := func(ctx context.Context) {
-		// pass
+		if reconcileTimer != nil {
+			reconcileTimer.Stop()
+		}
+		usageSampler.Stop()
 	}
 
 	return &Compute{
@@ -17,6 +17,7 @@ type ComputeConfigParams struct {
 	DefaultJobResourceLimits     model.ResourceUsageData
 	PhysicalResourcesProvider    capacity.Provider
 	IgnorePhysicalResourceLimits bool
+	CapacityTrackerConfig        capacity.TrackerConfig
 
 	ExecutorBufferBackoffDuration time.Duration
 
@@ -31,9 +32,16 @@ type ComputeConfigParams struct {
 	// Bid strategies config
 	JobSelectionPolicy model.JobSelectionPolicy
 
+	// Pricing config
+	RateCard model.RateCard
+
 	// logging running executions
 	LogRunningExecutionsInterval time.Duration
 
+	ExecutionReconciliationDelay time.Duration
+
+	ResourceUsageSamplingInterval time.Duration
+
 	SimulatorConfig model.SimulatorConfigCompute
 }
 
@@ -44,6 +52,8 @@ type ComputeConfig struct {
 	JobResourceLimits            model.ResourceUsageData
 	DefaultJobResourceLimits     model.ResourceUsageData
 	IgnorePhysicalResourceLimits bool
+	// CapacityTrackerConfig is the policy used to commit the running capacity of the node to executions
+	CapacityTrackerConfig capacity.TrackerConfig
 
 	// How long the buffer would backoff before polling the queue again for new jobs
 	ExecutorBufferBackoffDuration time.Duration
@@ -67,9 +77,19 @@ type ComputeConfig struct {
 	// Bid strategies config
 	JobSelectionPolicy model.JobSelectionPolicy
 
+	// RateCard is used to quote a price in the bids of this compute node
+	RateCard model.RateCard
+
 	// logging running executions
 	LogRunningExecutionsInterval time.Duration
 
+	// ExecutionReconciliationDelay is how long the node waits after starting before failing the executions left
+	// active in a persistent execution store, to give it a chance to connect to their requester nodes first.
+	ExecutionReconciliationDelay time.Duration
+
+	// ResourceUsageSamplingInterval is how often the actual resource usage of running executions is sampled
+	ResourceUsageSamplingInterval time.Duration
+
 	SimulatorConfig model.SimulatorConfigCompute
 }
 
@@ -100,9 +120,24 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 	if params.LogRunningExecutionsInterval == 0 {
 		params.LogRunningExecutionsInterval = DefaultComputeConfig.LogRunningExecutionsInterval
 	}
+	if params.ExecutionReconciliationDelay == 0 {
+		params.ExecutionReconciliationDelay = DefaultComputeConfig.ExecutionReconciliationDelay
+	}
+	if params.ResourceUsageSamplingInterval == 0 {
+		params.ResourceUsageSamplingInterval = DefaultComputeConfig.ResourceUsageSamplingInterval
+	}
 	if params.ExecutorBufferBackoffDuration == 0 {
 		params.ExecutorBufferBackoffDuration = DefaultComputeConfig.ExecutorBufferBackoffDuration
 	}
+	if params.CapacityTrackerConfig.Policy == "" {
+		params.CapacityTrackerConfig.Policy = DefaultComputeConfig.CapacityTrackerConfig.Policy
+	}
+	if params.CapacityTrackerConfig.CPUOvercommitRatio == 0 {
+		params.CapacityTrackerConfig.CPUOvercommitRatio = DefaultComputeConfig.CapacityTrackerConfig.CPUOvercommitRatio
+	}
+	if params.CapacityTrackerConfig.MemoryOvercommitRatio == 0 {
+		params.CapacityTrackerConfig.MemoryOvercommitRatio = DefaultComputeConfig.CapacityTrackerConfig.MemoryOvercommitRatio
+	}
 
 	// Get available physical resources in the host
 	physicalResourcesProvider := params.PhysicalResourcesProvider
@@ -139,6 +174,7 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 		JobResourceLimits:             jobResourceLimits,
 		DefaultJobResourceLimits:      defaultJobResourceLimits,
 		IgnorePhysicalResourceLimits:  params.IgnorePhysicalResourceLimits,
+		CapacityTrackerConfig:         params.CapacityTrackerConfig,
 		ExecutorBufferBackoffDuration: params.ExecutorBufferBackoffDuration,
 
 		JobNegotiationTimeout:      params.JobNegotiationTimeout,
@@ -150,8 +186,12 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 
 		JobSelectionPolicy: params.JobSelectionPolicy,
 
-		LogRunningExecutionsInterval: params.LogRunningExecutionsInterval,
-		SimulatorConfig:              params.SimulatorConfig,
+		RateCard: params.RateCard,
+
+		LogRunningExecutionsInterval:  params.LogRunningExecutionsInterval,
+		ExecutionReconciliationDelay:  params.ExecutionReconciliationDelay,
+		ResourceUsageSamplingInterval: params.ResourceUsageSamplingInterval,
+		SimulatorConfig:               params.SimulatorConfig,
 	}
 
 	validateConfig(config, physicalResources)
@@ -182,6 +222,12 @@ func validateConfig(config ComputeConfig, physicalResources model.ResourceUsageD
 		return
 	}
 
+	rateCard := config.RateCard
+	if rateCard.Base < 0 || rateCard.CPU < 0 || rateCard.Memory < 0 || rateCard.Disk < 0 || rateCard.GPU < 0 {
+		err = fmt.Errorf("rate card %+v has negative prices", rateCard)
+		return
+	}
+
 	if !config.DefaultJobResourceLimits.LessThanEq(config.JobResourceLimits) {
 		err = fmt.Errorf("default job resource limits %+v exceed job resource limits %+v",
 			config.DefaultJobResourceLimits, config.JobResourceLimits)
@@ -3,6 +3,7 @@ package node
 import (
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity/system"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 )
@@ -14,13 +15,20 @@ var DefaultComputeConfig = ComputeConfigParams{
 		Memory: 100 * 1024 * 1024, // 100Mi
 	},
 	ExecutorBufferBackoffDuration: 50 * time.Millisecond,
+	CapacityTrackerConfig: capacity.TrackerConfig{
+		Policy:                capacity.TrackerPolicyStrict,
+		CPUOvercommitRatio:    1,
+		MemoryOvercommitRatio: 1,
+	},
 
 	JobNegotiationTimeout:      3 * time.Minute,
 	MinJobExecutionTimeout:     500 * time.Millisecond,
 	MaxJobExecutionTimeout:     60 * time.Minute,
 	DefaultJobExecutionTimeout: 10 * time.Minute,
 
-	LogRunningExecutionsInterval: 10 * time.Second,
+	LogRunningExecutionsInterval:  10 * time.Second,
+	ExecutionReconciliationDelay:  10 * time.Second,
+	ResourceUsageSamplingInterval: 15 * time.Second,
 }
 
 var DefaultRequesterConfig = RequesterConfigParams{
@@ -28,7 +36,18 @@ var DefaultRequesterConfig = RequesterConfigParams{
 	DefaultJobExecutionTimeout: 30 * time.Minute,
 
 	HousekeepingBackgroundTaskInterval: 30 * time.Second,
+	ScheduleBackgroundTaskInterval:     10 * time.Second,
+	RetentionBackgroundTaskInterval:    10 * time.Minute,
+	LeaseBackgroundTaskInterval:        10 * time.Second,
+	LeaseDuration:                      1 * time.Minute,
+	LostNodeGracePeriod:                2 * DefaultNodeInfoPublisherInterval,
 	NodeRankRandomnessRange:            10,
+	NodeRankDataLocalityWeight:         20,
+	// the resources compute nodes allocate by default to jobs that don't request them
+	DefaultJobResources: model.ResourceUsageData{
+		CPU:    0.1,               // 100m
+		Memory: 100 * 1024 * 1024, // 100Mi
+	},
 
 	MinBacalhauVersion: model.BuildVersionInfo{
 		Major: "0", Minor: "3", GitVersion: "v0.3.20",
@@ -13,8 +13,20 @@ type RequesterConfigParams struct {
 	DefaultJobExecutionTimeout time.Duration
 
 	HousekeepingBackgroundTaskInterval time.Duration
+	ScheduleBackgroundTaskInterval     time.Duration
+	RetentionBackgroundTaskInterval    time.Duration
+	LeaseBackgroundTaskInterval        time.Duration
+	LeaseDuration                      time.Duration
+	LostNodeGracePeriod                time.Duration
 	NodeRankRandomnessRange            int
+	NodeRankDataLocalityWeight         int
+	DefaultJobResources                model.ResourceUsageData
 	JobSelectionPolicy                 model.JobSelectionPolicy
+	AdmissionPolicy                    model.AdmissionPolicy
+	ClientQuota                        model.ClientQuota
+	ReservationLimits                  model.ReservationLimits
+	JobRetention                       model.RetentionPolicy
+	JobArchivePath                     string
 	SimulatorConfig                    model.SimulatorConfigRequester
 
 	// minimum version of compute nodes that the requester will accept and route jobs to
@@ -31,10 +43,36 @@ type RequesterConfig struct {
 
 	// HousekeepingBackgroundTaskInterval background task interval that periodically checks for expired states
 	HousekeepingBackgroundTaskInterval time.Duration
+	// ScheduleBackgroundTaskInterval background task interval that periodically submits the jobs of due schedules
+	ScheduleBackgroundTaskInterval time.Duration
+	// RetentionBackgroundTaskInterval background task interval that periodically archives and purges expired jobs
+	RetentionBackgroundTaskInterval time.Duration
+	// LeaseBackgroundTaskInterval background task interval that periodically renews the lease of this node
+	// and adopts the jobs of requester nodes whose lease expired
+	LeaseBackgroundTaskInterval time.Duration
+	// LeaseDuration is how long the jobs of this node are kept from being adopted after the lease was renewed
+	LeaseDuration time.Duration
+	// LostNodeGracePeriod is how long executions are given to be updated before they are considered lost and moved
+	// to other nodes, when their compute node is no longer discovered because its node info expired
+	LostNodeGracePeriod time.Duration
 	// NodeRankRandomnessRange defines the range of randomness used to rank nodes
 	NodeRankRandomnessRange int
-	JobSelectionPolicy      model.JobSelectionPolicy
-	SimulatorConfig         model.SimulatorConfigRequester
+	// NodeRankDataLocalityWeight defines the rank given to nodes that already hold all the inputs of a job
+	NodeRankDataLocalityWeight int
+	JobSelectionPolicy         model.JobSelectionPolicy
+	// AdmissionPolicy decides which submitted jobs are scheduled, rejected or wait for an approval
+	AdmissionPolicy model.AdmissionPolicy
+	// DefaultJobResources are the resources charged to clients for the executions of jobs that don't request them
+	DefaultJobResources model.ResourceUsageData
+	// ClientQuota limits the jobs each client can submit. Clients are not limited if empty
+	ClientQuota model.ClientQuota
+	// ReservationLimits limits the capacity each client can reserve. Reservations are not limited if empty
+	ReservationLimits model.ReservationLimits
+	// JobRetention decides when terminal jobs are purged from the job store. Jobs are kept forever if empty
+	JobRetention model.RetentionPolicy
+	// JobArchivePath is the file purged jobs are exported to. Required if the retention policy is enabled
+	JobArchivePath  string
+	SimulatorConfig model.SimulatorConfigRequester
 
 	// minimum version of compute nodes that the requester will accept and route jobs to
 	MinBacalhauVersion model.BuildVersionInfo
@@ -62,9 +100,39 @@ func NewRequesterConfigWith(params RequesterConfigParams) (config RequesterConfi
 	if params.HousekeepingBackgroundTaskInterval == 0 {
 		params.HousekeepingBackgroundTaskInterval = DefaultRequesterConfig.HousekeepingBackgroundTaskInterval
 	}
+	if params.ScheduleBackgroundTaskInterval == 0 {
+		params.ScheduleBackgroundTaskInterval = DefaultRequesterConfig.ScheduleBackgroundTaskInterval
+	}
+	if params.RetentionBackgroundTaskInterval == 0 {
+		params.RetentionBackgroundTaskInterval = DefaultRequesterConfig.RetentionBackgroundTaskInterval
+	}
+	if params.JobRetention.IsEnabled() && params.JobArchivePath == "" {
+		err = fmt.Errorf("a job archive path is required to enforce the job retention policy")
+		return
+	}
+	if params.LeaseBackgroundTaskInterval == 0 {
+		params.LeaseBackgroundTaskInterval = DefaultRequesterConfig.LeaseBackgroundTaskInterval
+	}
+	if params.LeaseDuration == 0 {
+		params.LeaseDuration = DefaultRequesterConfig.LeaseDuration
+	}
+	if params.LeaseDuration <= params.LeaseBackgroundTaskInterval {
+		err = fmt.Errorf("lease duration %s must be greater than the lease renewal interval %s",
+			params.LeaseDuration, params.LeaseBackgroundTaskInterval)
+		return
+	}
+	if params.LostNodeGracePeriod == 0 {
+		params.LostNodeGracePeriod = DefaultRequesterConfig.LostNodeGracePeriod
+	}
 	if params.NodeRankRandomnessRange == 0 {
 		params.NodeRankRandomnessRange = DefaultRequesterConfig.NodeRankRandomnessRange
 	}
+	if params.NodeRankDataLocalityWeight == 0 {
+		params.NodeRankDataLocalityWeight = DefaultRequesterConfig.NodeRankDataLocalityWeight
+	}
+	if params.DefaultJobResources.IsZero() {
+		params.DefaultJobResources = DefaultRequesterConfig.DefaultJobResources
+	}
 	if params.MinBacalhauVersion == (model.BuildVersionInfo{}) {
 		params.MinBacalhauVersion = DefaultRequesterConfig.MinBacalhauVersion
 	}
@@ -73,8 +141,20 @@ func NewRequesterConfigWith(params RequesterConfigParams) (config RequesterConfi
 		MinJobExecutionTimeout:             params.MinJobExecutionTimeout,
 		DefaultJobExecutionTimeout:         params.DefaultJobExecutionTimeout,
 		HousekeepingBackgroundTaskInterval: params.HousekeepingBackgroundTaskInterval,
+		ScheduleBackgroundTaskInterval:     params.ScheduleBackgroundTaskInterval,
+		RetentionBackgroundTaskInterval:    params.RetentionBackgroundTaskInterval,
+		LeaseBackgroundTaskInterval:        params.LeaseBackgroundTaskInterval,
+		LeaseDuration:                      params.LeaseDuration,
+		LostNodeGracePeriod:                params.LostNodeGracePeriod,
 		JobSelectionPolicy:                 params.JobSelectionPolicy,
+		AdmissionPolicy:                    params.AdmissionPolicy,
+		ClientQuota:                        params.ClientQuota,
+		ReservationLimits:                  params.ReservationLimits,
+		JobRetention:                       params.JobRetention,
+		JobArchivePath:                     params.JobArchivePath,
 		NodeRankRandomnessRange:            params.NodeRankRandomnessRange,
+		NodeRankDataLocalityWeight:         params.NodeRankDataLocalityWeight,
+		DefaultJobResources:                params.DefaultJobResources,
 		SimulatorConfig:                    params.SimulatorConfig,
 		MinBacalhauVersion:                 params.MinBacalhauVersion,
 	}
@@ -79,6 +79,7 @@ func (f *StandardStorageProvidersFactory) Get(
 		executor_util.StandardStorageProviderOptions{
 			API:                  nodeConfig.IPFSClient,
 			FilecoinUnsealedPath: nodeConfig.FilecoinUnsealedPath,
+			InputCache:           nodeConfig.InputCache,
 		},
 	)
 }
@@ -100,6 +101,7 @@ func (f *StandardExecutorsFactory) Get(
 			Storage: executor_util.StandardStorageProviderOptions{
 				API:                  nodeConfig.IPFSClient,
 				FilecoinUnsealedPath: nodeConfig.FilecoinUnsealedPath,
+				InputCache:           nodeConfig.InputCache,
 			},
 		},
 	)
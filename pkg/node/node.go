@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute/store"
 	"github.com/bacalhau-project/bacalhau/pkg/config"
 	"github.com/bacalhau-project/bacalhau/pkg/ipfs"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
@@ -16,6 +17,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/routing"
 	"github.com/bacalhau-project/bacalhau/pkg/routing/inmemory"
 	"github.com/bacalhau-project/bacalhau/pkg/simulator"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/cache"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/bacalhau-project/bacalhau/pkg/version"
 	"github.com/imdario/mergo"
@@ -36,6 +38,8 @@ type NodeConfig struct {
 	IPFSClient                ipfs.Client
 	CleanupManager            *system.CleanupManager
 	JobStore                  jobstore.Store
+	ExecutionStore            store.ExecutionStore
+	InputCache                *cache.Cache
 	Host                      host.Host
 	FilecoinUnsealedPath      string
 	EstuaryAPIKey             string
@@ -184,11 +188,6 @@ func NewNode(
 	// register consumers of node info published over gossipSub
 	nodeInfoSubscriber := pubsub.NewChainedSubscriber[model.NodeInfo](true)
 	nodeInfoSubscriber.Add(pubsub.SubscriberFunc[model.NodeInfo](nodeInfoStore.Add))
-	err = nodeInfoPubSub.Subscribe(ctx, nodeInfoSubscriber)
-	if err != nil {
-		gossipSubCancel()
-		return nil, err
-	}
 
 	// public http api server
 	apiServer, err := publicapi.NewAPIServer(publicapi.APIServerParams{
@@ -236,6 +235,8 @@ func NewNode(
 			routedHost,
 			apiServer,
 			config.ComputeConfig,
+			config.ExecutionStore,
+			config.InputCache,
 			config.SimulatorNodeID,
 			simulatorRequestHandler,
 			storageProviders,
@@ -272,6 +273,17 @@ func NewNode(
 		return cleanupErr
 	})
 
+	if requesterNode != nil {
+		// re-attempt queued jobs when compute nodes report more available capacity
+		nodeInfoSubscriber.Add(requesterNode.nodeInfoSubscriber)
+	}
+	// subscribe to node info after all consumers are registered
+	err = nodeInfoPubSub.Subscribe(ctx, nodeInfoSubscriber)
+	if err != nil {
+		gossipSubCancel()
+		return nil, err
+	}
+
 	if requesterNode != nil && computeNode != nil {
 		// To enable nodes self-dialing themselves as libp2p doesn't support it.
 		computeNode.RegisterLocalComputeCallback(requesterNode.localCallback)
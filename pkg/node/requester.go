@@ -8,6 +8,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
 	"github.com/bacalhau-project/bacalhau/pkg/eventhandler"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
 	"github.com/bacalhau-project/bacalhau/pkg/pubsub"
@@ -35,6 +36,7 @@ type Requester struct {
 	JobStore           jobstore.Store
 	computeProxy       *bprotocol.ComputeProxy
 	localCallback      compute.Callback
+	nodeInfoSubscriber pubsub.Subscriber[model.NodeInfo]
 	requesterAPIServer *requester_publicapi.RequesterAPIServer
 	cleanupFunc        func(ctx context.Context)
 }
@@ -99,9 +101,13 @@ func NewRequesterNode(
 		ranking.NewEnginesNodeRanker(),
 		ranking.NewLabelsNodeRanker(),
 		ranking.NewMaxUsageNodeRanker(),
+		ranking.NewReservationsNodeRanker(),
 		ranking.NewMinVersionNodeRanker(ranking.MinVersionNodeRankerParams{MinVersion: config.MinBacalhauVersion}),
 
 		// arbitrary rankers
+		ranking.NewDataLocalityNodeRanker(ranking.DataLocalityNodeRankerParams{
+			Weight: config.NodeRankDataLocalityWeight,
+		}),
 		ranking.NewRandomNodeRanker(ranking.RandomNodeRankerParams{
 			RandomnessRange: config.NodeRankRandomnessRange,
 		}),
@@ -119,6 +125,8 @@ func NewRequesterNode(
 		EventEmitter: requester.NewEventEmitter(requester.EventEmitterParams{
 			EventConsumer: localJobEventConsumer,
 		}),
+		LostNodeGracePeriod: config.LostNodeGracePeriod,
+		DefaultJobResources: config.DefaultJobResources,
 	})
 
 	publicKey := host.Peerstore().PubKey(host.ID())
@@ -127,27 +135,79 @@ func NewRequesterNode(
 		return nil, err
 	}
 
-	selectionStrategy := bidstrategy.FromJobSelectionPolicy(config.JobSelectionPolicy)
+	selectionStrategy := bidstrategy.NewChainedBidStrategy(
+		bidstrategy.NewAdmissionPolicyStrategy(config.AdmissionPolicy),
+		bidstrategy.FromJobSelectionPolicy(config.JobSelectionPolicy),
+	)
+
+	queue := requester.NewQueue(requester.QueueParams{
+		ID:               host.ID().String(),
+		Scheduler:        scheduler,
+		Store:            jobStore,
+		NodeDiscoverer:   nodeDiscoveryChain,
+		DispatchInterval: config.HousekeepingBackgroundTaskInterval,
+	})
+
+	usageLedger := requester.NewUsageLedger(requester.UsageLedgerParams{
+		Store: jobStore,
+		Quota: config.ClientQuota,
+	})
+
+	reservationManager := requester.NewReservationManager(requester.ReservationManagerParams{
+		ID:              host.ID().String(),
+		NodeInfoStore:   nodeInfoStore,
+		ComputeEndpoint: computeProxy,
+		Limits:          config.ReservationLimits,
+	})
 
 	endpoint := requester.NewBaseEndpoint(&requester.BaseEndpointParams{
 		ID:                         host.ID().String(),
 		PublicKey:                  marshaledPublicKey,
 		Selector:                   selectionStrategy,
 		Store:                      jobStore,
-		Scheduler:                  scheduler,
+		Queue:                      queue,
 		Verifiers:                  verifiers,
 		StorageProviders:           storageProviders,
 		MinJobExecutionTimeout:     config.MinJobExecutionTimeout,
 		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
+		Quotas:                     usageLedger,
 	})
 
 	housekeeping := requester.NewHousekeeping(requester.HousekeepingParams{
+		Endpoint:            endpoint,
+		DependencyScheduler: endpoint,
+		TimeoutHandler:      scheduler,
+		StragglerHandler:    scheduler,
+		LostHandler:         scheduler,
+		JobStore:            jobStore,
+		NodeID:              host.ID().String(),
+		Interval:            config.HousekeepingBackgroundTaskInterval,
+	})
+
+	scheduleRunner := requester.NewScheduleRunner(requester.ScheduleRunnerParams{
 		Endpoint: endpoint,
 		JobStore: jobStore,
 		NodeID:   host.ID().String(),
-		Interval: config.HousekeepingBackgroundTaskInterval,
+		Interval: config.ScheduleBackgroundTaskInterval,
 	})
 
+	// archive and purge the terminal jobs that expired according to the retention policy
+	var archiveReader requester.ArchiveReader
+	var retentionEnforcer *requester.RetentionEnforcer
+	if config.JobArchivePath != "" {
+		jobArchive := requester.NewJobArchive(config.JobArchivePath)
+		archiveReader = jobArchive
+		if config.JobRetention.IsEnabled() {
+			retentionEnforcer = requester.NewRetentionEnforcer(requester.RetentionEnforcerParams{
+				JobStore: jobStore,
+				Archive:  jobArchive,
+				Policy:   config.JobRetention,
+				NodeID:   host.ID().String(),
+				Interval: config.RetentionBackgroundTaskInterval,
+			})
+		}
+	}
+
 	// if this node is the simulator, then we pass incoming requests to the simulator before passing them to the endpoint
 	if simulatorRequestHandler != nil {
 		bprotocol.NewCallbackHandler(bprotocol.CallbackHandlerParams{
@@ -162,6 +222,32 @@ func NewRequesterNode(
 		})
 	}
 
+	// renew the lease of this node over the job store, and adopt the jobs of other requester nodes sharing the
+	// same job store whose lease expired.
+	leaseKeeper, err := requester.NewLeaseKeeper(ctx, requester.LeaseKeeperParams{
+		JobStore:      jobStore,
+		Adopter:       requester.JobAdopters{scheduler, queue},
+		NodeID:        host.ID().String(),
+		NodePublicKey: marshaledPublicKey,
+		Duration:      config.LeaseDuration,
+		Interval:      config.LeaseBackgroundTaskInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// resume jobs that were in progress when this node last stopped, in case the job store is persistent.
+	// We wait for a housekeeping interval before resuming them to give the node a chance to discover compute nodes.
+	unfinishedJobs, err := jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resumeTimer := time.AfterFunc(config.HousekeepingBackgroundTaskInterval, func() {
+		resumeCtx := logger.ContextWithNodeIDLogger(context.Background(), host.ID().String())
+		scheduler.ResumeJobs(resumeCtx, unfinishedJobs)
+		queue.ResumeJobs(resumeCtx, unfinishedJobs)
+	})
+
 	// register debug info providers for the /debug endpoint
 	debugInfoProviders := []model.DebugInfoProvider{}
 
@@ -169,6 +255,10 @@ func NewRequesterNode(
 	requesterAPIServer := requester_publicapi.NewRequesterAPIServer(requester_publicapi.RequesterAPIServerParams{
 		APIServer:          apiServer,
 		Requester:          endpoint,
+		Queue:              queue,
+		Usage:              usageLedger,
+		Reservations:       reservationManager,
+		Archive:            archiveReader,
 		DebugInfoProviders: debugInfoProviders,
 		JobStore:           jobStore,
 		StorageProviders:   storageProviders,
@@ -218,8 +308,15 @@ func NewRequesterNode(
 
 	// A single cleanup function to make sure the order of closing dependencies is correct
 	cleanupFunc := func(ctx context.Context) {
-		// stop the housekeeping background task
+		// stop the housekeeping, queue, schedule, retention and lease background tasks
 		housekeeping.Stop()
+		queue.Stop()
+		scheduleRunner.Stop()
+		if retentionEnforcer != nil {
+			retentionEnforcer.Stop()
+		}
+		leaseKeeper.Stop()
+		resumeTimer.Stop()
 
 		cleanupErr := bufferedJobEventPubSub.Close(ctx)
 		if cleanupErr != nil {
@@ -244,6 +341,7 @@ func NewRequesterNode(
 	return &Requester{
 		Endpoint:           endpoint,
 		localCallback:      scheduler,
+		nodeInfoSubscriber: queue,
 		JobStore:           jobStore,
 		computeProxy:       standardComputeProxy,
 		cleanupFunc:        cleanupFunc,
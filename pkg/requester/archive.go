@@ -0,0 +1,150 @@
+package requester
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"golang.org/x/exp/slices"
+)
+
+// JobArchive is a gzip compressed file of JSON lines, each holding a job that was purged from the job store
+// together with its state and history. Every batch of archived jobs is appended as a separate gzip member,
+// and the members are read back as a single stream.
+type JobArchive struct {
+	path string
+	mtx  sync.RWMutex
+}
+
+var _ ArchiveReader = (*JobArchive)(nil)
+
+func NewJobArchive(path string) *JobArchive {
+	return &JobArchive{path: path}
+}
+
+// Append writes the jobs at the end of the archive.
+func (a *JobArchive) Append(jobs []model.JobWithInfo) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gomnd
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, job := range jobs {
+		if err = encoder.Encode(job); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// GetJobs returns the archived jobs that match the query. The archive is empty if it was never written to.
+func (a *JobArchive) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]model.JobWithInfo, error) {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+
+	file, err := os.Open(a.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var result []model.JobWithInfo
+	decoder := json.NewDecoder(reader)
+	for {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		var job model.JobWithInfo
+		err = decoder.Decode(&job)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isArchivedJobMatching(query, job.Job) {
+			result = append(result, job)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		left, right := result[i].Job.Metadata, result[j].Job.Metadata
+		if query.SortReverse {
+			left, right = right, left
+		}
+		switch query.SortBy {
+		case "id":
+			return left.ID < right.ID
+		case "created_at":
+			return left.CreatedAt.Before(right.CreatedAt)
+		default:
+			return false
+		}
+	})
+	if query.Offset > 0 {
+		if query.Offset >= len(result) {
+			return nil, nil
+		}
+		result = result[query.Offset:]
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+// isArchivedJobMatching returns true if the job matches the filters of the query, in the same way the job store
+// filters the jobs it holds.
+func isArchivedJobMatching(query jobstore.JobQuery, job model.Job) bool {
+	if query.ID != "" && !strings.HasPrefix(job.Metadata.ID, query.ID) {
+		return false
+	}
+	if !query.ReturnAll && query.ClientID != "" && query.ClientID != job.Metadata.ClientID {
+		return false
+	}
+	if query.ParentID != "" && query.ParentID != job.Metadata.ParentID {
+		return false
+	}
+
+	// If we are not using include tags, by default every job is included.
+	// If a job is specifically included, that overrides it being excluded.
+	included := len(query.IncludeTags) == 0
+	for _, tag := range job.Spec.Annotations {
+		if slices.Contains(query.IncludeTags, model.IncludedTag(tag)) {
+			return true
+		}
+		if slices.Contains(query.ExcludeTags, model.ExcludedTag(tag)) {
+			return false
+		}
+	}
+	return included
+}
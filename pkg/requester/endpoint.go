@@ -15,6 +15,7 @@ import (
 	"github.com/bacalhau-project/bacalhau/pkg/verifier"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -22,13 +23,16 @@ import (
 type BaseEndpointParams struct {
 	ID                         string
 	PublicKey                  []byte
-	Scheduler                  Scheduler
+	Queue                      Queue
 	Selector                   bidstrategy.BidStrategy
 	Store                      jobstore.Store
 	Verifiers                  verifier.VerifierProvider
 	StorageProviders           storage.StorageProvider
 	MinJobExecutionTimeout     time.Duration
 	DefaultJobExecutionTimeout time.Duration
+	// Quotas is used to check that clients did not reach their quota before accepting their jobs.
+	// Quotas are not enforced if not set.
+	Quotas QuotaEnforcer
 }
 
 // BaseEndpoint base implementation of requester Endpoint
@@ -37,6 +41,7 @@ type BaseEndpoint struct {
 	queue      Queue
 	store      jobstore.Store
 	selector   bidstrategy.BidStrategy
+	quotas     QuotaEnforcer
 	transforms []jobtransform.Transformer
 }
 
@@ -47,17 +52,22 @@ func NewBaseEndpoint(params *BaseEndpointParams) *BaseEndpoint {
 		jobtransform.NewRequesterInfo(params.ID, params.PublicKey),
 	}
 
-	queue := NewQueue(params.Store, params.Scheduler)
 	return &BaseEndpoint{
 		id:         params.ID,
-		queue:      queue,
+		queue:      params.Queue,
 		selector:   params.Selector,
 		store:      params.Store,
+		quotas:     params.Quotas,
 		transforms: transforms,
 	}
 }
 
 func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePayload) (*model.Job, error) {
+	return node.submitJob(ctx, data, "")
+}
+
+// submitJob submits a new job, which is a child of the given parent if parentID is not empty
+func (node *BaseEndpoint) submitJob(ctx context.Context, data model.JobCreatePayload, parentID string) (*model.Job, error) {
 	jobUUID, err := uuid.NewRandom()
 	if err != nil {
 		return &model.Job{}, fmt.Errorf("error creating job id: %w", err)
@@ -84,12 +94,19 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 	// ctx, span := system.NewRootSpan(ctx, system.GetTracer(), "pkg/controller.SubmitJob")
 	// defer span.End()
 
+	if node.quotas != nil {
+		if err = node.quotas.CheckQuota(ctx, data.ClientID); err != nil {
+			return &model.Job{}, err
+		}
+	}
+
 	job := &model.Job{
 		APIVersion: data.APIVersion,
 		Metadata: model.Metadata{
 			ID:        jobID,
 			ClientID:  data.ClientID,
 			CreatedAt: time.Now(),
+			ParentID:  parentID,
 		},
 		Spec: *data.Spec,
 	}
@@ -101,23 +118,28 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 		}
 	}
 
-	err = node.store.CreateJob(ctx, *job)
-	if err != nil {
-		return job, err
+	// jobs can only depend on jobs of the same client, as the results of the dependencies are wired into their inputs
+	for _, dependency := range job.Spec.Dependencies {
+		dependencyJob, getErr := node.store.GetJob(ctx, dependency.JobID)
+		if getErr != nil {
+			return job, getErr
+		}
+		if dependencyJob.Metadata.ClientID != data.ClientID {
+			return job, fmt.Errorf("job %s was submitted by another client and can't be a dependency", dependency.JobID)
+		}
 	}
 
-	err = node.queue.EnqueueJob(ctx, *job)
+	err = node.store.CreateJob(ctx, *job)
 	if err != nil {
 		return job, err
 	}
 
-	selectRequest := bidstrategy.BidStrategyRequest{NodeID: node.id, Job: *job}
-	response, err := node.selector.ShouldBid(ctx, selectRequest)
-	if err != nil {
-		return job, err
+	// jobs with dependencies are scheduled once their dependencies complete
+	if len(job.Spec.Dependencies) > 0 {
+		return job, nil
 	}
 
-	return job, node.handleBidResponse(ctx, *job, response)
+	return job, node.scheduleJob(ctx, *job)
 }
 
 func (node *BaseEndpoint) ApproveJob(ctx context.Context, approval ApproveJobRequest) error {
@@ -133,6 +155,22 @@ func (node *BaseEndpoint) ApproveJob(ctx context.Context, approval ApproveJobReq
 	if err != nil {
 		return err
 	}
+	if approval.Response.ShouldWait {
+		return nil
+	}
+
+	// release the job from waiting for an approval, so that it is either started or canceled
+	err = node.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID: job.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{
+			ExpectedState: model.JobStatePendingApproval,
+		},
+		NewState: model.JobStateQueued,
+		Comment:  fmt.Sprintf("job approval returned should bid: %t", approval.Response.ShouldBid),
+	})
+	if err != nil {
+		return err
+	}
 
 	return node.handleBidResponse(ctx, job, approval.Response)
 }
@@ -141,12 +179,43 @@ func (node *BaseEndpoint) CancelJob(ctx context.Context, request CancelJobReques
 	return node.queue.CancelJob(ctx, request)
 }
 
+// scheduleJob queues the job and starts it if the selection policy allows it
+func (node *BaseEndpoint) scheduleJob(ctx context.Context, job model.Job) error {
+	err := node.queue.EnqueueJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	selectRequest := bidstrategy.BidStrategyRequest{NodeID: node.id, Job: job}
+	response, err := node.selector.ShouldBid(ctx, selectRequest)
+	if err != nil {
+		return err
+	}
+
+	return node.handleBidResponse(ctx, job, response)
+}
+
 func (node *BaseEndpoint) handleBidResponse(ctx context.Context, job model.Job, response bidstrategy.BidStrategyResponse) error {
 	if response.ShouldWait {
-		return nil
+		// the job is parked until it is approved or rejected through ApproveJob
+		return node.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+			JobID: job.Metadata.ID,
+			Condition: jobstore.UpdateJobCondition{
+				ExpectedState: model.JobStateQueued,
+			},
+			NewState: model.JobStatePendingApproval,
+			Comment:  fmt.Sprintf("job waiting for approval: %s", response.Reason),
+		})
 	}
 
 	if response.ShouldBid {
+		served, err := node.serveCachedResult(ctx, job)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[handleBidResponse] failed to serve cached result of job %s", job.Metadata.ID)
+		}
+		if served {
+			return nil
+		}
 		return node.queue.StartJob(ctx, StartJobRequest{Job: job})
 	}
 
@@ -160,3 +229,4 @@ func (node *BaseEndpoint) handleBidResponse(ctx context.Context, job model.Job,
 
 // Compile-time interface check:
 var _ Endpoint = (*BaseEndpoint)(nil)
+var _ DependencyScheduler = (*BaseEndpoint)(nil)
@@ -44,15 +44,18 @@ func getTestEndpoint(t *testing.T, strategy bidstrategy.BidStrategy) (Endpoint,
 	require.NoError(t, err)
 	store := inmemory.NewJobStore()
 	endpoint := NewBaseEndpoint(&BaseEndpointParams{
-		Scheduler: &mockScheduler{
-			handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
-				store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
-					JobID:    sjr.Job.Metadata.ID,
-					NewState: model.JobStateInProgress,
-				})
-				return nil
+		Queue: NewQueue(QueueParams{
+			Store: store,
+			Scheduler: &mockScheduler{
+				handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
+					store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+						JobID:    sjr.Job.Metadata.ID,
+						NewState: model.JobStateInProgress,
+					})
+					return nil
+				},
 			},
-		},
+		}),
 		Selector:         strategy,
 		Store:            store,
 		Verifiers:        model.NewNoopProvider[model.Verifier, verifier.Verifier](verifier_mock),
@@ -95,9 +98,9 @@ func TestEndpointAppliesJobSelectionPolicy(t *testing.T) {
 		runTest(t, true, false, model.JobStateInProgress)
 	})
 
-	t.Run("queues when strategy says to wait", func(t *testing.T) {
-		runTest(t, false, true, model.JobStateQueued)
-		runTest(t, true, true, model.JobStateQueued)
+	t.Run("waits for approval when strategy says to wait", func(t *testing.T) {
+		runTest(t, false, true, model.JobStatePendingApproval)
+		runTest(t, true, true, model.JobStatePendingApproval)
 	})
 }
 
@@ -116,7 +119,7 @@ func TestEndpointAcceptsApprovals(t *testing.T) {
 
 		state, err := store.GetJobState(context.Background(), job.Metadata.ID)
 		require.NoError(t, err)
-		require.Equal(t, model.JobStateQueued, state.State)
+		require.Equal(t, model.JobStatePendingApproval, state.State)
 
 		err = endpoint.ApproveJob(context.Background(), ApproveJobRequest{
 			ClientID: "",
@@ -139,7 +142,57 @@ func TestEndpointAcceptsApprovals(t *testing.T) {
 
 	t.Run("rejects unknown client", func(t *testing.T) {
 		t.Setenv("BACALHAU_JOB_APPROVER", "hello")
-		runTest(t, false, model.JobStateQueued)
-		runTest(t, true, model.JobStateQueued)
+		runTest(t, false, model.JobStatePendingApproval)
+		runTest(t, true, model.JobStatePendingApproval)
 	})
 }
+
+func TestEndpointUpdatesJobDeal(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestEndpoint(t, &mockBidStrategy{response: bidstrategy.BidStrategyResponse{ShouldBid: true}})
+
+	spec, err := model.NewJobWithSaneProductionDefaults()
+	require.NoError(t, err)
+	job, err := endpoint.SubmitJob(ctx, model.JobCreatePayload{Spec: &spec.Spec})
+	require.NoError(t, err)
+
+	concurrency, minBids, timeout := 3, 2, 3600.0
+	updated, err := endpoint.UpdateJob(ctx, model.JobUpdatePayload{
+		JobID:       job.Metadata.ID,
+		Concurrency: &concurrency,
+		MinBids:     &minBids,
+		Timeout:     &timeout,
+	})
+	require.NoError(t, err)
+	require.Equal(t, concurrency, updated.Spec.Deal.Concurrency)
+	require.Equal(t, minBids, updated.Spec.Deal.MinBids)
+	require.Equal(t, timeout, updated.Spec.Timeout)
+
+	stored, err := store.GetJob(ctx, job.Metadata.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated.Spec.Deal, stored.Spec.Deal)
+
+	// jobs that were already sharded can be updated
+	stored.Spec.ExecutionPlan.Shards = []model.JobShard{{Index: 0}, {Index: 1}}
+	require.NoError(t, store.UpdateJob(ctx, jobstore.UpdateJobRequest{Job: stored}))
+	concurrency = 1
+	updated, err = endpoint.UpdateJob(ctx, model.JobUpdatePayload{JobID: job.Metadata.ID, Concurrency: &concurrency})
+	require.NoError(t, err)
+	require.Equal(t, concurrency, updated.Spec.Deal.Concurrency)
+	require.Equal(t, stored.Spec.ExecutionPlan, updated.Spec.ExecutionPlan)
+
+	// the timeout of jobs with executions can't be updated, as compute nodes enforce the timeout they received
+	require.NoError(t, store.CreateExecution(ctx, model.ExecutionState{JobID: job.Metadata.ID, NodeID: "node"}))
+	_, err = endpoint.UpdateJob(ctx, model.JobUpdatePayload{JobID: job.Metadata.ID, Timeout: &timeout})
+	require.Error(t, err)
+
+	// invalid deals are rejected
+	invalid := 0
+	_, err = endpoint.UpdateJob(ctx, model.JobUpdatePayload{JobID: job.Metadata.ID, Concurrency: &invalid})
+	require.Error(t, err)
+
+	// completed jobs can't be updated
+	completeJob(t, store, job.Metadata.ID, model.StorageSpec{})
+	_, err = endpoint.UpdateJob(ctx, model.JobUpdatePayload{JobID: job.Metadata.ID, Timeout: &timeout})
+	require.Error(t, err)
+}
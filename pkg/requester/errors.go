@@ -47,3 +47,19 @@ func NewErrJobAlreadyTerminal(jobID string) ErrJobAlreadyTerminal {
 func (e ErrJobAlreadyTerminal) Error() string {
 	return fmt.Errorf("job %s is already in a terminal state", e.JobID).Error()
 }
+
+// ErrQuotaExceeded is returned when a client reached its quota and is not allowed to submit more jobs
+type ErrQuotaExceeded struct {
+	ClientID string
+	Limit    string
+	Used     float64
+	Allowed  float64
+}
+
+func NewErrQuotaExceeded(clientID, limit string, used, allowed float64) ErrQuotaExceeded {
+	return ErrQuotaExceeded{ClientID: clientID, Limit: limit, Used: used, Allowed: allowed}
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("client %s exceeded its quota of %s. used: %g, allowed: %g", e.ClientID, e.Limit, e.Used, e.Allowed)
+}
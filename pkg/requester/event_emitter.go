@@ -50,6 +50,7 @@ func (e EventEmitter) EmitBidReceived(
 	// we flip senders to mimic a bid was received instead of being asked
 	event.SourceNodeID = request.RoutingMetadata.TargetPeerID
 	event.TargetNodeID = "" // localdb don't assume a target node for events coming from compute nodes
+	event.Price = response.Price
 	e.EmitEventSilently(ctx, event)
 }
 
@@ -0,0 +1,160 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// members of gang jobs listen on ports in [minGangPort, minGangPort+gangPortRange)
+	minGangPort   = 30000
+	gangPortRange = 10000
+)
+
+// acceptGangBidsIfPossible holds the pending bids of a gang shard until there are enough of them to start all the
+// missing members of the gang together. The cheapest bids are then accepted and the rest rejected, and each accepted
+// member is sent the addresses at which it and the other members can be reached.
+// make sure to call this function with the lock held
+func (s *scheduler) acceptGangBidsIfPossible(
+	ctx context.Context, job model.Job, executions []model.ExecutionState, pendingBids []model.ExecutionState, receivedBidsCount int) {
+	var members []string
+	for _, execution := range executions {
+		if execution.State.IsActive() {
+			members = append(members, execution.NodeID)
+		}
+	}
+	missing := system.Max(job.Spec.Deal.Concurrency-len(members), 0)
+	if receivedBidsCount < job.Spec.Deal.MinBids || len(pendingBids) < missing {
+		log.Ctx(ctx).Debug().Msgf("holding %d bids for job %s until %d members of the gang can start together",
+			len(pendingBids), job.Metadata.ID, missing)
+		return
+	}
+
+	// the cheapest bids are accepted first
+	sort.SliceStable(pendingBids, func(i, j int) bool {
+		return pendingBids[i].Price < pendingBids[j].Price
+	})
+	accepted := pendingBids[:missing]
+	for _, bid := range accepted {
+		members = append(members, bid.NodeID)
+	}
+	sort.Strings(members)
+	var addresses []string
+	if len(accepted) > 0 {
+		var err error
+		addresses, err = s.gangAddresses(ctx, job, accepted[0].ShardIndex, members)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[acceptGangBidsIfPossible] holding the bids of job %s", job.Metadata.ID)
+			return
+		}
+	}
+	for _, bid := range accepted {
+		s.notifyBidAccepted(ctx, bid, compute.GangMembership{
+			Addresses: addresses,
+			Rank:      sort.SearchStrings(members, bid.NodeID),
+		})
+	}
+	for _, bid := range pendingBids[missing:] {
+		s.notifyBidRejected(ctx, bid)
+	}
+}
+
+// releaseBrokenGangs releases the remaining members of the gang shards of the job that no longer have enough
+// executions, so that the whole gang can be started again together. Pending bids are rejected, and executions
+// that are still running are canceled. Executions that already finished running keep their results.
+// make sure to call this function with the lock held
+func (s *scheduler) releaseBrokenGangs(ctx context.Context, jobID string) {
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[releaseBrokenGangs] failed to get job")
+		return
+	}
+	if !job.Spec.Deal.Gang {
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[releaseBrokenGangs] failed to get job state")
+		return
+	}
+	if jobState.State != model.JobStateInProgress {
+		return
+	}
+
+	for _, executions := range groupExecutionsByShard(job, jobState) {
+		if isShardWaitingForNodes(job, executions) || countActiveExecutions(executions) >= job.Spec.Deal.Concurrency {
+			continue
+		}
+		for _, execution := range executions {
+			if isGangMemberReleasable(execution.State) {
+				log.Ctx(ctx).Debug().Msgf("releasing execution %s as another member of its gang dropped out", execution)
+				s.discardExecution(ctx, execution, model.ExecutionStateCanceled, "another member of the gang dropped out")
+			}
+		}
+	}
+}
+
+// isGangMemberReleasable returns true if the execution didn't finish running yet
+func isGangMemberReleasable(state model.ExecutionStateType) bool {
+	return state == model.ExecutionStateAskForBid || state == model.ExecutionStateAskForBidAccepted ||
+		state == model.ExecutionStateBidAccepted
+}
+
+// gangAddresses returns the host:port addresses at which the members of a gang shard can reach each other, in the
+// order of the given node ids. Each member is reached on the host of its node, on a port derived from the job,
+// the shard and the rank of the member, so that members running on the same host don't collide.
+func (s *scheduler) gangAddresses(ctx context.Context, job model.Job, shardIndex int, members []string) ([]string, error) {
+	nodes, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if host, ok := nodeHost(node.PeerInfo.Addrs); ok {
+			hosts[node.PeerInfo.ID.String()] = host
+		}
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(fmt.Sprintf("%s/%d", job.Metadata.ID, shardIndex)))
+	addresses := make([]string, 0, len(members))
+	for rank, member := range members {
+		host, ok := hosts[member]
+		if !ok {
+			return nil, fmt.Errorf("no ip address is known for node %s", member)
+		}
+		port := minGangPort + (int(hash.Sum32()%gangPortRange)+rank)%gangPortRange
+		addresses = append(addresses, net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+	return addresses, nil
+}
+
+// nodeHost returns the ip address at which the node can be reached by other nodes, preferring addresses that are
+// not loopback addresses, which only nodes running on the same host can reach.
+func nodeHost(addrs []multiaddr.Multiaddr) (string, bool) {
+	var loopback string
+	for _, addr := range addrs {
+		ip, err := manet.ToIP(addr)
+		if err != nil || ip.IsUnspecified() {
+			continue
+		}
+		if !ip.IsLoopback() {
+			return ip.String(), true
+		}
+		if loopback == "" {
+			loopback = ip.String()
+		}
+	}
+	return loopback, loopback != ""
+}
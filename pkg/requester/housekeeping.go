@@ -6,21 +6,30 @@ import (
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/rs/zerolog/log"
 )
 
 type HousekeepingParams struct {
-	Endpoint Endpoint
-	JobStore jobstore.Store
-	NodeID   string
-	Interval time.Duration
+	Endpoint            Endpoint
+	DependencyScheduler DependencyScheduler
+	TimeoutHandler      ExecutionTimeoutHandler
+	StragglerHandler    StragglerHandler
+	LostHandler         LostExecutionHandler
+	JobStore            jobstore.Store
+	NodeID              string
+	Interval            time.Duration
 }
 
 type Housekeeping struct {
-	endpoint Endpoint
-	jobStore jobstore.Store
-	nodeID   string
-	interval time.Duration
+	endpoint            Endpoint
+	dependencyScheduler DependencyScheduler
+	timeoutHandler      ExecutionTimeoutHandler
+	stragglerHandler    StragglerHandler
+	lostHandler         LostExecutionHandler
+	jobStore            jobstore.Store
+	nodeID              string
+	interval            time.Duration
 
 	stopChannel chan struct{}
 	stopOnce    sync.Once
@@ -28,11 +37,15 @@ type Housekeeping struct {
 
 func NewHousekeeping(params HousekeepingParams) *Housekeeping {
 	h := &Housekeeping{
-		endpoint:    params.Endpoint,
-		jobStore:    params.JobStore,
-		nodeID:      params.NodeID,
-		interval:    params.Interval,
-		stopChannel: make(chan struct{}),
+		endpoint:            params.Endpoint,
+		dependencyScheduler: params.DependencyScheduler,
+		timeoutHandler:      params.TimeoutHandler,
+		stragglerHandler:    params.StragglerHandler,
+		lostHandler:         params.LostHandler,
+		jobStore:            params.JobStore,
+		nodeID:              params.NodeID,
+		interval:            params.Interval,
+		stopChannel:         make(chan struct{}),
 	}
 
 	go h.housekeepingBackgroundTask()
@@ -51,13 +64,29 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 				continue
 			}
 			now := time.Now()
+			var ownedJobs []model.JobWithInfo
 			for _, jobDescription := range jobs {
 				// in case the job store is shared between multiple nodes, we only want to clean up jobs that are owned by this node
 				if jobDescription.Job.Metadata.Requester.RequesterNodeID != h.nodeID {
 					continue
 				}
+				ownedJobs = append(ownedJobs, jobDescription)
+				// jobs waiting for their dependencies are not running yet
+				if isWaitingForDependencies(jobDescription) {
+					continue
+				}
+				if h.lostHandler != nil {
+					h.lostHandler.MigrateLostExecutions(ctx, jobDescription.Job.Metadata.ID, now)
+				}
+				if jobDescription.Job.Spec.Deal.Speculation.IsEnabled() && h.stragglerHandler != nil {
+					h.stragglerHandler.LaunchBackupExecutions(ctx, jobDescription.Job.Metadata.ID, now)
+				}
+				// executions of jobs with phase timeouts are also timed out one by one, and replaced
+				if jobDescription.Job.Spec.Timeouts.IsSet() && h.timeoutHandler != nil {
+					h.timeoutHandler.TimeoutExecutions(ctx, jobDescription.Job.Metadata.ID, now)
+				}
 				// cancel jobs that have been in progress beyond the timeout period
-				if now.Sub(jobDescription.State.CreateTime).Seconds() > jobDescription.Job.Spec.Timeout {
+				if now.Sub(timeoutStartTime(jobDescription.State)).Seconds() > jobDescription.Job.Spec.Timeout {
 					log.Ctx(ctx).Info().Msgf("job %s timed out. Canceling", jobDescription.Job.Metadata.ID)
 					go func(jobID string) {
 						_, innerErr := h.endpoint.CancelJob(ctx, CancelJobRequest{
@@ -70,6 +99,9 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 					}(jobDescription.Job.Metadata.ID)
 				}
 			}
+			if h.dependencyScheduler != nil {
+				h.dependencyScheduler.ScheduleDependentJobs(ctx, ownedJobs)
+			}
 		case <-h.stopChannel:
 			log.Ctx(ctx).Debug().Msg("stopped housekeeping task")
 			ticker.Stop()
@@ -78,6 +110,15 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 	}
 }
 
+// timeoutStartTime returns the time from which the job timeout is measured. Jobs are only timed out from the time
+// they were scheduled, so that jobs waiting for their dependencies are given their full timeout to run.
+func timeoutStartTime(state model.JobState) time.Time {
+	if state.ScheduleTime.IsZero() {
+		return state.CreateTime
+	}
+	return state.ScheduleTime
+}
+
 func (h *Housekeeping) Stop() {
 	h.stopOnce.Do(func() {
 		h.stopChannel <- struct{}{}
@@ -0,0 +1,143 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+// mockTimeoutEndpoint records the jobs that were canceled
+type mockTimeoutEndpoint struct {
+	Endpoint
+	mu       sync.Mutex
+	canceled []string
+}
+
+func (m *mockTimeoutEndpoint) CancelJob(_ context.Context, request CancelJobRequest) (CancelJobResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.canceled = append(m.canceled, request.JobID)
+	return CancelJobResult{}, nil
+}
+
+func (m *mockTimeoutEndpoint) canceledJobs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.canceled...)
+}
+
+// mockTimeoutHandler records the jobs whose executions were checked for phase timeouts
+type mockTimeoutHandler struct {
+	mu      sync.Mutex
+	checked map[string]bool
+}
+
+func (m *mockTimeoutHandler) TimeoutExecutions(_ context.Context, jobID string, _ time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checked[jobID] = true
+}
+
+func (m *mockTimeoutHandler) wasChecked(jobID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checked[jobID]
+}
+
+func TestHousekeepingTimesOutJobsWithPhaseTimeouts(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	endpoint := &mockTimeoutEndpoint{}
+	timeoutHandler := &mockTimeoutHandler{checked: make(map[string]bool)}
+
+	for _, job := range []model.Job{
+		{
+			Metadata: model.Metadata{ID: "job-timeout", Requester: model.JobRequester{RequesterNodeID: "requester"}},
+			Spec:     model.Spec{Timeout: 0.05},
+		},
+		{
+			Metadata: model.Metadata{ID: "phase-timeouts", Requester: model.JobRequester{RequesterNodeID: "requester"}},
+			Spec:     model.Spec{Timeout: 0.05, Timeouts: model.JobTimeouts{Execution: 60}},
+		},
+	} {
+		require.NoError(t, store.CreateJob(ctx, job))
+		require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+			JobID:    job.Metadata.ID,
+			NewState: model.JobStateInProgress,
+		}))
+	}
+
+	housekeeping := NewHousekeeping(HousekeepingParams{
+		Endpoint:       endpoint,
+		TimeoutHandler: timeoutHandler,
+		JobStore:       store,
+		NodeID:         "requester",
+		Interval:       20 * time.Millisecond,
+	})
+	t.Cleanup(housekeeping.Stop)
+
+	// the job-level timeout applies to jobs with phase timeouts too, on top of their phase timeouts
+	require.Eventually(t, func() bool {
+		canceled := endpoint.canceledJobs()
+		return slices.Contains(canceled, "job-timeout") && slices.Contains(canceled, "phase-timeouts")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, timeoutHandler.wasChecked("phase-timeouts"))
+	require.False(t, timeoutHandler.wasChecked("job-timeout"))
+}
+
+func TestHousekeepingTimesOutDependentJobsFromTheirScheduleTime(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	endpoint := &mockTimeoutEndpoint{}
+	requester := model.JobRequester{RequesterNodeID: "requester"}
+
+	// the dependency runs for longer than the timeout of the job that depends on it
+	require.NoError(t, store.CreateJob(ctx, model.Job{
+		Metadata: model.Metadata{ID: "dependency", Requester: requester},
+		Spec:     model.Spec{Timeout: 60},
+	}))
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    "dependency",
+		NewState: model.JobStateInProgress,
+	}))
+	require.NoError(t, store.CreateJob(ctx, model.Job{
+		Metadata: model.Metadata{ID: "dependent", Requester: requester},
+		Spec:     model.Spec{Timeout: 0.3, Dependencies: []model.JobDependency{{JobID: "dependency", Path: "/inputs"}}},
+	}))
+
+	housekeeping := NewHousekeeping(HousekeepingParams{
+		Endpoint: endpoint,
+		JobStore: store,
+		NodeID:   "requester",
+		Interval: 20 * time.Millisecond,
+	})
+	t.Cleanup(housekeeping.Stop)
+
+	time.Sleep(400 * time.Millisecond)
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    "dependency",
+		NewState: model.JobStateCompleted,
+	}))
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    "dependent",
+		NewState: model.JobStateInProgress,
+	}))
+
+	// the dependent job is given its full timeout once scheduled
+	require.Never(t, func() bool {
+		return slices.Contains(endpoint.canceledJobs(), "dependent")
+	}, 150*time.Millisecond, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return slices.Contains(endpoint.canceledJobs(), "dependent")
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NotContains(t, endpoint.canceledJobs(), "dependency")
+}
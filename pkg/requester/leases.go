@@ -0,0 +1,209 @@
+package requester
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// AdoptJobs takes over the jobs that were owned by a requester node whose lease expired. The compute nodes running
+// the executions of the jobs are asked to send their callbacks to this node, and the scheduling of the jobs is resumed.
+// The ownership of the jobs must already be transferred to this node in the job store.
+func (s *scheduler) AdoptJobs(ctx context.Context, jobs []model.JobWithInfo) {
+	for _, jobWithInfo := range jobs {
+		for _, execution := range jobWithInfo.State.Executions {
+			if execution.State.IsTerminal() || execution.ComputeReference == "" {
+				continue
+			}
+			_, err := s.computeService.UpdateRequester(ctx, compute.UpdateRequesterRequest{
+				ExecutionID: execution.ComputeReference,
+				RoutingMetadata: compute.RoutingMetadata{
+					SourcePeerID: s.id,
+					TargetPeerID: execution.NodeID,
+				},
+			})
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msgf("[AdoptJobs] failed to update requester of execution %s", execution)
+			}
+		}
+	}
+	s.ResumeJobs(ctx, jobs)
+}
+
+// JobAdopters hands the adopted jobs to each of its adopters, in order.
+type JobAdopters []JobAdopter
+
+func (a JobAdopters) AdoptJobs(ctx context.Context, jobs []model.JobWithInfo) {
+	for _, adopter := range a {
+		adopter.AdoptJobs(ctx, jobs)
+	}
+}
+
+type LeaseKeeperParams struct {
+	JobStore      jobstore.Store
+	Adopter       JobAdopter
+	NodeID        string
+	NodePublicKey model.PublicKey
+	Duration      time.Duration
+	Interval      time.Duration
+}
+
+// LeaseKeeper is a background task that renews the lease of this requester node in the job store, and adopts the
+// jobs and schedules of other requester nodes sharing the same job store whose lease expired.
+type LeaseKeeper struct {
+	jobStore      jobstore.Store
+	adopter       JobAdopter
+	nodeID        string
+	nodePublicKey model.PublicKey
+	duration      time.Duration
+	interval      time.Duration
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewLeaseKeeper(ctx context.Context, params LeaseKeeperParams) (*LeaseKeeper, error) {
+	k := &LeaseKeeper{
+		jobStore:      params.JobStore,
+		adopter:       params.Adopter,
+		nodeID:        params.NodeID,
+		nodePublicKey: params.NodePublicKey,
+		duration:      params.Duration,
+		interval:      params.Interval,
+		stopChannel:   make(chan struct{}),
+	}
+
+	// take the lease before resuming this node's jobs, so that other nodes don't adopt them
+	if err := k.renewLease(ctx, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	go k.leaseBackgroundTask()
+	return k, nil
+}
+
+func (k *LeaseKeeper) leaseBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(k.interval)
+	for {
+		select {
+		case <-ticker.C:
+			k.keepLease(ctx, time.Now().UTC())
+		case <-k.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped lease keeper task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// keepLease renews the lease of this node, and adopts the jobs and schedules of nodes whose lease expired
+func (k *LeaseKeeper) keepLease(ctx context.Context, now time.Time) {
+	if err := k.renewLease(ctx, now); err != nil {
+		// don't adopt other nodes' jobs if this node can't keep its own lease
+		log.Ctx(ctx).Error().Err(err).Msg("[keepLease] failed to renew lease")
+		return
+	}
+	leases, err := k.jobStore.GetLeases(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[keepLease] failed to get leases")
+		return
+	}
+	expiredNodes := make(map[string]struct{})
+	for _, lease := range leases {
+		if lease.NodeID != k.nodeID && lease.IsExpired(now) {
+			expiredNodes[lease.NodeID] = struct{}{}
+		}
+	}
+	if len(expiredNodes) == 0 {
+		return
+	}
+	k.adoptJobs(ctx, expiredNodes)
+	k.adoptSchedules(ctx, expiredNodes)
+}
+
+func (k *LeaseKeeper) renewLease(ctx context.Context, now time.Time) error {
+	return k.jobStore.RenewLease(ctx, model.RequesterLease{
+		NodeID:     k.nodeID,
+		RenewTime:  now,
+		ExpireTime: now.Add(k.duration),
+	})
+}
+
+// adoptJobs transfers the ownership of the in progress jobs of the expired nodes to this node
+func (k *LeaseKeeper) adoptJobs(ctx context.Context, expiredNodes map[string]struct{}) {
+	jobs, err := k.jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[adoptJobs] failed to get in progress jobs")
+		return
+	}
+	var adopted []model.JobWithInfo
+	for _, jobWithInfo := range jobs {
+		job := jobWithInfo.Job
+		previousOwner := job.Metadata.Requester.RequesterNodeID
+		if _, ok := expiredNodes[previousOwner]; !ok {
+			continue
+		}
+		job.Metadata.Requester = model.JobRequester{
+			RequesterNodeID:    k.nodeID,
+			RequesterPublicKey: k.nodePublicKey,
+		}
+		// the expected version makes sure only a single node adopts the job if multiple nodes are on standby
+		err = k.jobStore.UpdateJob(ctx, jobstore.UpdateJobRequest{
+			Job: job,
+			Condition: jobstore.UpdateJobCondition{
+				ExpectedVersion: jobWithInfo.State.Version,
+			},
+			Comment: "adopted from requester node " + previousOwner + " whose lease expired",
+		})
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("failed to adopt job %s", job.Metadata.ID)
+			continue
+		}
+		jobState, err := k.jobStore.GetJobState(ctx, job.Metadata.ID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[adoptJobs] failed to get state of job %s", job.Metadata.ID)
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("adopted job %s from requester node %s whose lease expired", job.Metadata.ID, previousOwner)
+		adopted = append(adopted, model.JobWithInfo{Job: job, State: jobState})
+	}
+	if len(adopted) > 0 {
+		k.adopter.AdoptJobs(ctx, adopted)
+	}
+}
+
+// adoptSchedules transfers the ownership of the schedules of the expired nodes to this node
+func (k *LeaseKeeper) adoptSchedules(ctx context.Context, expiredNodes map[string]struct{}) {
+	schedules, err := k.jobStore.GetSchedules(ctx, "")
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[adoptSchedules] failed to get schedules")
+		return
+	}
+	for _, schedule := range schedules {
+		previousOwner := schedule.RequesterNodeID
+		if _, ok := expiredNodes[previousOwner]; !ok {
+			continue
+		}
+		schedule.RequesterNodeID = k.nodeID
+		err = k.jobStore.UpdateSchedule(ctx, jobstore.UpdateScheduleRequest{
+			Schedule:        schedule,
+			ExpectedVersion: schedule.Version,
+		})
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("failed to adopt schedule %s", schedule.ID)
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("adopted schedule %s from requester node %s whose lease expired", schedule.ID, previousOwner)
+	}
+}
+
+func (k *LeaseKeeper) Stop() {
+	k.stopOnce.Do(func() {
+		k.stopChannel <- struct{}{}
+	})
+}
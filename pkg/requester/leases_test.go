@@ -0,0 +1,86 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockJobAdopter struct {
+	adopted []model.JobWithInfo
+}
+
+func (m *mockJobAdopter) AdoptJobs(_ context.Context, jobs []model.JobWithInfo) {
+	m.adopted = append(m.adopted, jobs...)
+}
+
+func TestLeaseKeeperAdoptsJobsOfExpiredNodes(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+	now := time.Now().UTC()
+
+	createJob := func(owner string) string {
+		job := model.NewJob()
+		job.Metadata.ID = "job-" + owner
+		job.Metadata.Requester.RequesterNodeID = owner
+		require.NoError(t, store.CreateJob(ctx, *job))
+		require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+			JobID:    job.Metadata.ID,
+			NewState: model.JobStateInProgress,
+		}))
+		return job.Metadata.ID
+	}
+	getOwner := func(jobID string) string {
+		job, err := store.GetJob(ctx, jobID)
+		require.NoError(t, err)
+		return job.Metadata.Requester.RequesterNodeID
+	}
+
+	expiredJob := createJob("expired")
+	aliveJob := createJob("alive")
+	require.NoError(t, store.RenewLease(ctx, model.RequesterLease{NodeID: "expired", ExpireTime: now.Add(-time.Second)}))
+	require.NoError(t, store.RenewLease(ctx, model.RequesterLease{NodeID: "alive", ExpireTime: now.Add(time.Minute)}))
+	require.NoError(t, store.CreateSchedule(ctx, model.Schedule{ID: "schedule", RequesterNodeID: "expired", CreatedAt: now}))
+
+	adopter := &mockJobAdopter{}
+	keeper, err := NewLeaseKeeper(ctx, LeaseKeeperParams{
+		JobStore: store,
+		Adopter:  adopter,
+		NodeID:   "standby",
+		Duration: time.Minute,
+		Interval: time.Hour,
+	})
+	require.NoError(t, err)
+	defer keeper.Stop()
+
+	keeper.keepLease(ctx, now)
+	require.Equal(t, "standby", getOwner(expiredJob))
+	require.Equal(t, "alive", getOwner(aliveJob))
+	require.Len(t, adopter.adopted, 1)
+	require.Equal(t, expiredJob, adopter.adopted[0].Job.Metadata.ID)
+	require.Equal(t, "standby", adopter.adopted[0].Job.Metadata.Requester.RequesterNodeID)
+
+	schedule, err := store.GetSchedule(ctx, "schedule")
+	require.NoError(t, err)
+	require.Equal(t, "standby", schedule.RequesterNodeID)
+
+	// the lease of the standby node was renewed, so other nodes don't adopt the jobs back
+	leases, err := store.GetLeases(ctx)
+	require.NoError(t, err)
+	for _, lease := range leases {
+		if lease.NodeID == "standby" {
+			require.False(t, lease.IsExpired(now))
+		}
+	}
+
+	// jobs are only adopted once
+	keeper.keepLease(ctx, now)
+	require.Len(t, adopter.adopted, 1)
+}
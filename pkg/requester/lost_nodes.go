@@ -0,0 +1,70 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// MigrateLostExecutions marks the executions of the job as lost when their compute node is no longer discovered,
+// which happens once the node stopped publishing its node info for longer than the node info TTL. Other nodes are
+// then asked to replace the lost executions according to the job's retry policy.
+// Nodes are given a grace period to publish their node info after this node starts and after their execution
+// last changed, so that executions are not considered lost before their node had a chance to be discovered.
+func (s *scheduler) MigrateLostExecutions(ctx context.Context, jobID string, now time.Time) {
+	if s.lostNodeGracePeriod <= 0 || now.Sub(s.startTime) < s.lostNodeGracePeriod {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[MigrateLostExecutions] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[MigrateLostExecutions] failed to get job state")
+		return
+	}
+	if jobState.State != model.JobStateInProgress {
+		return
+	}
+
+	nodes, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[MigrateLostExecutions] failed to find nodes")
+		return
+	}
+	liveNodes := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		liveNodes[node.PeerInfo.ID.String()] = struct{}{}
+	}
+
+	lost := 0
+	for _, execution := range jobState.Executions {
+		if !isExecutionOnNode(execution.State) || now.Sub(execution.UpdateTime) < s.lostNodeGracePeriod {
+			continue
+		}
+		if _, ok := liveNodes[execution.NodeID]; ok {
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("execution %s lost with compute node %s", execution, execution.NodeID)
+		reason := fmt.Sprintf("compute node %s stopped publishing its node info", model.ShortID(execution.NodeID))
+		if s.discardExecution(ctx, execution, model.ExecutionStateLost, reason) {
+			lost++
+		}
+	}
+	if lost > 0 {
+		s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("%d executions were lost with their compute nodes", lost))
+	}
+}
+
+// isExecutionOnNode returns true if the execution depends on its compute node to make progress
+func isExecutionOnNode(state model.ExecutionStateType) bool {
+	return state == model.ExecutionStateAskForBidAccepted || state == model.ExecutionStateBidAccepted ||
+		state == model.ExecutionStateResultProposed || state == model.ExecutionStateResultAccepted
+}
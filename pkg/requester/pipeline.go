@@ -0,0 +1,170 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// SubmitPipeline submits every stage of the pipeline as a job, where the dependencies between stages are
+// translated to dependencies between their jobs. The jobs are returned in the same order as the stages.
+func (node *BaseEndpoint) SubmitPipeline(ctx context.Context, data model.PipelineCreatePayload) ([]*model.Job, error) {
+	stages, err := job.SortPipelineStages(*data.Pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	stageJobs := make(map[string]*model.Job, len(stages))
+	for _, stage := range stages {
+		spec := stage.Spec
+		spec.Dependencies = append([]model.JobDependency{}, spec.Dependencies...)
+		for _, dependency := range stage.Dependencies {
+			spec.Dependencies = append(spec.Dependencies, model.JobDependency{
+				JobID: stageJobs[dependency.Stage].Metadata.ID,
+				Path:  dependency.Path,
+			})
+		}
+
+		stageJob, submitErr := node.SubmitJob(ctx, model.JobCreatePayload{
+			ClientID:   data.ClientID,
+			APIVersion: data.APIVersion,
+			Spec:       &spec,
+		})
+		if submitErr != nil {
+			node.cancelPipeline(ctx, stageJobs, fmt.Sprintf("failed to submit pipeline stage %s: %s", stage.Name, submitErr))
+			return nil, submitErr
+		}
+		stageJobs[stage.Name] = stageJob
+	}
+
+	jobs := make([]*model.Job, 0, len(data.Pipeline.Stages))
+	for _, stage := range data.Pipeline.Stages {
+		jobs = append(jobs, stageJobs[stage.Name])
+	}
+	return jobs, nil
+}
+
+func (node *BaseEndpoint) cancelPipeline(ctx context.Context, stageJobs map[string]*model.Job, reason string) {
+	for _, stageJob := range stageJobs {
+		_, err := node.CancelJob(ctx, CancelJobRequest{
+			JobID:  stageJob.Metadata.ID,
+			Reason: reason,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[cancelPipeline] failed to cancel job %s", stageJob.Metadata.ID)
+		}
+	}
+}
+
+// ScheduleDependentJobs schedules jobs waiting for their dependencies once all of them have completed,
+// after wiring the dependencies' published results into the jobs' inputs. Jobs are canceled
+// if any of their dependencies failed, was canceled, or no longer exists.
+func (node *BaseEndpoint) ScheduleDependentJobs(ctx context.Context, jobs []model.JobWithInfo) {
+	for _, jobWithInfo := range jobs {
+		if !isWaitingForDependencies(jobWithInfo) {
+			continue
+		}
+		if err := node.scheduleDependentJob(ctx, jobWithInfo); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[ScheduleDependentJobs] failed to schedule job %s", jobWithInfo.Job.Metadata.ID)
+		}
+	}
+}
+
+func (node *BaseEndpoint) scheduleDependentJob(ctx context.Context, jobWithInfo model.JobWithInfo) error {
+	dependentJob := jobWithInfo.Job
+	results := make([]model.StorageSpec, 0, len(dependentJob.Spec.Dependencies))
+	for _, dependency := range dependentJob.Spec.Dependencies {
+		dependencyState, err := node.store.GetJobState(ctx, dependency.JobID)
+		if isJobNotFound(err) {
+			return node.cancelDependentJob(ctx, dependentJob, fmt.Sprintf("dependency %s no longer exists", dependency.JobID), false)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch dependencyState.State {
+		case model.JobStateCompleted:
+			result, ok := getPublishedResult(dependencyState)
+			if !ok {
+				return node.cancelDependentJob(ctx, dependentJob,
+					fmt.Sprintf("dependency %s completed without publishing a result", dependency.JobID), false)
+			}
+			result.Path = dependency.Path
+			results = append(results, result)
+		case model.JobStateCancelled:
+			return node.cancelDependentJob(ctx, dependentJob, fmt.Sprintf("dependency %s was canceled", dependency.JobID), true)
+		case model.JobStateError:
+			return node.cancelDependentJob(ctx, dependentJob, fmt.Sprintf("dependency %s failed", dependency.JobID), false)
+		default:
+			// still waiting for the dependency to complete
+			return nil
+		}
+	}
+
+	// wiring the results is idempotent in case a previous attempt stopped before the job was queued
+	for _, result := range results {
+		if !containsStorageSpec(dependentJob.Spec.Inputs, result) {
+			dependentJob.Spec.Inputs = append(dependentJob.Spec.Inputs, result)
+		}
+	}
+	err := node.store.UpdateJob(ctx, jobstore.UpdateJobRequest{
+		Job: dependentJob,
+		Condition: jobstore.UpdateJobCondition{
+			ExpectedState:   model.JobStateNew,
+			ExpectedVersion: jobWithInfo.State.Version,
+		},
+		Comment: "dependencies completed",
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Debug().Msgf("dependencies of job %s completed. Scheduling", dependentJob.Metadata.ID)
+	return node.scheduleJob(ctx, dependentJob)
+}
+
+func (node *BaseEndpoint) cancelDependentJob(ctx context.Context, dependentJob model.Job, reason string, userTriggered bool) error {
+	log.Ctx(ctx).Info().Msgf("canceling job %s because %s", dependentJob.Metadata.ID, reason)
+	_, err := node.CancelJob(ctx, CancelJobRequest{
+		JobID:         dependentJob.Metadata.ID,
+		Reason:        reason,
+		UserTriggered: userTriggered,
+	})
+	return err
+}
+
+// isWaitingForDependencies returns true if the job has dependencies and has not been queued yet
+func isWaitingForDependencies(jobWithInfo model.JobWithInfo) bool {
+	return jobWithInfo.State.State == model.JobStateNew && len(jobWithInfo.Job.Spec.Dependencies) > 0
+}
+
+// getPublishedResult returns the result published by the first completed execution of the job
+func getPublishedResult(jobState model.JobState) (model.StorageSpec, bool) {
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateCompleted && model.IsValidStorageSourceType(execution.PublishedResult.StorageSource) {
+			return execution.PublishedResult, true
+		}
+	}
+	return model.StorageSpec{}, false
+}
+
+// isJobNotFound returns true if the error is returned by a job store for a job that doesn't exist
+func isJobNotFound(err error) bool {
+	var notFound *bacerrors.JobNotFound
+	return errors.As(err, &notFound) || errors.As(err, &jobstore.ErrJobNotFound{})
+}
+
+func containsStorageSpec(specs []model.StorageSpec, spec model.StorageSpec) bool {
+	for _, s := range specs {
+		if s.StorageSource == spec.StorageSource && s.Name == spec.Name && s.CID == spec.CID && s.Path == spec.Path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	noop_storage "github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestPipelineEndpoint(t *testing.T) (*BaseEndpoint, jobstore.Store) {
+	store := inmemory.NewJobStore()
+	endpoint := NewBaseEndpoint(&BaseEndpointParams{
+		Queue: NewQueue(QueueParams{
+			Store: store,
+			Scheduler: &mockScheduler{
+				handleStartJob: func(ctx context.Context, sjr StartJobRequest) error {
+					return store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+						JobID:    sjr.Job.Metadata.ID,
+						NewState: model.JobStateInProgress,
+					})
+				},
+				handleCancelJob: func(ctx context.Context, cjr CancelJobRequest) (CancelJobResult, error) {
+					_, err := jobstore.StopJob(ctx, store, cjr.JobID, cjr.Reason, cjr.UserTriggered)
+					return CancelJobResult{}, err
+				},
+			},
+		}),
+		Selector:         &mockBidStrategy{response: bidstrategy.BidStrategyResponse{ShouldBid: true}},
+		Store:            store,
+		StorageProviders: model.NewNoopProvider[model.StorageSourceType, storage.Storage](noop_storage.NewNoopStorage(noop_storage.StorageConfig{})),
+	})
+	return endpoint, store
+}
+
+func completeJob(t *testing.T, store jobstore.Store, jobID string, result model.StorageSpec) {
+	ctx := context.Background()
+	require.NoError(t, store.CreateExecution(ctx, model.ExecutionState{
+		JobID:            jobID,
+		NodeID:           "node",
+		ComputeReference: "e-" + jobID,
+		State:            model.ExecutionStateCompleted,
+		PublishedResult:  result,
+	}))
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobID,
+		NewState: model.JobStateCompleted,
+	}))
+}
+
+func requireJobState(t *testing.T, store jobstore.Store, jobID string, expected model.JobStateType) {
+	state, err := store.GetJobState(context.Background(), jobID)
+	require.NoError(t, err)
+	require.Equal(t, expected, state.State)
+}
+
+func scheduleDependentJobs(t *testing.T, endpoint *BaseEndpoint, store jobstore.Store) {
+	jobs, err := store.GetInProgressJobs(context.Background())
+	require.NoError(t, err)
+	endpoint.ScheduleDependentJobs(context.Background(), jobs)
+}
+
+func TestPipelineSchedulesStagesAfterTheirDependencies(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+
+	jobs, err := endpoint.SubmitPipeline(ctx, model.PipelineCreatePayload{
+		Pipeline: &model.Pipeline{
+			Stages: []model.PipelineStage{
+				{Name: "aggregate", Dependencies: []model.PipelineDependency{{Stage: "process", Path: "/inputs/processed"}}},
+				{Name: "download"},
+				{Name: "process", Dependencies: []model.PipelineDependency{{Stage: "download", Path: "/inputs/raw"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	aggregate, download, process := jobs[0], jobs[1], jobs[2]
+	require.Equal(t, []model.JobDependency{{JobID: process.Metadata.ID, Path: "/inputs/processed"}}, aggregate.Spec.Dependencies)
+
+	requireJobState(t, store, download.Metadata.ID, model.JobStateInProgress)
+	requireJobState(t, store, process.Metadata.ID, model.JobStateNew)
+	requireJobState(t, store, aggregate.Metadata.ID, model.JobStateNew)
+
+	// nothing changes while the dependencies are still running
+	scheduleDependentJobs(t, endpoint, store)
+	requireJobState(t, store, process.Metadata.ID, model.JobStateNew)
+
+	result := model.StorageSpec{StorageSource: model.StorageSourceIPFS, Name: "download-result", CID: "QmDownload"}
+	completeJob(t, store, download.Metadata.ID, result)
+	scheduleDependentJobs(t, endpoint, store)
+	requireJobState(t, store, process.Metadata.ID, model.JobStateInProgress)
+	requireJobState(t, store, aggregate.Metadata.ID, model.JobStateNew)
+
+	processJob, err := store.GetJob(ctx, process.Metadata.ID)
+	require.NoError(t, err)
+	result.Path = "/inputs/raw"
+	require.Equal(t, []model.StorageSpec{result}, processJob.Spec.Inputs)
+}
+
+func TestJobsOnlyDependOnJobsOfTheSameClient(t *testing.T) {
+	ctx := context.Background()
+	endpoint, _ := getTestPipelineEndpoint(t)
+
+	dependency, err := endpoint.SubmitJob(ctx, model.JobCreatePayload{ClientID: "client", Spec: &model.Spec{}})
+	require.NoError(t, err)
+	spec := &model.Spec{Dependencies: []model.JobDependency{{JobID: dependency.Metadata.ID, Path: "/inputs"}}}
+
+	_, err = endpoint.SubmitJob(ctx, model.JobCreatePayload{ClientID: "other-client", Spec: spec})
+	require.Error(t, err)
+	_, err = endpoint.SubmitJob(ctx, model.JobCreatePayload{ClientID: "client", Spec: spec})
+	require.NoError(t, err)
+}
+
+func TestPipelineCancelsChildrenOfCanceledStages(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+
+	jobs, err := endpoint.SubmitPipeline(ctx, model.PipelineCreatePayload{
+		Pipeline: &model.Pipeline{
+			Stages: []model.PipelineStage{
+				{Name: "parent"},
+				{Name: "child-1", Dependencies: []model.PipelineDependency{{Stage: "parent", Path: "/inputs"}}},
+				{Name: "child-2", Dependencies: []model.PipelineDependency{{Stage: "parent", Path: "/inputs"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	parent, child1, child2 := jobs[0], jobs[1], jobs[2]
+
+	_, err = endpoint.CancelJob(ctx, CancelJobRequest{JobID: parent.Metadata.ID, Reason: "canceled", UserTriggered: true})
+	require.NoError(t, err)
+	scheduleDependentJobs(t, endpoint, store)
+	requireJobState(t, store, child1.Metadata.ID, model.JobStateCancelled)
+	requireJobState(t, store, child2.Metadata.ID, model.JobStateCancelled)
+}
+
+func TestPipelineFailsChildrenOfPurgedStages(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+
+	jobs, err := endpoint.SubmitPipeline(ctx, model.PipelineCreatePayload{
+		Pipeline: &model.Pipeline{
+			Stages: []model.PipelineStage{
+				{Name: "parent"},
+				{Name: "child", Dependencies: []model.PipelineDependency{{Stage: "parent", Path: "/inputs"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	parent, child := jobs[0], jobs[1]
+
+	require.NoError(t, store.DeleteJob(ctx, parent.Metadata.ID))
+	scheduleDependentJobs(t, endpoint, store)
+	requireJobState(t, store, child.Metadata.ID, model.JobStateError)
+}
+
+func TestPipelineFailsChildrenOfFailedStages(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+
+	jobs, err := endpoint.SubmitPipeline(ctx, model.PipelineCreatePayload{
+		Pipeline: &model.Pipeline{
+			Stages: []model.PipelineStage{
+				{Name: "parent"},
+				{Name: "child", Dependencies: []model.PipelineDependency{{Stage: "parent", Path: "/inputs"}}},
+				{Name: "grandchild", Dependencies: []model.PipelineDependency{{Stage: "child", Path: "/inputs"}}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	parent, child, grandchild := jobs[0], jobs[1], jobs[2]
+
+	_, err = endpoint.CancelJob(ctx, CancelJobRequest{JobID: parent.Metadata.ID, Reason: "failed"})
+	require.NoError(t, err)
+	scheduleDependentJobs(t, endpoint, store)
+	requireJobState(t, store, child.Metadata.ID, model.JobStateError)
+	scheduleDependentJobs(t, endpoint, store)
+	requireJobState(t, store, grandchild.Metadata.ID, model.JobStateError)
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
 	"github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 	"github.com/bacalhau-project/bacalhau/pkg/publicapi"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
 	"github.com/bacalhau-project/bacalhau/pkg/system"
 	"github.com/rs/zerolog/log"
 )
@@ -74,6 +76,41 @@ func (apiClient *RequesterAPIClient) List(
 	return res.Jobs, nil
 }
 
+// ListArchived lists the jobs that were purged from the requester node by its job retention policy and archived,
+// with the same filters as List.
+func (apiClient *RequesterAPIClient) ListArchived(
+	ctx context.Context,
+	idFilter string,
+	includeTags []model.IncludedTag,
+	excludeTags []model.ExcludedTag,
+	maxJobs int,
+	returnAll bool,
+	sortBy string,
+	sortReverse bool,
+) (
+	[]*model.JobWithInfo, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListArchived")
+	defer span.End()
+
+	req := listRequest{
+		ClientID:    system.GetClientID(),
+		MaxJobs:     maxJobs,
+		JobID:       idFilter,
+		IncludeTags: includeTags,
+		ExcludeTags: excludeTags,
+		ReturnAll:   returnAll,
+		SortBy:      sortBy,
+		SortReverse: sortReverse,
+		Archived:    true,
+	}
+
+	var res listResponse
+	if err := apiClient.Post(ctx, APIPrefix+"list", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Jobs, nil
+}
+
 // Cancel will request that the job with the specified ID is stopped. The JobInfo will be returned if the cancel
 // was submitted. If no match is found, Cancel returns false with a nil error.
 func (apiClient *RequesterAPIClient) Cancel(ctx context.Context, jobID string, reason string) (*model.JobState, error) {
@@ -133,6 +170,78 @@ func (apiClient *RequesterAPIClient) Cancel(ctx context.Context, jobID string, r
 	return res.State, nil
 }
 
+// Update changes the concurrency, min bids or timeout of the job with the specified full or short ID, and returns
+// the updated job. Only the fields of the payload that are set are updated, and its client ID is set by the client.
+func (apiClient *RequesterAPIClient) Update(ctx context.Context, jobID string, update model.JobUpdatePayload) (*model.Job, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Update")
+	defer span.End()
+
+	if jobID == "" {
+		return &model.Job{}, fmt.Errorf("jobID must be non-empty in an Update call")
+	}
+
+	// Check the existence of a job with the provided ID, whether it is a short or long ID.
+	jobInfo, found, err := apiClient.Get(ctx, jobID)
+	if err != nil {
+		return &model.Job{}, err
+	}
+	if !found {
+		return &model.Job{}, bacerrors.NewJobNotFound(jobID)
+	}
+
+	update.ClientID = system.GetClientID()
+	update.JobID = jobInfo.State.JobID
+	req, err := newSignedRequest(ctx, update)
+	if err != nil {
+		return &model.Job{}, err
+	}
+
+	var res updateResponse
+	if err := apiClient.Post(ctx, APIPrefix+"update", req, &res); err != nil {
+		return &model.Job{}, err
+	}
+
+	return res.Job, nil
+}
+
+// Approve approves or rejects the job with the specified full or short ID that is waiting for an approval.
+// Approved jobs are scheduled, and rejected jobs fail with the given reason. Only the client configured as
+// the job approver of the requester node can approve jobs.
+func (apiClient *RequesterAPIClient) Approve(ctx context.Context, jobID string, approved bool, reason string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Approve")
+	defer span.End()
+
+	if jobID == "" {
+		return fmt.Errorf("jobID must be non-empty in an Approve call")
+	}
+
+	// Check the existence of a job with the provided ID, whether it is a short or long ID.
+	jobInfo, found, err := apiClient.Get(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return bacerrors.NewJobNotFound(jobID)
+	}
+
+	req, err := newSignedRequest(ctx, JobApprovePayload{
+		ApproveJobRequest: requester.ApproveJobRequest{
+			ClientID: system.GetClientID(),
+			JobID:    jobInfo.State.JobID,
+			Response: bidstrategy.BidStrategyResponse{
+				ShouldBid: approved,
+				Reason:    reason,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct{}
+	return apiClient.Post(ctx, APIPrefix+"approve", req, &res)
+}
+
 // Get returns job data for a particular job ID. If no match is found, Get returns false with a nil error.
 func (apiClient *RequesterAPIClient) Get(ctx context.Context, jobID string) (*model.JobWithInfo, bool, error) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Get")
@@ -296,6 +405,303 @@ func (apiClient *RequesterAPIClient) Submit(
 	return res.Job, nil
 }
 
+// SubmitPipeline submits the stages of a pipeline as jobs, and returns them in the same order as the stages.
+func (apiClient *RequesterAPIClient) SubmitPipeline(
+	ctx context.Context,
+	pipeline *model.Pipeline,
+) ([]*model.Job, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SubmitPipeline")
+	defer span.End()
+
+	payload := model.PipelineCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: model.APIVersionLatest().String(),
+		Pipeline:   pipeline,
+	}
+
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return nil, err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+	log.Ctx(ctx).Trace().RawJSON("json", rawPayloadJSON).Msgf("jsonRaw")
+
+	// sign the raw bytes representation of model.PipelineCreatePayload
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Trace().Str("signature", signature).Msgf("signature")
+
+	req := signedRequest{
+		Payload:         &rawPayloadJSON,
+		ClientSignature: signature,
+		ClientPublicKey: system.GetClientPublicKey(),
+	}
+
+	var res submitPipelineResponse
+	if err := apiClient.Post(ctx, APIPrefix+"submit_pipeline", req, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Jobs, nil
+}
+
+// SubmitTemplate submits a job for every combination of the parameters of the job template, and returns the
+// parent ID shared by the jobs along with the jobs themselves.
+func (apiClient *RequesterAPIClient) SubmitTemplate(ctx context.Context, template *model.JobTemplate) (string, []*model.Job, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SubmitTemplate")
+	defer span.End()
+
+	req, err := newSignedRequest(ctx, model.JobTemplateCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: model.APIVersionLatest().String(),
+		Template:   template,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var res submitTemplateResponse
+	if err := apiClient.Post(ctx, APIPrefix+"submit_template", req, &res); err != nil {
+		return "", nil, err
+	}
+
+	return res.ParentID, res.Jobs, nil
+}
+
+// ListChildren returns the jobs submitted from the job template with the given parent ID, oldest first.
+func (apiClient *RequesterAPIClient) ListChildren(ctx context.Context, parentID string) ([]*model.JobWithInfo, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListChildren")
+	defer span.End()
+
+	req := listRequest{
+		ClientID: system.GetClientID(),
+		ParentID: parentID,
+		SortBy:   "created_at",
+	}
+
+	var res listResponse
+	if err := apiClient.Post(ctx, APIPrefix+"list", req, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Jobs, nil
+}
+
+// CancelChildren cancels the jobs submitted from the job template with the given parent ID that are not
+// terminal yet, and returns their states. If no job has the parent ID, CancelChildren returns a nil slice.
+func (apiClient *RequesterAPIClient) CancelChildren(ctx context.Context, parentID string, reason string) ([]*model.JobState, error) {
+	children, err := apiClient.ListChildren(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []*model.JobState
+	for _, child := range children {
+		if child.State.State.IsTerminal() {
+			continue
+		}
+		state, err := apiClient.Cancel(ctx, child.Job.Metadata.ID, reason)
+		if err != nil {
+			return states, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Queue returns the jobs waiting in the requester queue for enough capacity to run.
+func (apiClient *RequesterAPIClient) Queue(ctx context.Context) (model.QueueInfo, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Queue")
+	defer span.End()
+
+	req := struct{}{}
+	var res queueResponse
+	if err := apiClient.Post(ctx, APIPrefix+"queue", req, &res); err != nil {
+		return model.QueueInfo{}, err
+	}
+
+	return res.Queue, nil
+}
+
+// Usage returns the resources consumed by the jobs of this client since a point in time.
+func (apiClient *RequesterAPIClient) Usage(ctx context.Context, since time.Time) (model.ClientUsage, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Usage")
+	defer span.End()
+
+	req := usageRequest{
+		ClientID: system.GetClientID(),
+		Since:    since,
+	}
+	var res usageResponse
+	if err := apiClient.Post(ctx, APIPrefix+"usage", req, &res); err != nil {
+		return model.ClientUsage{}, err
+	}
+
+	return res.Usage, nil
+}
+
+// Reserve reserves capacity on the compute nodes matching the selectors during the given window. It returns the
+// reservation, whose ID is the token jobs carry to run on the reserved capacity, and the ids of the nodes holding it.
+func (apiClient *RequesterAPIClient) Reserve(
+	ctx context.Context,
+	selectors []model.LabelSelectorRequirement,
+	resources model.ResourceUsageConfig,
+	startTime, endTime time.Time,
+) (model.Reservation, []string, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Reserve")
+	defer span.End()
+
+	req, err := newSignedRequest(ctx, model.ReservationPayload{
+		ClientID:      system.GetClientID(),
+		NodeSelectors: selectors,
+		Resources:     resources,
+		StartTime:     startTime,
+		EndTime:       endTime,
+	})
+	if err != nil {
+		return model.Reservation{}, nil, err
+	}
+
+	var res reserveResponse
+	if err := apiClient.Post(ctx, APIPrefix+"reserve", req, &res); err != nil {
+		return model.Reservation{}, nil, err
+	}
+	return res.Reservation, res.NodeIDs, nil
+}
+
+// CancelReservation cancels a reservation of this client, and releases the capacity it holds back.
+func (apiClient *RequesterAPIClient) CancelReservation(ctx context.Context, reservationID string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.CancelReservation")
+	defer span.End()
+
+	req, err := newSignedRequest(ctx, model.CancelReservationPayload{
+		ClientID:      system.GetClientID(),
+		ReservationID: reservationID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct{}
+	return apiClient.Post(ctx, APIPrefix+"cancel_reservation", req, &res)
+}
+
+// CreateSchedule creates a schedule that submits a job with the given spec every time the cron expression is due.
+func (apiClient *RequesterAPIClient) CreateSchedule(
+	ctx context.Context,
+	cron string,
+	overlapPolicy model.ScheduleOverlapPolicy,
+	j *model.Job,
+) (model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.CreateSchedule")
+	defer span.End()
+
+	req, err := newSignedRequest(ctx, model.ScheduleCreatePayload{
+		ClientID:      system.GetClientID(),
+		APIVersion:    j.APIVersion,
+		Cron:          cron,
+		OverlapPolicy: overlapPolicy,
+		Spec:          &j.Spec,
+	})
+	if err != nil {
+		return model.Schedule{}, err
+	}
+
+	var res scheduleResponse
+	if err := apiClient.Post(ctx, APIPrefix+"create_schedule", req, &res); err != nil {
+		return model.Schedule{}, err
+	}
+
+	return res.Schedule, nil
+}
+
+// ListSchedules returns the schedules created by this client.
+func (apiClient *RequesterAPIClient) ListSchedules(ctx context.Context) ([]model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListSchedules")
+	defer span.End()
+
+	req := listSchedulesRequest{
+		ClientID: system.GetClientID(),
+	}
+
+	var res listSchedulesResponse
+	if err := apiClient.Post(ctx, APIPrefix+"list_schedules", req, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Schedules, nil
+}
+
+// UpdateSchedule pauses or resumes the schedule with the given full or short id.
+func (apiClient *RequesterAPIClient) UpdateSchedule(ctx context.Context, scheduleID string, paused bool) (model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.UpdateSchedule")
+	defer span.End()
+
+	if scheduleID == "" {
+		return model.Schedule{}, fmt.Errorf("scheduleID must be non-empty in a UpdateSchedule call")
+	}
+
+	req, err := newSignedRequest(ctx, model.ScheduleUpdatePayload{
+		ClientID:   system.GetClientID(),
+		ScheduleID: scheduleID,
+		Paused:     paused,
+	})
+	if err != nil {
+		return model.Schedule{}, err
+	}
+
+	var res scheduleResponse
+	if err := apiClient.Post(ctx, APIPrefix+"update_schedule", req, &res); err != nil {
+		return model.Schedule{}, err
+	}
+
+	return res.Schedule, nil
+}
+
+// DeleteSchedule deletes the schedule with the given full or short id.
+func (apiClient *RequesterAPIClient) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.DeleteSchedule")
+	defer span.End()
+
+	if scheduleID == "" {
+		return fmt.Errorf("scheduleID must be non-empty in a DeleteSchedule call")
+	}
+
+	req, err := newSignedRequest(ctx, model.ScheduleDeletePayload{
+		ClientID:   system.GetClientID(),
+		ScheduleID: scheduleID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct{}
+	return apiClient.Post(ctx, APIPrefix+"delete_schedule", req, &res)
+}
+
+// EvictCachedResult stops serving the results of the completed job with the same spec as the given job to identical jobs.
+func (apiClient *RequesterAPIClient) EvictCachedResult(ctx context.Context, jobID string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.EvictCachedResult")
+	defer span.End()
+
+	if jobID == "" {
+		return fmt.Errorf("jobID must be non-empty in an EvictCachedResult call")
+	}
+
+	req, err := newSignedRequest(ctx, model.ResultCacheEvictPayload{
+		ClientID: system.GetClientID(),
+		JobID:    jobID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct{}
+	return apiClient.Post(ctx, APIPrefix+"evict_cache", req, &res)
+}
+
 func (apiClient *RequesterAPIClient) Debug(ctx context.Context) (map[string]model.DebugInfo, error) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Debug")
 	defer span.End()
@@ -308,3 +714,26 @@ func (apiClient *RequesterAPIClient) Debug(ctx context.Context) (map[string]mode
 
 	return res, nil
 }
+
+// newSignedRequest signs the raw bytes representation of the payload with the local client key,
+// for verification on the server.
+func newSignedRequest(ctx context.Context, payload any) (signedRequest, error) {
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return signedRequest{}, err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+	log.Ctx(ctx).Trace().RawJSON("json", rawPayloadJSON).Msgf("jsonRaw")
+
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return signedRequest{}, err
+	}
+	log.Ctx(ctx).Trace().Str("signature", signature).Msgf("signature")
+
+	return signedRequest{
+		Payload:         &rawPayloadJSON,
+		ClientSignature: signature,
+		ClientPublicKey: system.GetClientPublicKey(),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package publicapi
+
+import (
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type evictCacheRequest = SignedRequest[model.ResultCacheEvictPayload] //nolint:unused // Swagger wants this
+
+// evictCache godoc
+//
+//	@ID						pkg/requester/publicapi/evictCache
+//	@Summary				Stops serving the cached results of a job to identical jobs.
+//	@Description.markdown	endpoints_evict_cache
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					evictCacheRequest	body		evictCacheRequest	true	" "
+//	@Success				200					{object}	string
+//	@Failure				400					{object}	string
+//	@Failure				403					{object}	string
+//	@Failure				500					{object}	string
+//	@Router					/requester/evict_cache [post]
+func (s *RequesterAPIServer) evictCache(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	evictPayload, err := unmarshalSignedJob[model.ResultCacheEvictPayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, evictPayload.ClientID)
+	res.Header().Set(handlerwrapper.HTTPHeaderJobID, evictPayload.JobID)
+
+	// Get the job, check it exists and check it belongs to the same client
+	job, err := s.jobStore.GetJob(ctx, evictPayload.JobID)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msgf("Missing job: %s", err)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+	if job.Metadata.ClientID != evictPayload.ClientID {
+		log.Ctx(ctx).Debug().Msgf("Mismatched ClientIDs for cache eviction, existing job: %s and eviction request: %s",
+			job.Metadata.ClientID, evictPayload.ClientID)
+
+		errorResponse := bacerrors.ErrorToErrorResponse(errors.Errorf("mismatched client id: %s", evictPayload.ClientID))
+		http.Error(res, errorResponse, http.StatusForbidden)
+		return
+	}
+
+	if err = s.requester.EvictCachedResult(ctx, evictPayload); err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
@@ -15,12 +15,14 @@ import (
 type listRequest struct {
 	JobID       string              `json:"id" example:"9304c616-291f-41ad-b862-54e133c0149e"`
 	ClientID    string              `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	ParentID    string              `json:"parent_id" example:"5fc0ed1e-6fd7-4e15-8a4b-3a7d2e8e6f4c"`
 	IncludeTags []model.IncludedTag `json:"include_tags" example:"['any-tag']"`
 	ExcludeTags []model.ExcludedTag `json:"exclude_tags" example:"['any-tag']"`
 	MaxJobs     int                 `json:"max_jobs" example:"10"`
 	ReturnAll   bool                `json:"return_all" `
 	SortBy      string              `json:"sort_by" example:"created_at"`
 	SortReverse bool                `json:"sort_reverse"`
+	Archived    bool                `json:"archived"`
 }
 
 type ListRequest = listRequest
@@ -56,6 +58,11 @@ func (s *RequesterAPIServer) list(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set(handlerwrapper.HTTPHeaderClientID, listReq.ClientID)
 	res.Header().Set(handlerwrapper.HTTPHeaderJobID, listReq.JobID)
 
+	if listReq.Archived {
+		s.listArchived(res, req, listReq)
+		return
+	}
+
 	jobList, err := s.getJobsList(ctx, listReq)
 	if err != nil {
 		_, ok := err.(*bacerrors.JobNotFound)
@@ -88,19 +95,52 @@ func (s *RequesterAPIServer) list(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// listArchived lists the jobs that were purged from the job store by the retention policy and archived.
+func (s *RequesterAPIServer) listArchived(res http.ResponseWriter, req *http.Request, listReq ListRequest) {
+	ctx := req.Context()
+	if s.archiveReader == nil {
+		http.Error(res, "job archive is not enabled on this requester node", http.StatusBadRequest)
+		return
+	}
+	archived, err := s.archiveReader.GetJobs(ctx, getJobsQuery(listReq))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error reading job archive")
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jobWithInfos := make([]*model.JobWithInfo, len(archived))
+	for i := range archived {
+		jobWithInfos[i] = &archived[i]
+	}
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(ListResponse{
+		Jobs: jobWithInfos,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (s *RequesterAPIServer) getJobsList(ctx context.Context, listReq ListRequest) ([]model.Job, error) {
-	list, err := s.jobStore.GetJobs(ctx, jobstore.JobQuery{
+	list, err := s.jobStore.GetJobs(ctx, getJobsQuery(listReq))
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func getJobsQuery(listReq ListRequest) jobstore.JobQuery {
+	return jobstore.JobQuery{
 		ClientID:    listReq.ClientID,
 		ID:          listReq.JobID,
+		ParentID:    listReq.ParentID,
 		Limit:       listReq.MaxJobs,
 		IncludeTags: listReq.IncludeTags,
 		ExcludeTags: listReq.ExcludeTags,
 		ReturnAll:   listReq.ReturnAll,
 		SortBy:      listReq.SortBy,
 		SortReverse: listReq.SortReverse,
-	})
-	if err != nil {
-		return nil, err
 	}
-	return list, nil
 }
@@ -0,0 +1,40 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+type queueResponse struct {
+	Queue model.QueueInfo `json:"queue"`
+}
+
+// queue godoc
+//
+//	@ID						pkg/requester/publicapi/queue
+//	@Summary				Returns the jobs waiting in the requester queue.
+//	@Description.markdown	endpoints_queue
+//	@Tags					Job
+//	@Produce				json
+//	@Success				200	{object}	queueResponse
+//	@Failure				500	{object}	string
+//	@Router					/requester/queue [get]
+func (s *RequesterAPIServer) queue(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	queueInfo, err := s.queueInfoProvider.GetQueueInfo(ctx)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(queueResponse{
+		Queue: queueInfo,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,86 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+type reserveRequest = SignedRequest[model.ReservationPayload] //nolint:unused // Swagger wants this
+
+type reserveResponse struct {
+	Reservation model.Reservation `json:"reservation"`
+	NodeIDs     []string          `json:"node_ids"`
+}
+
+// reserve godoc
+//
+//	@ID						pkg/requester/publicapi/reserve
+//	@Summary				Reserves capacity on compute nodes for a future time window.
+//	@Description.markdown	endpoints_reserve
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					reserveRequest	body		reserveRequest	true	" "
+//	@Success				200				{object}	reserveResponse
+//	@Failure				400				{object}	string
+//	@Failure				500				{object}	string
+//	@Router					/requester/reserve [post]
+func (s *RequesterAPIServer) reserve(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	reservePayload, err := unmarshalSignedJob[model.ReservationPayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, reservePayload.ClientID)
+
+	reservation, err := s.capacityReserver.ReserveCapacity(ctx, reservePayload)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(reserveResponse{
+		Reservation: reservation.Reservation,
+		NodeIDs:     reservation.NodeIDs,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+type cancelReservationRequest = SignedRequest[model.CancelReservationPayload] //nolint:unused // Swagger wants this
+
+// cancelReservation godoc
+//
+//	@ID						pkg/requester/publicapi/cancelReservation
+//	@Summary				Cancels a reservation and releases the capacity it holds back.
+//	@Description.markdown	endpoints_cancel_reservation
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					cancelReservationRequest	body		cancelReservationRequest	true	" "
+//	@Success				200							{object}	string
+//	@Failure				400							{object}	string
+//	@Failure				500							{object}	string
+//	@Router					/requester/cancel_reservation [post]
+func (s *RequesterAPIServer) cancelReservation(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	cancelPayload, err := unmarshalSignedJob[model.CancelReservationPayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, cancelPayload.ClientID)
+
+	if err = s.capacityReserver.CancelReservation(ctx, cancelPayload); err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
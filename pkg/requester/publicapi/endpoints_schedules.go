@@ -0,0 +1,211 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type createScheduleRequest = SignedRequest[model.ScheduleCreatePayload] //nolint:unused // Swagger wants this
+type updateScheduleRequest = SignedRequest[model.ScheduleUpdatePayload] //nolint:unused // Swagger wants this
+type deleteScheduleRequest = SignedRequest[model.ScheduleDeletePayload] //nolint:unused // Swagger wants this
+
+type scheduleResponse struct {
+	Schedule model.Schedule `json:"schedule"`
+}
+
+type listSchedulesRequest struct {
+	ClientID string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+}
+
+type listSchedulesResponse struct {
+	Schedules []model.Schedule `json:"schedules"`
+}
+
+// createSchedule godoc
+//
+//	@ID						pkg/requester/publicapi/createSchedule
+//	@Summary				Creates a schedule that submits a job every time its cron expression is due.
+//	@Description.markdown	endpoints_create_schedule
+//	@Tags					Schedule
+//	@Accept					json
+//	@Produce				json
+//	@Param					createScheduleRequest	body		createScheduleRequest	true	" "
+//	@Success				200						{object}	scheduleResponse
+//	@Failure				400						{object}	string
+//	@Failure				500						{object}	string
+//	@Router					/requester/create_schedule [post]
+func (s *RequesterAPIServer) createSchedule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	scheduleCreatePayload, err := unmarshalSignedJob[model.ScheduleCreatePayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, scheduleCreatePayload.ClientID)
+
+	if err = job.VerifyScheduleCreatePayload(ctx, &scheduleCreatePayload); err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := s.requester.CreateSchedule(ctx, scheduleCreatePayload)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(scheduleResponse{
+		Schedule: schedule,
+	})
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// listSchedules godoc
+//
+//	@ID						pkg/requester/publicapi/listSchedules
+//	@Summary				Lists the schedules of a client.
+//	@Description.markdown	endpoints_list_schedules
+//	@Tags					Schedule
+//	@Accept					json
+//	@Produce				json
+//	@Param					listSchedulesRequest	body		listSchedulesRequest	true	" "
+//	@Success				200						{object}	listSchedulesResponse
+//	@Failure				400						{object}	string
+//	@Failure				500						{object}	string
+//	@Router					/requester/list_schedules [post]
+func (s *RequesterAPIServer) listSchedules(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var listReq listSchedulesRequest
+	if err := json.NewDecoder(req.Body).Decode(&listReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, listReq.ClientID)
+
+	schedules, err := s.jobStore.GetSchedules(ctx, listReq.ClientID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(listSchedulesResponse{
+		Schedules: schedules,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateSchedule godoc
+//
+//	@ID						pkg/requester/publicapi/updateSchedule
+//	@Summary				Pauses or resumes a schedule.
+//	@Description.markdown	endpoints_update_schedule
+//	@Tags					Schedule
+//	@Accept					json
+//	@Produce				json
+//	@Param					updateScheduleRequest	body		updateScheduleRequest	true	" "
+//	@Success				200						{object}	scheduleResponse
+//	@Failure				400						{object}	string
+//	@Failure				403						{object}	string
+//	@Failure				500						{object}	string
+//	@Router					/requester/update_schedule [post]
+func (s *RequesterAPIServer) updateSchedule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	scheduleUpdatePayload, err := unmarshalSignedJob[model.ScheduleUpdatePayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, scheduleUpdatePayload.ClientID)
+
+	if !s.checkScheduleOwner(ctx, res, scheduleUpdatePayload.ScheduleID, scheduleUpdatePayload.ClientID) {
+		return
+	}
+
+	schedule, err := s.requester.UpdateSchedule(ctx, scheduleUpdatePayload)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(scheduleResponse{
+		Schedule: schedule,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteSchedule godoc
+//
+//	@ID						pkg/requester/publicapi/deleteSchedule
+//	@Summary				Deletes a schedule.
+//	@Description.markdown	endpoints_delete_schedule
+//	@Tags					Schedule
+//	@Accept					json
+//	@Produce				json
+//	@Param					deleteScheduleRequest	body		deleteScheduleRequest	true	" "
+//	@Success				200						{object}	string
+//	@Failure				400						{object}	string
+//	@Failure				403						{object}	string
+//	@Failure				500						{object}	string
+//	@Router					/requester/delete_schedule [post]
+func (s *RequesterAPIServer) deleteSchedule(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	scheduleDeletePayload, err := unmarshalSignedJob[model.ScheduleDeletePayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, scheduleDeletePayload.ClientID)
+
+	if !s.checkScheduleOwner(ctx, res, scheduleDeletePayload.ScheduleID, scheduleDeletePayload.ClientID) {
+		return
+	}
+
+	if err = s.requester.DeleteSchedule(ctx, scheduleDeletePayload); err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res.WriteHeader(http.StatusOK)
+}
+
+// checkScheduleOwner writes an error response and returns false if the schedule doesn't exist or
+// wasn't created by the client.
+func (s *RequesterAPIServer) checkScheduleOwner(ctx context.Context, res http.ResponseWriter, scheduleID, clientID string) bool {
+	schedule, err := s.jobStore.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msgf("Missing schedule: %s", err)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return false
+	}
+
+	// We can compare the payload's client ID against the existing schedule as we have confirmed
+	// the public key that the request was signed with matches the client ID the request claims.
+	if schedule.ClientID != clientID {
+		log.Ctx(ctx).Debug().Msgf("Mismatched ClientIDs for schedule %s, existing schedule: %s and request: %s",
+			scheduleID, schedule.ClientID, clientID)
+
+		errorResponse := bacerrors.ErrorToErrorResponse(errors.Errorf("mismatched client id: %s", clientID))
+		http.Error(res, errorResponse, http.StatusForbidden)
+		return false
+	}
+	return true
+}
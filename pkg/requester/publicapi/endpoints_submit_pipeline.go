@@ -0,0 +1,59 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+type submitPipelineRequest = SignedRequest[model.PipelineCreatePayload] //nolint:unused // Swagger wants this
+
+type submitPipelineResponse struct {
+	Jobs []*model.Job `json:"jobs"`
+}
+
+// submitPipeline godoc
+//
+//	@ID						pkg/requester/publicapi/submitPipeline
+//	@Summary				Submits a pipeline of jobs to the network.
+//	@Description.markdown	endpoints_submit_pipeline
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					submitPipelineRequest	body		submitPipelineRequest	true	" "
+//	@Success				200						{object}	submitPipelineResponse
+//	@Failure				400						{object}	string
+//	@Failure				500						{object}	string
+//	@Router					/requester/submit_pipeline [post]
+func (s *RequesterAPIServer) submitPipeline(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	pipelineCreatePayload, err := unmarshalSignedJob[model.PipelineCreatePayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, pipelineCreatePayload.ClientID)
+
+	if err = job.VerifyPipelineCreatePayload(ctx, &pipelineCreatePayload); err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := s.requester.SubmitPipeline(ctx, pipelineCreatePayload)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(submitPipelineResponse{
+		Jobs: jobs,
+	})
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,61 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+type submitTemplateRequest = SignedRequest[model.JobTemplateCreatePayload] //nolint:unused // Swagger wants this
+
+type submitTemplateResponse struct {
+	ParentID string       `json:"parent_id"`
+	Jobs     []*model.Job `json:"jobs"`
+}
+
+// submitTemplate godoc
+//
+//	@ID						pkg/requester/publicapi/submitTemplate
+//	@Summary				Submits a job for every combination of the parameters of a job template.
+//	@Description.markdown	endpoints_submit_template
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					submitTemplateRequest	body		submitTemplateRequest	true	" "
+//	@Success				200						{object}	submitTemplateResponse
+//	@Failure				400						{object}	string
+//	@Failure				500						{object}	string
+//	@Router					/requester/submit_template [post]
+func (s *RequesterAPIServer) submitTemplate(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	templateCreatePayload, err := unmarshalSignedJob[model.JobTemplateCreatePayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, templateCreatePayload.ClientID)
+
+	if err = job.VerifyJobTemplateCreatePayload(ctx, &templateCreatePayload); err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := s.requester.SubmitJobTemplate(ctx, templateCreatePayload)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(submitTemplateResponse{
+		ParentID: jobs[0].Metadata.ParentID,
+		Jobs:     jobs,
+	})
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,82 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bacalhau-project/bacalhau/pkg/bacerrors"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type updateRequest = SignedRequest[model.JobUpdatePayload] //nolint:unused // Swagger wants this
+
+type updateResponse struct {
+	Job *model.Job `json:"job"`
+}
+
+// update godoc
+//
+//	@ID						pkg/requester/publicapi/update
+//	@Summary				Updates the deal or timeout of the job with the job-id specified in the body payload.
+//	@Description.markdown	endpoints_update
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					updateRequest	body		updateRequest	true	" "
+//	@Success				200				{object}	updateResponse
+//	@Failure				400				{object}	string
+//	@Failure				401				{object}	string
+//	@Failure				403				{object}	string
+//	@Failure				500				{object}	string
+//	@Router					/requester/update [post]
+func (s *RequesterAPIServer) update(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	jobUpdatePayload, err := unmarshalSignedJob[model.JobUpdatePayload](ctx, req.Body)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, jobUpdatePayload.ClientID)
+	ctx = system.AddJobIDToBaggage(ctx, jobUpdatePayload.JobID)
+
+	// Get the job, check it exists and check it belongs to the same client
+	job, err := s.jobStore.GetJob(ctx, jobUpdatePayload.JobID)
+	if err != nil {
+		log.Ctx(ctx).Debug().Msgf("Missing job: %s", err)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// We can compare the payload's client ID against the existing job's metadata
+	// as we have confirmed the public key that the request was signed with matches
+	// the client ID the request claims.
+	if job.Metadata.ClientID != jobUpdatePayload.ClientID {
+		log.Ctx(ctx).Debug().Msgf("Mismatched ClientIDs for update, existing job: %s and update request: %s",
+			job.Metadata.ClientID, jobUpdatePayload.ClientID)
+
+		errorResponse := bacerrors.ErrorToErrorResponse(errors.Errorf("mismatched client id: %s", jobUpdatePayload.ClientID))
+		http.Error(res, errorResponse, http.StatusForbidden)
+		return
+	}
+
+	updatedJob, err := s.requester.UpdateJob(ctx, jobUpdatePayload)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusBadRequest)
+		return
+	}
+
+	res.Header().Set(handlerwrapper.HTTPHeaderJobID, updatedJob.Metadata.ID)
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(updateResponse{
+		Job: &updatedJob,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
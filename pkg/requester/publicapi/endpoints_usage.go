@@ -0,0 +1,63 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+// defaultUsagePeriod is the period usage is reported for if the request does not set when it starts.
+const defaultUsagePeriod = 24 * time.Hour
+
+type usageRequest struct {
+	ClientID string    `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	Since    time.Time `json:"since" example:"2023-03-01T00:00:00Z"`
+}
+
+type usageResponse struct {
+	Usage model.ClientUsage `json:"usage"`
+}
+
+// usage godoc
+//
+//	@ID						pkg/requester/publicapi/usage
+//	@Summary				Returns the resources consumed by the jobs of a client.
+//	@Description.markdown	endpoints_usage
+//	@Tags					Job
+//	@Accept					json
+//	@Produce				json
+//	@Param					usageRequest	body		usageRequest	true	" "
+//	@Success				200				{object}	usageResponse
+//	@Failure				400				{object}	string
+//	@Failure				500				{object}	string
+//	@Router					/requester/usage [post]
+func (s *RequesterAPIServer) usage(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var usageReq usageRequest
+	if err := json.NewDecoder(req.Body).Decode(&usageReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, usageReq.ClientID)
+
+	if usageReq.Since.IsZero() {
+		usageReq.Since = time.Now().Add(-defaultUsagePeriod)
+	}
+	usage, err := s.usageProvider.GetUsage(ctx, usageReq.ClientID, usageReq.Since)
+	if err != nil {
+		httpError(ctx, res, err, http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(usageResponse{
+		Usage: usage,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -17,6 +17,10 @@ const APIPrefix = "requester/"
 type RequesterAPIServerParams struct {
 	APIServer          *publicapi.APIServer
 	Requester          requester.Endpoint
+	Queue              requester.QueueInfoProvider
+	Usage              requester.UsageProvider
+	Reservations       requester.CapacityReserver
+	Archive            requester.ArchiveReader
 	DebugInfoProviders []model.DebugInfoProvider
 	JobStore           jobstore.Store
 	StorageProviders   storage.StorageProvider
@@ -25,6 +29,10 @@ type RequesterAPIServerParams struct {
 type RequesterAPIServer struct {
 	apiServer          *publicapi.APIServer
 	requester          requester.Endpoint
+	queueInfoProvider  requester.QueueInfoProvider
+	usageProvider      requester.UsageProvider
+	capacityReserver   requester.CapacityReserver
+	archiveReader      requester.ArchiveReader
 	debugInfoProviders []model.DebugInfoProvider
 	jobStore           jobstore.Store
 	storageProviders   storage.StorageProvider
@@ -37,6 +45,10 @@ func NewRequesterAPIServer(params RequesterAPIServerParams) *RequesterAPIServer
 	return &RequesterAPIServer{
 		apiServer:          params.APIServer,
 		requester:          params.Requester,
+		queueInfoProvider:  params.Queue,
+		usageProvider:      params.Usage,
+		capacityReserver:   params.Reservations,
+		archiveReader:      params.Archive,
 		debugInfoProviders: params.DebugInfoProviders,
 		jobStore:           params.JobStore,
 		storageProviders:   params.StorageProviders,
@@ -51,8 +63,20 @@ func (s *RequesterAPIServer) RegisterAllHandlers() error {
 		{URI: "/" + APIPrefix + "results", Handler: http.HandlerFunc(s.results)},
 		{URI: "/" + APIPrefix + "events", Handler: http.HandlerFunc(s.events)},
 		{URI: "/" + APIPrefix + "submit", Handler: http.HandlerFunc(s.submit)},
+		{URI: "/" + APIPrefix + "submit_pipeline", Handler: http.HandlerFunc(s.submitPipeline)},
+		{URI: "/" + APIPrefix + "submit_template", Handler: http.HandlerFunc(s.submitTemplate)},
 		{URI: "/" + APIPrefix + "approve", Handler: http.HandlerFunc(s.approve)},
 		{URI: "/" + APIPrefix + "cancel", Handler: http.HandlerFunc(s.cancel)},
+		{URI: "/" + APIPrefix + "update", Handler: http.HandlerFunc(s.update)},
+		{URI: "/" + APIPrefix + "evict_cache", Handler: http.HandlerFunc(s.evictCache)},
+		{URI: "/" + APIPrefix + "queue", Handler: http.HandlerFunc(s.queue)},
+		{URI: "/" + APIPrefix + "usage", Handler: http.HandlerFunc(s.usage)},
+		{URI: "/" + APIPrefix + "reserve", Handler: http.HandlerFunc(s.reserve)},
+		{URI: "/" + APIPrefix + "cancel_reservation", Handler: http.HandlerFunc(s.cancelReservation)},
+		{URI: "/" + APIPrefix + "create_schedule", Handler: http.HandlerFunc(s.createSchedule)},
+		{URI: "/" + APIPrefix + "list_schedules", Handler: http.HandlerFunc(s.listSchedules)},
+		{URI: "/" + APIPrefix + "update_schedule", Handler: http.HandlerFunc(s.updateSchedule)},
+		{URI: "/" + APIPrefix + "delete_schedule", Handler: http.HandlerFunc(s.deleteSchedule)},
 		{URI: "/" + APIPrefix + "websocket/events", Handler: http.HandlerFunc(s.websocketJobEvents), Raw: true},
 		{URI: "/" + APIPrefix + "debug", Handler: http.HandlerFunc(s.debug)},
 	}
@@ -2,21 +2,105 @@ package requester
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/pubsub"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 )
 
+type QueueParams struct {
+	ID        string
+	Scheduler Scheduler
+	Store     jobstore.Store
+	// NodeDiscoverer is used to check if the compute nodes have enough available capacity to run a job.
+	// Jobs are started as soon as they are approved if not set.
+	NodeDiscoverer NodeDiscoverer
+	// DispatchInterval is how often the queued jobs are re-attempted, in case the capacity reported by the
+	// compute nodes was missed. Queued jobs are only re-attempted when nodes report more capacity if not set.
+	DispatchInterval time.Duration
+}
+
+// queue holds the approved jobs until the compute nodes have enough available capacity to run them.
+// Jobs are started by order of their priority class, and jobs of the same priority are shared fairly
+// between the clients that submitted them, by starting the jobs of clients with the fewest running jobs first.
+// Queued jobs are re-attempted whenever a compute node reports more available capacity than before, and
+// periodically. The queue only lives in memory, and is restored from the job store when the node restarts
+// or adopts the jobs of another requester node.
 type queue struct {
-	scheduler Scheduler
-	store     jobstore.Store
+	id             string
+	scheduler      Scheduler
+	store          jobstore.Store
+	nodeDiscoverer NodeDiscoverer
+	interval       time.Duration
+
+	// approved jobs waiting for enough capacity to run
+	pending map[string]queuedJob
+	// last available capacity reported by each compute node
+	availableCapacity map[string]model.ResourceUsageData
+	mu                sync.Mutex
+
+	stopChannel chan struct{}
+}
+
+type queuedJob struct {
+	job      model.Job
+	queuedAt time.Time
+}
+
+func NewQueue(params QueueParams) *queue {
+	q := &queue{
+		id:                params.ID,
+		scheduler:         params.Scheduler,
+		store:             params.Store,
+		nodeDiscoverer:    params.NodeDiscoverer,
+		interval:          params.DispatchInterval,
+		pending:           make(map[string]queuedJob),
+		availableCapacity: make(map[string]model.ResourceUsageData),
+		stopChannel:       make(chan struct{}, 1),
+	}
+	q.mu.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        "Queue.mu",
+	})
+	if q.interval > 0 {
+		go q.dispatchBackgroundTask()
+	}
+	return q
 }
 
-func NewQueue(store jobstore.Store, scheduler Scheduler) Queue {
-	return &queue{
-		scheduler: scheduler,
-		store:     store,
+func (q *queue) dispatchBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(q.interval)
+	for {
+		select {
+		case <-ticker.C:
+			q.mu.Lock()
+			if len(q.pending) > 0 {
+				if err := q.dispatch(ctx, ""); err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("[dispatchBackgroundTask] failed to dispatch queued jobs")
+				}
+			}
+			q.mu.Unlock()
+		case <-q.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped queue dispatch task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (q *queue) Stop() {
+	select {
+	case q.stopChannel <- struct{}{}:
+	default:
 	}
 }
 
@@ -30,22 +114,52 @@ func (q *queue) EnqueueJob(ctx context.Context, job model.Job) error {
 	})
 }
 
+// StartJob marks the job as approved to run, and starts the queued jobs that the compute nodes have capacity for.
+// An error is returned if the job was started and failed to be scheduled.
 func (q *queue) StartJob(ctx context.Context, req StartJobRequest) error {
-	err := q.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
-		JobID: req.Job.Metadata.ID,
-		Condition: jobstore.UpdateJobCondition{
-			ExpectedState: model.JobStateQueued,
-		},
-		NewState: model.JobStateNew,
-	})
-	if err != nil {
-		return err
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[req.Job.Metadata.ID] = queuedJob{job: req.Job, queuedAt: time.Now()}
+	return q.dispatch(ctx, req.Job.Metadata.ID)
+}
+
+// ResumeJobs restores the jobs owned by this node that were waiting in the queue when the node last stopped,
+// and starts the ones that the compute nodes have capacity for. Jobs are only queued once they are approved,
+// so restored jobs are considered approved.
+func (q *queue) ResumeJobs(ctx context.Context, jobs []model.JobWithInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	restored := 0
+	for _, jobWithInfo := range jobs {
+		job := jobWithInfo.Job
+		if jobWithInfo.State.State != model.JobStateQueued || job.Metadata.Requester.RequesterNodeID != q.id {
+			continue
+		}
+		if _, ok := q.pending[job.Metadata.ID]; ok {
+			continue
+		}
+		q.pending[job.Metadata.ID] = queuedJob{job: job, queuedAt: jobWithInfo.State.UpdateTime}
+		restored++
+	}
+	if restored == 0 {
+		return
 	}
+	log.Ctx(ctx).Info().Msgf("restored %d queued jobs", restored)
+	if err := q.dispatch(ctx, ""); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[ResumeJobs] failed to dispatch queued jobs")
+	}
+}
 
-	return q.scheduler.StartJob(ctx, req)
+// AdoptJobs restores the queued jobs adopted from a requester node whose lease expired.
+func (q *queue) AdoptJobs(ctx context.Context, jobs []model.JobWithInfo) {
+	q.ResumeJobs(ctx, jobs)
 }
 
 func (q *queue) CancelJob(ctx context.Context, req CancelJobRequest) (CancelJobResult, error) {
+	q.mu.Lock()
+	delete(q.pending, req.JobID)
+	q.mu.Unlock()
+
 	err := q.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
 		JobID: req.JobID,
 		Condition: jobstore.UpdateJobCondition{
@@ -60,3 +174,226 @@ func (q *queue) CancelJob(ctx context.Context, req CancelJobRequest) (CancelJobR
 	}
 	return CancelJobResult{}, err
 }
+
+// GetQueueInfo returns the approved jobs waiting for capacity in the order they will be started.
+func (q *queue) GetQueueInfo(ctx context.Context) (model.QueueInfo, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	runningJobs, err := q.countRunningJobs(ctx)
+	if err != nil {
+		return model.QueueInfo{}, err
+	}
+	jobs := make([]model.QueuedJob, 0, len(q.pending))
+	for i, queued := range orderQueuedJobs(q.pending, runningJobs) {
+		jobs = append(jobs, model.QueuedJob{
+			JobID:    queued.job.Metadata.ID,
+			ClientID: queued.job.Metadata.ClientID,
+			Priority: queued.job.Spec.Priority,
+			QueuedAt: queued.queuedAt,
+			Position: i + 1,
+		})
+	}
+	return model.QueueInfo{
+		Jobs:        jobs,
+		RunningJobs: runningJobs,
+	}, nil
+}
+
+// Handle node info published by the compute nodes, and re-attempts the queued jobs if a node's
+// available capacity increased since it was last seen.
+func (q *queue) Handle(ctx context.Context, nodeInfo model.NodeInfo) error {
+	if !nodeInfo.IsComputeNode() {
+		return nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	nodeID := nodeInfo.PeerInfo.ID.String()
+	available := nodeInfo.ComputeNodeInfo.AvailableCapacity
+	previous, seen := q.availableCapacity[nodeID]
+	q.availableCapacity[nodeID] = available
+	if len(q.pending) == 0 || (seen && available.LessThanEq(previous)) {
+		return nil
+	}
+
+	log.Ctx(ctx).Debug().Msgf("capacity of node %s increased to %s. Re-attempting %d queued jobs", nodeID, available, len(q.pending))
+	go func(ctx context.Context) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if err := q.dispatch(ctx, ""); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[Handle] failed to dispatch queued jobs")
+		}
+	}(util.NewDetachedContext(ctx))
+	return nil
+}
+
+// dispatch starts the pending jobs that the compute nodes have capacity for, in the queue's order.
+// The error of starting the requested job is returned, while other jobs that fail to start are canceled.
+// make sure to call this function with the lock held
+func (q *queue) dispatch(ctx context.Context, requestedJobID string) error {
+	runningJobs, err := q.countRunningJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	// capacity reserved by jobs started in this round, as the nodes didn't report it yet
+	reserved := make(map[string]model.ResourceUsageData)
+	var requestedJobErr error
+	for _, queued := range orderQueuedJobs(q.pending, runningJobs) {
+		jobID := queued.job.Metadata.ID
+		if !q.reserveCapacity(ctx, queued.job, reserved) {
+			log.Ctx(ctx).Debug().Msgf("not enough capacity to start job %s. Keeping it queued", jobID)
+			continue
+		}
+		delete(q.pending, jobID)
+		err = q.startJob(ctx, queued.job)
+		if err == nil {
+			continue
+		}
+		if jobID == requestedJobID {
+			requestedJobErr = err
+			continue
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("[dispatch] failed to start queued job %s", jobID)
+		if _, cancelErr := q.scheduler.CancelJob(ctx, CancelJobRequest{JobID: jobID, Reason: err.Error()}); cancelErr != nil {
+			log.Ctx(ctx).Error().Err(cancelErr).Msgf("[dispatch] failed to cancel job %s", jobID)
+		}
+	}
+	return requestedJobErr
+}
+
+func (q *queue) startJob(ctx context.Context, job model.Job) error {
+	err := q.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID: job.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{
+			ExpectedState: model.JobStateQueued,
+		},
+		NewState: model.JobStateNew,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.scheduler.StartJob(ctx, StartJobRequest{Job: job})
+}
+
+// reserveCapacity returns true if enough compute nodes have the available capacity to run the job, and reserves
+// the job's resources on these nodes. Nodes that didn't report their capacity are considered to have enough of it.
+// It also returns true if the network doesn't have enough nodes to run the job regardless of their capacity,
+// so that the scheduler fails the job instead of keeping it queued forever.
+func (q *queue) reserveCapacity(ctx context.Context, job model.Job, reserved map[string]model.ResourceUsageData) bool {
+	if q.nodeDiscoverer == nil {
+		return true
+	}
+	nodes, err := q.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[reserveCapacity] failed to find nodes for job %s", job.Metadata.ID)
+		return true
+	}
+	requiredNodes := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
+	if len(nodes) < requiredNodes {
+		return true
+	}
+
+	jobUsage := capacity.ParseResourceUsageConfig(job.Spec.Resources)
+	var availableNodes []string
+	for _, node := range nodes {
+		nodeID := node.PeerInfo.ID.String()
+		if node.ComputeNodeInfo.MaxCapacity.IsZero() ||
+			jobUsage.Add(reserved[nodeID]).LessThanEq(node.ComputeNodeInfo.AvailableCapacity) {
+			availableNodes = append(availableNodes, nodeID)
+		}
+	}
+	if len(availableNodes) < requiredNodes {
+		return false
+	}
+	for _, nodeID := range availableNodes[:requiredNodes] {
+		reserved[nodeID] = reserved[nodeID].Add(jobUsage)
+	}
+	return true
+}
+
+// countRunningJobs returns the number of jobs in progress per client
+func (q *queue) countRunningJobs(ctx context.Context) (map[string]int, error) {
+	jobs, err := q.store.GetInProgressJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	runningJobs := make(map[string]int)
+	for _, job := range jobs {
+		if job.State.State == model.JobStateInProgress {
+			runningJobs[job.Job.Metadata.ClientID]++
+		}
+	}
+	return runningJobs, nil
+}
+
+// orderQueuedJobs returns the jobs in the order they should be started. Jobs of a higher priority class come first.
+// Within the same priority class, the next job is the oldest job of the client with the fewest running jobs,
+// including the jobs ahead of it in the queue, so that a client submitting many jobs can't starve the others.
+func orderQueuedJobs(pending map[string]queuedJob, runningJobs map[string]int) []queuedJob {
+	jobs := make([]queuedJob, 0, len(pending))
+	for _, job := range pending {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].job.Spec.Priority != jobs[j].job.Spec.Priority {
+			return jobs[i].job.Spec.Priority > jobs[j].job.Spec.Priority
+		}
+		return jobs[i].queuedAt.Before(jobs[j].queuedAt)
+	})
+
+	shares := make(map[string]int, len(runningJobs))
+	for clientID, count := range runningJobs {
+		shares[clientID] = count
+	}
+	ordered := make([]queuedJob, 0, len(jobs))
+	for start := 0; start < len(jobs); {
+		priority := jobs[start].job.Spec.Priority
+		// the jobs of each client in this priority class, oldest first
+		var clients [][]queuedJob
+		clientIndex := make(map[string]int)
+		end := start
+		for ; end < len(jobs) && jobs[end].job.Spec.Priority == priority; end++ {
+			clientID := jobs[end].job.Metadata.ClientID
+			if _, ok := clientIndex[clientID]; !ok {
+				clientIndex[clientID] = len(clients)
+				clients = append(clients, nil)
+			}
+			clients[clientIndex[clientID]] = append(clients[clientIndex[clientID]], jobs[end])
+		}
+
+		for len(ordered) < end {
+			next := -1
+			for i, clientJobs := range clients {
+				if len(clientJobs) == 0 {
+					continue
+				}
+				if next < 0 || isFairerShare(clientJobs[0], clients[next][0], shares) {
+					next = i
+				}
+			}
+			job := clients[next][0]
+			ordered = append(ordered, job)
+			clients[next] = clients[next][1:]
+			shares[job.job.Metadata.ClientID]++
+		}
+		start = end
+	}
+	return ordered
+}
+
+// isFairerShare returns true if the client of the candidate job has fewer jobs running than the client of the
+// current job, or the same number of jobs and the candidate job was queued first.
+func isFairerShare(candidate, current queuedJob, shares map[string]int) bool {
+	candidateShare, currentShare := shares[candidate.job.Metadata.ClientID], shares[current.job.Metadata.ClientID]
+	if candidateShare != currentShare {
+		return candidateShare < currentShare
+	}
+	return candidate.queuedAt.Before(current.queuedAt)
+}
+
+// compile-time interface assertions
+var _ Queue = (*queue)(nil)
+var _ QueueInfoProvider = (*queue)(nil)
+var _ pubsub.Subscriber[model.NodeInfo] = (*queue)(nil)
+var _ JobAdopter = (*queue)(nil)
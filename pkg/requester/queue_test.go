@@ -0,0 +1,211 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderQueuedJobs(t *testing.T) {
+	now := time.Now()
+	pending := make(map[string]queuedJob)
+	add := func(jobID string, clientID string, priority model.PriorityClass) {
+		job := model.Job{
+			Metadata: model.Metadata{ID: jobID, ClientID: clientID},
+			Spec:     model.Spec{Priority: priority},
+		}
+		pending[jobID] = queuedJob{job: job, queuedAt: now.Add(time.Duration(len(pending)) * time.Second)}
+	}
+	add("a-1", "a", model.PriorityClassNormal)
+	add("a-2", "a", model.PriorityClassNormal)
+	add("a-3", "a", model.PriorityClassNormal)
+	add("b-1", "b", model.PriorityClassNormal)
+	add("c-1", "c", model.PriorityClassLow)
+	add("b-2", "b", model.PriorityClassHigh)
+	add("c-2", "c", model.PriorityClassNormal)
+
+	var order []string
+	for _, queued := range orderQueuedJobs(pending, map[string]int{"c": 2}) {
+		order = append(order, queued.job.Metadata.ID)
+	}
+	require.Equal(t, []string{"b-2", "a-1", "a-2", "b-1", "a-3", "c-2", "c-1"}, order)
+}
+
+func TestQueueWaitsForCapacity(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+
+	var mu sync.Mutex
+	var started []string
+	scheduler := &mockScheduler{
+		handleStartJob: func(_ context.Context, request StartJobRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			started = append(started, request.Job.Metadata.ID)
+			return nil
+		},
+	}
+	startedJobs := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, started...)
+	}
+
+	node := model.NodeInfo{
+		PeerInfo: peer.AddrInfo{ID: peer.ID("node-0")},
+		NodeType: model.NodeTypeCompute,
+		ComputeNodeInfo: model.ComputeNodeInfo{
+			MaxCapacity:       model.ResourceUsageData{CPU: 2},
+			AvailableCapacity: model.ResourceUsageData{CPU: 1},
+		},
+	}
+	discoverer := &mockNodeDiscoverer{nodes: []model.NodeInfo{node}}
+	q := NewQueue(QueueParams{Scheduler: scheduler, Store: store, NodeDiscoverer: discoverer})
+
+	submit := func(jobID string, cpu string) {
+		job := model.Job{
+			Metadata: model.Metadata{ID: jobID, ClientID: "client"},
+			Spec: model.Spec{
+				Resources: model.ResourceUsageConfig{CPU: cpu},
+				Deal:      model.Deal{Concurrency: 1},
+			},
+		}
+		require.NoError(t, store.CreateJob(ctx, job))
+		require.NoError(t, q.EnqueueJob(ctx, job))
+		require.NoError(t, q.StartJob(ctx, StartJobRequest{Job: job}))
+	}
+
+	submit("small", "1")
+	submit("large", "2")
+	require.Equal(t, []string{"small"}, startedJobs())
+
+	info, err := q.GetQueueInfo(ctx)
+	require.NoError(t, err)
+	require.Len(t, info.Jobs, 1)
+	require.Equal(t, "large", info.Jobs[0].JobID)
+	require.Equal(t, 1, info.Jobs[0].Position)
+
+	state, err := store.GetJobState(ctx, "large")
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateQueued, state.State)
+
+	// the first report of a node is not enough capacity for the queued job
+	require.NoError(t, q.Handle(ctx, node))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, []string{"small"}, startedJobs())
+
+	node.ComputeNodeInfo.AvailableCapacity = model.ResourceUsageData{CPU: 2}
+	discoverer.nodes = []model.NodeInfo{node}
+	require.NoError(t, q.Handle(ctx, node))
+	require.Eventually(t, func() bool {
+		return len(startedJobs()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"small", "large"}, startedJobs())
+
+	state, err = store.GetJobState(ctx, "large")
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateNew, state.State)
+
+	// cancelled queued jobs are removed from the queue
+	submit("too-large", "4")
+	_, err = q.CancelJob(ctx, CancelJobRequest{JobID: "too-large"})
+	require.NoError(t, err)
+	info, err = q.GetQueueInfo(ctx)
+	require.NoError(t, err)
+	require.Empty(t, info.Jobs)
+	state, err = store.GetJobState(ctx, "too-large")
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateCancelled, state.State)
+}
+
+func TestQueueResumesQueuedJobs(t *testing.T) {
+	ctx := context.Background()
+	store := inmemory.NewJobStore()
+
+	var mu sync.Mutex
+	var started []string
+	scheduler := &mockScheduler{
+		handleStartJob: func(_ context.Context, request StartJobRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			started = append(started, request.Job.Metadata.ID)
+			return nil
+		},
+	}
+	startedJobs := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, started...)
+	}
+
+	node := model.NodeInfo{
+		PeerInfo: peer.AddrInfo{ID: peer.ID("node-0")},
+		NodeType: model.NodeTypeCompute,
+		ComputeNodeInfo: model.ComputeNodeInfo{
+			MaxCapacity:       model.ResourceUsageData{CPU: 2},
+			AvailableCapacity: model.ResourceUsageData{CPU: 1},
+		},
+	}
+	discoverer := &mockNodeDiscoverer{nodes: []model.NodeInfo{node}}
+
+	// jobs queued by this node and by another node before they stopped
+	var jobs []model.JobWithInfo
+	for _, owner := range []string{"requester", "other"} {
+		job := model.Job{
+			Metadata: model.Metadata{
+				ID:        "job-" + owner,
+				ClientID:  "client",
+				Requester: model.JobRequester{RequesterNodeID: owner},
+			},
+			Spec: model.Spec{
+				Resources: model.ResourceUsageConfig{CPU: "2"},
+				Deal:      model.Deal{Concurrency: 1},
+			},
+		}
+		require.NoError(t, store.CreateJob(ctx, job))
+		require.NoError(t, NewQueue(QueueParams{Store: store}).EnqueueJob(ctx, job))
+		state, err := store.GetJobState(ctx, job.Metadata.ID)
+		require.NoError(t, err)
+		jobs = append(jobs, model.JobWithInfo{Job: job, State: state})
+	}
+
+	q := NewQueue(QueueParams{
+		ID:               "requester",
+		Scheduler:        scheduler,
+		Store:            store,
+		NodeDiscoverer:   discoverer,
+		DispatchInterval: 20 * time.Millisecond,
+	})
+	t.Cleanup(q.Stop)
+
+	// only the jobs owned by this node are restored
+	q.ResumeJobs(ctx, jobs)
+	info, err := q.GetQueueInfo(ctx)
+	require.NoError(t, err)
+	require.Len(t, info.Jobs, 1)
+	require.Equal(t, "job-requester", info.Jobs[0].JobID)
+	require.Empty(t, startedJobs())
+
+	// queued jobs are re-attempted periodically, even if no node reports more capacity
+	node.ComputeNodeInfo.AvailableCapacity = model.ResourceUsageData{CPU: 2}
+	q.mu.Lock()
+	discoverer.nodes = []model.NodeInfo{node}
+	q.mu.Unlock()
+	require.Eventually(t, func() bool {
+		return len(startedJobs()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"job-requester"}, startedJobs())
+
+	// adopted jobs are restored the same way
+	jobs[1].Job.Metadata.Requester.RequesterNodeID = "requester"
+	q.AdoptJobs(ctx, jobs[1:])
+	require.Equal(t, []string{"job-requester", "job-other"}, startedJobs())
+}
@@ -0,0 +1,60 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+)
+
+type DataLocalityNodeRankerParams struct {
+	// Weight is the rank given to nodes that hold all the inputs of the job
+	Weight int
+}
+
+// DataLocalityNodeRanker ranks higher the nodes that advertise holding the inputs of the job locally,
+// so that large inputs are not fetched again over the network.
+type DataLocalityNodeRanker struct {
+	weight int
+}
+
+func NewDataLocalityNodeRanker(params DataLocalityNodeRankerParams) *DataLocalityNodeRanker {
+	if params.Weight <= 0 {
+		panic(fmt.Sprintf("data locality weight must be > 0: %d", params.Weight))
+	}
+	return &DataLocalityNodeRanker{
+		weight: params.Weight,
+	}
+}
+
+// RankNodes ranks nodes based on the job inputs they hold locally:
+// - Rank Weight * fraction of the job inputs the node holds locally.
+// - Rank 0: Job has no inputs that can be held locally, or the node doesn't advertise its local storage.
+func (s *DataLocalityNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	var keys []string
+	for _, input := range job.Spec.Inputs {
+		if key := input.LocalityKey(); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		rank := 0
+		if len(keys) > 0 && node.ComputeNodeInfo.LocalStorage != nil {
+			held := 0
+			for _, key := range keys {
+				if node.ComputeNodeInfo.LocalStorage.Test(key) {
+					held++
+				}
+			}
+			rank = s.weight * held / len(keys)
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
@@ -0,0 +1,76 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/bloom"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/suite"
+)
+
+type DataLocalityNodeRankerSuite struct {
+	suite.Suite
+	DataLocalityNodeRanker *DataLocalityNodeRanker
+	allPeer                model.NodeInfo
+	somePeer               model.NodeInfo
+	nonePeer               model.NodeInfo
+	unknownPeer            model.NodeInfo
+}
+
+func (s *DataLocalityNodeRankerSuite) SetupSuite() {
+	newNode := func(id string, cids ...string) model.NodeInfo {
+		filter := bloom.New(len(cids), 0.0001)
+		for _, cid := range cids {
+			filter.Add(model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: cid}.LocalityKey())
+		}
+		return model.NodeInfo{
+			PeerInfo:        peer.AddrInfo{ID: peer.ID(id)},
+			ComputeNodeInfo: model.ComputeNodeInfo{LocalStorage: filter},
+		}
+	}
+	s.allPeer = newNode("all", "cid1", "cid2")
+	s.somePeer = newNode("some", "cid1")
+	s.nonePeer = newNode("none", "cid3")
+	s.unknownPeer = model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID("unknown")}}
+}
+
+func (s *DataLocalityNodeRankerSuite) SetupTest() {
+	s.DataLocalityNodeRanker = NewDataLocalityNodeRanker(DataLocalityNodeRankerParams{Weight: 20})
+}
+
+func TestDataLocalityNodeRankerSuite(t *testing.T) {
+	suite.Run(t, new(DataLocalityNodeRankerSuite))
+}
+
+func (s *DataLocalityNodeRankerSuite) TestRankNodes() {
+	job := model.Job{Spec: model.Spec{Inputs: []model.StorageSpec{
+		{StorageSource: model.StorageSourceIPFS, CID: "cid1"},
+		{StorageSource: model.StorageSourceIPFS, CID: "cid2"},
+	}}}
+	nodes := []model.NodeInfo{s.allPeer, s.somePeer, s.nonePeer, s.unknownPeer}
+	ranks, err := s.DataLocalityNodeRanker.RankNodes(context.Background(), job, nodes)
+	s.NoError(err)
+	s.Equal(len(nodes), len(ranks))
+	assertEquals(s.T(), ranks, "all", 20)
+	assertEquals(s.T(), ranks, "some", 10)
+	assertEquals(s.T(), ranks, "none", 0)
+	assertEquals(s.T(), ranks, "unknown", 0)
+}
+
+func (s *DataLocalityNodeRankerSuite) TestRankNodes_NoLocalInputs() {
+	job := model.Job{Spec: model.Spec{Inputs: []model.StorageSpec{
+		{StorageSource: model.StorageSourceInline, URL: "data:text/plain,hello"},
+	}}}
+	nodes := []model.NodeInfo{s.allPeer, s.somePeer, s.nonePeer, s.unknownPeer}
+	ranks, err := s.DataLocalityNodeRanker.RankNodes(context.Background(), job, nodes)
+	s.NoError(err)
+	s.Equal(len(nodes), len(ranks))
+	assertEquals(s.T(), ranks, "all", 0)
+	assertEquals(s.T(), ranks, "some", 0)
+	assertEquals(s.T(), ranks, "none", 0)
+	assertEquals(s.T(), ranks, "unknown", 0)
+}
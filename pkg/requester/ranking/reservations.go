@@ -0,0 +1,46 @@
+package ranking
+
+import (
+	"context"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type ReservationsNodeRanker struct {
+}
+
+func NewReservationsNodeRanker() *ReservationsNodeRanker {
+	return &ReservationsNodeRanker{}
+}
+
+// RankNodes ranks nodes based on the capacity reservations they hold and the job's reservation token:
+// - Rank 10: Node holds the job's reservation.
+// - Rank -1: Node doesn't hold the job's reservation.
+// - Rank 0: Job doesn't carry a reservation token.
+func (s *ReservationsNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	ranks := make([]requester.NodeRank, len(nodes))
+	reservationID := job.Spec.Reservation
+	reservationKey := model.ReservationKey(reservationID)
+	for i, node := range nodes {
+		rank := 0
+		if reservationID != "" {
+			rank = -1
+			for _, key := range node.ComputeNodeInfo.ReservationKeys {
+				if key == reservationKey {
+					rank = 10
+					break
+				}
+			}
+			if rank < 0 {
+				log.Ctx(ctx).Trace().Msgf("filtering node %s doesn't hold reservation %s", node.PeerInfo.ID, reservationID)
+			}
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
@@ -0,0 +1,66 @@
+//go:build unit || !integration
+
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReservationsNodeRankerSuite struct {
+	suite.Suite
+	ReservationsNodeRanker *ReservationsNodeRanker
+	nodes                  []model.NodeInfo
+}
+
+func (s *ReservationsNodeRankerSuite) SetupSuite() {
+	s.nodes = []model.NodeInfo{
+		{
+			PeerInfo: peer.AddrInfo{ID: peer.ID("reserved")},
+			ComputeNodeInfo: model.ComputeNodeInfo{
+				ReservationKeys: []string{model.ReservationKey("demo")},
+			},
+		},
+		{
+			PeerInfo: peer.AddrInfo{ID: peer.ID("other")},
+			ComputeNodeInfo: model.ComputeNodeInfo{
+				ReservationKeys: []string{model.ReservationKey("nightly")},
+			},
+		},
+		{
+			PeerInfo: peer.AddrInfo{ID: peer.ID("none")},
+		},
+	}
+}
+
+func (s *ReservationsNodeRankerSuite) SetupTest() {
+	s.ReservationsNodeRanker = NewReservationsNodeRanker()
+}
+
+func TestReservationsNodeRankerSuite(t *testing.T) {
+	suite.Run(t, new(ReservationsNodeRankerSuite))
+}
+
+func (s *ReservationsNodeRankerSuite) TestRankNodes_WithReservation() {
+	job := model.Job{Spec: model.Spec{Reservation: "demo"}}
+	ranks, err := s.ReservationsNodeRanker.RankNodes(context.Background(), job, s.nodes)
+	s.NoError(err)
+	s.Equal(len(s.nodes), len(ranks))
+	assertEquals(s.T(), ranks, "reserved", 10)
+	assertEquals(s.T(), ranks, "other", -1)
+	assertEquals(s.T(), ranks, "none", -1)
+}
+
+func (s *ReservationsNodeRankerSuite) TestRankNodes_WithoutReservation() {
+	job := model.Job{}
+	ranks, err := s.ReservationsNodeRanker.RankNodes(context.Background(), job, s.nodes)
+	s.NoError(err)
+	s.Equal(len(s.nodes), len(ranks))
+	assertEquals(s.T(), ranks, "reserved", 0)
+	assertEquals(s.T(), ranks, "other", 0)
+	assertEquals(s.T(), ranks, "none", 0)
+}
@@ -0,0 +1,198 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/routing"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type ReservationManagerParams struct {
+	ID              string
+	NodeInfoStore   routing.NodeInfoStore
+	ComputeEndpoint compute.Endpoint
+	Limits          model.ReservationLimits
+}
+
+// ReservationManager reserves capacity for a future time window on the compute nodes matching the labels requested
+// by a client. The compute nodes hold back the reserved capacity during the window, and only release it to jobs
+// of the same client carrying the reservation's ID as their reservation token. The capacity each client can hold
+// back is limited, and clients can cancel their reservations to release it early.
+type ReservationManager struct {
+	id             string
+	nodeInfoStore  routing.NodeInfoStore
+	computeService compute.Endpoint
+	limits         model.ReservationLimits
+	// reservations made through this requester node that have not expired yet, by reservation ID
+	reservations map[string]ReserveCapacityResponse
+	mu           sync.Mutex
+}
+
+func NewReservationManager(params ReservationManagerParams) *ReservationManager {
+	return &ReservationManager{
+		id:             params.ID,
+		nodeInfoStore:  params.NodeInfoStore,
+		computeService: params.ComputeEndpoint,
+		limits:         params.Limits,
+		reservations:   make(map[string]ReserveCapacityResponse),
+	}
+}
+
+// ReserveCapacity asks every compute node matching the selectors of the payload to hold back the requested capacity
+// during the requested window. It fails if none of the nodes could hold back the capacity.
+func (m *ReservationManager) ReserveCapacity(ctx context.Context, payload model.ReservationPayload) (ReserveCapacityResponse, error) {
+	if !payload.EndTime.After(payload.StartTime) || !payload.EndTime.After(time.Now()) {
+		return ReserveCapacityResponse{}, fmt.Errorf("reservation window must end after it starts, and in the future")
+	}
+	resources := capacity.ParseResourceUsageConfig(payload.Resources)
+	if resources.IsZero() {
+		return ReserveCapacityResponse{}, fmt.Errorf("reservation must reserve some resources")
+	}
+	if err := m.checkLimits(payload, resources); err != nil {
+		return ReserveCapacityResponse{}, err
+	}
+	selector := labels.Everything()
+	if len(payload.NodeSelectors) > 0 {
+		requirements, err := model.FromLabelSelectorRequirements(payload.NodeSelectors...)
+		if err != nil {
+			return ReserveCapacityResponse{}, err
+		}
+		selector = labels.NewSelector().Add(requirements...)
+	}
+
+	nodes, err := m.nodeInfoStore.List(ctx)
+	if err != nil {
+		return ReserveCapacityResponse{}, err
+	}
+
+	response := ReserveCapacityResponse{
+		Reservation: model.Reservation{
+			ID:        uuid.NewString(),
+			ClientID:  payload.ClientID,
+			Resources: resources,
+			StartTime: payload.StartTime,
+			EndTime:   payload.EndTime,
+		},
+	}
+	matchedNodes := 0
+	for _, node := range nodes {
+		if !node.IsComputeNode() || !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if m.limits.MaxNodes > 0 && len(response.NodeIDs) >= m.limits.MaxNodes {
+			break
+		}
+		matchedNodes++
+		nodeID := node.PeerInfo.ID.String()
+		reserveResponse, err := m.computeService.Reserve(ctx, compute.ReserveRequest{
+			RoutingMetadata: compute.RoutingMetadata{
+				SourcePeerID: m.id,
+				TargetPeerID: nodeID,
+			},
+			Reservation: response.Reservation,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[ReserveCapacity] failed to reserve capacity on node %s", nodeID)
+			continue
+		}
+		if !reserveResponse.Accepted {
+			log.Ctx(ctx).Debug().Msgf("node %s did not reserve capacity: %s", nodeID, reserveResponse.Reason)
+			continue
+		}
+		response.NodeIDs = append(response.NodeIDs, nodeID)
+	}
+
+	if len(response.NodeIDs) == 0 {
+		return response, fmt.Errorf("none of the %d compute nodes matching the selectors could reserve the capacity", matchedNodes)
+	}
+	log.Ctx(ctx).Info().Msgf("reserved capacity %s on %d nodes with reservation %s",
+		resources, len(response.NodeIDs), response.Reservation.ID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reservations[response.Reservation.ID] = response
+	return response, nil
+}
+
+// checkLimits returns an error if the reservation exceeds the limits of its client.
+func (m *ReservationManager) checkLimits(payload model.ReservationPayload, resources model.ResourceUsageData) error {
+	if m.limits.MaxDuration > 0 && payload.EndTime.Sub(payload.StartTime) > m.limits.MaxDuration {
+		return fmt.Errorf("reservation window must be at most %s", m.limits.MaxDuration)
+	}
+	if !m.limits.MaxResources.IsZero() && !resources.LessThanEq(m.limits.MaxResources) {
+		return fmt.Errorf("reservation must hold back at most %s on each node", m.limits.MaxResources)
+	}
+	if m.limits.MaxActiveReservations > 0 {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.expireReservations(time.Now())
+		active := 0
+		for _, reservation := range m.reservations {
+			if reservation.Reservation.ClientID == payload.ClientID {
+				active++
+			}
+		}
+		if active >= m.limits.MaxActiveReservations {
+			return fmt.Errorf("client %s already holds %d reservations", payload.ClientID, active)
+		}
+	}
+	return nil
+}
+
+// CancelReservation releases the capacity held back by a reservation of the client on every node holding it.
+func (m *ReservationManager) CancelReservation(ctx context.Context, payload model.CancelReservationPayload) error {
+	m.mu.Lock()
+	m.expireReservations(time.Now())
+	reservation, ok := m.reservations[payload.ReservationID]
+	if ok && reservation.Reservation.ClientID == payload.ClientID {
+		delete(m.reservations, payload.ReservationID)
+	}
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("reservation %s not found", payload.ReservationID)
+	}
+	if reservation.Reservation.ClientID != payload.ClientID {
+		return fmt.Errorf("reservation %s was made by another client", payload.ReservationID)
+	}
+
+	for _, nodeID := range reservation.NodeIDs {
+		response, err := m.computeService.CancelReservation(ctx, compute.CancelReservationRequest{
+			RoutingMetadata: compute.RoutingMetadata{
+				SourcePeerID: m.id,
+				TargetPeerID: nodeID,
+			},
+			ReservationID: payload.ReservationID,
+			ClientID:      payload.ClientID,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[CancelReservation] failed to cancel reservation on node %s", nodeID)
+			continue
+		}
+		if !response.Canceled {
+			log.Ctx(ctx).Debug().Msgf("node %s did not cancel reservation: %s", nodeID, response.Reason)
+		}
+	}
+	log.Ctx(ctx).Info().Msgf("canceled reservation %s", payload.ReservationID)
+	return nil
+}
+
+// expireReservations forgets the reservations whose window ended.
+// make sure to call this function with the lock held
+func (m *ReservationManager) expireReservations(now time.Time) {
+	for id, reservation := range m.reservations {
+		if reservation.Reservation.IsExpired(now) {
+			delete(m.reservations, id)
+		}
+	}
+}
+
+// compile-time interface check
+var _ CapacityReserver = (*ReservationManager)(nil)
@@ -0,0 +1,175 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/routing"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// mockNodeInfoStore lists a fixed set of nodes
+type mockNodeInfoStore struct {
+	routing.NodeInfoStore
+	nodes []model.NodeInfo
+}
+
+func (m *mockNodeInfoStore) List(context.Context) ([]model.NodeInfo, error) {
+	return m.nodes, nil
+}
+
+// mockReserveEndpoint accepts the reservations of the nodes that have capacity, and records them
+type mockReserveEndpoint struct {
+	compute.Endpoint
+	capacity     map[string]bool
+	reservations map[string]model.Reservation
+}
+
+func (m *mockReserveEndpoint) CancelReservation(
+	_ context.Context, request compute.CancelReservationRequest) (compute.CancelReservationResponse, error) {
+	reservation, ok := m.reservations[request.TargetPeerID]
+	if !ok || reservation.ID != request.ReservationID {
+		return compute.CancelReservationResponse{Reason: "reservation not found"}, nil
+	}
+	delete(m.reservations, request.TargetPeerID)
+	return compute.CancelReservationResponse{Canceled: true}, nil
+}
+
+func (m *mockReserveEndpoint) Reserve(_ context.Context, request compute.ReserveRequest) (compute.ReserveResponse, error) {
+	if !m.capacity[request.TargetPeerID] {
+		return compute.ReserveResponse{Reason: "not enough capacity"}, nil
+	}
+	m.reservations[request.TargetPeerID] = request.Reservation
+	return compute.ReserveResponse{Accepted: true}, nil
+}
+
+func TestReservationManagerReservesCapacityOnSelectedNodes(t *testing.T) {
+	ctx := context.Background()
+	newNode := func(id string, labels map[string]string) model.NodeInfo {
+		return model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}, NodeType: model.NodeTypeCompute, Labels: labels}
+	}
+	endpoint := &mockReserveEndpoint{
+		capacity: map[string]bool{
+			peer.ID("demo-1").String(): true,
+			peer.ID("other").String():  true,
+		},
+		reservations: make(map[string]model.Reservation),
+	}
+	manager := NewReservationManager(ReservationManagerParams{
+		ID: "requester",
+		NodeInfoStore: &mockNodeInfoStore{nodes: []model.NodeInfo{
+			newNode("demo-1", map[string]string{"demo": "true"}),
+			newNode("demo-2", map[string]string{"demo": "true"}),
+			newNode("other", map[string]string{"demo": "false"}),
+		}},
+		ComputeEndpoint: endpoint,
+	})
+
+	payload := model.ReservationPayload{
+		ClientID:      "client",
+		NodeSelectors: []model.LabelSelectorRequirement{{Key: "demo", Operator: "=", Values: []string{"true"}}},
+		Resources:     model.ResourceUsageConfig{CPU: "2", Memory: "4Gi"},
+		StartTime:     time.Now().Add(time.Hour),
+		EndTime:       time.Now().Add(2 * time.Hour),
+	}
+	response, err := manager.ReserveCapacity(ctx, payload)
+	require.NoError(t, err)
+	require.NotEmpty(t, response.Reservation.ID)
+	require.Equal(t, "client", response.Reservation.ClientID)
+	require.Equal(t, model.ResourceUsageData{CPU: 2, Memory: 4 << 30}, response.Reservation.Resources)
+
+	// only the selected node with enough capacity holds the reservation
+	require.Equal(t, []string{peer.ID("demo-1").String()}, response.NodeIDs)
+	require.Len(t, endpoint.reservations, 1)
+	require.Equal(t, response.Reservation, endpoint.reservations[peer.ID("demo-1").String()])
+
+	// reservations fail if no selected node has capacity
+	endpoint.capacity = nil
+	_, err = manager.ReserveCapacity(ctx, payload)
+	require.Error(t, err)
+
+	// and if their window is invalid
+	payload.EndTime = time.Now().Add(-time.Minute)
+	_, err = manager.ReserveCapacity(ctx, payload)
+	require.Error(t, err)
+}
+
+func TestReservationManagerLimitsAndCancelsReservations(t *testing.T) {
+	ctx := context.Background()
+	newNode := func(id string) model.NodeInfo {
+		return model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(id)}, NodeType: model.NodeTypeCompute}
+	}
+	endpoint := &mockReserveEndpoint{
+		capacity: map[string]bool{
+			peer.ID("node-1").String(): true,
+			peer.ID("node-2").String(): true,
+		},
+		reservations: make(map[string]model.Reservation),
+	}
+	manager := NewReservationManager(ReservationManagerParams{
+		ID:              "requester",
+		NodeInfoStore:   &mockNodeInfoStore{nodes: []model.NodeInfo{newNode("node-1"), newNode("node-2")}},
+		ComputeEndpoint: endpoint,
+		Limits: model.ReservationLimits{
+			MaxActiveReservations: 1,
+			MaxNodes:              1,
+			MaxDuration:           2 * time.Hour,
+			MaxResources:          model.ResourceUsageData{CPU: 4, Memory: 8 << 30},
+		},
+	})
+
+	payload := model.ReservationPayload{
+		ClientID:  "client",
+		Resources: model.ResourceUsageConfig{CPU: "2", Memory: "4Gi"},
+		StartTime: time.Now(),
+		EndTime:   time.Now().Add(time.Hour),
+	}
+
+	// reservations exceeding the limits are rejected
+	tooLong := payload
+	tooLong.EndTime = payload.StartTime.Add(3 * time.Hour)
+	_, err := manager.ReserveCapacity(ctx, tooLong)
+	require.Error(t, err)
+	tooLarge := payload
+	tooLarge.Resources = model.ResourceUsageConfig{CPU: "8"}
+	_, err = manager.ReserveCapacity(ctx, tooLarge)
+	require.Error(t, err)
+
+	// reservations are held on at most MaxNodes nodes
+	response, err := manager.ReserveCapacity(ctx, payload)
+	require.NoError(t, err)
+	require.Len(t, response.NodeIDs, 1)
+	require.Len(t, endpoint.reservations, 1)
+
+	// each client holds at most MaxActiveReservations reservations
+	_, err = manager.ReserveCapacity(ctx, payload)
+	require.Error(t, err)
+	otherClient := payload
+	otherClient.ClientID = "other"
+	otherResponse, err := manager.ReserveCapacity(ctx, otherClient)
+	require.NoError(t, err)
+
+	// reservations can only be canceled by their client
+	require.Error(t, manager.CancelReservation(ctx, model.CancelReservationPayload{
+		ClientID: "other", ReservationID: response.Reservation.ID,
+	}))
+	require.Error(t, manager.CancelReservation(ctx, model.CancelReservationPayload{
+		ClientID: "client", ReservationID: "unknown",
+	}))
+	require.NoError(t, manager.CancelReservation(ctx, model.CancelReservationPayload{
+		ClientID: "client", ReservationID: response.Reservation.ID,
+	}))
+	for _, reservation := range endpoint.reservations {
+		require.Equal(t, otherResponse.Reservation.ID, reservation.ID)
+	}
+
+	// which frees the client to make another reservation
+	_, err = manager.ReserveCapacity(ctx, payload)
+	require.NoError(t, err)
+}
@@ -0,0 +1,119 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// EvictCachedResult stops serving the results of the completed job with the same spec as the payload's job.
+func (node *BaseEndpoint) EvictCachedResult(ctx context.Context, data model.ResultCacheEvictPayload) error {
+	job, err := node.store.GetJob(ctx, data.JobID)
+	if err != nil {
+		return err
+	}
+	if !jobutils.IsCacheable(job.Spec) {
+		return fmt.Errorf("job %s is not deterministic and its results are never cached", job.Metadata.ID)
+	}
+	specHash, err := jobutils.SpecHash(job.Spec)
+	if err != nil {
+		return err
+	}
+	return node.store.DeleteResultCacheEntry(ctx, specHash)
+}
+
+// serveCachedResult completes the job with the results of an identical deterministic job that already completed,
+// and returns true if it did. The job is expected to be queued.
+func (node *BaseEndpoint) serveCachedResult(ctx context.Context, job model.Job) (bool, error) {
+	if !jobutils.IsCacheable(job.Spec) {
+		return false, nil
+	}
+	specHash, err := jobutils.SpecHash(job.Spec)
+	if err != nil {
+		return false, err
+	}
+	entry, err := node.store.GetResultCacheEntry(ctx, specHash)
+	if err != nil {
+		if errors.As(err, &jobstore.ErrResultCacheEntryNotFound{}) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	cachedState, err := node.store.GetJobState(ctx, entry.JobID)
+	if err != nil || cachedState.State != model.JobStateCompleted {
+		// the cached job is gone or was reset, so its results can no longer be served
+		log.Ctx(ctx).Debug().Err(err).Msgf("evicting unusable cached results of job %s", entry.JobID)
+		return false, node.store.DeleteResultCacheEntry(ctx, specHash)
+	}
+	cachedJob, err := node.store.GetJob(ctx, entry.JobID)
+	if err != nil {
+		return false, err
+	}
+
+	if len(cachedJob.Spec.ExecutionPlan.Shards) > 0 {
+		job.Spec.ExecutionPlan = cachedJob.Spec.ExecutionPlan
+		err = node.store.UpdateJob(ctx, jobstore.UpdateJobRequest{
+			Job: job,
+			Condition: jobstore.UpdateJobCondition{
+				ExpectedState: model.JobStateQueued,
+			},
+			Comment: "execution plan copied from cached job " + cachedJob.Metadata.ID,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, execution := range cachedState.Executions {
+		if execution.State != model.ExecutionStateCompleted {
+			continue
+		}
+		err = node.store.CreateExecution(ctx, model.ExecutionState{
+			JobID:                job.Metadata.ID,
+			NodeID:               execution.NodeID,
+			ComputeReference:     execution.ComputeReference,
+			State:                model.ExecutionStateCompleted,
+			Status:               "result served from cache of job " + cachedJob.Metadata.ID,
+			VerificationProposal: execution.VerificationProposal,
+			VerificationResult:   execution.VerificationResult,
+			PublishedResult:      execution.PublishedResult,
+			RunOutput:            execution.RunOutput,
+			ShardIndex:           execution.ShardIndex,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = node.store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID: job.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{
+			ExpectedState: model.JobStateQueued,
+		},
+		NewState: model.JobStateCompleted,
+		Comment:  "results served from cache of job " + cachedJob.Metadata.ID,
+	})
+	return err == nil, err
+}
+
+// cacheJobResult caches the results of a completed deterministic job, so that they are served to identical jobs.
+func cacheJobResult(ctx context.Context, store jobstore.Store, job model.Job) error {
+	if !jobutils.IsCacheable(job.Spec) {
+		return nil
+	}
+	specHash, err := jobutils.SpecHash(job.Spec)
+	if err != nil {
+		return err
+	}
+	return store.PutResultCacheEntry(ctx, model.ResultCacheEntry{
+		SpecHash:  specHash,
+		JobID:     job.Metadata.ID,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,62 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func submitCacheableTestJob(t *testing.T, endpoint *BaseEndpoint, doNotCache bool) *model.Job {
+	job, err := endpoint.SubmitJob(context.Background(), model.JobCreatePayload{
+		ClientID:   "client",
+		APIVersion: model.APIVersionLatest().String(),
+		Spec: &model.Spec{
+			Engine: model.EngineWasm,
+			Wasm: model.JobSpecWasm{
+				EntryModule: model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmModule"},
+				EntryPoint:  "_start",
+			},
+			Inputs:     []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmInput", Path: "/inputs"}},
+			Deal:       model.Deal{Concurrency: 1},
+			DoNotCache: doNotCache,
+		},
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func TestIdenticalJobsAreServedFromCache(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+
+	result := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmResult"}
+	job := submitCacheableTestJob(t, endpoint, false)
+	requireJobState(t, store, job.Metadata.ID, model.JobStateInProgress)
+	completeJob(t, store, job.Metadata.ID, result)
+	completedJob, err := store.GetJob(ctx, job.Metadata.ID)
+	require.NoError(t, err)
+	require.NoError(t, cacheJobResult(ctx, store, completedJob))
+
+	cachedJob := submitCacheableTestJob(t, endpoint, false)
+	requireJobState(t, store, cachedJob.Metadata.ID, model.JobStateCompleted)
+	state, err := store.GetJobState(ctx, cachedJob.Metadata.ID)
+	require.NoError(t, err)
+	require.Len(t, state.Executions, 1)
+	require.Equal(t, result, state.Executions[0].PublishedResult)
+
+	// jobs that opt out of the cache always run
+	uncachedJob := submitCacheableTestJob(t, endpoint, true)
+	requireJobState(t, store, uncachedJob.Metadata.ID, model.JobStateInProgress)
+
+	// evicting the cache through any identical job runs the next identical job again
+	require.NoError(t, endpoint.EvictCachedResult(ctx, model.ResultCacheEvictPayload{ClientID: "client", JobID: cachedJob.Metadata.ID}))
+	require.ErrorAs(t, endpoint.EvictCachedResult(ctx, model.ResultCacheEvictPayload{ClientID: "client", JobID: job.Metadata.ID}),
+		&jobstore.ErrResultCacheEntryNotFound{})
+	rerunJob := submitCacheableTestJob(t, endpoint, false)
+	requireJobState(t, store, rerunJob.Metadata.ID, model.JobStateInProgress)
+}
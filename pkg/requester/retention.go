@@ -0,0 +1,140 @@
+package requester
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+type RetentionEnforcerParams struct {
+	JobStore jobstore.Store
+	Archive  *JobArchive
+	Policy   model.RetentionPolicy
+	NodeID   string
+	Interval time.Duration
+}
+
+// RetentionEnforcer is a background task that purges the terminal jobs owned by this requester node from the
+// job store once they expire according to the retention policy. Expired jobs are exported to the job archive
+// before they are purged, and are kept in the store if they could not be archived.
+type RetentionEnforcer struct {
+	jobStore jobstore.Store
+	archive  *JobArchive
+	policy   model.RetentionPolicy
+	nodeID   string
+	interval time.Duration
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewRetentionEnforcer(params RetentionEnforcerParams) *RetentionEnforcer {
+	r := &RetentionEnforcer{
+		jobStore:    params.JobStore,
+		archive:     params.Archive,
+		policy:      params.Policy,
+		nodeID:      params.NodeID,
+		interval:    params.Interval,
+		stopChannel: make(chan struct{}),
+	}
+
+	go r.retentionBackgroundTask()
+	return r
+}
+
+func (r *RetentionEnforcer) retentionBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(r.interval)
+	for {
+		select {
+		case <-ticker.C:
+			r.enforceRetention(ctx, time.Now())
+		case <-r.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped retention enforcer task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// enforceRetention archives and purges the jobs that expired at the given time. When the policy limits the number
+// of jobs, the terminal jobs whose state changed the longest time ago are purged first. Jobs that other jobs are
+// still waiting on are kept, as their results are wired into the inputs of the dependent jobs.
+func (r *RetentionEnforcer) enforceRetention(ctx context.Context, now time.Time) {
+	jobs, err := r.jobStore.GetJobs(ctx, jobstore.JobQuery{ReturnAll: true})
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msg("failed to get jobs")
+		return
+	}
+
+	var terminalJobs []model.JobWithInfo
+	dependencies := make(map[string]struct{})
+	for _, job := range jobs {
+		// in case the job store is shared between multiple nodes, we only want to purge jobs that are owned by this node,
+		// but jobs of any node can depend on them
+		owned := job.Metadata.Requester.RequesterNodeID == r.nodeID
+		if !owned && len(job.Spec.Dependencies) == 0 {
+			continue
+		}
+		jobState, stateErr := r.jobStore.GetJobState(ctx, job.Metadata.ID)
+		if stateErr != nil {
+			log.Ctx(ctx).Error().Err(stateErr).Msgf("[enforceRetention] failed to get state of job %s", job.Metadata.ID)
+			continue
+		}
+		if !jobState.State.IsTerminal() {
+			for _, dependency := range job.Spec.Dependencies {
+				dependencies[dependency.JobID] = struct{}{}
+			}
+		} else if owned {
+			terminalJobs = append(terminalJobs, model.JobWithInfo{Job: job, State: jobState})
+		}
+	}
+	sort.Slice(terminalJobs, func(i, j int) bool {
+		return terminalJobs[i].State.UpdateTime.Before(terminalJobs[j].State.UpdateTime)
+	})
+
+	excess := 0
+	if r.policy.MaxCount > 0 {
+		excess = len(terminalJobs) - r.policy.MaxCount
+	}
+	var expiredJobs []model.JobWithInfo
+	for i, job := range terminalJobs {
+		if i >= excess && !r.policy.IsExpired(job.State, now) {
+			continue
+		}
+		if _, ok := dependencies[job.Job.Metadata.ID]; ok {
+			continue
+		}
+		job.History, err = r.jobStore.GetJobHistory(ctx, job.Job.Metadata.ID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[enforceRetention] failed to get history of job %s", job.Job.Metadata.ID)
+			continue
+		}
+		expiredJobs = append(expiredJobs, job)
+	}
+	if len(expiredJobs) == 0 {
+		return
+	}
+
+	if err = r.archive.Append(expiredJobs); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[enforceRetention] failed to archive expired jobs")
+		return
+	}
+	for _, job := range expiredJobs {
+		if err = r.jobStore.DeleteJob(ctx, job.Job.Metadata.ID); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[enforceRetention] failed to purge job %s", job.Job.Metadata.ID)
+		}
+	}
+	log.Ctx(ctx).Debug().Msgf("archived and purged %d expired jobs", len(expiredJobs))
+}
+
+func (r *RetentionEnforcer) Stop() {
+	r.stopOnce.Do(func() {
+		r.stopChannel <- struct{}{}
+	})
+}
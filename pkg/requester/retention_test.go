@@ -0,0 +1,155 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRetentionTestJob(
+	t *testing.T, store jobstore.Store, nodeID string, state model.JobStateType, annotations ...string) string {
+	ctx := context.Background()
+	job := model.Job{
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			ClientID:  "client",
+			CreatedAt: time.Now(),
+			Requester: model.JobRequester{RequesterNodeID: nodeID},
+		},
+		Spec: model.Spec{Annotations: annotations},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    job.Metadata.ID,
+		NewState: state,
+	}))
+	return job.Metadata.ID
+}
+
+func newTestRetentionEnforcer(t *testing.T, policy model.RetentionPolicy) (*RetentionEnforcer, jobstore.Store) {
+	store := inmemory.NewJobStore()
+	return &RetentionEnforcer{
+		jobStore: store,
+		archive:  NewJobArchive(filepath.Join(t.TempDir(), "archive.jsonl.gz")),
+		policy:   policy,
+		nodeID:   "node",
+	}, store
+}
+
+func TestRetentionEnforcerArchivesExpiredJobs(t *testing.T) {
+	ctx := context.Background()
+	enforcer, store := newTestRetentionEnforcer(t, model.RetentionPolicy{
+		MaxAge:      48 * time.Hour,
+		StateMaxAge: map[model.JobStateType]time.Duration{model.JobStateError: time.Hour},
+	})
+
+	completed := createRetentionTestJob(t, store, "node", model.JobStateCompleted, "tag")
+	failed := createRetentionTestJob(t, store, "node", model.JobStateError)
+	inProgress := createRetentionTestJob(t, store, "node", model.JobStateInProgress)
+	otherNode := createRetentionTestJob(t, store, "other-node", model.JobStateCompleted)
+
+	// failed jobs expire first
+	now := time.Now()
+	enforcer.enforceRetention(ctx, now.Add(2*time.Hour))
+	_, err := store.GetJob(ctx, failed)
+	require.Error(t, err)
+	requireJobState(t, store, completed, model.JobStateCompleted)
+
+	enforcer.enforceRetention(ctx, now.Add(72*time.Hour))
+	_, err = store.GetJob(ctx, completed)
+	require.Error(t, err)
+	// jobs in progress or owned by other nodes are kept
+	requireJobState(t, store, inProgress, model.JobStateInProgress)
+	requireJobState(t, store, otherNode, model.JobStateCompleted)
+
+	archived, err := enforcer.archive.GetJobs(ctx, jobstore.JobQuery{})
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	require.Equal(t, failed, archived[0].Job.Metadata.ID)
+	require.Equal(t, model.JobStateError, archived[0].State.State)
+	require.NotEmpty(t, archived[0].History)
+
+	archived, err = enforcer.archive.GetJobs(ctx, jobstore.JobQuery{IncludeTags: []model.IncludedTag{"tag"}})
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	require.Equal(t, completed, archived[0].Job.Metadata.ID)
+
+	archived, err = enforcer.archive.GetJobs(ctx, jobstore.JobQuery{ID: model.ShortID(failed)})
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+}
+
+func TestRetentionEnforcerKeepsMaxCountJobs(t *testing.T) {
+	ctx := context.Background()
+	enforcer, store := newTestRetentionEnforcer(t, model.RetentionPolicy{MaxCount: 1})
+
+	oldest := createRetentionTestJob(t, store, "node", model.JobStateCompleted)
+	older := createRetentionTestJob(t, store, "node", model.JobStateCancelled)
+	newest := createRetentionTestJob(t, store, "node", model.JobStateCompleted)
+
+	enforcer.enforceRetention(ctx, time.Now())
+	requireJobState(t, store, newest, model.JobStateCompleted)
+
+	archived, err := enforcer.archive.GetJobs(ctx, jobstore.JobQuery{SortBy: "created_at"})
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	require.Equal(t, oldest, archived[0].Job.Metadata.ID)
+	require.Equal(t, older, archived[1].Job.Metadata.ID)
+
+	// the archive is appended to
+	enforcer.enforceRetention(ctx, time.Now())
+	createRetentionTestJob(t, store, "node", model.JobStateCompleted)
+	enforcer.enforceRetention(ctx, time.Now())
+	archived, err = enforcer.archive.GetJobs(ctx, jobstore.JobQuery{SortBy: "created_at", SortReverse: true, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	require.Equal(t, newest, archived[0].Job.Metadata.ID)
+	require.Equal(t, older, archived[1].Job.Metadata.ID)
+}
+
+func TestRetentionEnforcerKeepsDependenciesOfPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	enforcer, store := newTestRetentionEnforcer(t, model.RetentionPolicy{MaxCount: 1})
+
+	dependency := createRetentionTestJob(t, store, "node", model.JobStateCompleted)
+	dependent := model.Job{
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			CreatedAt: time.Now(),
+			Requester: model.JobRequester{RequesterNodeID: "other-node"},
+		},
+		Spec: model.Spec{Dependencies: []model.JobDependency{{JobID: dependency, Path: "/inputs"}}},
+	}
+	require.NoError(t, store.CreateJob(ctx, dependent))
+	newest := createRetentionTestJob(t, store, "node", model.JobStateCompleted)
+
+	// the dependency is kept while a job of any node is waiting on it
+	enforcer.enforceRetention(ctx, time.Now())
+	requireJobState(t, store, dependency, model.JobStateCompleted)
+	requireJobState(t, store, newest, model.JobStateCompleted)
+
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    dependent.Metadata.ID,
+		NewState: model.JobStateCancelled,
+	}))
+	enforcer.enforceRetention(ctx, time.Now())
+	_, err := store.GetJob(ctx, dependency)
+	require.Error(t, err)
+	requireJobState(t, store, newest, model.JobStateCompleted)
+}
+
+func TestJobArchiveIsEmptyUntilWritten(t *testing.T) {
+	archive := NewJobArchive(filepath.Join(t.TempDir(), "archive.jsonl.gz"))
+	archived, err := archive.GetJobs(context.Background(), jobstore.JobQuery{})
+	require.NoError(t, err)
+	require.Empty(t, archived)
+}
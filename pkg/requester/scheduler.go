@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/compute/capacity"
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
 	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/logger"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
@@ -34,6 +36,11 @@ type SchedulerParams struct {
 	Verifiers        verifier.VerifierProvider
 	StorageProviders storage.StorageProvider
 	EventEmitter     EventEmitter
+	// LostNodeGracePeriod is how long a compute node can go undiscovered before its executions are considered lost.
+	// Executions are never considered lost if zero.
+	LostNodeGracePeriod time.Duration
+	// DefaultJobResources are the resources charged to clients for the executions of jobs that don't request them.
+	DefaultJobResources model.ResourceUsageData
 }
 
 type scheduler struct {
@@ -46,20 +53,28 @@ type scheduler struct {
 	verifiers        verifier.VerifierProvider
 	storageProviders storage.StorageProvider
 	eventEmitter     EventEmitter
-	mu               sync.Mutex
+	runDurations     runDurationsBySpec
+	defaultResources model.ResourceUsageData
+	// lost node detection
+	lostNodeGracePeriod time.Duration
+	startTime           time.Time
+	mu                  sync.Mutex
 }
 
 func NewScheduler(params SchedulerParams) *scheduler {
 	res := &scheduler{
-		id:               params.ID,
-		host:             params.Host,
-		jobStore:         params.JobStore,
-		nodeDiscoverer:   params.NodeDiscoverer,
-		nodeRanker:       params.NodeRanker,
-		computeService:   params.ComputeEndpoint,
-		verifiers:        params.Verifiers,
-		storageProviders: params.StorageProviders,
-		eventEmitter:     params.EventEmitter,
+		id:                  params.ID,
+		host:                params.Host,
+		jobStore:            params.JobStore,
+		nodeDiscoverer:      params.NodeDiscoverer,
+		nodeRanker:          params.NodeRanker,
+		computeService:      params.ComputeEndpoint,
+		verifiers:           params.Verifiers,
+		storageProviders:    params.StorageProviders,
+		eventEmitter:        params.EventEmitter,
+		lostNodeGracePeriod: params.LostNodeGracePeriod,
+		defaultResources:    params.DefaultJobResources,
+		startTime:           time.Now(),
 	}
 
 	// TODO: replace with job level lock
@@ -71,38 +86,25 @@ func NewScheduler(params SchedulerParams) *scheduler {
 }
 
 func (s *scheduler) StartJob(ctx context.Context, req StartJobRequest) error {
-	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, req.Job)
+	job := req.Job
+	rankedNodes, err := s.rankNodes(ctx, job)
 	if err != nil {
 		return err
 	}
-	log.Ctx(ctx).Debug().Msgf("found %d nodes for job %s", len(nodeIDs), req.Job.Metadata.ID)
 
-	rankedNodes, err := s.nodeRanker.RankNodes(ctx, req.Job, nodeIDs)
-	if err != nil {
-		return err
-	}
-
-	// filter nodes with rank below 0
-	var filteredNodes []NodeRank
-	for _, node := range rankedNodes {
-		if node.Rank >= 0 {
-			filteredNodes = append(filteredNodes, node)
-		}
-	}
-	rankedNodes = filteredNodes
-	log.Ctx(ctx).Debug().Msgf("ranked %d nodes for job %s", len(rankedNodes), req.Job.Metadata.ID)
-
-	minBids := system.Max(req.Job.Spec.Deal.MinBids, req.Job.Spec.Deal.Concurrency)
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
 	if len(rankedNodes) < minBids {
 		return NewErrNotEnoughNodes(minBids, len(rankedNodes))
 	}
 
-	sort.Slice(rankedNodes, func(i, j int) bool {
-		return rankedNodes[i].Rank > rankedNodes[j].Rank
-	})
+	if job.Spec.Sharding.IsEnabled() && len(job.Spec.ExecutionPlan.Shards) == 0 {
+		if err = s.generateShards(ctx, &job); err != nil {
+			return err
+		}
+	}
 
 	err = s.jobStore.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
-		JobID: req.Job.Metadata.ID,
+		JobID: job.Metadata.ID,
 		Condition: jobstore.UpdateJobCondition{
 			ExpectedState: model.JobStateNew,
 		},
@@ -111,13 +113,35 @@ func (s *scheduler) StartJob(ctx context.Context, req StartJobRequest) error {
 	if err != nil {
 		return errors.Wrap(err, "error saving job id")
 	}
-	s.eventEmitter.EmitJobCreated(ctx, req.Job)
+	s.eventEmitter.EmitJobCreated(ctx, job)
 
-	selectedNodes := rankedNodes[:system.Min(len(rankedNodes), minBids*OverAskForBidsFactor)]
-	go s.notifyAskForBid(logger.ContextWithNodeIDLogger(context.Background(), s.id), trace.LinkFromContext(ctx), &req.Job, selectedNodes)
+	// shards that can't be assigned enough nodes yet are scheduled once nodes respond to their bid requests
+	shardIndexes := make([]int, job.Spec.ExecutionPlan.TotalShards())
+	for i := range shardIndexes {
+		shardIndexes[i] = i
+	}
+	assignments, _ := assignNodesToShards(job, model.JobState{}, rankedNodes, shardIndexes, nil, minBids)
+	go s.notifyAskForBid(logger.ContextWithNodeIDLogger(context.Background(), s.id), trace.LinkFromContext(ctx), &job, assignments)
 	return nil
 }
 
+// generateShards splits the inputs of the job into shards, and persists them in the job's execution plan
+func (s *scheduler) generateShards(ctx context.Context, job *model.Job) error {
+	shards, err := jobutils.GenerateShards(ctx, job.Spec, s.storageProviders)
+	if err != nil {
+		return errors.Wrap(err, "error generating shards")
+	}
+	job.Spec.ExecutionPlan.Shards = shards
+	log.Ctx(ctx).Debug().Msgf("split job %s into %d shards", job.Metadata.ID, len(shards))
+	return s.jobStore.UpdateJob(ctx, jobstore.UpdateJobRequest{
+		Job: *job,
+		Condition: jobstore.UpdateJobCondition{
+			ExpectedState: model.JobStateNew,
+		},
+		Comment: fmt.Sprintf("split into %d shards", len(shards)),
+	})
+}
+
 func (s *scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (CancelJobResult, error) {
 	log.Ctx(ctx).Debug().Msgf("Requester node %s received CancelJob for job: %s with reason %s",
 		s.id, request.JobID, request.Reason)
@@ -136,11 +160,99 @@ func (s *scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (Ca
 	return CancelJobResult{}, nil
 }
 
+// rankNodes finds the nodes that are suitable to execute the job, and returns them sorted by their rank
+// after filtering out nodes with rank below 0.
+func (s *scheduler) rankNodes(ctx context.Context, job model.Job) ([]NodeRank, error) {
+	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("found %d nodes for job %s", len(nodeIDs), job.Metadata.ID)
+
+	rankedNodes, err := s.nodeRanker.RankNodes(ctx, job, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter nodes with rank below 0
+	var filteredNodes []NodeRank
+	for _, node := range rankedNodes {
+		if node.Rank >= 0 {
+			filteredNodes = append(filteredNodes, node)
+		}
+	}
+	log.Ctx(ctx).Debug().Msgf("ranked %d nodes for job %s", len(filteredNodes), job.Metadata.ID)
+
+	sort.Slice(filteredNodes, func(i, j int) bool {
+		return filteredNodes[i].Rank > filteredNodes[j].Rank
+	})
+	return filteredNodes, nil
+}
+
+// ResumeJobs resumes scheduling jobs owned by this node that were in progress when the node last stopped,
+// which is only possible when the job store is persistent.
+// Bid requests that were in flight are considered lost, pending bids and results are acted upon, and the
+// job is retried or failed if it can no longer meet its deal.
+func (s *scheduler) ResumeJobs(ctx context.Context, jobs []model.JobWithInfo) {
+	for _, jobWithInfo := range jobs {
+		job := jobWithInfo.Job
+		if job.Metadata.Requester.RequesterNodeID != s.id {
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("resuming job %s in state %s", job.Metadata.ID, jobWithInfo.State.State)
+		switch jobWithInfo.State.State {
+		case model.JobStateNew:
+			// jobs waiting for their dependencies are scheduled by the housekeeping task
+			if isWaitingForDependencies(jobWithInfo) {
+				continue
+			}
+			// the node stopped after the job was approved, but before it was scheduled
+			if err := s.StartJob(ctx, StartJobRequest{Job: job}); err != nil {
+				s.mu.Lock()
+				s.stopJob(ctx, job.Metadata.ID, err.Error(), false)
+				s.mu.Unlock()
+			}
+		case model.JobStateInProgress:
+			s.resumeJob(ctx, job.Metadata.ID, jobWithInfo.State)
+		}
+	}
+}
+
+func (s *scheduler) resumeJob(ctx context.Context, jobID string, jobState model.JobState) {
+	for _, execution := range jobState.Executions {
+		if execution.State != model.ExecutionStateAskForBid {
+			continue
+		}
+		err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+			ExecutionID: execution.ID(),
+			Condition: jobstore.UpdateExecutionCondition{
+				ExpectedState:   execution.State,
+				ExpectedVersion: execution.Version,
+			},
+			NewValues: model.ExecutionState{
+				State:  model.ExecutionStateAskForBidRejected,
+				Status: "requester node stopped before receiving a bid",
+			},
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[resumeJob] failed to update execution %s", execution)
+		}
+	}
+
+	s.startAcceptingBidsIfPossible(ctx, jobID)
+	s.startVerificationIfPossible(ctx, jobID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failIfRecoveryIsNotPossible(ctx, jobID, errors.New("not enough executions left after requester node restarted"))
+	s.dispatchWaitingShards(ctx, jobID)
+}
+
 //////////////////////////////
 //    Job fsm handlers    //
 //////////////////////////////
 
-func (s *scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *model.Job, nodes []NodeRank) {
+func (s *scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *model.Job, assignments []shardAssignment) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester.Scheduler.StartJob",
 		trace.WithLinks(link), // link to any api traces
 		trace.WithSpanKind(trace.SpanKindInternal),
@@ -154,27 +266,38 @@ func (s *scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *m
 	// persist the intent to ask the node for a bid, which is helpful to avoid asking an unresponsive node again during retries.
 	// we persist the intent for all nodes before asking any node to bid, so that we don't fail the job if the first node we ask rejects the
 	// the bid before we persist the intent to ask the other nodes.
-	for _, node := range nodes {
+	err := s.createAskForBidExecutions(ctx, job, assignments, 0)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error creating execution")
+		return
+	}
+
+	newCtx := util.NewDetachedContext(ctx)
+	for _, assignment := range assignments {
+		go s.doNotifyAskForBid(newCtx, link, job, assignment)
+	}
+}
+
+func (s *scheduler) createAskForBidExecutions(ctx context.Context, job *model.Job, assignments []shardAssignment, attempt int) error {
+	for _, assignment := range assignments {
 		err := s.jobStore.CreateExecution(ctx, model.ExecutionState{
-			JobID:  job.Metadata.ID,
-			NodeID: node.NodeInfo.PeerInfo.ID.String(),
-			State:  model.ExecutionStateAskForBid,
+			JobID:      job.Metadata.ID,
+			NodeID:     assignment.Node.NodeInfo.PeerInfo.ID.String(),
+			State:      model.ExecutionStateAskForBid,
+			ShardIndex: assignment.ShardIndex,
+			Attempt:    attempt,
 		})
 		if err != nil {
-			log.Ctx(ctx).Error().Err(err).Msg("error creating execution")
-			return
+			return err
 		}
 	}
-
-	newCtx := util.NewDetachedContext(ctx)
-	for _, node := range nodes {
-		go s.doNotifyAskForBid(newCtx, link, job, node.NodeInfo)
-	}
+	return nil
 }
 
-func (s *scheduler) doNotifyAskForBid(ctx context.Context, link trace.Link, job *model.Job, nodeInfo model.NodeInfo) {
+func (s *scheduler) doNotifyAskForBid(ctx context.Context, link trace.Link, job *model.Job, assignment shardAssignment) {
+	nodeInfo := assignment.Node.NodeInfo
 	request := compute.AskForBidRequest{
-		Job: *job,
+		Job: job.Shard(assignment.ShardIndex),
 		RoutingMetadata: compute.RoutingMetadata{
 			SourcePeerID: s.id,
 			TargetPeerID: nodeInfo.PeerInfo.ID.String(),
@@ -189,7 +312,7 @@ func (s *scheduler) doNotifyAskForBid(ctx context.Context, link trace.Link, job
 	s.handleAskForBidResponse(ctx, request, bid)
 }
 
-func (s *scheduler) notifyBidAccepted(ctx context.Context, execution model.ExecutionState) {
+func (s *scheduler) notifyBidAccepted(ctx context.Context, execution model.ExecutionState, gang compute.GangMembership) {
 	log.Ctx(ctx).Debug().Msgf("Requester node %s responding with BidAccepted for bid: %s", s.id, execution.ComputeReference)
 	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
 		ExecutionID: execution.ID(),
@@ -212,6 +335,7 @@ func (s *scheduler) notifyBidAccepted(ctx context.Context, execution model.Execu
 					SourcePeerID: s.id,
 					TargetPeerID: execution.NodeID,
 				},
+				Gang: gang,
 			}
 			response, notifyErr := s.computeService.BidAccepted(ctx, request)
 			if notifyErr != nil {
@@ -409,6 +533,7 @@ func (s *scheduler) handleAskForBidResponse(ctx context.Context,
 			ComputeReference: response.ExecutionID,
 			State:            newState,
 			Status:           response.Reason,
+			Price:            response.Price,
 		},
 	})
 	if err != nil {
@@ -420,36 +545,130 @@ func (s *scheduler) handleAskForBidResponse(ctx context.Context,
 	// we only notify if we've already received more than MinBids
 	if response.Accepted {
 		s.eventEmitter.EmitBidReceived(ctx, request, response)
-		s.startAcceptingBidsIfPossible(ctx, response)
+		s.startAcceptingBidsIfPossible(ctx, response.JobID)
 	} else {
 		s.mu.Lock()
-		defer s.mu.Unlock()
 		s.failIfRecoveryIsNotPossible(ctx, response.JobID, errors.New("not enough bids received"))
+		s.mu.Unlock()
+	}
+
+	// the node can now be asked to bid on shards that are still waiting for nodes
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dispatchWaitingShards(ctx, response.JobID)
+}
+
+// dispatchWaitingShards asks free nodes to bid on the shards of the job that were not assigned enough nodes
+// when the job was scheduled. The job is failed if no node is left to execute these shards.
+// make sure to call this function with the lock held
+func (s *scheduler) dispatchWaitingShards(ctx context.Context, jobID string) {
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[dispatchWaitingShards] failed to get job")
+		return
+	}
+	if job.Spec.ExecutionPlan.TotalShards() <= 1 {
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[dispatchWaitingShards] failed to get job state")
+		return
+	}
+	if jobState.State != model.JobStateInProgress {
+		return
+	}
+
+	var waitingShards []int
+	for shardIndex, executions := range groupExecutionsByShard(job, jobState) {
+		if isShardWaitingForNodes(job, executions) {
+			waitingShards = append(waitingShards, shardIndex)
+		}
+	}
+	if len(waitingShards) == 0 {
+		return
+	}
+
+	rankedNodes, err := s.rankNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[dispatchWaitingShards] failed to rank nodes")
+		return
+	}
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
+	assignments, _ := assignNodesToShards(job, jobState, rankedNodes, waitingShards, nil, minBids)
+	if len(assignments) == 0 {
+		// nodes are only busy while we are waiting for their bids
+		for _, execution := range jobState.Executions {
+			if execution.State == model.ExecutionStateAskForBid {
+				return
+			}
+		}
+		s.stopJob(ctx, jobID, fmt.Sprintf("not enough nodes to execute %d remaining shards", len(waitingShards)), false)
+		return
+	}
+
+	err = s.createAskForBidExecutions(ctx, &job, assignments, jobState.LatestAttempt())
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[dispatchWaitingShards] failed to create executions")
+		return
+	}
+	log.Ctx(ctx).Debug().Msgf("asking %d nodes to bid on %d waiting shards of job %s", len(assignments), len(waitingShards), jobID)
+
+	newCtx := util.NewDetachedContext(ctx)
+	for _, assignment := range assignments {
+		go s.doNotifyAskForBid(newCtx, trace.LinkFromContext(ctx), &job, assignment)
 	}
 }
 
 // startAcceptingBidsIfPossible is called when a compute node has accepted a bid
 // If we have received more than MinBids, we start accepting/rejecting bids, and notify the compute node of the decision
-func (s *scheduler) startAcceptingBidsIfPossible(ctx context.Context, response compute.AskForBidResponse) {
+func (s *scheduler) startAcceptingBidsIfPossible(ctx context.Context, jobID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	jobState, err := s.jobStore.GetJobState(ctx, response.JobID)
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get job state")
 		return
 	}
 
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get job")
+		return
+	}
+	// bids are accepted independently for each shard of the job
+	rejectedOverpricedBids := false
+	for _, executions := range groupExecutionsByShard(job, jobState) {
+		if s.acceptBidsIfPossible(ctx, job, executions) {
+			rejectedOverpricedBids = true
+		}
+	}
+	if rejectedOverpricedBids {
+		s.failIfRecoveryIsNotPossible(ctx, jobID, errors.New("not enough bids within the job's max price"))
+	}
+}
+
+// acceptBidsIfPossible accepts the cheapest bids of the shard once MinBids were received, and rejects the bids that
+// quote more than the job's max price. It returns true if bids were rejected because of their price.
+// make sure to call this function with the lock held
+func (s *scheduler) acceptBidsIfPossible(ctx context.Context, job model.Job, executions []model.ExecutionState) bool {
 	var pendingBids []model.ExecutionState
+	var overpricedBids []model.ExecutionState
 	var receivedBidsCount int
 	var activeExecutionsCount int
-	for _, execution := range jobState.Executions {
+	maxPrice := job.Spec.Deal.MaxPrice
+	for _, execution := range executions {
 		// total compute nodes that submitted a bid for this job
 		if execution.HasAcceptedAskForBid() {
 			receivedBidsCount++
 		}
 		// compute nodes that are waiting for a decision on their bid
 		if execution.State == model.ExecutionStateAskForBidAccepted {
-			pendingBids = append(pendingBids, execution)
+			if maxPrice > 0 && execution.Price > maxPrice {
+				overpricedBids = append(overpricedBids, execution)
+			} else {
+				pendingBids = append(pendingBids, execution)
+			}
 		}
 		// compute nodes that are actively executing the job, or has completed execution
 		if execution.State.IsActive() {
@@ -457,23 +676,44 @@ func (s *scheduler) startAcceptingBidsIfPossible(ctx context.Context, response c
 		}
 	}
 
-	job, err := s.jobStore.GetJob(ctx, response.JobID)
-	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get job")
-		return
+	// bids quoting more than the job's max price will never qualify
+	for _, bid := range overpricedBids {
+		log.Ctx(ctx).Debug().Msgf("rejecting bid %s quoting %.2f over the max price %.2f", bid.ComputeReference, bid.Price, maxPrice)
+		s.notifyBidRejected(ctx, bid)
 	}
+
+	// members of a gang are only accepted together
+	if job.Spec.Deal.Gang {
+		s.acceptGangBidsIfPossible(ctx, job, executions, pendingBids, receivedBidsCount)
+		return len(overpricedBids) > 0
+	}
+
 	// if we have more than MinBids, we start selecting the best bids and notify the compute nodes
 	if receivedBidsCount >= job.Spec.Deal.MinBids {
+		// the cheapest bids are accepted first
+		sort.SliceStable(pendingBids, func(i, j int) bool {
+			return pendingBids[i].Price < pendingBids[j].Price
+		})
 		// TODO: we should verify a bid acceptance was received by the compute node before rejecting other bids
 		for _, candidate := range pendingBids {
+			// backup executions run alongside the slow execution until one of them proposes its results
+			if candidate.Speculative {
+				if hasProposedResultsInShard(executions) {
+					s.notifyBidRejected(ctx, candidate)
+				} else {
+					s.notifyBidAccepted(ctx, candidate, compute.GangMembership{})
+				}
+				continue
+			}
 			if activeExecutionsCount < job.Spec.Deal.Concurrency {
-				s.notifyBidAccepted(ctx, candidate)
+				s.notifyBidAccepted(ctx, candidate, compute.GangMembership{})
 				activeExecutionsCount++
 			} else {
 				s.notifyBidRejected(ctx, candidate)
 			}
 		}
 	}
+	return len(overpricedBids) > 0
 }
 
 func (s *scheduler) OnRunComplete(ctx context.Context, result compute.RunResult) {
@@ -481,19 +721,29 @@ func (s *scheduler) OnRunComplete(ctx context.Context, result compute.RunResult)
 		s.id, result.ExecutionID, result.SourcePeerID)
 	s.eventEmitter.EmitRunComplete(ctx, result)
 
+	executionID := model.ExecutionID{
+		JobID:       result.JobID,
+		NodeID:      result.SourcePeerID,
+		ExecutionID: result.ExecutionID,
+	}
+	// the execution was last updated when its bid was accepted and it started running
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[OnRunComplete] failed to get execution")
+		return
+	}
+	runStartTime := execution.UpdateTime
+
 	// update execution state
-	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
-		ExecutionID: model.ExecutionID{
-			JobID:       result.JobID,
-			NodeID:      result.SourcePeerID,
-			ExecutionID: result.ExecutionID,
-		},
+	err = s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: executionID,
 		Condition: jobstore.UpdateExecutionCondition{
 			ExpectedState: model.ExecutionStateBidAccepted,
 		},
 		NewValues: model.ExecutionState{
 			VerificationProposal: result.ResultProposal,
 			RunOutput:            result.RunCommandResult,
+			ResourceUsage:        result.ResourceUsage,
 			State:                model.ExecutionStateResultProposed,
 		},
 	})
@@ -502,9 +752,81 @@ func (s *scheduler) OnRunComplete(ctx context.Context, result compute.RunResult)
 		return
 	}
 
+	runDuration := time.Since(runStartTime)
+	s.recordUsage(ctx, executionID, runDuration, result.ResourceUsage)
+
+	s.mu.Lock()
+	s.recordRunDuration(ctx, result.JobID, runDuration)
+	s.cancelSpeculativeExecutions(ctx, executionID)
+	s.mu.Unlock()
+
 	s.startVerificationIfPossible(ctx, result.JobID)
 }
 
+// recordUsage charges the resources consumed by an execution that stopped running to the client that submitted
+// the job, whether it completed its run or not.
+func (s *scheduler) recordUsage(
+	ctx context.Context, executionID model.ExecutionID, runDuration time.Duration, usage *model.ResourceUsageSummary) {
+	job, err := s.jobStore.GetJob(ctx, executionID.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[recordUsage] failed to get job %s", executionID.JobID)
+		return
+	}
+	err = s.jobStore.AddUsageRecord(ctx, model.UsageRecord{
+		ClientID:    job.Metadata.ClientID,
+		JobID:       executionID.JobID,
+		NodeID:      executionID.NodeID,
+		ExecutionID: executionID.ExecutionID,
+		Resources:   s.chargedResources(job, usage),
+		Duration:    runDuration,
+		CompletedAt: time.Now(),
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[recordUsage] failed to record usage of execution %s", executionID)
+	}
+}
+
+// recordUsageIfRan charges the usage of an execution that was running until it was discarded or failed.
+func (s *scheduler) recordUsageIfRan(ctx context.Context, execution model.ExecutionState) {
+	if execution.State != model.ExecutionStateBidAccepted {
+		return
+	}
+	// the execution was last updated when its bid was accepted and it started running
+	s.recordUsage(ctx, execution.ID(), time.Since(execution.UpdateTime), nil)
+}
+
+// chargedResources returns the resources charged for an execution of the job. The peak usage sampled by the compute
+// node is preferred. Otherwise, the resources allocated to the execution are charged, which are the resources
+// requested by the job, or the defaults of the compute nodes for the resources it didn't request.
+func (s *scheduler) chargedResources(job model.Job, usage *model.ResourceUsageSummary) model.ResourceUsageData {
+	allocated := capacity.ParseResourceUsageConfig(job.Spec.Resources).Intersect(s.defaultResources)
+	if usage == nil {
+		return allocated
+	}
+	charged := usage.Requested.Intersect(allocated)
+	if usage.Peak.CPU > 0 {
+		charged.CPU = usage.Peak.CPU
+	}
+	if usage.Peak.Memory > 0 {
+		charged.Memory = usage.Peak.Memory
+	}
+	return charged
+}
+
+// getExecution returns the current state of the execution
+func (s *scheduler) getExecution(ctx context.Context, executionID model.ExecutionID) (model.ExecutionState, error) {
+	jobState, err := s.jobStore.GetJobState(ctx, executionID.JobID)
+	if err != nil {
+		return model.ExecutionState{}, err
+	}
+	for _, execution := range jobState.Executions {
+		if execution.ID() == executionID {
+			return execution, nil
+		}
+	}
+	return model.ExecutionState{}, jobstore.NewErrExecutionNotFound(executionID)
+}
+
 func (s *scheduler) startVerificationIfPossible(ctx context.Context, jobID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -515,31 +837,35 @@ func (s *scheduler) startVerificationIfPossible(ctx context.Context, jobID strin
 		return
 	}
 
-	var pendingVerifications []model.ExecutionState
-	for _, execution := range jobState.Executions {
-		// compute nodes that are waiting for their results to be verified
-		if execution.State == model.ExecutionStateResultProposed {
-			pendingVerifications = append(pendingVerifications, execution)
-		}
-	}
-
 	job, err := s.jobStore.GetJob(ctx, jobID)
 	if err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get job")
+		log.Ctx(ctx).Error().Err(err).Msg("[startVerificationIfPossible] failed to get job")
 		return
 	}
-	// TODO: technically we can start verifying if we have enough results compared to deal's confidence
-	//  and concurrency. Though we will have ot handle the case where verification fails, but can still
-	//  succeed if we wait for more results.
-	if len(pendingVerifications) >= job.Spec.Deal.Concurrency {
-		verifiedResults, verificationErr := s.verifyResult(ctx, job, pendingVerifications)
-		if verificationErr != nil {
-			s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("failed to verify job %s: %w", jobID, verificationErr))
-			return
+
+	// the results of each shard are verified independently
+	for _, executions := range groupExecutionsByShard(job, jobState) {
+		var pendingVerifications []model.ExecutionState
+		for _, execution := range executions {
+			// compute nodes that are waiting for their results to be verified
+			if execution.State == model.ExecutionStateResultProposed {
+				pendingVerifications = append(pendingVerifications, execution)
+			}
 		}
-		if len(verifiedResults) == 0 {
-			s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("failed to verify job %s: no verified results", jobID))
-			return
+
+		// TODO: technically we can start verifying if we have enough results compared to deal's confidence
+		//  and concurrency. Though we will have ot handle the case where verification fails, but can still
+		//  succeed if we wait for more results.
+		if len(pendingVerifications) >= job.Spec.Deal.Concurrency {
+			verifiedResults, verificationErr := s.verifyResult(ctx, job, pendingVerifications)
+			if verificationErr != nil {
+				s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("failed to verify job %s: %w", jobID, verificationErr))
+				return
+			}
+			if len(verifiedResults) == 0 {
+				s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("failed to verify job %s: no verified results", jobID))
+				return
+			}
 		}
 	}
 }
@@ -582,6 +908,17 @@ func (s *scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 		// An execution is still being worked on, so the job isn't completed yet.
 		return
 	}
+	job, err := s.jobStore.GetJob(ctx, result.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[OnPublishComplete] failed to get job")
+		return
+	}
+	for _, executions := range groupExecutionsByShard(job, jobState) {
+		if !hasCompletedExecution(executions) {
+			// A shard is still waiting to be executed, so the job isn't completed yet.
+			return
+		}
+	}
 	err = s.jobStore.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
 		JobID:    result.JobID,
 		NewState: model.JobStateCompleted,
@@ -592,6 +929,9 @@ func (s *scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 	} else {
 		log.Ctx(ctx).Info().Msgf("Job %s completed successfully", result.JobID)
 	}
+	if err = cacheJobResult(ctx, s.jobStore, job); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[OnPublishComplete] failed to cache the results of job %s", result.JobID)
+	}
 }
 
 func (s *scheduler) OnCancelComplete(ctx context.Context, result compute.CancelResult) {
@@ -604,13 +944,20 @@ func (s *scheduler) OnComputeFailure(ctx context.Context, result compute.Compute
 	log.Ctx(ctx).Debug().Err(result).Msgf("Requester node %s received ComputeFailure for execution: %s from %s",
 		s.id, result.ExecutionID, result.SourcePeerID)
 
+	executionID := model.ExecutionID{
+		JobID:       result.JobID,
+		NodeID:      result.SourcePeerID,
+		ExecutionID: result.ExecutionID,
+	}
+	execution, err := s.getExecution(ctx, executionID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[OnComputeFailure] failed to get execution")
+		return
+	}
+
 	// update execution state
-	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
-		ExecutionID: model.ExecutionID{
-			JobID:       result.JobID,
-			NodeID:      result.SourcePeerID,
-			ExecutionID: result.ExecutionID,
-		},
+	err = s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: executionID,
 		Condition: jobstore.UpdateExecutionCondition{
 			UnexpectedStates: []model.ExecutionStateType{
 				model.ExecutionStateCompleted,
@@ -626,6 +973,7 @@ func (s *scheduler) OnComputeFailure(ctx context.Context, result compute.Compute
 		log.Ctx(ctx).Error().Err(err).Msgf("[OnComputeFailure] failed to update execution")
 		return
 	}
+	s.recordUsageIfRan(ctx, execution)
 
 	s.eventEmitter.EmitComputeFailure(ctx, result)
 	s.mu.Lock()
@@ -635,11 +983,183 @@ func (s *scheduler) OnComputeFailure(ctx context.Context, result compute.Compute
 
 // make sure to call this function with the lock held
 func (s *scheduler) failIfRecoveryIsNotPossible(ctx context.Context, jobID string, failure error) {
-	if !s.isRecoveryStillPossible(ctx, jobID) {
+	s.releaseBrokenGangs(ctx, jobID)
+	var computeError compute.ComputeError
+	preempted := errors.As(failure, &computeError) && computeError.Preempted
+	if !s.isRecoveryStillPossible(ctx, jobID) && !s.retryIfPossible(ctx, jobID, preempted) {
 		s.stopJob(ctx, jobID, failure.Error(), false)
 	}
 }
 
+// retryIfPossible asks fresh nodes to bid on the job to replace discarded executions, as long as the job's
+// retry policy allows it. Executions preempted by higher priority jobs didn't fail on their own, so they are
+// retried even if the retry policy has no attempts left. It returns false if the job can't be retried.
+// make sure to call this function with the lock held
+func (s *scheduler) retryIfPossible(ctx context.Context, jobID string, preempted bool) bool {
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to get job")
+		return false
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to get job state")
+		return false
+	}
+	if jobState.State.IsTerminal() {
+		return false
+	}
+
+	retryPolicy := job.Spec.Deal.RetryPolicy
+	attempt := jobState.LatestAttempt()
+	if attempt >= retryPolicy.MaxAttempts && !preempted {
+		return false
+	}
+
+	rankedNodes, err := s.rankNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to rank nodes")
+		return false
+	}
+
+	// nodes that are still working on a shard are never asked to bid on it again, and nodes that didn't
+	// respond with an execution id can't be asked again, as the new execution would have the same id
+	// as the discarded one.
+	excludedNodes := make(map[string]struct{})
+	if retryPolicy.ExcludePreviousNodes {
+		for _, execution := range jobState.Executions {
+			if execution.State.IsDiscarded() {
+				excludedNodes[execution.NodeID] = struct{}{}
+			}
+		}
+	}
+
+	// only shards that lost their executions are retried. Shards waiting for nodes are dispatched as nodes free up.
+	var failedShards []int
+	for shardIndex, executions := range groupExecutionsByShard(job, jobState) {
+		if !isShardWaitingForNodes(job, executions) && countActiveExecutions(executions) < job.Spec.Deal.Concurrency {
+			failedShards = append(failedShards, shardIndex)
+		}
+	}
+
+	assignments, ok := assignNodesToShards(job, jobState, rankedNodes, failedShards, excludedNodes, job.Spec.Deal.Concurrency)
+	if !ok {
+		log.Ctx(ctx).Debug().Msgf("not enough nodes to retry %d shards of job %s. available: %d",
+			len(failedShards), jobID, len(assignments))
+		return false
+	}
+
+	// persist the intent to ask the nodes for a bid before backing off, so that other failures
+	// received in the meantime don't consider the job as unrecoverable.
+	err = s.createAskForBidExecutions(ctx, &job, assignments, attempt+1)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to create executions")
+		return false
+	}
+	log.Ctx(ctx).Info().Msgf("retrying job %s on %d nodes (attempt %d of %d)",
+		jobID, len(assignments), attempt+1, retryPolicy.MaxAttempts)
+
+	go s.notifyAskForBidAfterBackoff(
+		util.NewDetachedContext(ctx), trace.LinkFromContext(ctx), &job, assignments, retryPolicy.GetBackoff())
+	return true
+}
+
+// TimeoutExecutions discards the executions of the job that exceeded the timeout of their current phase.
+// Stale bids are rejected, and nodes that are too slow to run, verify or publish the job are asked to cancel
+// their execution, before asking other nodes to replace them according to the job's retry policy.
+func (s *scheduler) TimeoutExecutions(ctx context.Context, jobID string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[TimeoutExecutions] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[TimeoutExecutions] failed to get job state")
+		return
+	}
+	if jobState.State != model.JobStateInProgress {
+		return
+	}
+
+	timedOut := 0
+	for _, execution := range jobState.Executions {
+		timeout := job.Spec.Timeouts.GetPhaseTimeout(execution.State)
+		if timeout <= 0 || now.Sub(execution.UpdateTime) <= timeout {
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("execution %s timed out in state %s after %s", execution, execution.State, timeout)
+		reason := fmt.Sprintf("timed out in state %s after %s", execution.State, timeout)
+		if s.discardExecution(ctx, execution, model.ExecutionStateFailed, reason) {
+			timedOut++
+		}
+	}
+	if timedOut > 0 {
+		s.failIfRecoveryIsNotPossible(ctx, jobID, fmt.Errorf("%d executions timed out", timedOut))
+	}
+}
+
+// discardExecution discards an execution that is no longer needed, and notifies the compute node. Pending bids
+// are rejected, and executions that are already running are moved to runningState and canceled.
+// It returns false if the execution couldn't be updated.
+// make sure to call this function with the lock held
+func (s *scheduler) discardExecution(
+	ctx context.Context, execution model.ExecutionState, runningState model.ExecutionStateType, reason string) bool {
+	if execution.State == model.ExecutionStateAskForBidAccepted {
+		// the node is waiting for a decision on its bid, which we reject so that it stops holding resources for the job
+		s.notifyBidRejected(ctx, execution)
+		return true
+	}
+
+	newState := runningState
+	if execution.State == model.ExecutionStateAskForBid {
+		newState = model.ExecutionStateAskForBidRejected
+	}
+	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition: jobstore.UpdateExecutionCondition{
+			ExpectedState:   execution.State,
+			ExpectedVersion: execution.Version,
+		},
+		NewValues: model.ExecutionState{
+			State:  newState,
+			Status: reason,
+		},
+		Comment: reason,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[discardExecution] failed to update execution %s", execution)
+		return false
+	}
+	s.recordUsageIfRan(ctx, execution)
+	// nodes that haven't responded to the bid request have no execution to cancel yet
+	if execution.HasAcceptedAskForBid() {
+		s.notifyCancel(ctx, reason, execution)
+	}
+	return true
+}
+
+func (s *scheduler) notifyAskForBidAfterBackoff(
+	ctx context.Context, link trace.Link, job *model.Job, assignments []shardAssignment, backoff time.Duration) {
+	time.Sleep(backoff)
+
+	// the job might have been canceled or timed out while we were backing off
+	jobState, err := s.jobStore.GetJobState(ctx, job.Metadata.ID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[notifyAskForBidAfterBackoff] failed to get job state")
+		return
+	}
+	if jobState.State.IsTerminal() {
+		return
+	}
+
+	for _, assignment := range assignments {
+		go s.doNotifyAskForBid(ctx, link, job, assignment)
+	}
+}
+
 func (s *scheduler) isRecoveryStillPossible(ctx context.Context, jobID string) bool {
 	jobState, err := s.jobStore.GetJobState(ctx, jobID)
 	if err != nil {
@@ -652,14 +1172,13 @@ func (s *scheduler) isRecoveryStillPossible(ctx context.Context, jobID string) b
 		return false
 	}
 
-	activeExecutions := 0
-	for _, execution := range jobState.Executions {
-		if !execution.State.IsDiscarded() {
-			activeExecutions++
+	// shards waiting for nodes are dispatched as nodes free up, and the job is failed if none are left
+	for _, executions := range groupExecutionsByShard(job, jobState) {
+		if !isShardWaitingForNodes(job, executions) && countActiveExecutions(executions) < job.Spec.Deal.Concurrency {
+			return false
 		}
 	}
-
-	return activeExecutions >= job.Spec.Deal.Concurrency
+	return true
 }
 
 // make sure to call this function with the lock held
@@ -676,6 +1195,7 @@ func (s *scheduler) stopJob(ctx context.Context, jobID, reason string, userReque
 	}
 
 	for _, execution := range cancelledExecutions {
+		s.recordUsageIfRan(ctx, execution)
 		s.notifyCancel(ctx, reason, execution)
 	}
 	eventName := model.JobEventError
@@ -693,4 +1213,8 @@ func (s *scheduler) stopJob(ctx context.Context, jobID, reason string, userReque
 
 // compile-time check that BackendCallback implements the expected interfaces
 var _ Scheduler = (*scheduler)(nil)
+var _ ExecutionTimeoutHandler = (*scheduler)(nil)
+var _ LostExecutionHandler = (*scheduler)(nil)
+var _ StragglerHandler = (*scheduler)(nil)
+var _ JobAdopter = (*scheduler)(nil)
 var _ compute.Callback = (*scheduler)(nil)
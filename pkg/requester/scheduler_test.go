@@ -2,8 +2,27 @@ package requester
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/compute"
+	"github.com/bacalhau-project/bacalhau/pkg/eventhandler"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	noop_storage "github.com/bacalhau-project/bacalhau/pkg/storage/noop"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/verifier"
+	noop_verifier "github.com/bacalhau-project/bacalhau/pkg/verifier/noop"
+	"github.com/google/uuid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
 )
 
 type startJobHandler func(context.Context, StartJobRequest) error
@@ -59,4 +78,818 @@ func (m *mockScheduler) StartJob(ctx context.Context, sjr StartJobRequest) error
 	return m.handleStartJob(ctx, sjr)
 }
 
+// UpdateJob implements Scheduler
+func (m *mockScheduler) UpdateJob(ctx context.Context, ujr UpdateJobRequest) error {
+	return nil
+}
+
 var _ Scheduler = (*mockScheduler)(nil)
+
+type mockNodeDiscoverer struct {
+	nodes []model.NodeInfo
+}
+
+// FindNodes implements NodeDiscoverer
+func (m *mockNodeDiscoverer) FindNodes(context.Context, model.Job) ([]model.NodeInfo, error) {
+	return m.nodes, nil
+}
+
+type mockNodeRanker struct{}
+
+// RankNodes implements NodeRanker
+func (m *mockNodeRanker) RankNodes(_ context.Context, _ model.Job, nodes []model.NodeInfo) ([]NodeRank, error) {
+	ranks := make([]NodeRank, 0, len(nodes))
+	for _, node := range nodes {
+		ranks = append(ranks, NodeRank{NodeInfo: node, Rank: 1})
+	}
+	return ranks, nil
+}
+
+// mockComputeEndpoint accepts every ask for bid, and ignores every other request
+type mockComputeEndpoint struct {
+	compute.Endpoint
+	// prices quoted in the bids of each node
+	prices map[string]float64
+	// gang membership sent to each node when its bid was accepted
+	gangs sync.Map
+}
+
+func (m *mockComputeEndpoint) AskForBid(_ context.Context, request compute.AskForBidRequest) (compute.AskForBidResponse, error) {
+	return compute.AskForBidResponse{
+		ExecutionMetadata: compute.ExecutionMetadata{
+			ExecutionID: uuid.NewString(),
+			JobID:       request.Job.Metadata.ID,
+		},
+		Accepted: true,
+		Price:    m.prices[request.TargetPeerID],
+	}, nil
+}
+
+func (m *mockComputeEndpoint) BidAccepted(_ context.Context, request compute.BidAcceptedRequest) (compute.BidAcceptedResponse, error) {
+	m.gangs.Store(request.TargetPeerID, request.Gang)
+	return compute.BidAcceptedResponse{}, nil
+}
+
+func (m *mockComputeEndpoint) BidRejected(context.Context, compute.BidRejectedRequest) (compute.BidRejectedResponse, error) {
+	return compute.BidRejectedResponse{}, nil
+}
+
+func (m *mockComputeEndpoint) ResultAccepted(context.Context, compute.ResultAcceptedRequest) (compute.ResultAcceptedResponse, error) {
+	return compute.ResultAcceptedResponse{}, nil
+}
+
+func (m *mockComputeEndpoint) CancelExecution(context.Context, compute.CancelExecutionRequest) (compute.CancelExecutionResponse, error) {
+	return compute.CancelExecutionResponse{}, nil
+}
+
+func getTestScheduler(t *testing.T, nodeCount int) (*scheduler, jobstore.Store) {
+	nodes := make([]model.NodeInfo, 0, nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		nodes = append(nodes, model.NodeInfo{PeerInfo: peer.AddrInfo{
+			ID: peer.ID(fmt.Sprintf("node-%d", i)),
+			Addrs: []multiaddr.Multiaddr{
+				multiaddr.StringCast("/ip4/127.0.0.1/tcp/1235"),
+				multiaddr.StringCast(fmt.Sprintf("/ip4/10.0.0.%d/tcp/1235", i)),
+			},
+		}})
+	}
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(context.Background()) })
+	verifier_mock, err := noop_verifier.NewNoopVerifier(context.Background(), cm)
+	require.NoError(t, err)
+
+	store := inmemory.NewJobStore()
+	s := NewScheduler(SchedulerParams{
+		ID:              "requester",
+		JobStore:        store,
+		NodeDiscoverer:  &mockNodeDiscoverer{nodes: nodes},
+		NodeRanker:      &mockNodeRanker{},
+		ComputeEndpoint: &mockComputeEndpoint{},
+		Verifiers:       model.NewNoopProvider[model.Verifier, verifier.Verifier](verifier_mock),
+		StorageProviders: model.NewNoopProvider[model.StorageSourceType, storage.Storage](noop_storage.NewNoopStorage(
+			noop_storage.StorageConfig{
+				ExternalHooks: noop_storage.StorageConfigExternalHooks{
+					// every input is a directory of three files
+					Explode: func(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+						var items []model.StorageSpec
+						for _, name := range []string{"a", "b", "c"} {
+							item := spec
+							item.Path = filepath.Join(spec.Path, name)
+							items = append(items, item)
+						}
+						return items, nil
+					},
+				},
+			})),
+		EventEmitter: NewEventEmitter(EventEmitterParams{
+			EventConsumer: eventhandler.JobEventHandlerFunc(func(context.Context, model.JobEvent) error { return nil }),
+		}),
+	})
+	return s, store
+}
+
+// waitForRunningExecution waits until the job has a single execution with an accepted bid, and returns it
+func waitForRunningExecution(t *testing.T, store jobstore.Store, jobID string) model.ExecutionState {
+	var running model.ExecutionState
+	require.Eventually(t, func() bool {
+		jobState, err := store.GetJobState(context.Background(), jobID)
+		require.NoError(t, err)
+		for _, execution := range jobState.Executions {
+			if execution.State == model.ExecutionStateAskForBid || execution.State == model.ExecutionStateAskForBidAccepted {
+				return false
+			}
+			if execution.State == model.ExecutionStateBidAccepted {
+				running = execution
+			}
+		}
+		return running.State == model.ExecutionStateBidAccepted
+	}, 5*time.Second, 10*time.Millisecond)
+	return running
+}
+
+func failExecution(s *scheduler, execution model.ExecutionState) {
+	s.OnComputeFailure(context.Background(), compute.ComputeError{
+		RoutingMetadata: compute.RoutingMetadata{
+			SourcePeerID: execution.NodeID,
+			TargetPeerID: s.id,
+		},
+		ExecutionMetadata: compute.ExecutionMetadata{
+			ExecutionID: execution.ComputeReference,
+			JobID:       execution.JobID,
+		},
+		Err: "execution failed",
+	})
+}
+
+func TestSchedulerRetriesFailedExecutions(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{
+				Concurrency: 1,
+				RetryPolicy: model.RetryPolicy{
+					MaxAttempts:          1,
+					ExcludePreviousNodes: true,
+				},
+			},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	// the first attempt asks three nodes to bid, and accepts one of them
+	firstExecution := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, 0, firstExecution.Attempt)
+
+	// failing the execution should ask the only node that wasn't asked before
+	failExecution(s, firstExecution)
+	retriedExecution := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, 1, retriedExecution.Attempt)
+	require.NotEqual(t, firstExecution.NodeID, retriedExecution.NodeID)
+
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+	require.Len(t, jobState.Executions, 4)
+
+	// no more attempts left
+	failExecution(s, retriedExecution)
+	jobState, err = store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateError, jobState.State)
+}
+
+func TestSchedulerFailsWithoutRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{Concurrency: 1},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	failExecution(s, waitForRunningExecution(t, store, job.ID()))
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateError, jobState.State)
+}
+
+func TestSchedulerRetriesPreemptedExecutions(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{Concurrency: 1},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	// preempted executions are retried even without a retry policy
+	preemptedExecution := waitForRunningExecution(t, store, job.ID())
+	s.OnComputeFailure(ctx, compute.ComputeError{
+		RoutingMetadata: compute.RoutingMetadata{
+			SourcePeerID: preemptedExecution.NodeID,
+			TargetPeerID: s.id,
+		},
+		ExecutionMetadata: compute.ExecutionMetadata{
+			ExecutionID: preemptedExecution.ComputeReference,
+			JobID:       preemptedExecution.JobID,
+		},
+		Err:       "execution preempted by higher priority execution",
+		Preempted: true,
+	})
+	retriedExecution := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, 1, retriedExecution.Attempt)
+
+	// regular failures still follow the retry policy
+	failExecution(s, retriedExecution)
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateError, jobState.State)
+}
+
+func TestSchedulerAcceptsCheapestBids(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 3)
+	s.computeService.(*mockComputeEndpoint).prices = map[string]float64{
+		peer.ID("node-0").String(): 8,
+		peer.ID("node-1").String(): 3,
+		peer.ID("node-2").String(): 2,
+	}
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{Concurrency: 1, MinBids: 3, MaxPrice: 5},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	running := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, peer.ID("node-2").String(), running.NodeID)
+	require.Equal(t, 2.0, running.Price)
+
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+	for _, execution := range jobState.Executions {
+		if execution.NodeID != running.NodeID {
+			require.Equal(t, model.ExecutionStateBidRejected, execution.State)
+		}
+	}
+}
+
+func TestSchedulerFailsWithoutBidsWithinMaxPrice(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 1)
+	s.computeService.(*mockComputeEndpoint).prices = map[string]float64{peer.ID("node-0").String(): 8}
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{Concurrency: 1, MaxPrice: 5},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	require.Eventually(t, func() bool {
+		jobState, err := store.GetJobState(ctx, job.ID())
+		require.NoError(t, err)
+		return jobState.State == model.JobStateError
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSchedulerTimesOutSlowExecutions(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{
+				Concurrency: 1,
+				RetryPolicy: model.RetryPolicy{
+					MaxAttempts:          1,
+					ExcludePreviousNodes: true,
+				},
+			},
+			Timeouts: model.JobTimeouts{Execution: 60},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+	firstExecution := waitForRunningExecution(t, store, job.ID())
+
+	// nothing happens while the execution is within its timeout
+	s.TimeoutExecutions(ctx, job.ID(), time.Now())
+	require.Equal(t, firstExecution.ComputeReference, waitForRunningExecution(t, store, job.ID()).ComputeReference)
+
+	// the slow execution is discarded and replaced by an execution on another node
+	s.TimeoutExecutions(ctx, job.ID(), time.Now().Add(2*time.Minute))
+	retriedExecution := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, 1, retriedExecution.Attempt)
+	require.NotEqual(t, firstExecution.NodeID, retriedExecution.NodeID)
+
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+	for _, execution := range jobState.Executions {
+		if execution.ID() == firstExecution.ID() {
+			require.Equal(t, model.ExecutionStateFailed, execution.State)
+			require.Contains(t, execution.Status, "timed out")
+		}
+	}
+
+	// no more attempts left
+	s.TimeoutExecutions(ctx, job.ID(), time.Now().Add(4*time.Minute))
+	jobState, err = store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateError, jobState.State)
+}
+
+func TestSchedulerMigratesExecutionsOfLostNodes(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+	s.lostNodeGracePeriod = time.Minute
+	discoverer := s.nodeDiscoverer.(*mockNodeDiscoverer)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{
+				Concurrency: 1,
+				RetryPolicy: model.RetryPolicy{MaxAttempts: 1},
+			},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+	firstExecution := waitForRunningExecution(t, store, job.ID())
+
+	// the node is still discovered, so its execution is kept
+	s.MigrateLostExecutions(ctx, job.ID(), time.Now().Add(2*time.Minute))
+	require.Equal(t, firstExecution.ComputeReference, waitForRunningExecution(t, store, job.ID()).ComputeReference)
+
+	// the node stopped publishing its node info, but the execution is still within the grace period
+	removeNode := func(nodeID string) {
+		var nodes []model.NodeInfo
+		for _, node := range discoverer.nodes {
+			if node.PeerInfo.ID.String() != nodeID {
+				nodes = append(nodes, node)
+			}
+		}
+		discoverer.nodes = nodes
+	}
+	removeNode(firstExecution.NodeID)
+	s.MigrateLostExecutions(ctx, job.ID(), s.startTime.Add(30*time.Second))
+	require.Equal(t, firstExecution.ComputeReference, waitForRunningExecution(t, store, job.ID()).ComputeReference)
+
+	// the lost execution is replaced by an execution on another node
+	s.MigrateLostExecutions(ctx, job.ID(), time.Now().Add(2*time.Minute))
+	retriedExecution := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, 1, retriedExecution.Attempt)
+	require.NotEqual(t, firstExecution.NodeID, retriedExecution.NodeID)
+
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+	for _, execution := range jobState.Executions {
+		if execution.ID() == firstExecution.ID() {
+			require.Equal(t, model.ExecutionStateLost, execution.State)
+		}
+	}
+	history, err := store.GetJobHistory(ctx, job.ID())
+	require.NoError(t, err)
+	var lostComments []string
+	for _, event := range history {
+		if event.ExecutionState != nil && event.ExecutionState.New == model.ExecutionStateLost {
+			lostComments = append(lostComments, event.Comment)
+		}
+	}
+	require.Len(t, lostComments, 1)
+	require.Contains(t, lostComments[0], "stopped publishing its node info")
+
+	// no more attempts left once the second node is lost too
+	removeNode(retriedExecution.NodeID)
+	s.MigrateLostExecutions(ctx, job.ID(), time.Now().Add(4*time.Minute))
+	jobState, err = store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateError, jobState.State)
+}
+
+func TestSchedulerStartsGangMembersTogether(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+	computeService := s.computeService.(*mockComputeEndpoint)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{
+				Concurrency: 2,
+				Gang:        true,
+				RetryPolicy: model.RetryPolicy{MaxAttempts: 1},
+			},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	// waitForGang waits until the job has two running members of the given attempt, and every other bid was rejected
+	waitForGang := func(attempt int) []model.ExecutionState {
+		var members []model.ExecutionState
+		require.Eventually(t, func() bool {
+			jobState, err := store.GetJobState(ctx, job.ID())
+			require.NoError(t, err)
+			members = nil
+			for _, execution := range jobState.Executions {
+				if execution.State == model.ExecutionStateAskForBid || execution.State == model.ExecutionStateAskForBidAccepted {
+					return false
+				}
+				if execution.State == model.ExecutionStateBidAccepted && execution.Attempt == attempt {
+					members = append(members, execution)
+				}
+			}
+			return len(members) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		return members
+	}
+	// host returns the ip address the node of the execution is reachable at
+	host := func(execution model.ExecutionState) string {
+		for i, node := range s.nodeDiscoverer.(*mockNodeDiscoverer).nodes {
+			if node.PeerInfo.ID.String() == execution.NodeID {
+				return fmt.Sprintf("10.0.0.%d", i)
+			}
+		}
+		return ""
+	}
+	requirePeers := func(members []model.ExecutionState) {
+		for i, member := range members {
+			var gang compute.GangMembership
+			require.Eventually(t, func() bool {
+				value, ok := computeService.gangs.Load(member.NodeID)
+				if ok {
+					gang = value.(compute.GangMembership)
+				}
+				return ok && len(gang.Addresses) == 2
+			}, 5*time.Second, 10*time.Millisecond)
+
+			// each member is reached on the non-loopback address of its node
+			own, err := net.ResolveTCPAddr("tcp", gang.Addresses[gang.Rank])
+			require.NoError(t, err)
+			require.Equal(t, host(member), own.IP.String())
+			peer, err := net.ResolveTCPAddr("tcp", gang.Addresses[1-gang.Rank])
+			require.NoError(t, err)
+			require.Equal(t, host(members[1-i]), peer.IP.String())
+			require.NotEqual(t, own.Port, peer.Port)
+		}
+	}
+
+	// both members are accepted together, and each is given its own address and the address of the other
+	members := waitForGang(0)
+	requirePeers(members)
+
+	// a member dropping out releases the other, and a new gang is started
+	failExecution(s, members[0])
+	retriedMembers := waitForGang(1)
+	requirePeers(retriedMembers)
+
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+	for _, execution := range jobState.Executions {
+		if execution.ID() == members[1].ID() {
+			require.Equal(t, model.ExecutionStateCanceled, execution.State)
+		}
+	}
+}
+
+func TestSchedulerLaunchesBackupExecutionsForStragglers(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{
+				Concurrency: 1,
+				Speculation: model.SpeculationPolicy{Percentile: 90, MinDuration: 60},
+			},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+	original := waitForRunningExecution(t, store, job.ID())
+
+	// the execution is not slow yet
+	s.LaunchBackupExecutions(ctx, job.ID(), time.Now())
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	executionCount := len(jobState.Executions)
+
+	// the backup execution runs alongside the slow execution, and is only launched once
+	s.LaunchBackupExecutions(ctx, job.ID(), time.Now().Add(2*time.Minute))
+	var backup model.ExecutionState
+	require.Eventually(t, func() bool {
+		jobState, err = store.GetJobState(ctx, job.ID())
+		require.NoError(t, err)
+		for _, execution := range jobState.Executions {
+			if execution.Speculative {
+				backup = execution
+			}
+		}
+		return backup.State == model.ExecutionStateBidAccepted
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NotEqual(t, original.NodeID, backup.NodeID)
+	s.LaunchBackupExecutions(ctx, job.ID(), time.Now().Add(4*time.Minute))
+	jobState, err = store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Len(t, jobState.Executions, executionCount+1)
+
+	// the results of the backup execution are proposed first, and the slow execution is canceled
+	s.OnRunComplete(ctx, compute.RunResult{
+		RoutingMetadata: compute.RoutingMetadata{
+			SourcePeerID: backup.NodeID,
+			TargetPeerID: s.id,
+		},
+		ExecutionMetadata: compute.ExecutionMetadata{
+			ExecutionID: backup.ComputeReference,
+			JobID:       job.ID(),
+		},
+	})
+	jobState, err = store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	for _, execution := range jobState.Executions {
+		switch execution.ID() {
+		case original.ID():
+			require.Equal(t, model.ExecutionStateCanceled, execution.State)
+		case backup.ID():
+			require.Equal(t, model.ExecutionStateResultAccepted, execution.State)
+		}
+	}
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+}
+
+func TestSchedulerResumesJobsAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			Requester: model.JobRequester{RequesterNodeID: s.id},
+		},
+		Spec: model.Spec{
+			Deal: model.Deal{
+				Concurrency: 1,
+				RetryPolicy: model.RetryPolicy{MaxAttempts: 1},
+			},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    job.ID(),
+		NewState: model.JobStateInProgress,
+	}))
+	// a bid request that was in flight when the requester stopped
+	require.NoError(t, store.CreateExecution(ctx, model.ExecutionState{
+		JobID:  job.ID(),
+		NodeID: "node-0",
+		State:  model.ExecutionStateAskForBid,
+	}))
+
+	jobs, err := store.GetInProgressJobs(ctx)
+	require.NoError(t, err)
+	s.ResumeJobs(ctx, jobs)
+
+	execution := waitForRunningExecution(t, store, job.ID())
+	require.Equal(t, 1, execution.Attempt)
+	require.NotEqual(t, "node-0", execution.NodeID)
+}
+
+// waitForRunningShards waits until every shard of the job has an execution with an accepted bid, and returns them
+func waitForRunningShards(t *testing.T, store jobstore.Store, jobID string, totalShards int) []model.ExecutionState {
+	running := make([]model.ExecutionState, totalShards)
+	require.Eventually(t, func() bool {
+		jobState, err := store.GetJobState(context.Background(), jobID)
+		require.NoError(t, err)
+		for _, execution := range jobState.Executions {
+			if execution.State == model.ExecutionStateAskForBid || execution.State == model.ExecutionStateAskForBidAccepted {
+				return false
+			}
+			if execution.State == model.ExecutionStateBidAccepted {
+				running[execution.ShardIndex] = execution
+			}
+		}
+		for _, execution := range running {
+			if execution.State != model.ExecutionStateBidAccepted {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	return running
+}
+
+func TestSchedulerShardsJobInputs(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Inputs: []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmInputs", Path: "/inputs"}},
+			Sharding: model.JobShardingConfig{
+				GlobPattern: "/inputs/*",
+				BatchSize:   2,
+			},
+			Deal: model.Deal{Concurrency: 1},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	// the three input files are split into two shards
+	storedJob, err := store.GetJob(ctx, job.ID())
+	require.NoError(t, err)
+	shards := storedJob.Spec.ExecutionPlan.Shards
+	require.Len(t, shards, 2)
+	require.Len(t, shards[0].Inputs, 2)
+	require.Equal(t, "/inputs/a", shards[0].Inputs[0].Path)
+	require.Equal(t, "/inputs/b", shards[0].Inputs[1].Path)
+	require.Len(t, shards[1].Inputs, 1)
+	require.Equal(t, "/inputs/c", shards[1].Inputs[0].Path)
+
+	running := waitForRunningShards(t, store, job.ID(), 2)
+	require.NotEqual(t, running[0].NodeID, running[1].NodeID)
+
+	// failing a shard fails the job when it can't be retried
+	failExecution(s, running[1])
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateError, jobState.State)
+}
+
+func TestSchedulerDispatchesShardsAsNodesFreeUp(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 1)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Inputs:   []model.StorageSpec{{StorageSource: model.StorageSourceIPFS, CID: "QmInputs", Path: "/inputs"}},
+			Sharding: model.JobShardingConfig{BatchSize: 1},
+			Deal:     model.Deal{Concurrency: 1},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	// the only node is asked to bid on the next shard once it responded to the previous one
+	for _, execution := range waitForRunningShards(t, store, job.ID(), 3) {
+		require.Equal(t, peer.ID("node-0").String(), execution.NodeID)
+	}
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+	require.Len(t, jobState.Executions, 3)
+}
+
+func TestSchedulerAdjustsExecutionsToUpdatedConcurrency(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Deal: model.Deal{Concurrency: 1},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+	first := waitForRunningExecution(t, store, job.ID())
+
+	updateConcurrency := func(concurrency int) {
+		jobState, err := store.GetJobState(ctx, job.ID())
+		require.NoError(t, err)
+		job.Spec.Deal.Concurrency = concurrency
+		require.NoError(t, store.UpdateJob(ctx, jobstore.UpdateJobRequest{
+			Job:       job,
+			Condition: jobstore.UpdateJobCondition{ExpectedVersion: jobState.Version},
+		}))
+		require.NoError(t, s.UpdateJob(ctx, UpdateJobRequest{Job: job}))
+	}
+	countExecutions := func(state model.ExecutionStateType) int {
+		jobState, err := store.GetJobState(ctx, job.ID())
+		require.NoError(t, err)
+		count := 0
+		for _, execution := range jobState.Executions {
+			if execution.State == state {
+				count++
+			}
+		}
+		return count
+	}
+
+	// more nodes are asked to bid when the concurrency is raised
+	updateConcurrency(3)
+	require.Eventually(t, func() bool {
+		return countExecutions(model.ExecutionStateBidAccepted) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the most recently started executions are canceled when the concurrency is lowered
+	updateConcurrency(1)
+	require.Equal(t, 1, countExecutions(model.ExecutionStateBidAccepted))
+	require.Equal(t, 2, countExecutions(model.ExecutionStateCanceled))
+	jobState, err := store.GetJobState(ctx, job.ID())
+	require.NoError(t, err)
+	for _, execution := range jobState.Executions {
+		if execution.State == model.ExecutionStateBidAccepted {
+			require.Equal(t, first.ID(), execution.ID())
+		}
+	}
+	require.Equal(t, model.JobStateInProgress, jobState.State)
+}
+
+func TestSchedulerRecordsUsageOfExecutions(t *testing.T) {
+	ctx := context.Background()
+	s, store := getTestScheduler(t, 4)
+	s.defaultResources = model.ResourceUsageData{CPU: 0.1, Memory: 100 << 20}
+
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString(), ClientID: "client"},
+		Spec: model.Spec{
+			Resources: model.ResourceUsageConfig{Memory: "1Gi"},
+			Deal: model.Deal{
+				Concurrency: 1,
+				RetryPolicy: model.RetryPolicy{MaxAttempts: 1},
+			},
+		},
+	}
+	require.NoError(t, store.CreateJob(ctx, job))
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: job}))
+
+	// failed executions are charged the resources allocated to them, including the defaults of the compute nodes
+	failedExecution := waitForRunningExecution(t, store, job.ID())
+	failExecution(s, failedExecution)
+	records, err := store.GetUsageRecords(ctx, "client", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, failedExecution.ComputeReference, records[0].ExecutionID)
+	require.Equal(t, model.ResourceUsageData{CPU: 0.1, Memory: 1 << 30}, records[0].Resources)
+
+	// completed executions are charged their sampled peak usage
+	completedExecution := waitForRunningExecution(t, store, job.ID())
+	s.OnRunComplete(ctx, compute.RunResult{
+		RoutingMetadata: compute.RoutingMetadata{SourcePeerID: completedExecution.NodeID, TargetPeerID: s.id},
+		ExecutionMetadata: compute.ExecutionMetadata{
+			ExecutionID: completedExecution.ComputeReference,
+			JobID:       job.ID(),
+		},
+		ResourceUsage: &model.ResourceUsageSummary{
+			Requested: model.ResourceUsageData{CPU: 0.1, Memory: 1 << 30},
+			Peak:      model.ResourceUsageData{CPU: 0.05, Memory: 512 << 20},
+			Samples:   3,
+		},
+	})
+	records, err = store.GetUsageRecords(ctx, "client", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	for _, record := range records {
+		if record.ExecutionID == completedExecution.ComputeReference {
+			require.Equal(t, model.ResourceUsageData{CPU: 0.05, Memory: 512 << 20}, record.Resources)
+		}
+	}
+
+	// executions canceled while running are charged too
+	require.NoError(t, store.CreateJob(ctx, model.Job{
+		Metadata: model.Metadata{ID: "canceled", ClientID: "client"},
+		Spec:     model.Spec{Deal: model.Deal{Concurrency: 1}},
+	}))
+	canceledJob, err := store.GetJob(ctx, "canceled")
+	require.NoError(t, err)
+	require.NoError(t, s.StartJob(ctx, StartJobRequest{Job: canceledJob}))
+	canceledExecution := waitForRunningExecution(t, store, "canceled")
+	_, err = s.CancelJob(ctx, CancelJobRequest{JobID: "canceled", Reason: "canceled by the user", UserTriggered: true})
+	require.NoError(t, err)
+	records, err = store.GetUsageRecords(ctx, "client", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for _, record := range records {
+		if record.ExecutionID == canceledExecution.ComputeReference {
+			require.Equal(t, s.defaultResources, record.Resources)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util/cron"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// maxScheduleUpdateAttempts is the number of times an update is re-applied to the latest version of a schedule
+// when it was concurrently updated.
+const maxScheduleUpdateAttempts = 3
+
+// CreateSchedule creates a schedule that submits a job with the payload's spec every time its cron expression is due.
+func (node *BaseEndpoint) CreateSchedule(ctx context.Context, data model.ScheduleCreatePayload) (model.Schedule, error) {
+	cronSchedule, err := cron.Parse(data.Cron)
+	if err != nil {
+		return model.Schedule{}, err
+	}
+	now := time.Now().UTC()
+	nextRunAt := cronSchedule.Next(now)
+	if nextRunAt.IsZero() {
+		return model.Schedule{}, fmt.Errorf("cron expression %s is never due", data.Cron)
+	}
+
+	schedule := model.Schedule{
+		ID:              uuid.NewString(),
+		ClientID:        data.ClientID,
+		APIVersion:      data.APIVersion,
+		Cron:            data.Cron,
+		OverlapPolicy:   data.OverlapPolicy,
+		Spec:            *data.Spec,
+		RequesterNodeID: node.id,
+		CreatedAt:       now,
+		NextRunAt:       nextRunAt,
+	}
+	if err = node.store.CreateSchedule(ctx, schedule); err != nil {
+		return model.Schedule{}, err
+	}
+	return node.store.GetSchedule(ctx, schedule.ID)
+}
+
+// UpdateSchedule pauses or resumes a schedule. Runs that were due while the schedule was paused are not submitted.
+func (node *BaseEndpoint) UpdateSchedule(ctx context.Context, data model.ScheduleUpdatePayload) (model.Schedule, error) {
+	return updateSchedule(ctx, node.store, data.ScheduleID, func(schedule *model.Schedule) error {
+		if schedule.Paused == data.Paused {
+			return nil
+		}
+		schedule.Paused = data.Paused
+		if !data.Paused {
+			cronSchedule, err := cron.Parse(schedule.Cron)
+			if err != nil {
+				return err
+			}
+			schedule.NextRunAt = cronSchedule.Next(time.Now().UTC())
+		}
+		return nil
+	})
+}
+
+// DeleteSchedule deletes a schedule. Jobs that were already submitted by the schedule are not affected.
+func (node *BaseEndpoint) DeleteSchedule(ctx context.Context, data model.ScheduleDeletePayload) error {
+	schedule, err := node.store.GetSchedule(ctx, data.ScheduleID)
+	if err != nil {
+		return err
+	}
+	return node.store.DeleteSchedule(ctx, schedule.ID)
+}
+
+// updateSchedule applies the update to the latest version of the schedule, and retries if the schedule was
+// concurrently updated. The updated schedule is returned.
+func updateSchedule(
+	ctx context.Context, store jobstore.Store, scheduleID string, update func(*model.Schedule) error) (model.Schedule, error) {
+	var err error
+	for attempt := 0; attempt < maxScheduleUpdateAttempts; attempt++ {
+		var schedule model.Schedule
+		schedule, err = store.GetSchedule(ctx, scheduleID)
+		if err != nil {
+			return model.Schedule{}, err
+		}
+		if err = update(&schedule); err != nil {
+			return model.Schedule{}, err
+		}
+		err = store.UpdateSchedule(ctx, jobstore.UpdateScheduleRequest{
+			Schedule:        schedule,
+			ExpectedVersion: schedule.Version,
+		})
+		if err == nil {
+			return store.GetSchedule(ctx, schedule.ID)
+		}
+		if !errors.As(err, &jobstore.ErrInvalidScheduleVersion{}) {
+			return model.Schedule{}, err
+		}
+	}
+	return model.Schedule{}, err
+}
+
+type ScheduleRunnerParams struct {
+	Endpoint Endpoint
+	JobStore jobstore.Store
+	NodeID   string
+	Interval time.Duration
+}
+
+// ScheduleRunner is a background task that submits the jobs of the schedules owned by this requester node
+// when they are due, according to their overlap policy if the job of their previous run is still in progress.
+type ScheduleRunner struct {
+	endpoint Endpoint
+	jobStore jobstore.Store
+	nodeID   string
+	interval time.Duration
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewScheduleRunner(params ScheduleRunnerParams) *ScheduleRunner {
+	r := &ScheduleRunner{
+		endpoint:    params.Endpoint,
+		jobStore:    params.JobStore,
+		nodeID:      params.NodeID,
+		interval:    params.Interval,
+		stopChannel: make(chan struct{}),
+	}
+
+	go r.scheduleBackgroundTask()
+	return r
+}
+
+func (r *ScheduleRunner) scheduleBackgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(r.interval)
+	for {
+		select {
+		case <-ticker.C:
+			r.runDueSchedules(ctx, time.Now().UTC())
+		case <-r.stopChannel:
+			log.Ctx(ctx).Debug().Msg("stopped schedule runner task")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// runDueSchedules submits the jobs of the schedules that are due at the given time
+func (r *ScheduleRunner) runDueSchedules(ctx context.Context, now time.Time) {
+	schedules, err := r.jobStore.GetSchedules(ctx, "")
+	if err != nil {
+		log.Ctx(ctx).Err(err).Msg("failed to get schedules")
+		return
+	}
+	for _, schedule := range schedules {
+		// in case the job store is shared between multiple nodes, we only want to run schedules that are owned by this node
+		if schedule.RequesterNodeID != r.nodeID || schedule.Paused {
+			continue
+		}
+		if err = r.runSchedule(ctx, schedule, now); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[runDueSchedules] failed to run schedule %s", schedule.ID)
+		}
+	}
+}
+
+func (r *ScheduleRunner) runSchedule(ctx context.Context, schedule model.Schedule, now time.Time) error {
+	due := !schedule.NextRunAt.After(now)
+	if !due && schedule.PendingRuns == 0 {
+		return nil
+	}
+	cronSchedule, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return err
+	}
+	previousInProgress := r.isJobInProgress(ctx, schedule.LastJobID)
+
+	submit := false
+	cancelPrevious := false
+	if due {
+		// runs that were missed while the requester was down are collapsed into a single run
+		schedule.NextRunAt = cronSchedule.Next(now)
+		if !previousInProgress {
+			submit = true
+		} else {
+			switch schedule.OverlapPolicy {
+			case model.ScheduleOverlapSkip:
+				log.Ctx(ctx).Info().Msgf("skipping run of schedule %s as job %s is still in progress", schedule.ID, schedule.LastJobID)
+			case model.ScheduleOverlapQueue:
+				schedule.PendingRuns++
+			case model.ScheduleOverlapCancelPrevious:
+				cancelPrevious = true
+				submit = true
+			}
+		}
+	} else if !previousInProgress {
+		// submit the runs delayed by the queue overlap policy once the previous job completes
+		schedule.PendingRuns--
+		submit = true
+	}
+
+	// claim the run before submitting the job, so that the same run is not submitted twice if the schedule was
+	// concurrently updated
+	err = r.jobStore.UpdateSchedule(ctx, jobstore.UpdateScheduleRequest{
+		Schedule:        schedule,
+		ExpectedVersion: schedule.Version,
+	})
+	if err != nil || !submit {
+		return err
+	}
+
+	if cancelPrevious {
+		_, err = r.endpoint.CancelJob(ctx, CancelJobRequest{
+			JobID:  schedule.LastJobID,
+			Reason: fmt.Sprintf("canceled by the next run of schedule %s", schedule.ID),
+			// the client asked for the previous job to be canceled through the overlap policy
+			UserTriggered: true,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[runSchedule] failed to cancel job %s", schedule.LastJobID)
+		}
+	}
+
+	spec := schedule.Spec
+	job, err := r.endpoint.SubmitJob(ctx, model.JobCreatePayload{
+		ClientID:   schedule.ClientID,
+		APIVersion: schedule.APIVersion,
+		Spec:       &spec,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to submit job: %w", err)
+	}
+	log.Ctx(ctx).Info().Msgf("schedule %s submitted job %s", schedule.ID, job.Metadata.ID)
+
+	_, err = updateSchedule(ctx, r.jobStore, schedule.ID, func(latest *model.Schedule) error {
+		latest.LastJobID = job.Metadata.ID
+		latest.LastRunAt = now
+		return nil
+	})
+	return err
+}
+
+// isJobInProgress returns true if the job exists and is not in a terminal state yet
+func (r *ScheduleRunner) isJobInProgress(ctx context.Context, jobID string) bool {
+	if jobID == "" {
+		return false
+	}
+	jobState, err := r.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("failed to get state of job %s", jobID)
+		return false
+	}
+	return !jobState.State.IsTerminal()
+}
+
+func (r *ScheduleRunner) Stop() {
+	r.stopOnce.Do(func() {
+		r.stopChannel <- struct{}{}
+	})
+}
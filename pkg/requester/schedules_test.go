@@ -0,0 +1,85 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleRunnerOverlapPolicies(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+	runner := &ScheduleRunner{endpoint: endpoint, jobStore: store}
+
+	createSchedule := func(policy model.ScheduleOverlapPolicy) model.Schedule {
+		schedule, err := endpoint.CreateSchedule(ctx, model.ScheduleCreatePayload{
+			ClientID:      "client",
+			APIVersion:    model.APIVersionLatest().String(),
+			Cron:          "0 * * * *",
+			OverlapPolicy: policy,
+			Spec:          &model.Spec{Deal: model.Deal{Concurrency: 1}},
+		})
+		require.NoError(t, err)
+		return schedule
+	}
+	getSchedule := func(id string) model.Schedule {
+		schedule, err := store.GetSchedule(ctx, id)
+		require.NoError(t, err)
+		return schedule
+	}
+
+	skip := createSchedule(model.ScheduleOverlapSkip)
+	queue := createSchedule(model.ScheduleOverlapQueue)
+	cancelPrevious := createSchedule(model.ScheduleOverlapCancelPrevious)
+	paused := createSchedule(model.ScheduleOverlapSkip)
+	_, err := endpoint.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: paused.ID, Paused: true})
+	require.NoError(t, err)
+
+	firstRun := skip.NextRunAt
+	require.Equal(t, 0, firstRun.Minute())
+
+	// nothing is due yet
+	runner.runDueSchedules(ctx, firstRun.Add(-time.Minute))
+	require.Empty(t, getSchedule(skip.ID).LastJobID)
+
+	runner.runDueSchedules(ctx, firstRun)
+	firstJobs := make(map[string]string)
+	for _, schedule := range []model.Schedule{skip, queue, cancelPrevious} {
+		stored := getSchedule(schedule.ID)
+		require.NotEmpty(t, stored.LastJobID)
+		require.Equal(t, firstRun.Add(time.Hour), stored.NextRunAt)
+		requireJobState(t, store, stored.LastJobID, model.JobStateInProgress)
+		firstJobs[schedule.ID] = stored.LastJobID
+	}
+	require.Empty(t, getSchedule(paused.ID).LastJobID)
+
+	// the next run is due while the first jobs are still in progress
+	runner.runDueSchedules(ctx, firstRun.Add(time.Hour))
+	require.Equal(t, firstJobs[skip.ID], getSchedule(skip.ID).LastJobID)
+	require.Equal(t, firstJobs[queue.ID], getSchedule(queue.ID).LastJobID)
+	require.Equal(t, 1, getSchedule(queue.ID).PendingRuns)
+	require.NotEqual(t, firstJobs[cancelPrevious.ID], getSchedule(cancelPrevious.ID).LastJobID)
+	requireJobState(t, store, firstJobs[cancelPrevious.ID], model.JobStateCancelled)
+
+	// the queued run is submitted once the previous job completes, without waiting for the next run
+	completeJob(t, store, firstJobs[queue.ID], model.StorageSpec{})
+	completeJob(t, store, firstJobs[skip.ID], model.StorageSpec{})
+	runner.runDueSchedules(ctx, firstRun.Add(time.Hour+time.Minute))
+	require.NotEqual(t, firstJobs[queue.ID], getSchedule(queue.ID).LastJobID)
+	require.Equal(t, 0, getSchedule(queue.ID).PendingRuns)
+	require.Equal(t, firstJobs[skip.ID], getSchedule(skip.ID).LastJobID)
+
+	runner.runDueSchedules(ctx, firstRun.Add(2*time.Hour))
+	require.NotEqual(t, firstJobs[skip.ID], getSchedule(skip.ID).LastJobID)
+
+	// resumed schedules don't submit the runs missed while they were paused
+	resumed, err := endpoint.UpdateSchedule(ctx, model.ScheduleUpdatePayload{ScheduleID: paused.ID, Paused: false})
+	require.NoError(t, err)
+	require.True(t, resumed.NextRunAt.After(time.Now()))
+	require.Empty(t, resumed.LastJobID)
+}
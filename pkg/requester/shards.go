@@ -0,0 +1,140 @@
+package requester
+
+import (
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+)
+
+// shardAssignment is the intent to ask a node to bid on a shard of a job
+type shardAssignment struct {
+	ShardIndex int
+	Node       NodeRank
+}
+
+// groupExecutionsByShard returns the executions of the job indexed by the shard they are processing
+func groupExecutionsByShard(job model.Job, jobState model.JobState) [][]model.ExecutionState {
+	shards := make([][]model.ExecutionState, job.Spec.ExecutionPlan.TotalShards())
+	for _, execution := range jobState.Executions {
+		if execution.ShardIndex < len(shards) {
+			shards[execution.ShardIndex] = append(shards[execution.ShardIndex], execution)
+		}
+	}
+	return shards
+}
+
+// countActiveExecutions returns the number of executions that have not been discarded
+func countActiveExecutions(executions []model.ExecutionState) int {
+	count := 0
+	for _, execution := range executions {
+		if !execution.State.IsDiscarded() {
+			count++
+		}
+	}
+	return count
+}
+
+// isShardWaitingForNodes returns true if the shard doesn't have enough executions because there were not enough
+// free nodes when it was last scheduled, rather than because its executions were discarded in its latest attempt.
+// Such shards are scheduled as soon as nodes are free again, without counting as a retry.
+// Jobs that are not sharded are always assigned enough nodes when they are scheduled.
+func isShardWaitingForNodes(job model.Job, shardExecutions []model.ExecutionState) bool {
+	if job.Spec.ExecutionPlan.TotalShards() <= 1 {
+		return false
+	}
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
+	if countActiveExecutions(shardExecutions) >= minBids {
+		return false
+	}
+	latestAttempt := 0
+	for _, execution := range shardExecutions {
+		latestAttempt = system.Max(latestAttempt, execution.Attempt)
+	}
+	for _, execution := range shardExecutions {
+		if execution.State.IsDiscarded() && execution.Attempt == latestAttempt {
+			return false
+		}
+	}
+	return true
+}
+
+// hasCompletedExecution returns true if any of the executions completed and published its result
+func hasCompletedExecution(executions []model.ExecutionState) bool {
+	for _, execution := range executions {
+		if execution.State == model.ExecutionStateCompleted {
+			return true
+		}
+	}
+	return false
+}
+
+// assignNodesToShards selects the nodes to ask to bid on the given shards, so that each shard has targetBids
+// executions, and then up to OverAskForBidsFactor times the missing ones. Nodes are first spread across the shards before
+// over asking, so that shards don't starve when there are fewer nodes than shards.
+// A node is never asked to bid on a shard it is already executing, and is only asked to bid on a single shard at a
+// time, as pending bid requests are identified by the job and node ids only. Nodes that didn't respond with an
+// execution id for a previous bid request can't be asked again for the same reason.
+// It returns false if any of the shards could not be assigned enough nodes.
+func assignNodesToShards(
+	job model.Job,
+	jobState model.JobState,
+	rankedNodes []NodeRank,
+	shardIndexes []int,
+	excludedNodes map[string]struct{},
+	targetBids int,
+) ([]shardAssignment, bool) {
+	busyNodes := make(map[string]struct{})
+	for nodeID := range excludedNodes {
+		busyNodes[nodeID] = struct{}{}
+	}
+	for _, execution := range jobState.Executions {
+		if !execution.HasAcceptedAskForBid() {
+			busyNodes[execution.NodeID] = struct{}{}
+		}
+	}
+
+	shardExecutions := groupExecutionsByShard(job, jobState)
+	shardNodes := make(map[int]map[string]struct{}, len(shardIndexes))
+	for _, shardIndex := range shardIndexes {
+		shardNodes[shardIndex] = make(map[string]struct{})
+		for _, execution := range shardExecutions[shardIndex] {
+			if !execution.State.IsDiscarded() {
+				shardNodes[shardIndex][execution.NodeID] = struct{}{}
+			}
+		}
+	}
+
+	var assignments []shardAssignment
+	assign := func(shardIndex int, count int) int {
+		assigned := 0
+		for _, node := range rankedNodes {
+			if assigned >= count {
+				break
+			}
+			nodeID := node.NodeInfo.PeerInfo.ID.String()
+			if _, busy := busyNodes[nodeID]; busy {
+				continue
+			}
+			if _, executing := shardNodes[shardIndex][nodeID]; executing {
+				continue
+			}
+			busyNodes[nodeID] = struct{}{}
+			shardNodes[shardIndex][nodeID] = struct{}{}
+			assignments = append(assignments, shardAssignment{ShardIndex: shardIndex, Node: node})
+			assigned++
+		}
+		return assigned
+	}
+
+	satisfied := true
+	required := make(map[int]int, len(shardIndexes))
+	for _, shardIndex := range shardIndexes {
+		required[shardIndex] = targetBids - countActiveExecutions(shardExecutions[shardIndex])
+		if assign(shardIndex, required[shardIndex]) < required[shardIndex] {
+			satisfied = false
+		}
+	}
+	for _, shardIndex := range shardIndexes {
+		assign(shardIndex, required[shardIndex]*(OverAskForBidsFactor-1))
+	}
+	return assignments, satisfied
+}
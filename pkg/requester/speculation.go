@@ -0,0 +1,291 @@
+package requester
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/util"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxRunDurationSamples is the number of recently completed executions of a job spec whose run durations are kept
+	maxRunDurationSamples = 100
+	// minRunDurationSamples is the number of completed executions of a job spec required before using their
+	// percentile as the threshold to launch backup executions
+	minRunDurationSamples = 10
+	// maxRunDurationSpecs is the number of job specs whose run durations are kept
+	maxRunDurationSpecs = 1000
+)
+
+// runDurations keeps the run durations of the most recently completed executions
+type runDurations struct {
+	samples []time.Duration
+	next    int
+}
+
+func (d *runDurations) add(duration time.Duration) {
+	if len(d.samples) < maxRunDurationSamples {
+		d.samples = append(d.samples, duration)
+		return
+	}
+	d.samples[d.next] = duration
+	d.next = (d.next + 1) % maxRunDurationSamples
+}
+
+// percentile returns the given percentile of the recorded durations, or false if not enough durations were recorded
+func (d *runDurations) percentile(p float64) (time.Duration, bool) {
+	if len(d.samples) < minRunDurationSamples {
+		return 0, false
+	}
+	sorted := make([]time.Duration, len(d.samples))
+	copy(sorted, d.samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	index := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index], true
+}
+
+// runDurationsBySpec keeps the run durations of the executions of each job spec, so that executions are only
+// compared with the other shards and the previous runs of the same job, and not with unrelated jobs.
+// The durations of the least recently completed job specs are dropped when too many specs are kept.
+type runDurationsBySpec struct {
+	specs map[string]*specRunDurations
+	// added counts the recorded durations, and orders the job specs by when their last duration was recorded
+	added uint64
+}
+
+type specRunDurations struct {
+	runDurations
+	lastAdded uint64
+}
+
+func (d *runDurationsBySpec) add(specHash string, duration time.Duration) {
+	if d.specs == nil {
+		d.specs = make(map[string]*specRunDurations)
+	}
+	durations, ok := d.specs[specHash]
+	if !ok {
+		if len(d.specs) >= maxRunDurationSpecs {
+			d.evictOldest()
+		}
+		durations = &specRunDurations{}
+		d.specs[specHash] = durations
+	}
+	d.added++
+	durations.lastAdded = d.added
+	durations.add(duration)
+}
+
+// percentile returns the given percentile of the durations recorded for the job spec, or false if not enough
+// durations were recorded
+func (d *runDurationsBySpec) percentile(specHash string, p float64) (time.Duration, bool) {
+	durations, ok := d.specs[specHash]
+	if !ok {
+		return 0, false
+	}
+	return durations.percentile(p)
+}
+
+func (d *runDurationsBySpec) evictOldest() {
+	var oldest string
+	for specHash, durations := range d.specs {
+		if oldest == "" || durations.lastAdded < d.specs[oldest].lastAdded {
+			oldest = specHash
+		}
+	}
+	delete(d.specs, oldest)
+}
+
+// LaunchBackupExecutions asks other nodes to bid on the shards of the job whose execution has been running for
+// longer than the job's speculation policy allows. Each shard is given a single backup execution, and
+// the results of whichever execution proposes them first are used.
+func (s *scheduler) LaunchBackupExecutions(ctx context.Context, jobID string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[LaunchBackupExecutions] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[LaunchBackupExecutions] failed to get job state")
+		return
+	}
+	policy := job.Spec.Deal.Speculation
+	if jobState.State != model.JobStateInProgress || !policy.IsEnabled() {
+		return
+	}
+
+	threshold := policy.GetMinDuration()
+	specHash, err := jobutils.WorkloadHash(job.Spec)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[LaunchBackupExecutions] failed to hash job spec")
+	} else if percentile, ok := s.runDurations.percentile(specHash, policy.Percentile); ok && percentile > threshold {
+		threshold = percentile
+	}
+	if threshold <= 0 {
+		// not enough executions of the job completed yet to know what slow means
+		return
+	}
+
+	var stragglingShards []int
+	for shardIndex, executions := range groupExecutionsByShard(job, jobState) {
+		if isShardStraggling(executions, threshold, now) {
+			stragglingShards = append(stragglingShards, shardIndex)
+		}
+	}
+	if len(stragglingShards) == 0 {
+		return
+	}
+
+	rankedNodes, err := s.rankNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[LaunchBackupExecutions] failed to rank nodes")
+		return
+	}
+	var assignments []shardAssignment
+	assignedNodes := make(map[string]struct{})
+	for _, shardIndex := range stragglingShards {
+		shardAssignments, _ := assignNodesToShards(
+			job, jobState, rankedNodes, []int{shardIndex}, assignedNodes, job.Spec.Deal.Concurrency+1)
+		if len(shardAssignments) == 0 {
+			log.Ctx(ctx).Debug().Msgf("no free node to run a backup execution of shard %d of job %s", shardIndex, jobID)
+			continue
+		}
+		assignment := shardAssignments[0]
+		err = s.jobStore.CreateExecution(ctx, model.ExecutionState{
+			JobID:       jobID,
+			NodeID:      assignment.Node.NodeInfo.PeerInfo.ID.String(),
+			State:       model.ExecutionStateAskForBid,
+			ShardIndex:  shardIndex,
+			Attempt:     jobState.LatestAttempt(),
+			Speculative: true,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[LaunchBackupExecutions] failed to create execution")
+			continue
+		}
+		assignedNodes[assignment.Node.NodeInfo.PeerInfo.ID.String()] = struct{}{}
+		assignments = append(assignments, assignment)
+	}
+	if len(assignments) == 0 {
+		return
+	}
+	log.Ctx(ctx).Info().Msgf("launching %d backup executions of job %s running for longer than %s",
+		len(assignments), jobID, threshold)
+
+	newCtx := util.NewDetachedContext(ctx)
+	for _, assignment := range assignments {
+		go s.doNotifyAskForBid(newCtx, trace.LinkFromContext(ctx), &job, assignment)
+	}
+}
+
+// recordRunDuration records the run duration of a completed execution of the job, which is used to tell
+// whether the other executions of the same job spec are slow.
+// make sure to call this function with the lock held
+func (s *scheduler) recordRunDuration(ctx context.Context, jobID string, runDuration time.Duration) {
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[recordRunDuration] failed to get job")
+		return
+	}
+	specHash, err := jobutils.WorkloadHash(job.Spec)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[recordRunDuration] failed to hash job spec")
+		return
+	}
+	s.runDurations.add(specHash, runDuration)
+}
+
+// cancelSpeculativeExecutions cancels the other executions of the shard of an execution that just proposed its
+// results, as only the results of the first execution are used. If another execution of the shard proposed its
+// results first, the execution itself is canceled instead.
+// make sure to call this function with the lock held
+func (s *scheduler) cancelSpeculativeExecutions(ctx context.Context, executionID model.ExecutionID) {
+	job, err := s.jobStore.GetJob(ctx, executionID.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[cancelSpeculativeExecutions] failed to get job")
+		return
+	}
+	if !job.Spec.Deal.Speculation.IsEnabled() {
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, executionID.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[cancelSpeculativeExecutions] failed to get job state")
+		return
+	}
+
+	var proposed model.ExecutionState
+	for _, execution := range jobState.Executions {
+		if execution.ID() == executionID {
+			proposed = execution
+		}
+	}
+	if proposed.State != model.ExecutionStateResultProposed {
+		return
+	}
+
+	shardExecutions := groupExecutionsByShard(job, jobState)[proposed.ShardIndex]
+	for _, execution := range shardExecutions {
+		if execution.ID() != executionID && hasProposedResults(execution) {
+			s.discardExecution(ctx, proposed, model.ExecutionStateCanceled,
+				"another execution of the same shard proposed its results first")
+			return
+		}
+	}
+	for _, execution := range shardExecutions {
+		if execution.ID() != executionID && !execution.State.IsTerminal() {
+			s.discardExecution(ctx, execution, model.ExecutionStateCanceled,
+				"another execution of the same shard proposed its results first")
+		}
+	}
+}
+
+// isShardStraggling returns true if the only execution of the shard has been running for longer than the
+// threshold, and no backup execution was launched for it yet.
+func isShardStraggling(executions []model.ExecutionState, threshold time.Duration, now time.Time) bool {
+	straggling := false
+	for _, execution := range executions {
+		if execution.Speculative {
+			return false
+		}
+		if execution.State.IsDiscarded() {
+			continue
+		}
+		if execution.State != model.ExecutionStateBidAccepted {
+			return false
+		}
+		straggling = straggling || now.Sub(execution.UpdateTime) > threshold
+	}
+	return straggling
+}
+
+// hasProposedResults returns true if the execution has finished running, regardless of whether its results
+// were verified or published yet
+func hasProposedResults(execution model.ExecutionState) bool {
+	return execution.State == model.ExecutionStateResultProposed ||
+		execution.State == model.ExecutionStateResultAccepted ||
+		execution.State == model.ExecutionStateCompleted
+}
+
+// hasProposedResultsInShard returns true if any execution of the shard has finished running
+func hasProposedResultsInShard(executions []model.ExecutionState) bool {
+	for _, execution := range executions {
+		if hasProposedResults(execution) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDurationsPercentile(t *testing.T) {
+	var durations runDurations
+	for i := 1; i < minRunDurationSamples; i++ {
+		durations.add(time.Duration(i) * time.Second)
+	}
+	_, ok := durations.percentile(90)
+	require.False(t, ok)
+
+	// only the most recent durations are kept
+	for i := 1; i <= 2*maxRunDurationSamples; i++ {
+		durations.add(time.Duration(i) * time.Second)
+	}
+	percentile, ok := durations.percentile(90)
+	require.True(t, ok)
+	require.Equal(t, 190*time.Second, percentile)
+
+	percentile, _ = durations.percentile(1)
+	require.Equal(t, 101*time.Second, percentile)
+}
+
+func TestRunDurationsBySpec(t *testing.T) {
+	var durations runDurationsBySpec
+	for i := 1; i <= minRunDurationSamples; i++ {
+		durations.add("slow", time.Duration(i)*time.Hour)
+		durations.add("fast", time.Duration(i)*time.Second)
+	}
+
+	// the durations of other job specs are not used
+	percentile, ok := durations.percentile("fast", 90)
+	require.True(t, ok)
+	require.Equal(t, 9*time.Second, percentile)
+	_, ok = durations.percentile("unknown", 90)
+	require.False(t, ok)
+
+	// the least recently completed job specs are dropped first
+	for i := 0; i < maxRunDurationSpecs-1; i++ {
+		durations.add(fmt.Sprint(i), time.Second)
+	}
+	durations.add("fast", time.Second)
+	durations.add("new", time.Second)
+	require.Len(t, durations.specs, maxRunDurationSpecs)
+	_, ok = durations.percentile("slow", 90)
+	require.False(t, ok)
+	_, ok = durations.percentile("fast", 90)
+	require.True(t, ok)
+}
@@ -0,0 +1,50 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// SubmitJobTemplate submits a child job for every combination of the parameters of the template's matrix.
+// The children share the same generated parent ID, which is used to list, wait on and cancel them as a group.
+// If any child fails to be submitted, the children submitted so far are canceled.
+func (node *BaseEndpoint) SubmitJobTemplate(ctx context.Context, data model.JobTemplateCreatePayload) ([]*model.Job, error) {
+	specs, err := job.ExpandJobTemplate(*data.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	parentID := uuid.NewString()
+	jobs := make([]*model.Job, 0, len(specs))
+	for i := range specs {
+		child, submitErr := node.submitJob(ctx, model.JobCreatePayload{
+			ClientID:   data.ClientID,
+			APIVersion: data.APIVersion,
+			Spec:       &specs[i],
+		}, parentID)
+		if submitErr != nil {
+			node.cancelChildJobs(ctx, jobs, fmt.Sprintf("failed to submit job %d of template: %s", i, submitErr))
+			return nil, submitErr
+		}
+		jobs = append(jobs, child)
+	}
+	log.Ctx(ctx).Info().Msgf("submitted %d jobs of template with parent %s", len(jobs), parentID)
+	return jobs, nil
+}
+
+func (node *BaseEndpoint) cancelChildJobs(ctx context.Context, jobs []*model.Job, reason string) {
+	for _, child := range jobs {
+		_, err := node.CancelJob(ctx, CancelJobRequest{
+			JobID:  child.Metadata.ID,
+			Reason: reason,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[cancelChildJobs] failed to cancel job %s", child.Metadata.ID)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitJobTemplateSubmitsChildJobs(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+
+	jobs, err := endpoint.SubmitJobTemplate(ctx, model.JobTemplateCreatePayload{
+		ClientID:   "client",
+		APIVersion: model.APIVersionLatest().String(),
+		Template: &model.JobTemplate{
+			Spec: model.Spec{
+				Deal:   model.Deal{Concurrency: 1},
+				Docker: model.JobSpecDocker{EnvironmentVariables: []string{"SEED={{seed}}"}},
+			},
+			Matrix: map[string][]string{"seed": {"1", "2", "3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	parentID := jobs[0].Metadata.ParentID
+	require.NotEmpty(t, parentID)
+	for i, child := range jobs {
+		require.Equal(t, parentID, child.Metadata.ParentID)
+		require.Equal(t, []string{"SEED=" + []string{"1", "2", "3"}[i]}, child.Spec.Docker.EnvironmentVariables)
+		requireJobState(t, store, child.Metadata.ID, model.JobStateInProgress)
+	}
+
+	children, err := store.GetJobs(ctx, jobstore.JobQuery{ParentID: parentID})
+	require.NoError(t, err)
+	require.Len(t, children, 3)
+
+	_, err = endpoint.SubmitJobTemplate(ctx, model.JobTemplateCreatePayload{
+		ClientID:   "client",
+		APIVersion: model.APIVersionLatest().String(),
+		Template:   &model.JobTemplate{Matrix: map[string][]string{"seed": nil}},
+	})
+	require.Error(t, err)
+}
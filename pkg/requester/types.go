@@ -2,8 +2,10 @@ package requester
 
 import (
 	"context"
+	"time"
 
 	"github.com/bacalhau-project/bacalhau/pkg/bidstrategy"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
 	"github.com/bacalhau-project/bacalhau/pkg/model"
 )
 
@@ -11,16 +13,59 @@ import (
 type Endpoint interface {
 	// SubmitJob submits a new job to the network.
 	SubmitJob(context.Context, model.JobCreatePayload) (*model.Job, error)
+	// SubmitPipeline submits the stages of a pipeline as jobs to the network.
+	SubmitPipeline(context.Context, model.PipelineCreatePayload) ([]*model.Job, error)
+	// SubmitJobTemplate submits a job for every combination of the parameters of a job template.
+	SubmitJobTemplate(context.Context, model.JobTemplateCreatePayload) ([]*model.Job, error)
 	// ApproveJob approves or rejects the running of a job.
 	ApproveJob(context.Context, ApproveJobRequest) error
 	// CancelJob cancels an existing job.
 	CancelJob(context.Context, CancelJobRequest) (CancelJobResult, error)
+	// UpdateJob changes the deal or timeout of a job that is still in progress.
+	UpdateJob(context.Context, model.JobUpdatePayload) (model.Job, error)
+	// CreateSchedule creates a schedule that submits a job every time its cron expression is due.
+	CreateSchedule(context.Context, model.ScheduleCreatePayload) (model.Schedule, error)
+	// UpdateSchedule pauses or resumes an existing schedule.
+	UpdateSchedule(context.Context, model.ScheduleUpdatePayload) (model.Schedule, error)
+	// DeleteSchedule deletes an existing schedule.
+	DeleteSchedule(context.Context, model.ScheduleDeletePayload) error
+	// EvictCachedResult stops serving the results of a completed deterministic job to identical jobs.
+	EvictCachedResult(context.Context, model.ResultCacheEvictPayload) error
+}
+
+// DependencyScheduler schedules jobs that are waiting for their dependencies once they complete,
+// and cancels them if any of their dependencies can no longer complete.
+type DependencyScheduler interface {
+	ScheduleDependentJobs(context.Context, []model.JobWithInfo)
+}
+
+// ExecutionTimeoutHandler discards executions that exceeded the timeout of their current phase,
+// and replaces them with executions on other nodes if possible.
+type ExecutionTimeoutHandler interface {
+	TimeoutExecutions(ctx context.Context, jobID string, now time.Time)
+}
+
+// LostExecutionHandler discards executions whose compute node stopped publishing its node info,
+// and replaces them with executions on other nodes if possible.
+type LostExecutionHandler interface {
+	MigrateLostExecutions(ctx context.Context, jobID string, now time.Time)
+}
+
+// StragglerHandler launches backup executions on other nodes for executions that are running slower than usual.
+type StragglerHandler interface {
+	LaunchBackupExecutions(ctx context.Context, jobID string, now time.Time)
+}
+
+// JobAdopter takes over the jobs of requester nodes that stopped renewing their lease over a shared job store.
+type JobAdopter interface {
+	AdoptJobs(ctx context.Context, jobs []model.JobWithInfo)
 }
 
 // Scheduler distributes jobs to the compute nodes and tracks the executions.
 type Scheduler interface {
 	StartJob(context.Context, StartJobRequest) error
 	CancelJob(context.Context, CancelJobRequest) (CancelJobResult, error)
+	UpdateJob(context.Context, UpdateJobRequest) error
 }
 
 type Queue interface {
@@ -29,6 +74,32 @@ type Queue interface {
 	EnqueueJob(context.Context, model.Job) error
 }
 
+// QueueInfoProvider describes the jobs waiting in the queue for enough capacity to run.
+type QueueInfoProvider interface {
+	GetQueueInfo(context.Context) (model.QueueInfo, error)
+}
+
+// UsageProvider reports the resources consumed by the executions of a client.
+type UsageProvider interface {
+	GetUsage(ctx context.Context, clientID string, since time.Time) (model.ClientUsage, error)
+}
+
+// QuotaEnforcer checks whether a client is allowed to submit more jobs.
+type QuotaEnforcer interface {
+	CheckQuota(ctx context.Context, clientID string) error
+}
+
+// CapacityReserver reserves capacity on compute nodes for a future time window.
+type CapacityReserver interface {
+	ReserveCapacity(ctx context.Context, payload model.ReservationPayload) (ReserveCapacityResponse, error)
+	CancelReservation(ctx context.Context, payload model.CancelReservationPayload) error
+}
+
+// ArchiveReader queries the jobs that were purged from the job store and archived.
+type ArchiveReader interface {
+	GetJobs(ctx context.Context, query jobstore.JobQuery) ([]model.JobWithInfo, error)
+}
+
 // NodeDiscoverer discovers nodes in the network that are suitable to execute a job.
 type NodeDiscoverer interface {
 	FindNodes(ctx context.Context, job model.Job) ([]model.NodeInfo, error)
@@ -59,8 +130,19 @@ type CancelJobRequest struct {
 
 type CancelJobResult struct{}
 
+// UpdateJobRequest notifies that the deal of a job was updated, so that its executions are adjusted to the new deal.
+type UpdateJobRequest struct {
+	Job model.Job
+}
+
 type ApproveJobRequest struct {
 	ClientID string
 	JobID    string
 	Response bidstrategy.BidStrategyResponse
 }
+
+type ReserveCapacityResponse struct {
+	Reservation model.Reservation
+	// NodeIDs of the compute nodes that hold back the reserved capacity
+	NodeIDs []string
+}
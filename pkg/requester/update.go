@@ -0,0 +1,177 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+
+	jobutils "github.com/bacalhau-project/bacalhau/pkg/job"
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/bacalhau-project/bacalhau/pkg/util"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UpdateJob changes the concurrency, min bids or timeout of a job that is still in progress. The scheduler adjusts
+// the executions of the job to the new deal right away. The timeout can only be updated before any execution of
+// the job is created, as compute nodes enforce the timeout the executions were created with.
+func (node *BaseEndpoint) UpdateJob(ctx context.Context, data model.JobUpdatePayload) (model.Job, error) {
+	job, err := node.store.GetJob(ctx, data.JobID)
+	if err != nil {
+		return model.Job{}, err
+	}
+	jobState, err := node.store.GetJobState(ctx, job.Metadata.ID)
+	if err != nil {
+		return model.Job{}, err
+	}
+
+	if data.Concurrency != nil {
+		job.Spec.Deal.Concurrency = *data.Concurrency
+	}
+	if data.MinBids != nil {
+		if *data.MinBids < 0 {
+			return model.Job{}, fmt.Errorf("min bids must be >= 0")
+		}
+		job.Spec.Deal.MinBids = *data.MinBids
+	}
+	if data.Timeout != nil {
+		if *data.Timeout <= 0 {
+			return model.Job{}, fmt.Errorf("timeout must be > 0")
+		}
+		if len(jobState.Executions) > 0 {
+			return model.Job{}, fmt.Errorf("the timeout of job %s can't be updated after its executions were created", job.Metadata.ID)
+		}
+		job.Spec.Timeout = *data.Timeout
+	}
+	// the execution plan of sharded jobs is set by the requester node, and rejected by the validation of
+	// submitted jobs
+	validated := job
+	validated.Spec.ExecutionPlan = model.JobExecutionPlan{}
+	if err = jobutils.VerifyJob(ctx, &validated); err != nil {
+		return model.Job{}, err
+	}
+
+	// the expected version makes sure the job was not concurrently updated by the scheduler, such as being
+	// sharded or completed, since we read it
+	err = node.store.UpdateJob(ctx, jobstore.UpdateJobRequest{
+		Job: job,
+		Condition: jobstore.UpdateJobCondition{
+			ExpectedVersion: jobState.Version,
+		},
+		Comment: "job updated by the client",
+	})
+	if err != nil {
+		return model.Job{}, err
+	}
+	if err = node.queue.UpdateJob(ctx, UpdateJobRequest{Job: job}); err != nil {
+		return model.Job{}, err
+	}
+	return node.store.GetJob(ctx, job.Metadata.ID)
+}
+
+// UpdateJob replaces the job waiting in the queue with its updated version, as the capacity it needs might have
+// changed. Jobs that already started are updated by the scheduler.
+func (q *queue) UpdateJob(ctx context.Context, req UpdateJobRequest) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queued, ok := q.pending[req.Job.Metadata.ID]
+	if !ok {
+		return q.scheduler.UpdateJob(ctx, req)
+	}
+	queued.job = req.Job
+	q.pending[req.Job.Metadata.ID] = queued
+	return q.dispatch(ctx, "")
+}
+
+// UpdateJob adjusts the executions of an in progress job to its updated deal. More nodes are asked to bid on the
+// shards that need more executions, and surplus executions are canceled when the concurrency is lowered.
+// Pending bids are then accepted if enough were received, and results are verified if enough were proposed.
+func (s *scheduler) UpdateJob(ctx context.Context, req UpdateJobRequest) error {
+	jobID := req.Job.Metadata.ID
+	s.mu.Lock()
+	s.adjustExecutions(ctx, jobID)
+	s.mu.Unlock()
+
+	s.startAcceptingBidsIfPossible(ctx, jobID)
+	s.startVerificationIfPossible(ctx, jobID)
+	return nil
+}
+
+// adjustExecutions asks more nodes to bid on the shards of the job that have fewer executions than the deal requires,
+// and cancels the executions of the shards that have more running executions than the job's concurrency.
+// make sure to call this function with the lock held
+func (s *scheduler) adjustExecutions(ctx context.Context, jobID string) {
+	job, err := s.jobStore.GetJob(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[adjustExecutions] failed to get job")
+		return
+	}
+	jobState, err := s.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[adjustExecutions] failed to get job state")
+		return
+	}
+	if jobState.State != model.JobStateInProgress {
+		return
+	}
+
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
+	var missingShards []int
+	for shardIndex, executions := range groupExecutionsByShard(job, jobState) {
+		if hasCompletedExecution(executions) {
+			continue
+		}
+		if countActiveExecutions(executions) < minBids {
+			missingShards = append(missingShards, shardIndex)
+		}
+		s.cancelSurplusExecutions(ctx, job, executions)
+	}
+	if len(missingShards) == 0 {
+		return
+	}
+
+	rankedNodes, err := s.rankNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[adjustExecutions] failed to rank nodes")
+		return
+	}
+	assignments, _ := assignNodesToShards(job, jobState, rankedNodes, missingShards, nil, minBids)
+	if len(assignments) == 0 {
+		log.Ctx(ctx).Debug().Msgf("no free node to raise the executions of job %s to its updated deal", jobID)
+		return
+	}
+	err = s.createAskForBidExecutions(ctx, &job, assignments, jobState.LatestAttempt())
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[adjustExecutions] failed to create executions")
+		return
+	}
+	log.Ctx(ctx).Debug().Msgf("asking %d more nodes to bid on job %s after its deal was updated", len(assignments), jobID)
+
+	newCtx := util.NewDetachedContext(ctx)
+	for _, assignment := range assignments {
+		go s.doNotifyAskForBid(newCtx, trace.LinkFromContext(ctx), &job, assignment)
+	}
+}
+
+// cancelSurplusExecutions cancels the most recently accepted executions of the shard that exceed the job's
+// concurrency. Executions that already proposed their results are kept over the ones that are still running.
+// make sure to call this function with the lock held
+func (s *scheduler) cancelSurplusExecutions(ctx context.Context, job model.Job, executions []model.ExecutionState) {
+	var running []model.ExecutionState
+	activeExecutionsCount := 0
+	for _, execution := range executions {
+		if execution.Speculative || !execution.State.IsActive() {
+			continue
+		}
+		activeExecutionsCount++
+		if execution.State == model.ExecutionStateBidAccepted {
+			running = append(running, execution)
+		}
+	}
+	for i := len(running) - 1; i >= 0 && activeExecutionsCount > job.Spec.Deal.Concurrency; i-- {
+		if s.discardExecution(ctx, running[i], model.ExecutionStateCanceled, "job concurrency was lowered by the client") {
+			activeExecutionsCount--
+		}
+	}
+}
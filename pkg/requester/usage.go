@@ -0,0 +1,97 @@
+package requester
+
+import (
+	"context"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+)
+
+// quotaPeriod is the period over which the CPU hours of a client are limited.
+const quotaPeriod = 24 * time.Hour
+
+type UsageLedgerParams struct {
+	Store jobstore.Store
+	// Quota is applied to every client. Clients are not limited if the quota is empty.
+	Quota model.ClientQuota
+}
+
+// UsageLedger aggregates the resources consumed by the executions of each client,
+// and enforces the quota clients are subject to when they submit jobs.
+type UsageLedger struct {
+	store jobstore.Store
+	quota model.ClientQuota
+}
+
+func NewUsageLedger(params UsageLedgerParams) *UsageLedger {
+	return &UsageLedger{
+		store: params.Store,
+		quota: params.Quota,
+	}
+}
+
+// GetUsage returns the resources consumed by the executions of a client that completed their run since a point in time.
+func (l *UsageLedger) GetUsage(ctx context.Context, clientID string, since time.Time) (model.ClientUsage, error) {
+	usage := model.ClientUsage{
+		ClientID: clientID,
+		Since:    since,
+		Quota:    l.quota,
+	}
+	records, err := l.store.GetUsageRecords(ctx, clientID, since)
+	if err != nil {
+		return usage, err
+	}
+	for _, record := range records {
+		usage.Add(record)
+	}
+	usage.RunningJobs, err = l.countJobs(ctx, clientID, func(state model.JobStateType) bool {
+		// jobs that are waiting for an approval, for capacity in the queue or for their dependencies are not running
+		return state == model.JobStateInProgress
+	})
+	return usage, err
+}
+
+// CheckQuota returns ErrQuotaExceeded if the client already reached its quota and is not allowed to submit more jobs.
+// All the jobs of the client that are not done count towards its concurrent jobs, as jobs waiting to run are
+// started without checking the quota again.
+func (l *UsageLedger) CheckQuota(ctx context.Context, clientID string) error {
+	if l.quota.MaxConcurrentJobs > 0 {
+		activeJobs, err := l.countJobs(ctx, clientID, func(model.JobStateType) bool { return true })
+		if err != nil {
+			return err
+		}
+		if activeJobs >= l.quota.MaxConcurrentJobs {
+			return NewErrQuotaExceeded(clientID, "concurrent jobs", float64(activeJobs), float64(l.quota.MaxConcurrentJobs))
+		}
+	}
+	if l.quota.MaxCPUHoursPerDay > 0 {
+		usage, err := l.GetUsage(ctx, clientID, time.Now().Add(-quotaPeriod))
+		if err != nil {
+			return err
+		}
+		if usage.CPUHours >= l.quota.MaxCPUHoursPerDay {
+			return NewErrQuotaExceeded(clientID, "CPU hours per day", usage.CPUHours, l.quota.MaxCPUHoursPerDay)
+		}
+	}
+	return nil
+}
+
+// countJobs returns the number of jobs of the client that are not done yet, and whose state matches the filter.
+func (l *UsageLedger) countJobs(ctx context.Context, clientID string, filter func(model.JobStateType) bool) (int, error) {
+	jobs, err := l.store.GetInProgressJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, job := range jobs {
+		if job.Job.Metadata.ClientID == clientID && filter(job.State.State) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// compile-time interface assertions
+var _ UsageProvider = (*UsageLedger)(nil)
+var _ QuotaEnforcer = (*UsageLedger)(nil)
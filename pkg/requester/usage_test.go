@@ -0,0 +1,137 @@
+//go:build unit || !integration
+
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/jobstore"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func submitUsageTestJob(ctx context.Context, endpoint *BaseEndpoint, clientID string) (*model.Job, error) {
+	return endpoint.SubmitJob(ctx, model.JobCreatePayload{
+		ClientID:   clientID,
+		APIVersion: model.APIVersionLatest().String(),
+		Spec: &model.Spec{
+			Deal:      model.Deal{Concurrency: 1},
+			Resources: model.ResourceUsageConfig{CPU: "2", Memory: "1Gi"},
+		},
+	})
+}
+
+func TestUsageLedgerAggregatesClientUsage(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+	ledger := NewUsageLedger(UsageLedgerParams{Store: store, Quota: model.ClientQuota{MaxConcurrentJobs: 5}})
+
+	job, err := submitUsageTestJob(ctx, endpoint, "client")
+	require.NoError(t, err)
+	_, err = submitUsageTestJob(ctx, endpoint, "other-client")
+	require.NoError(t, err)
+
+	now := time.Now()
+	records := []model.UsageRecord{
+		{ClientID: "client", JobID: job.Metadata.ID, Resources: model.ResourceUsageData{CPU: 2, Memory: 1 << 30},
+			Duration: 30 * time.Minute, CompletedAt: now.Add(-time.Hour)},
+		{ClientID: "client", JobID: job.Metadata.ID, Resources: model.ResourceUsageData{CPU: 2, Memory: 1 << 30, GPU: 1},
+			Duration: 90 * time.Minute, CompletedAt: now.Add(-time.Minute)},
+		// outside of the period
+		{ClientID: "client", Resources: model.ResourceUsageData{CPU: 8}, Duration: time.Hour, CompletedAt: now.Add(-48 * time.Hour)},
+		{ClientID: "other-client", Resources: model.ResourceUsageData{CPU: 8}, Duration: time.Hour, CompletedAt: now},
+	}
+	for _, record := range records {
+		require.NoError(t, store.AddUsageRecord(ctx, record))
+	}
+
+	usage, err := ledger.GetUsage(ctx, "client", now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.RunningJobs)
+
+	// jobs waiting for capacity don't count as running
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    job.Metadata.ID,
+		NewState: model.JobStateQueued,
+	}))
+	usage, err = ledger.GetUsage(ctx, "client", now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, usage.RunningJobs)
+	require.Equal(t, 2, usage.Executions)
+	require.InDelta(t, 2, usage.WallClockHours, 0.001)
+	require.InDelta(t, 4, usage.CPUHours, 0.001)
+	require.InDelta(t, 2, usage.MemoryGBHours, 0.001)
+	require.InDelta(t, 1.5, usage.GPUHours, 0.001)
+	require.Equal(t, 5, usage.Quota.MaxConcurrentJobs)
+}
+
+func TestConcurrentJobsQuotaCountsJobsWaitingToRun(t *testing.T) {
+	ctx := context.Background()
+	endpoint, store := getTestPipelineEndpoint(t)
+	endpoint.quotas = NewUsageLedger(UsageLedgerParams{Store: store, Quota: model.ClientQuota{MaxConcurrentJobs: 2}})
+
+	queued, err := submitUsageTestJob(ctx, endpoint, "client")
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    queued.Metadata.ID,
+		NewState: model.JobStateQueued,
+	}))
+	_, err = endpoint.SubmitJob(ctx, model.JobCreatePayload{
+		ClientID: "client",
+		Spec:     &model.Spec{Dependencies: []model.JobDependency{{JobID: queued.Metadata.ID, Path: "/inputs"}}},
+	})
+	require.NoError(t, err)
+
+	// neither job is running, but both would run without checking the quota again
+	_, err = submitUsageTestJob(ctx, endpoint, "client")
+	require.ErrorAs(t, err, &ErrQuotaExceeded{})
+
+	require.NoError(t, store.UpdateJobState(ctx, jobstore.UpdateJobStateRequest{
+		JobID:    queued.Metadata.ID,
+		NewState: model.JobStateCancelled,
+	}))
+	_, err = submitUsageTestJob(ctx, endpoint, "client")
+	require.NoError(t, err)
+}
+
+func TestSubmitJobEnforcesClientQuota(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		quota       model.ClientQuota
+		usedCPUTime time.Duration
+		shouldFail  bool
+	}{
+		{name: "no quota", usedCPUTime: 100 * time.Hour},
+		{name: "below quotas", quota: model.ClientQuota{MaxConcurrentJobs: 2, MaxCPUHoursPerDay: 10}, usedCPUTime: time.Hour},
+		{name: "concurrent jobs", quota: model.ClientQuota{MaxConcurrentJobs: 1}, shouldFail: true},
+		{name: "cpu hours", quota: model.ClientQuota{MaxCPUHoursPerDay: 10}, usedCPUTime: 5 * time.Hour, shouldFail: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			endpoint, store := getTestPipelineEndpoint(t)
+			endpoint.quotas = NewUsageLedger(UsageLedgerParams{Store: store, Quota: tc.quota})
+
+			_, err := submitUsageTestJob(ctx, endpoint, "client")
+			require.NoError(t, err)
+			require.NoError(t, store.AddUsageRecord(ctx, model.UsageRecord{
+				ClientID:    "client",
+				Resources:   model.ResourceUsageData{CPU: 2},
+				Duration:    tc.usedCPUTime,
+				CompletedAt: time.Now(),
+			}))
+
+			// other clients are not affected by the usage of the client
+			_, err = submitUsageTestJob(ctx, endpoint, "other-client")
+			require.NoError(t, err)
+
+			_, err = submitUsageTestJob(ctx, endpoint, "client")
+			if tc.shouldFail {
+				require.ErrorAs(t, err, &ErrQuotaExceeded{})
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -53,6 +53,7 @@ func (e *RequestHandler) AskForBid(ctx context.Context, request compute.AskForBi
 		event := e.constructEvent(request.RoutingMetadata, response.ExecutionMetadata, model.JobEventBid)
 		// we flip senders to mimic a bid was received instead of being asked
 		event.SourceNodeID = request.RoutingMetadata.TargetPeerID
+		event.Price = response.Price
 		err = e.wallets.addEvent(event)
 		if err != nil {
 			return compute.AskForBidResponse{}, err
@@ -117,6 +118,20 @@ func (e *RequestHandler) CancelExecution(
 	return e.computeProxy.CancelExecution(ctx, request)
 }
 
+func (e *RequestHandler) UpdateRequester(
+	ctx context.Context, request compute.UpdateRequesterRequest) (compute.UpdateRequesterResponse, error) {
+	return e.computeProxy.UpdateRequester(ctx, request)
+}
+
+func (e *RequestHandler) Reserve(ctx context.Context, request compute.ReserveRequest) (compute.ReserveResponse, error) {
+	return e.computeProxy.Reserve(ctx, request)
+}
+
+func (e *RequestHandler) CancelReservation(
+	ctx context.Context, request compute.CancelReservationRequest) (compute.CancelReservationResponse, error) {
+	return e.computeProxy.CancelReservation(ctx, request)
+}
+
 func (e *RequestHandler) OnRunComplete(ctx context.Context, result compute.RunResult) {
 	event, err := e.constructEventFromExecution(result.RoutingMetadata, result.ExecutionID, model.JobEventResultsProposed)
 	if err != nil {
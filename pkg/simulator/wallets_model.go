@@ -2,6 +2,7 @@ package simulator
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -20,6 +21,9 @@ import (
 const MinWallet = 100 //nolint:gomnd
 const initialBalance = int64(1000)
 
+// defaultEscrowAmount is escrowed for bids that don't quote a price
+const defaultEscrowAmount = int64(33)
+
 type walletsModel struct {
 	// keep track of which wallet address "owns" which job
 	// the "ClientID" is only submitted for the create event
@@ -35,6 +39,10 @@ type walletsModel struct {
 	// of the key
 	escrow map[string]int64
 
+	// keep track of the price quoted in each bid, which is escrowed once the bid
+	// is accepted. Indexed the same way as the escrow
+	quotes generic.SyncMap[string, float64]
+
 	// don't trust the server publishing the result to mean the client accepted it
 	// mark in the smart contract that the client accepted it, instead
 	accepted generic.SyncMap[string, bool]
@@ -49,6 +57,7 @@ func newWalletsModel() *walletsModel {
 		jobOwners: generic.SyncMap[string, string]{},
 		balances:  generic.SyncMap[string, int64]{},
 		escrow:    map[string]int64{},
+		quotes:    generic.SyncMap[string, float64]{},
 		accepted:  generic.SyncMap[string, bool]{},
 	}
 	go w.logWallets()
@@ -111,9 +120,8 @@ func (wallets *walletsModel) addEvent(event model.JobEvent) error {
 		event = fromClient(event)
 		client, _ := wallets.jobOwners.Get(event.JobID)
 		server := event.TargetNodeID
-		// TODO: price in job spec! verify price per hour!
 		// TODO: the client itself should escrow the funds, not the smart contract?
-		err := wallets.escrowFunds(client, server, event.JobID, 33) //nolint:gomnd
+		err := wallets.escrowFunds(client, server, event.JobID, wallets.escrowAmount(client, server, event.JobID))
 		if err != nil {
 			return err
 		}
@@ -266,10 +274,23 @@ func (wallets *walletsModel) bid(event model.JobEvent) error {
 
 	walletAddress, _ := wallets.jobOwners.Get(event.JobID)
 	wallets.ensureWallet(walletAddress)
-	log.Info().Msgf("SIM: received bid event for job id: %s wallet address: %s\n", event.JobID, walletAddress)
+	if event.Price > 0 {
+		wallets.quotes.Put(escrowID(walletAddress, event.SourceNodeID, event.JobID), event.Price)
+	}
+	log.Info().Msgf("SIM: received bid event for job id: %s wallet address: %s price: %.2f\n",
+		event.JobID, walletAddress, event.Price)
 	return nil
 }
 
+// escrowAmount returns the price quoted by the server in its bid for the job, rounded up to whole units
+func (wallets *walletsModel) escrowAmount(client, server, jobID string) int64 {
+	price, ok := wallets.quotes.Get(escrowID(client, server, jobID))
+	if !ok {
+		return defaultEscrowAmount
+	}
+	return int64(math.Ceil(price))
+}
+
 func (wallets *walletsModel) bidAccepted(event model.JobEvent) error {
 	err := wallets.checkWallet(event)
 	if err != nil {
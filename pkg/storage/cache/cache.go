@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/util"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/rs/zerolog/log"
+)
+
+type Params struct {
+	// Dir is where the cached inputs are stored
+	Dir string
+	// MaxSize is the disk quota of the cache, in bytes
+	MaxSize uint64
+}
+
+// Cache is a local content store for the inputs of the jobs run by a compute node, so that inputs used by several
+// jobs are only fetched once. Inputs are keyed by their identifier, i.e. their CID or their URL, and are shared by
+// all the jobs using the same version of their content. Inputs that are not used by any job are evicted in least
+// recently used order when the cache goes over its disk quota.
+type Cache struct {
+	dir       string
+	maxSize   uint64
+	size      uint64
+	entries   map[string]*entry
+	lru       *list.List
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	mu        sync.Mutex
+}
+
+type entry struct {
+	key  string
+	spec model.StorageSpec
+	// version of the input's content, which is empty for inputs addressed by their content
+	version string
+	// path is where the input is stored in the cache
+	path string
+	// volumeType and targetSuffix are used to mount the cached input the same way as its storage provider would
+	volumeType   storage.StorageVolumeConnectorType
+	targetSuffix string
+	size         uint64
+	refs         int
+	lastUsed     time.Time
+	element      *list.Element
+}
+
+// Stats of the usage of the cache
+type Stats struct {
+	Entries   int
+	InUse     int
+	Size      uint64
+	MaxSize   uint64
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+func New(params Params) (*Cache, error) {
+	if err := os.MkdirAll(params.Dir, util.OS_USER_RWX); err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		dir:     params.Dir,
+		maxSize: params.MaxSize,
+		entries: make(map[string]*entry),
+		lru:     list.New(),
+	}
+	c.mu.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        "InputCache.mu",
+	})
+	return c, nil
+}
+
+// key returns the key of the input in the cache, and false if the input can't be cached.
+func key(spec model.StorageSpec) (string, bool) {
+	var id string
+	switch spec.StorageSource {
+	case model.StorageSourceIPFS:
+		id = spec.CID
+	case model.StorageSourceURLDownload:
+		id = spec.URL
+	}
+	if id == "" {
+		return "", false
+	}
+	hash := sha256.Sum256([]byte(spec.StorageSource.String() + ":" + id))
+	return hex.EncodeToString(hash[:]), true
+}
+
+// has returns the size of the cached input, and whether it is cached.
+func (c *Cache) has(key string) (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	return e.size, true
+}
+
+// acquire returns the volume of the cached input, which can't be evicted until it is released. Cached inputs of
+// another version are stale, and removed if they are not in use.
+func (c *Cache) acquire(key, version string, spec model.StorageSpec) (storage.StorageVolume, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if ok && e.version != version && e.refs == 0 {
+		c.removeLocked(e)
+		ok = false
+	}
+	if !ok || e.version != version {
+		c.misses++
+		return storage.StorageVolume{}, false
+	}
+	c.hits++
+	c.useLocked(e)
+	return e.volume(spec), true
+}
+
+// add moves the volume prepared by a storage provider into the cache, and acquires it. If the input was added
+// by another job in the meantime, the cached input is acquired instead and the volume is left untouched.
+// Stale versions of the input that are still in use are kept, and the volume is not cached.
+func (c *Cache) add(key, version string, spec model.StorageSpec, volume storage.StorageVolume) (storage.StorageVolume, error) {
+	size, err := util.DirSize(volume.Source)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	if size > c.maxSize {
+		return storage.StorageVolume{}, fmt.Errorf("input of %d bytes is larger than the cache", size)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		if e.version == version {
+			c.useLocked(e)
+			return e.volume(spec), nil
+		}
+		if e.refs > 0 {
+			return storage.StorageVolume{}, fmt.Errorf("stale version of the input is still in use")
+		}
+		c.removeLocked(e)
+	}
+
+	entryDir := filepath.Join(c.dir, key)
+	if err = os.MkdirAll(entryDir, util.OS_USER_RWX); err != nil {
+		return storage.StorageVolume{}, err
+	}
+	path := filepath.Join(entryDir, filepath.Base(volume.Source))
+	if err = os.Rename(volume.Source, path); err != nil {
+		_ = os.RemoveAll(entryDir)
+		return storage.StorageVolume{}, err
+	}
+
+	e := &entry{
+		key: key,
+		spec: model.StorageSpec{
+			StorageSource: spec.StorageSource,
+			CID:           spec.CID,
+			URL:           spec.URL,
+		},
+		version:      version,
+		path:         path,
+		volumeType:   volume.Type,
+		targetSuffix: strings.TrimPrefix(volume.Target, spec.Path),
+		size:         size,
+	}
+	e.element = c.lru.PushFront(e)
+	c.entries[key] = e
+	c.size += size
+	c.useLocked(e)
+	c.evictLocked()
+	return e.volume(spec), nil
+}
+
+// release releases a volume acquired from the cache, and returns false if the volume is not from the cache.
+func (c *Cache) release(key string, volume storage.StorageVolume) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.path != volume.Source {
+		return false
+	}
+	if e.refs > 0 {
+		e.refs--
+	}
+	e.lastUsed = time.Now()
+	c.evictLocked()
+	return true
+}
+
+func (c *Cache) useLocked(e *entry) {
+	e.refs++
+	e.lastUsed = time.Now()
+	c.lru.MoveToFront(e.element)
+}
+
+// evictLocked evicts the least recently used inputs that are not in use until the cache is within its quota.
+func (c *Cache) evictLocked() {
+	for element := c.lru.Back(); element != nil && c.size > c.maxSize; {
+		e := element.Value.(*entry)
+		element = element.Prev()
+		if e.refs > 0 {
+			continue
+		}
+		c.removeLocked(e)
+		c.evictions++
+	}
+}
+
+func (c *Cache) removeLocked(e *entry) {
+	if err := os.RemoveAll(filepath.Dir(e.path)); err != nil {
+		log.Error().Err(err).Msgf("[removeLocked] failed to remove cached input %s", e.path)
+	}
+	c.lru.Remove(e.element)
+	delete(c.entries, e.key)
+	c.size -= e.size
+}
+
+// Purge removes all the inputs that are not in use from the cache, and returns how many were removed.
+func (c *Cache) Purge(ctx context.Context) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	purged := 0
+	for _, e := range c.entries {
+		if e.refs == 0 {
+			c.removeLocked(e)
+			purged++
+		}
+	}
+	log.Ctx(ctx).Debug().Msgf("purged %d inputs from the cache", purged)
+	return purged
+}
+
+// Stats returns the usage of the cache
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	inUse := 0
+	for _, e := range c.entries {
+		if e.refs > 0 {
+			inUse++
+		}
+	}
+	return Stats{
+		Entries:   len(c.entries),
+		InUse:     inUse,
+		Size:      c.size,
+		MaxSize:   c.maxSize,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// localStorage returns the storage specs of the cached inputs, in most recently used order.
+func (c *Cache) localStorage() []model.StorageSpec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	specs := make([]model.StorageSpec, 0, len(c.entries))
+	for element := c.lru.Front(); element != nil; element = element.Next() {
+		specs = append(specs, element.Value.(*entry).spec)
+	}
+	return specs
+}
+
+// GetDebugInfo implements model.DebugInfoProvider
+func (c *Cache) GetDebugInfo(context.Context) (model.DebugInfo, error) {
+	return model.DebugInfo{
+		Component: "InputCache",
+		Info:      c.Stats(),
+	}, nil
+}
+
+// volume returns the volume mounting the cached input at the path of the spec.
+func (e *entry) volume(spec model.StorageSpec) storage.StorageVolume {
+	return storage.StorageVolume{
+		Type:   e.volumeType,
+		Source: e.path,
+		Target: spec.Path + e.targetSuffix,
+	}
+}
+
+// compile-time check that we implement the interface
+var _ model.DebugInfoProvider = (*Cache)(nil)
@@ -0,0 +1,201 @@
+//go:build unit || !integration
+
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bacalhau-project/bacalhau/pkg/logger"
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/bacalhau-project/bacalhau/pkg/storage/url/urldownload"
+	"github.com/bacalhau-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheSuite struct {
+	suite.Suite
+	server    *httptest.Server
+	downloads atomic.Int32
+	// content served for every URL, which is versioned by its ETag
+	content atomic.Value
+	storage storage.Storage
+	cache   *Cache
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+func (s *CacheSuite) SetupTest() {
+	logger.ConfigureTestLogging(s.T())
+	system.InitConfigForTesting(s.T())
+
+	s.downloads.Store(0)
+	s.content.Store("0123456789")
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content := s.content.Load().(string)
+		w.Header().Set("ETag", `"`+content+`"`)
+		if r.Method == http.MethodHead {
+			return
+		}
+		s.downloads.Add(1)
+		_, _ = w.Write([]byte(content))
+	}))
+	s.T().Cleanup(s.server.Close)
+
+	cm := system.NewCleanupManager()
+	s.T().Cleanup(func() { cm.Cleanup(context.Background()) })
+	urlStorage, err := urldownload.NewStorage(cm)
+	s.Require().NoError(err)
+
+	s.cache, err = New(Params{Dir: s.T().TempDir(), MaxSize: 25})
+	s.Require().NoError(err)
+	s.storage = Wrap(urlStorage, s.cache)
+}
+
+func (s *CacheSuite) spec(name string) model.StorageSpec {
+	return model.StorageSpec{
+		StorageSource: model.StorageSourceURLDownload,
+		URL:           s.server.URL + "/" + name,
+		Path:          "/inputs",
+	}
+}
+
+func (s *CacheSuite) TestInputsAreSharedAcrossJobs() {
+	ctx := context.Background()
+	spec := s.spec("file.txt")
+
+	hasStorage, err := s.storage.HasStorageLocally(ctx, spec)
+	s.NoError(err)
+	s.False(hasStorage)
+
+	volume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.Equal("/inputs/file.txt", volume.Target)
+	content, err := os.ReadFile(volume.Source)
+	s.NoError(err)
+	s.Equal("0123456789", string(content))
+
+	anotherVolume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.Equal(volume, anotherVolume)
+	s.Equal(int32(1), s.downloads.Load())
+
+	// the input is kept after the jobs complete, and advertised as local storage
+	s.NoError(s.storage.CleanupStorage(ctx, spec, volume))
+	s.NoError(s.storage.CleanupStorage(ctx, spec, anotherVolume))
+	s.FileExists(volume.Source)
+
+	hasStorage, err = s.storage.HasStorageLocally(ctx, spec)
+	s.NoError(err)
+	s.True(hasStorage)
+	size, err := s.storage.GetVolumeSize(ctx, spec)
+	s.NoError(err)
+	s.Equal(uint64(10), size)
+	localStorage, err := s.storage.ListLocalStorage(ctx)
+	s.NoError(err)
+	s.Equal([]model.StorageSpec{{StorageSource: model.StorageSourceURLDownload, URL: spec.URL}}, localStorage)
+
+	s.Equal(Stats{Entries: 1, Size: 10, MaxSize: 25, Hits: 1, Misses: 1}, s.cache.Stats())
+}
+
+func (s *CacheSuite) TestEviction() {
+	ctx := context.Background()
+	volumes := make(map[string]storage.StorageVolume)
+	for _, name := range []string{"a", "b", "c"} {
+		volume, err := s.storage.PrepareStorage(ctx, s.spec(name))
+		s.Require().NoError(err)
+		volumes[name] = volume
+	}
+
+	// inputs in use are not evicted, even if the cache is over its quota
+	s.Equal(3, s.cache.Stats().Entries)
+
+	// inputs are evicted in least recently used order once released
+	s.NoError(s.storage.CleanupStorage(ctx, s.spec("b"), volumes["b"]))
+	s.NoError(s.storage.CleanupStorage(ctx, s.spec("a"), volumes["a"]))
+	s.NoFileExists(volumes["b"].Source)
+	s.FileExists(volumes["a"].Source)
+
+	stats := s.cache.Stats()
+	s.Equal(2, stats.Entries)
+	s.Equal(1, stats.InUse)
+	s.Equal(uint64(1), stats.Evictions)
+
+	// purging only removes the inputs that are not in use
+	s.Equal(1, s.cache.Purge(ctx))
+	s.NoFileExists(volumes["a"].Source)
+	s.FileExists(volumes["c"].Source)
+	s.Equal(1, s.cache.Stats().Entries)
+}
+
+func (s *CacheSuite) TestInputLargerThanCache() {
+	ctx := context.Background()
+	s.cache.maxSize = 5
+	spec := s.spec("file.txt")
+
+	volume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.NotEqual(s.cache.dir, filepath.Dir(filepath.Dir(volume.Source)))
+	s.Equal(0, s.cache.Stats().Entries)
+
+	// the volume is cleaned up by the delegate
+	s.NoError(s.storage.CleanupStorage(ctx, spec, volume))
+	s.NoFileExists(volume.Source)
+}
+
+func (s *CacheSuite) TestURLInputsAreRevalidated() {
+	ctx := context.Background()
+	spec := s.spec("file.txt")
+
+	volume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.NoError(s.storage.CleanupStorage(ctx, spec, volume))
+
+	// the cached input is not used once the content of the URL changed
+	s.content.Store("9876543210")
+	volume, err = s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	content, err := os.ReadFile(volume.Source)
+	s.NoError(err)
+	s.Equal("9876543210", string(content))
+	s.Equal(int32(2), s.downloads.Load())
+	s.Equal(1, s.cache.Stats().Entries)
+
+	// jobs still using the stale version keep it, while new jobs get the new version
+	s.content.Store("abcdefghij")
+	newVolume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.NotEqual(volume.Source, newVolume.Source)
+	content, err = os.ReadFile(newVolume.Source)
+	s.NoError(err)
+	s.Equal("abcdefghij", string(content))
+	content, err = os.ReadFile(volume.Source)
+	s.NoError(err)
+	s.Equal("9876543210", string(content))
+	s.NoError(s.storage.CleanupStorage(ctx, spec, newVolume))
+	s.NoFileExists(newVolume.Source)
+	s.NoError(s.storage.CleanupStorage(ctx, spec, volume))
+}
+
+func (s *CacheSuite) TestURLInputsWithoutValidatorsAreNotCached() {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	s.T().Cleanup(server.Close)
+	spec := model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: server.URL + "/file.txt", Path: "/inputs"}
+
+	volume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.Equal(0, s.cache.Stats().Entries)
+	s.NoError(s.storage.CleanupStorage(ctx, spec, volume))
+	s.NoFileExists(volume.Source)
+}
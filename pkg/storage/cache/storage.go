@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bacalhau-project/bacalhau/pkg/model"
+	"github.com/bacalhau-project/bacalhau/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// revalidationTimeout is how long the server of a URL input can take to tell if the cached input is still current
+const revalidationTimeout = 10 * time.Second
+
+// cachingStorage is a storage that keeps the inputs prepared by its delegate in a cache shared across jobs,
+// instead of fetching them again for each job. The delegate must prepare volumes that it owns, and that can be
+// moved into the cache, as opposed to volumes that are already stored locally.
+//
+// URLs are not content addresses, so URL inputs are revalidated with their server before a cached input is used.
+// Inputs whose server doesn't return an ETag or a Last-Modified header are not cached.
+type cachingStorage struct {
+	delegate storage.Storage
+	cache    *Cache
+	client   *http.Client
+}
+
+func Wrap(delegate storage.Storage, cache *Cache) storage.Storage {
+	return &cachingStorage{
+		delegate: delegate,
+		cache:    cache,
+		client:   &http.Client{Timeout: revalidationTimeout},
+	}
+}
+
+func (s *cachingStorage) IsInstalled(ctx context.Context) (bool, error) {
+	return s.delegate.IsInstalled(ctx)
+}
+
+func (s *cachingStorage) HasStorageLocally(ctx context.Context, spec model.StorageSpec) (bool, error) {
+	if key, ok := key(spec); ok {
+		if _, cached := s.cache.has(key); cached {
+			return true, nil
+		}
+	}
+	return s.delegate.HasStorageLocally(ctx, spec)
+}
+
+func (s *cachingStorage) GetVolumeSize(ctx context.Context, spec model.StorageSpec) (uint64, error) {
+	if key, ok := key(spec); ok {
+		if size, cached := s.cache.has(key); cached {
+			return size, nil
+		}
+	}
+	return s.delegate.GetVolumeSize(ctx, spec)
+}
+
+func (s *cachingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	key, ok := key(spec)
+	if !ok {
+		return s.delegate.PrepareStorage(ctx, spec)
+	}
+	version, err := s.version(ctx, spec)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("not caching input %s that can't be revalidated", spec.URL)
+		return s.delegate.PrepareStorage(ctx, spec)
+	}
+	if volume, cached := s.cache.acquire(key, version, spec); cached {
+		log.Ctx(ctx).Debug().Str("source", volume.Source).Msg("using cached input")
+		return volume, nil
+	}
+
+	volume, err := s.delegate.PrepareStorage(ctx, spec)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	cachedVolume, err := s.cache.add(key, version, spec, volume)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msgf("[PrepareStorage] failed to cache input %s", volume.Source)
+		return volume, nil
+	}
+	// the delegate cleans up what is left of the volume after it was moved into the cache
+	if err = s.delegate.CleanupStorage(ctx, spec, volume); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msgf("[PrepareStorage] failed to clean up cached input %s", volume.Source)
+	}
+	return cachedVolume, nil
+}
+
+// version returns the version of the input's content. Inputs addressed by their content have a single version,
+// while the version of URL inputs is their ETag or Last-Modified header.
+func (s *cachingStorage) version(ctx context.Context, spec model.StorageSpec) (string, error) {
+	if spec.StorageSource != model.StorageSourceURLDownload {
+		return "", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, spec.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return "", fmt.Errorf("unexpected status %s", res.Status)
+	}
+	if etag := res.Header.Get("ETag"); etag != "" {
+		return "etag:" + etag, nil
+	}
+	if lastModified := res.Header.Get("Last-Modified"); lastModified != "" {
+		return "last-modified:" + lastModified, nil
+	}
+	return "", fmt.Errorf("no ETag or Last-Modified header")
+}
+
+func (s *cachingStorage) CleanupStorage(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) error {
+	if key, ok := key(spec); ok && s.cache.release(key, volume) {
+		return nil
+	}
+	return s.delegate.CleanupStorage(ctx, spec, volume)
+}
+
+func (s *cachingStorage) Upload(ctx context.Context, localPath string) (model.StorageSpec, error) {
+	return s.delegate.Upload(ctx, localPath)
+}
+
+func (s *cachingStorage) Explode(ctx context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return s.delegate.Explode(ctx, spec)
+}
+
+// ListLocalStorage returns the storage held locally by the delegate, and the cached inputs.
+func (s *cachingStorage) ListLocalStorage(ctx context.Context) ([]model.StorageSpec, error) {
+	specs, err := s.delegate.ListLocalStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(specs, s.cache.localStorage()...), nil
+}
+
+var _ storage.Storage = &cachingStorage{}
@@ -99,6 +99,33 @@ func (driver *ComboStorageProvider) Upload(
 	return provider.Upload(ctx, localPath)
 }
 
+func (driver *ComboStorageProvider) Explode(
+	ctx context.Context,
+	storageSpec model.StorageSpec,
+) ([]model.StorageSpec, error) {
+	provider, err := driver.getReadProvider(ctx, storageSpec)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Explode(ctx, storageSpec)
+}
+
+func (driver *ComboStorageProvider) ListLocalStorage(ctx context.Context) ([]model.StorageSpec, error) {
+	allProviders, err := driver.AllFetcher(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var specs []model.StorageSpec
+	for _, provider := range allProviders {
+		providerSpecs, err := provider.ListLocalStorage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, providerSpecs...)
+	}
+	return specs, nil
+}
+
 func (driver *ComboStorageProvider) getReadProvider(ctx context.Context, spec model.StorageSpec) (storage.Storage, error) {
 	return driver.ReadFetcher(ctx, spec)
 }
@@ -80,6 +80,17 @@ func (driver *StorageProvider) Upload(context.Context, string) (model.StorageSpe
 	return model.StorageSpec{}, fmt.Errorf("not implemented")
 }
 
+func (driver *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{
+		spec,
+	}, nil
+}
+
+// ListLocalStorage returns no storage, as local paths can't be shared across nodes.
+func (driver *StorageProvider) ListLocalStorage(context.Context) ([]model.StorageSpec, error) {
+	return nil, nil
+}
+
 func (driver *StorageProvider) getPathToVolume(volume model.StorageSpec) (string, error) {
 	var buffer bytes.Buffer
 	err := driver.localPathTemplate.Execute(&buffer, volume)
@@ -170,4 +170,14 @@ func (*InlineStorage) Upload(ctx context.Context, path string) (model.StorageSpe
 	}, err
 }
 
+// Explode returns the spec as is, as inline data can't be split without decoding it.
+func (*InlineStorage) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return []model.StorageSpec{spec}, nil
+}
+
+// ListLocalStorage returns no storage, as inline data is part of the job itself.
+func (*InlineStorage) ListLocalStorage(context.Context) ([]model.StorageSpec, error) {
+	return nil, nil
+}
+
 var _ storage.Storage = (*InlineStorage)(nil)
@@ -98,6 +98,45 @@ func (s *StorageProvider) Upload(ctx context.Context, localPath string) (model.S
 	}, nil
 }
 
+// ListLocalStorage returns the CIDs pinned by the IPFS node
+func (s *StorageProvider) ListLocalStorage(ctx context.Context) ([]model.StorageSpec, error) {
+	cids, err := s.ipfsClient.PinnedCIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	specs := make([]model.StorageSpec, 0, len(cids))
+	for _, cid := range cids {
+		specs = append(specs, model.StorageSpec{
+			StorageSource: model.StorageSourceIPFS,
+			CID:           cid,
+		})
+	}
+	return specs, nil
+}
+
+func (s *StorageProvider) Explode(ctx context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	treeNode, err := s.ipfsClient.GetTreeNode(ctx, spec.CID)
+	if err != nil {
+		return nil, err
+	}
+
+	flatNodes, err := ipfs.FlattenTreeNode(ctx, treeNode)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]model.StorageSpec, 0, len(flatNodes))
+	for _, node := range flatNodes {
+		specs = append(specs, model.StorageSpec{
+			StorageSource: model.StorageSourceIPFS,
+			Name:          spec.Name,
+			CID:           node.Cid.String(),
+			Path:          filepath.Join(append([]string{spec.Path}, node.Path...)...),
+		})
+	}
+	return specs, nil
+}
+
 func (s *StorageProvider) getFileFromIPFS(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
 	outputPath := filepath.Join(s.localDir, storageSpec.CID)
 
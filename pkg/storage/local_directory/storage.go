@@ -84,6 +84,11 @@ func (driver *StorageProvider) Explode(_ context.Context, spec model.StorageSpec
 	}, nil
 }
 
+// ListLocalStorage returns no storage, as local paths can't be shared across nodes.
+func (driver *StorageProvider) ListLocalStorage(context.Context) ([]model.StorageSpec, error) {
+	return nil, nil
+}
+
 func (driver *StorageProvider) getPathToVolume(volume model.StorageSpec) (string, error) {
 	// join the driver.LocalDirectoryPath with the volume.SourcePath
 	// use the os.PathSeparator to make sure we are using the correct separator for the OS
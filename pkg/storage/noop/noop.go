@@ -14,6 +14,7 @@ type StroageHandlerPrepareStorage func(ctx context.Context, storageSpec model.St
 type StroageHandlerCleanupStorage func(ctx context.Context, storageSpec model.StorageSpec, volume storage.StorageVolume) error
 type StroageHandlerUpload func(ctx context.Context, localPath string) (model.StorageSpec, error)
 type StroageHandlerExplode func(ctx context.Context, storageSpec model.StorageSpec) ([]model.StorageSpec, error)
+type StroageHandlerListLocalStorage func(ctx context.Context) ([]model.StorageSpec, error)
 
 type StorageConfigExternalHooks struct {
 	IsInstalled       StroageHandlerIsInstalled
@@ -23,6 +24,7 @@ type StorageConfigExternalHooks struct {
 	CleanupStorage    StroageHandlerCleanupStorage
 	Upload            StroageHandlerUpload
 	Explode           StroageHandlerExplode
+	ListLocalStorage  StroageHandlerListLocalStorage
 }
 
 type StorageConfig struct {
@@ -109,6 +111,14 @@ func (s *NoopStorage) Explode(ctx context.Context, spec model.StorageSpec) ([]mo
 	return []model.StorageSpec{}, nil
 }
 
+func (s *NoopStorage) ListLocalStorage(ctx context.Context) ([]model.StorageSpec, error) {
+	if s.Config.ExternalHooks.ListLocalStorage != nil {
+		handler := s.Config.ExternalHooks.ListLocalStorage
+		return handler(ctx)
+	}
+	return []model.StorageSpec{}, nil
+}
+
 //nolint:lll // Exception to the long rule
 func (s *NoopStorage) CleanupStorage(ctx context.Context, storageSpec model.StorageSpec, volume storage.StorageVolume) error {
 	if s.Config.ExternalHooks.CleanupStorage != nil {
@@ -25,6 +25,14 @@ type Storage interface {
 
 	// given a local file path - "store" it and return a StorageSpec
 	Upload(context.Context, string) (model.StorageSpec, error)
+
+	// Explode breaks the given volume down into storage specs for each file and folder it contains,
+	// mounted at their path within the volume. This is used to split the inputs of a job into shards.
+	Explode(context.Context, model.StorageSpec) ([]model.StorageSpec, error)
+
+	// ListLocalStorage returns the storage specs of the data held locally, which compute nodes advertise so
+	// that jobs are scheduled on the nodes that already hold their inputs.
+	ListLocalStorage(context.Context) ([]model.StorageSpec, error)
 }
 
 // a storage entity that is consumed are produced by a job
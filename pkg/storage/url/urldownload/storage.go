@@ -186,6 +186,11 @@ func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, e
 	return model.StorageSpec{}, fmt.Errorf("not implemented")
 }
 
+// ListLocalStorage returns no storage, as downloaded URLs are not kept after the job completes.
+func (sp *StorageProvider) ListLocalStorage(context.Context) ([]model.StorageSpec, error) {
+	return nil, nil
+}
+
 func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
 	// for the url download - explode will always result in a single item
 	// mounted at the path specified in the spec
@@ -77,6 +77,8 @@ func (s *ComputeSuite) setupNode() {
 		host,
 		apiServer,
 		s.config,
+		nil,
+		nil,
 		"",
 		nil,
 		model.NewNoopProvider[model.StorageSourceType, storage.Storage](noopstorage),
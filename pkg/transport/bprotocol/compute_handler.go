@@ -36,6 +36,9 @@ func NewComputeHandler(params ComputeHandlerParams) *ComputeHandler {
 	handler.host.SetStreamHandler(ResultAcceptedProtocolID, handler.onResultAccepted)
 	handler.host.SetStreamHandler(ResultRejectedProtocolID, handler.onResultRejected)
 	handler.host.SetStreamHandler(CancelProtocolID, handler.onCancelJob)
+	handler.host.SetStreamHandler(UpdateRequesterProtocolID, handler.onUpdateRequester)
+	handler.host.SetStreamHandler(ReserveProtocolID, handler.onReserve)
+	handler.host.SetStreamHandler(CancelReservationProtocolID, handler.onCancelReservation)
 	log.Debug().Msgf("ComputeHandler started on host %s", handler.host.ID().String())
 	return handler
 }
@@ -70,6 +73,22 @@ func (h *ComputeHandler) onCancelJob(stream network.Stream) {
 	handleStream[compute.CancelExecutionRequest, compute.CancelExecutionResponse](ctx, stream, h.computeEndpoint.CancelExecution)
 }
 
+func (h *ComputeHandler) onUpdateRequester(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	handleStream[compute.UpdateRequesterRequest, compute.UpdateRequesterResponse](ctx, stream, h.computeEndpoint.UpdateRequester)
+}
+
+func (h *ComputeHandler) onReserve(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	handleStream[compute.ReserveRequest, compute.ReserveResponse](ctx, stream, h.computeEndpoint.Reserve)
+}
+
+func (h *ComputeHandler) onCancelReservation(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	handleStream[compute.CancelReservationRequest, compute.CancelReservationResponse](
+		ctx, stream, h.computeEndpoint.CancelReservation)
+}
+
 //nolint:errcheck
 func handleStream[Request any, Response any](
 	ctx context.Context,
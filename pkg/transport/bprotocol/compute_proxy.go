@@ -105,6 +105,41 @@ func (p *ComputeProxy) CancelExecution(
 		ctx, p.host, request.TargetPeerID, CancelProtocolID, request)
 }
 
+func (p *ComputeProxy) UpdateRequester(
+	ctx context.Context, request compute.UpdateRequesterRequest) (compute.UpdateRequesterResponse, error) {
+	if request.TargetPeerID == p.host.ID().String() {
+		if p.localEndpoint == nil {
+			return compute.UpdateRequesterResponse{}, fmt.Errorf("unable to dial to self, unless a local compute endpoint is provided")
+		}
+		return p.localEndpoint.UpdateRequester(ctx, request)
+	}
+	return proxyRequest[compute.UpdateRequesterRequest, compute.UpdateRequesterResponse](
+		ctx, p.host, request.TargetPeerID, UpdateRequesterProtocolID, request)
+}
+
+func (p *ComputeProxy) Reserve(ctx context.Context, request compute.ReserveRequest) (compute.ReserveResponse, error) {
+	if request.TargetPeerID == p.host.ID().String() {
+		if p.localEndpoint == nil {
+			return compute.ReserveResponse{}, fmt.Errorf("unable to dial to self, unless a local compute endpoint is provided")
+		}
+		return p.localEndpoint.Reserve(ctx, request)
+	}
+	return proxyRequest[compute.ReserveRequest, compute.ReserveResponse](
+		ctx, p.host, request.TargetPeerID, ReserveProtocolID, request)
+}
+
+func (p *ComputeProxy) CancelReservation(
+	ctx context.Context, request compute.CancelReservationRequest) (compute.CancelReservationResponse, error) {
+	if request.TargetPeerID == p.host.ID().String() {
+		if p.localEndpoint == nil {
+			return compute.CancelReservationResponse{}, fmt.Errorf("unable to dial to self, unless a local compute endpoint is provided")
+		}
+		return p.localEndpoint.CancelReservation(ctx, request)
+	}
+	return proxyRequest[compute.CancelReservationRequest, compute.CancelReservationResponse](
+		ctx, p.host, request.TargetPeerID, CancelReservationProtocolID, request)
+}
+
 func proxyRequest[Request any, Response any](
 	ctx context.Context,
 	h host.Host,
@@ -1,13 +1,16 @@
 package bprotocol
 
 const (
-	ComputeServiceName       = "bacalhau.compute"
-	AskForBidProtocolID      = "/bacalhau/compute/ask_for_bid/1.0.0"
-	BidAcceptedProtocolID    = "/bacalhau/compute/bid_accepted/1.0.0"
-	BidRejectedProtocolID    = "/bacalhau/compute/bid_rejected/1.0.0"
-	ResultAcceptedProtocolID = "/bacalhau/compute/result_accepted/1.0.0"
-	ResultRejectedProtocolID = "/bacalhau/compute/result_rejected/1.0.0"
-	CancelProtocolID         = "/bacalhau/compute/cancel/1.0.0"
+	ComputeServiceName          = "bacalhau.compute"
+	AskForBidProtocolID         = "/bacalhau/compute/ask_for_bid/1.0.0"
+	BidAcceptedProtocolID       = "/bacalhau/compute/bid_accepted/1.0.0"
+	BidRejectedProtocolID       = "/bacalhau/compute/bid_rejected/1.0.0"
+	ResultAcceptedProtocolID    = "/bacalhau/compute/result_accepted/1.0.0"
+	ResultRejectedProtocolID    = "/bacalhau/compute/result_rejected/1.0.0"
+	CancelProtocolID            = "/bacalhau/compute/cancel/1.0.0"
+	UpdateRequesterProtocolID   = "/bacalhau/compute/update_requester/1.0.0"
+	ReserveProtocolID           = "/bacalhau/compute/reserve/1.0.0"
+	CancelReservationProtocolID = "/bacalhau/compute/cancel_reservation/1.0.0"
 
 	CallbackServiceName = "bacalhau.callback"
 	OnRunComplete       = "/bacalhau/callback/on_run_complete/1.0.0"
@@ -111,6 +111,41 @@ func (p *ComputeProxy) CancelExecution(
 		ctx, p.host, p.simulatorNodeID, bprotocol.CancelProtocolID, request)
 }
 
+func (p *ComputeProxy) UpdateRequester(
+	ctx context.Context, request compute.UpdateRequesterRequest) (compute.UpdateRequesterResponse, error) {
+	if p.simulatorNodeID == p.host.ID().String() {
+		if p.localEndpoint == nil {
+			return compute.UpdateRequesterResponse{}, fmt.Errorf("unable to dial to self, unless a local compute endpoint is provided")
+		}
+		return p.localEndpoint.UpdateRequester(ctx, request)
+	}
+	return proxyRequest[compute.UpdateRequesterRequest, compute.UpdateRequesterResponse](
+		ctx, p.host, p.simulatorNodeID, bprotocol.UpdateRequesterProtocolID, request)
+}
+
+func (p *ComputeProxy) Reserve(ctx context.Context, request compute.ReserveRequest) (compute.ReserveResponse, error) {
+	if p.simulatorNodeID == p.host.ID().String() {
+		if p.localEndpoint == nil {
+			return compute.ReserveResponse{}, fmt.Errorf("unable to dial to self, unless a local compute endpoint is provided")
+		}
+		return p.localEndpoint.Reserve(ctx, request)
+	}
+	return proxyRequest[compute.ReserveRequest, compute.ReserveResponse](
+		ctx, p.host, p.simulatorNodeID, bprotocol.ReserveProtocolID, request)
+}
+
+func (p *ComputeProxy) CancelReservation(
+	ctx context.Context, request compute.CancelReservationRequest) (compute.CancelReservationResponse, error) {
+	if p.simulatorNodeID == p.host.ID().String() {
+		if p.localEndpoint == nil {
+			return compute.CancelReservationResponse{}, fmt.Errorf("unable to dial to self, unless a local compute endpoint is provided")
+		}
+		return p.localEndpoint.CancelReservation(ctx, request)
+	}
+	return proxyRequest[compute.CancelReservationRequest, compute.CancelReservationResponse](
+		ctx, p.host, p.simulatorNodeID, bprotocol.CancelReservationProtocolID, request)
+}
+
 func proxyRequest[Request any, Response any](
 	ctx context.Context,
 	h host.Host,
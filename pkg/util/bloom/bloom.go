@@ -0,0 +1,67 @@
+// Package bloom implements a bloom filter that can be serialized to JSON, so that nodes can cheaply advertise
+// large sets of items, such as the data they hold locally, to other nodes.
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Filter is a set of strings that can report false positives, but never false negatives.
+type Filter struct {
+	// Bits is the bit array of the filter
+	Bits []byte `json:"Bits"`
+	// Hashes is the number of bits set for each item
+	Hashes int `json:"Hashes"`
+}
+
+// New returns an empty filter sized to hold the expected number of items with the given false positive rate.
+func New(expectedItems int, falsePositiveRate float64) *Filter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	bits := math.Ceil(-float64(expectedItems) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	hashes := int(math.Round(bits / float64(expectedItems) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &Filter{
+		Bits:   make([]byte, (int(bits)+7)/8),
+		Hashes: hashes,
+	}
+}
+
+// Add adds the item to the filter
+func (f *Filter) Add(item string) {
+	for _, index := range f.indexes(item) {
+		f.Bits[index/8] |= 1 << (index % 8)
+	}
+}
+
+// Test returns true if the item might have been added to the filter, and false if it was definitely not added.
+func (f *Filter) Test(item string) bool {
+	if f == nil || len(f.Bits) == 0 {
+		return false
+	}
+	for _, index := range f.indexes(item) {
+		if f.Bits[index/8]&(1<<(index%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// indexes returns the bits of the item, derived from two halves of a single hash as described by
+// Kirsch and Mitzenmacher in "Less Hashing, Same Performance: Building a Better Bloom Filter".
+func (f *Filter) indexes(item string) []uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(item))
+	sum := hash.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32
+	size := uint64(len(f.Bits)) * 8
+	indexes := make([]uint64, f.Hashes)
+	for i := range indexes {
+		indexes[i] = (h1 + uint64(i)*h2) % size
+	}
+	return indexes
+}
@@ -0,0 +1,40 @@
+//go:build unit || !integration
+
+package bloom
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	filter := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		filter.Add(fmt.Sprintf("item-%d", i))
+	}
+
+	data, err := json.Marshal(filter)
+	require.NoError(t, err)
+	var decoded Filter
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	for i := 0; i < 1000; i++ {
+		require.True(t, decoded.Test(fmt.Sprintf("item-%d", i)))
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		if decoded.Test(fmt.Sprintf("other-%d", i)) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 50)
+}
+
+func TestEmptyFilter(t *testing.T) {
+	var filter *Filter
+	require.False(t, filter.Test("item"))
+	require.False(t, New(0, 0.01).Test("item"))
+}
@@ -0,0 +1,56 @@
+//go:build unit || !integration
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	// a wednesday
+	from := time.Date(2023, time.March, 15, 10, 30, 45, 0, time.UTC)
+	for _, test := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2023, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{expr: "@hourly", expected: time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{expr: "*/20 * * * *", expected: time.Date(2023, time.March, 15, 10, 40, 0, 0, time.UTC)},
+		{expr: "15,45 9-17 * * *", expected: time.Date(2023, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{expr: "0 9 * * mon-fri", expected: time.Date(2023, time.March, 16, 9, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", expected: time.Date(2023, time.March, 19, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", expected: time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 feb *", expected: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// both day fields are restricted, so either of them matching is enough
+		{expr: "0 0 1 * fri", expected: time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", expected: time.Time{}},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			schedule, err := Parse(test.expr)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+	}
+}